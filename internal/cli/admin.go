@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"os"
 
-	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/notifications"
 	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/workflow"
 	"github.com/spf13/cobra"
 )
@@ -30,16 +29,15 @@ var subscribedProjectsCommand = &cobra.Command{
 	Use:   "list-subscribed-projects",
 	Short: "List all the projects with Snapsentry subscription tags. This is only for adminstators for review",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		webhookProvider := notifications.Webhook{
-			URL:      webhookURL,
-			Username: webhookUsername,
-			Password: webhookPassword,
+		webhook, err := webhookProvider()
+		if err != nil {
+			return err
 		}
 
 		return workflow.RunAdminProjectDisoceryWorkflow(
 			cloudProfile,
 			timeout,
-			webhookProvider,
+			webhook,
 			logLevel,
 		)
 	},
@@ -65,19 +63,18 @@ var orchestratorCommand = &cobra.Command{
 
 		return nil
 	},
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 
-		webhookProvider := notifications.Webhook{
-			URL:      webhookURL,
-			Username: webhookUsername,
-			Password: webhookPassword,
+		webhook, err := webhookProvider()
+		if err != nil {
+			return err
 		}
 
-		workflow.RunKubeOperatorWorkflow(
+		return workflow.RunKubeOperatorWorkflow(
 			"snapsentry",
 			cloudProfile,
 			timeout,
-			webhookProvider,
+			webhook,
 			logLevel,
 			kubeconfig,
 			incluster,
@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+var (
+	policyFile  string
+	policyPrune bool
+)
+
+var applyCommand = &cobra.Command{
+	Use:     "apply",
+	Short:   "Reconcile volume policies with a policy document",
+	Long:    `Reads a declarative policy document (YAML or JSON) that maps volume selectors to snapshot policies, compares it with the x-snapsentry-* metadata of the volumes in the project, prints the plan and applies it. With --prune, express, cron, daily, weekly, monthly and GFS settings that the document does not assign are removed. With --dry-run, only the plan is printed, e.g. to review a change in a pull request.`,
+	GroupID: "snapsentry",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fmt.Println(headerStyle.Render("Snapsentry - Apply Policy Document"))
+		return workflow.ApplyPolicyFile(cloudProfile, logLevel, policyFile, policyPrune, runOptions())
+	},
+}
+
+func init() {
+	applyCommand.Flags().StringVarP(&policyFile, "filename", "f", "", "Policy document to apply (required)")
+	applyCommand.Flags().BoolVar(&policyPrune, "prune", false, "Remove policies that the policy document does not assign")
+	addDryRunFlags(applyCommand)
+	_ = applyCommand.MarkFlagRequired("filename")
+
+	rootCommand.AddCommand(applyCommand)
+}
@@ -3,7 +3,6 @@ package cli
 import (
 	"fmt"
 
-	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/notifications"
 	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/workflow"
 	"github.com/spf13/cobra"
 )
@@ -12,25 +11,34 @@ var createSnapshotCommand = &cobra.Command{
 	Use:     "create-snapshots",
 	GroupID: "snapsentry",
 	Short:   "Execute the snapshot creation workflow",
-	Long:    `Scans for volumes with enabled policies, evaluates their schedules against the current time, and creates snapshots if required.`,
+	Long:    `Scans for volumes with enabled policies, evaluates their schedules against the current time, and creates snapshots if required. Created snapshots are awaited at the end of the run, up to --creation-timeout (per volume type or backend with --creation-timeout-for); snapshots still creating by then are confirmed or cleaned up by the next run. With --defaults-file, volumes without a policy of their own are evaluated with the default policies of the file. Inside a --blackout window, due snapshots are deferred until the window closes; paused volumes are skipped. With --dry-run, nothing is created; the evaluation of every volume and policy is printed as a plan instead.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		fmt.Println(headerStyle.Render("Snapsentry - Creation Workflow"))
 
-		webhookProvider := notifications.Webhook{
-			URL:      webhookURL,
-			Username: webhookUsername,
-			Password: webhookPassword,
+		notifyProvider, err := notifier()
+		if err != nil {
+			return err
 		}
 
-		return workflow.RunProjectSnapshotWorkflow(
+		_, err = workflow.RunProjectSnapshotWorkflow(
 			cloudProfile,
 			timeout,
-			webhookProvider,
+			notifyProvider,
 			logLevel,
+			runOptions(),
 		)
+		return err
 	},
 }
 
 func init() {
+	addDryRunFlags(createSnapshotCommand)
+	addConcurrencyFlag(createSnapshotCommand)
+	addRateLimitFlag(createSnapshotCommand)
+	addHookFlags(createSnapshotCommand)
+	addDefaultsFlag(createSnapshotCommand)
+	addBlackoutFlags(createSnapshotCommand)
+	addCreationTimeoutFlags(createSnapshotCommand)
+	addReportFlag(createSnapshotCommand)
 	rootCommand.AddCommand(createSnapshotCommand)
 }
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"os"
@@ -8,6 +9,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/metrics"
 	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/notifications"
 	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/workflow"
 	"github.com/go-co-op/gocron-ui/server"
@@ -19,24 +21,59 @@ var (
 	createSchedule string
 	expireSchedule string
 	bindAddress    string
+	digestSchedule string
 )
 
 var daemonCommand = &cobra.Command{
 	Use:     "daemon",
 	Short:   "Run Snapsentry in daemon mode",
 	GroupID: "snapsentry",
-	Long:    `Starts Snapsentry as a background service that continuously manages snapshot creation and expiry based on configured policies.`,
+	Long: `Starts Snapsentry as a background service that continuously manages snapshot creation and expiry based on configured policies.
+With --policy-file, the policy document is re-read and reconciled with the volume metadata before every snapshot creation run.
+With --defaults-file, volumes without a policy of their own inherit the default policies of the file; it is re-read on every run.
+Inside a --blackout window (e.g. storage maintenance), due snapshots are deferred until the window closes.
+The outcome of all runs is aggregated into a digest, sent on --digest-schedule to the notifiers subscribed to the "daily_digest" event.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		banner := fmt.Sprintf("Snapsentry - Daemon Mode \n\nVersion: %s\nBuild Date: %s", SnapsentryVersion, SnapsentryDate)
 		fmt.Println(headerStyle.Render(banner))
 
-		webhookProvider := notifications.Webhook{
-			URL:      webhookURL,
-			Username: webhookUsername,
-			Password: webhookPassword,
+		deliveries, err := notifiers()
+		if err != nil {
+			return err
+		}
+		notifyProvider := combineNotifiers(deliveries)
+
+		opts := runOptions()
+		if err := opts.Validate(); err != nil {
+			return err
+		}
+
+		if policyFile != "" {
+			if err := workflow.ValidatePolicyFile(policyFile); err != nil {
+				return err
+			}
+		}
+		if defaultsFile != "" {
+			if err := workflow.ValidateDefaultsFile(defaultsFile); err != nil {
+				return err
+			}
 		}
 
 		dlog := workflow.SetupLogger(logLevel, cloudProfile).With("component", "daemon")
+		if opts.DryRun {
+			dlog.Warn("Dry-run mode enabled; scheduled runs only print their plan")
+		}
+
+		// Notifications that could not be delivered before the last shutdown are sent first.
+		if notifySpoolDir != "" && !opts.DryRun {
+			result, err := notifications.ReplaySpool(context.Background(), notifySpoolDir, deliveries)
+			if err != nil {
+				dlog.Error("Some spooled notifications could not be replayed; they stay in the spool",
+					"spool_dir", notifySpoolDir, "replayed", result.Replayed, "remaining", result.Failed, "error", err)
+			} else if result.Replayed > 0 {
+				dlog.Info("Spooled notifications replayed", "spool_dir", notifySpoolDir, "replayed", result.Replayed)
+			}
+		}
 
 		s, err := gocron.NewScheduler()
 		if err != nil {
@@ -45,6 +82,8 @@ var daemonCommand = &cobra.Command{
 		s.Start()
 		dlog.Info("Scheduler started", "cloud", cloudProfile)
 
+		digest := workflow.NewDigestCollector(time.Now().UTC())
+
 		// 1. Declare the variable first so it can be used INSIDE the task closure
 		var snapshotJob gocron.Job
 
@@ -55,10 +94,16 @@ var daemonCommand = &cobra.Command{
 				false,
 			),
 			gocron.NewTask(func() {
-				// A. Run the Workflow
-				workflow.RunProjectSnapshotWorkflow(cloudProfile, timeout, webhookProvider, logLevel)
+				// A. Reconcile the policy document, so that the run sees the policies from git
+				if policyFile != "" {
+					_ = workflow.ReconcilePolicyFile(cloudProfile, logLevel, policyFile, policyPrune, opts.DryRun)
+				}
 
-				// B. Calculate and Log the Next Run (Post-Execution)
+				// B. Run the Workflow
+				report, _ := workflow.RunProjectSnapshotWorkflow(cloudProfile, timeout, notifyProvider, logLevel, opts)
+				digest.Add(report)
+
+				// C. Calculate and Log the Next Run (Post-Execution)
 				if snapshotJob != nil {
 					if nextRun, err := snapshotJob.NextRun(); err == nil {
 						dlog.Info("Snapshot Workflow completed",
@@ -93,7 +138,8 @@ var daemonCommand = &cobra.Command{
 			),
 			gocron.NewTask(func() {
 				// A. Run the Workflow
-				workflow.RunProjectSnapshotExpiryWorkflow(cloudProfile, timeout, logLevel, time.Now().UTC(), webhookProvider)
+				report, _ := workflow.RunProjectSnapshotExpiryWorkflow(cloudProfile, timeout, logLevel, time.Now().UTC(), notifyProvider, opts)
+				digest.Add(report)
 
 				// B. Calculate and Log the Next Run (Post-Execution)
 				if expireJob != nil {
@@ -120,9 +166,41 @@ var daemonCommand = &cobra.Command{
 				"next_run", nextRunSnapshot.Format(time.RFC3339))
 		}
 
+		// --- Daily Digest ---
+		if digestSchedule != "" {
+			digestJob, digestErr := s.NewJob(
+				gocron.CronJob(
+					digestSchedule,
+					false,
+				),
+				gocron.NewTask(func() {
+					workflow.SendDigest(digest, notifyProvider, time.Now().UTC(), dlog)
+				}),
+				gocron.WithName("Daily Digest"),
+				gocron.WithSingletonMode(gocron.LimitModeReschedule),
+			)
+			if digestErr != nil {
+				return digestErr
+			}
+
+			if nextRunDigest, err := digestJob.NextRun(); err == nil {
+				dlog.Info("Job Scheduled",
+					"job_name", digestJob.Name(),
+					"job_id", digestJob.ID(),
+					"schedule", digestSchedule,
+					"next_run", nextRunDigest.Format(time.RFC3339))
+			}
+		}
+
 		srv := server.NewServer(s, 8080, server.WithTitle("Snapsentry Go - Dashboard")) // with custom title if you want to customize the title of the UI (optional)
-		dlog.Info("Snapsentry Scheduler UI started", "address", bindAddress)
-		if err := http.ListenAndServe(bindAddress, srv.Router); err != nil {
+
+		// Prometheus metrics are served next to the dashboard on the same address.
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics.Handler())
+		mux.Handle("/", srv.Router)
+
+		dlog.Info("Snapsentry Scheduler UI started", "address", bindAddress, "metrics_path", "/metrics")
+		if err := http.ListenAndServe(bindAddress, mux); err != nil {
 			dlog.Error("Failed to start UI server", "error", err)
 			return s.Shutdown()
 		}
@@ -138,8 +216,19 @@ var daemonCommand = &cobra.Command{
 }
 
 func init() {
+	addDryRunFlags(daemonCommand)
+	addConcurrencyFlag(daemonCommand)
+	addRateLimitFlag(daemonCommand)
+	addHookFlags(daemonCommand)
+	addDefaultsFlag(daemonCommand)
+	addBlackoutFlags(daemonCommand)
+	addCreationTimeoutFlags(daemonCommand)
+	addStuckSnapshotFlag(daemonCommand)
 	rootCommand.AddCommand(daemonCommand)
 	daemonCommand.Flags().StringVar(&createSchedule, "create-schedule", "*/10 * * * *", "Cron schedule for snapshot creation")
 	daemonCommand.Flags().StringVar(&expireSchedule, "expire-schedule", "0 */6 * * *", "Cron schedule for snapshot expiration")
-	daemonCommand.Flags().StringVar(&bindAddress, "bind-address", "0.0.0.0:8080", "Address to bind the UI server")
+	daemonCommand.Flags().StringVar(&digestSchedule, "digest-schedule", "0 8 * * *", "Cron schedule for the digest notification (empty to disable)")
+	daemonCommand.Flags().StringVar(&policyFile, "policy-file", "", "Policy document reconciled before every snapshot creation run (see 'apply')")
+	daemonCommand.Flags().BoolVar(&policyPrune, "policy-prune", false, "Remove policies that the --policy-file does not assign")
+	daemonCommand.Flags().StringVar(&bindAddress, "bind-address", "0.0.0.0:8080", "Address to bind the UI server and the /metrics endpoint")
 }
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/notifications"
 	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/workflow"
 	"github.com/spf13/cobra"
 )
@@ -13,24 +12,30 @@ var expireSnapshotCommand = &cobra.Command{
 	Use:     "expire-snapshots",
 	GroupID: "snapsentry",
 	Short:   "Execute the snapshot expiry workflow",
-	Long:    `Scans all managed snapshots in the project, compares their stored expiry dates against the current UTC time, and permanently deletes those that have exceeded their retention period.`,
+	Long:    `Scans all managed snapshots in the project, compares their stored expiry dates against the current UTC time, and permanently deletes those that have exceeded their retention period. Managed snapshots stuck in error or creating for longer than --stuck-snapshot-age are deleted as well (force delete if needed) and reported through the snapshot_stuck notification. With --dry-run, nothing is deleted; the snapshots that would be deleted are printed as a plan instead.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		fmt.Println(headerStyle.Render("Snapsentry - Expiry Workflow"))
-		webhookProvider := notifications.Webhook{
-			URL:      webhookURL,
-			Username: webhookUsername,
-			Password: webhookPassword,
+		notifyProvider, err := notifier()
+		if err != nil {
+			return err
 		}
-		return workflow.RunProjectSnapshotExpiryWorkflow(
+		_, err = workflow.RunProjectSnapshotExpiryWorkflow(
 			cloudProfile,
 			timeout,
 			logLevel,
 			time.Now().UTC(),
-			webhookProvider,
+			notifyProvider,
+			runOptions(),
 		)
+		return err
 	},
 }
 
 func init() {
+	addDryRunFlags(expireSnapshotCommand)
+	addRateLimitFlag(expireSnapshotCommand)
+	addDefaultsFlag(expireSnapshotCommand)
+	addReportFlag(expireSnapshotCommand)
+	addStuckSnapshotFlag(expireSnapshotCommand)
 	rootCommand.AddCommand(expireSnapshotCommand)
 }
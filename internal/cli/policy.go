@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+var policyCommand = &cobra.Command{
+	Use:     "policy",
+	Short:   "Inspect the snapshot policies of volumes",
+	Long:    `Shows the snapshot policies configured in volume metadata, as SnapSentry reads them.`,
+	GroupID: "snapsentry",
+}
+
+var policyShowCommand = &cobra.Command{
+	Use:   "show",
+	Short: "Shows the policies of a volume",
+	Long:  `Prints every snapshot policy configured on the target volume after normalization (defaults applied), with its current and next window, and the other SnapSentry settings of the volume. Invalid policies are listed with the reason they are skipped.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fmt.Println(headerStyle.Render("Snapsentry - Volume Policies"))
+		return workflow.ShowVolumePolicies(cloudProfile, logLevel, volumeID, outputFormat)
+	},
+}
+
+var policyListCommand = &cobra.Command{
+	Use:   "list",
+	Short: "Lists every subscribed volume of the project",
+	Long:  `Lists every volume of the project carrying the x-snapsentry-managed tag, with a compact summary of its enabled policies and settings.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fmt.Println(headerStyle.Render("Snapsentry - Subscribed Volumes"))
+		return workflow.ListVolumePolicies(cloudProfile, logLevel, outputFormat)
+	},
+}
+
+func init() {
+	policyCommand.PersistentFlags().StringVarP(&outputFormat, "output", "o", workflow.OutputFormatTable, "Output format (table, json, yaml)")
+
+	// Flags specific to 'policy show'
+	policyShowCommand.Flags().StringVar(&volumeID, "volume-id", "", "UUID of the OpenStack volume (required)")
+	_ = policyShowCommand.MarkFlagRequired("volume-id")
+
+	rootCommand.AddCommand(policyCommand)
+	policyCommand.AddCommand(policyShowCommand)
+	policyCommand.AddCommand(policyListCommand)
+}
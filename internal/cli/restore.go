@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+// Flags for restore sub-commands
+var (
+	restoreSnapshotID   string
+	restorePolicy       string
+	restoreBefore       string
+	restoreVolumeName   string
+	restoreVolumeSize   int
+	restoreVolumeType   string
+	restoreAZ           string
+	restoreReason       string
+	restoreConfirmation bool
+)
+
+var restoreCommand = &cobra.Command{
+	Use:     "restore",
+	Short:   "Restore volumes from managed snapshots",
+	Long:    `Lists the managed snapshots of a volume and restores one of them, either to a new volume or by reverting the volume in place. Each restore is recorded in the metadata of the restored volume (x-snapsentry-restore-* tags) for audit.`,
+	GroupID: "snapsentry",
+}
+
+var restoreListCommand = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the restore points of a volume",
+	Long:  `Lists the managed snapshots of the target volume, newest first, with the policy, the policy window, the retention and the expiry date stored in their metadata. With --policy, only snapshots covering that policy are listed (including GFS snapshots of a higher tier).`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fmt.Println(headerStyle.Render("Snapsentry - Restore Points"))
+		return workflow.ListRestorePoints(cloudProfile, logLevel, volumeID, restorePolicy, outputFormat)
+	},
+}
+
+var restoreToNewVolumeCommand = &cobra.Command{
+	Use:   "to-new-volume",
+	Short: "Creates a new volume from a managed snapshot",
+	Long:  `Creates a new volume from a snapshot and waits until it is available; the source volume is not touched. The snapshot is either given by --snapshot-id, or it is the newest managed snapshot of --volume-id, optionally restricted to a policy (--policy daily) and to snapshots taken before a point in time (--before 2026-03-01T00:00:00Z).`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fmt.Println(headerStyle.Render("Snapsentry - Restore to New Volume"))
+		opts, err := restoreOptions()
+		if err != nil {
+			return err
+		}
+		return workflow.RestoreToNewVolume(cloudProfile, logLevel, timeout, opts)
+	},
+}
+
+var restoreRevertCommand = &cobra.Command{
+	Use:   "revert",
+	Short: "Reverts a volume in place to a managed snapshot",
+	Long:  `Reverts the target volume in place to a snapshot, discarding everything written after it. Cinder only reverts a detached volume to its most recent snapshot, on backends that support it (API microversion 3.40). Without --yes, the plan is printed and nothing is changed.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fmt.Println(headerStyle.Render("Snapsentry - Revert to Snapshot"))
+		opts, err := restoreOptions()
+		if err != nil {
+			return err
+		}
+		return workflow.RevertVolume(cloudProfile, logLevel, timeout, opts, restoreConfirmation)
+	},
+}
+
+// restoreOptions builds the snapshot selection and the new volume properties from the restore flags.
+func restoreOptions() (workflow.RestoreOptions, error) {
+	opts := workflow.RestoreOptions{
+		VolumeID:         volumeID,
+		SnapshotID:       restoreSnapshotID,
+		PolicyType:       restorePolicy,
+		Name:             restoreVolumeName,
+		Size:             restoreVolumeSize,
+		VolumeType:       restoreVolumeType,
+		AvailabilityZone: restoreAZ,
+		Reason:           restoreReason,
+	}
+	if restoreBefore != "" {
+		before, err := time.Parse(time.RFC3339, restoreBefore)
+		if err != nil {
+			return opts, fmt.Errorf("invalid --before '%s'; expected RFC3339, e.g. 2026-03-01T00:00:00Z: %w", restoreBefore, err)
+		}
+		opts.Before = before
+	}
+	return opts, nil
+}
+
+// addSnapshotSelectionFlags registers the flags choosing the snapshot to restore.
+func addSnapshotSelectionFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&volumeID, "volume-id", "", "UUID of the volume whose snapshots are restored")
+	cmd.Flags().StringVar(&restoreSnapshotID, "snapshot-id", "", "UUID of the snapshot to restore (default: the newest matching managed snapshot of --volume-id)")
+	cmd.Flags().StringVar(&restorePolicy, "policy", "", "Only consider snapshots covering this policy (express, cron, daily, weekly, monthly)")
+	cmd.Flags().StringVar(&restoreBefore, "before", "", "Only consider snapshots created before this RFC3339 timestamp")
+	cmd.Flags().StringVar(&restoreReason, "reason", "", "Reason recorded in the audit metadata, e.g. a ticket number")
+}
+
+func init() {
+	// Flags specific to 'restore list'
+	restoreListCommand.Flags().StringVar(&volumeID, "volume-id", "", "UUID of the OpenStack volume (required)")
+	restoreListCommand.Flags().StringVar(&restorePolicy, "policy", "", "Only list snapshots covering this policy")
+	restoreListCommand.Flags().StringVarP(&outputFormat, "output", "o", workflow.OutputFormatTable, "Output format (table, json, yaml)")
+	_ = restoreListCommand.MarkFlagRequired("volume-id")
+
+	// Flags specific to 'restore to-new-volume'
+	addSnapshotSelectionFlags(restoreToNewVolumeCommand)
+	restoreToNewVolumeCommand.Flags().StringVar(&restoreVolumeName, "name", "", "Name of the new volume (default 'restored-<snapshot name>')")
+	restoreToNewVolumeCommand.Flags().IntVar(&restoreVolumeSize, "size", 0, "Size of the new volume in GB (default: the snapshot size)")
+	restoreToNewVolumeCommand.Flags().StringVar(&restoreVolumeType, "volume-type", "", "Volume type of the new volume (default: the Cinder default)")
+	restoreToNewVolumeCommand.Flags().StringVar(&restoreAZ, "availability-zone", "", "Availability zone of the new volume (default: the Cinder default)")
+	restoreToNewVolumeCommand.MarkFlagsOneRequired("volume-id", "snapshot-id")
+
+	// Flags specific to 'restore revert'
+	addSnapshotSelectionFlags(restoreRevertCommand)
+	restoreRevertCommand.Flags().BoolVar(&restoreConfirmation, "yes", false, "Confirm the revert; without it only the plan is printed")
+	_ = restoreRevertCommand.MarkFlagRequired("volume-id")
+
+	rootCommand.AddCommand(restoreCommand)
+	restoreCommand.AddCommand(restoreListCommand)
+	restoreCommand.AddCommand(restoreToNewVolumeCommand)
+	restoreCommand.AddCommand(restoreRevertCommand)
+}
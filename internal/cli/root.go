@@ -2,7 +2,11 @@ package cli
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/notifications"
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/workflow"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -13,6 +17,26 @@ var (
 	webhookURL             string
 	webhookUsername        string
 	webhookPassword        string
+	webhookBearerToken     string
+	webhookHeaders         []string
+	webhookEvents          []string
+	notifiersConfig        string
+	notifyMaxRetries       int
+	notifyDedupPeriod      time.Duration
+	notifySpoolDir         string
+	dryRun                 bool
+	outputFormat           string
+	reportPath             string
+	concurrency            int
+	rateLimit              float64
+	hookDir                string
+	allowHTTPHooks         bool
+	defaultsFile           string
+	blackouts              []string
+	blackoutTimeZone       string
+	creationTimeout        time.Duration
+	creationTimeouts       []string
+	stuckSnapshotAge       time.Duration
 )
 
 var rootCommand = &cobra.Command{
@@ -40,6 +64,160 @@ and automatically manages the lifecycle (creation and expiry) of those snapshots
 Author: Aravindh Murugesan`,
 }
 
+// addDryRunFlags registers the plan mode flags on a workflow command.
+func addDryRunFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Evaluate policies and print the plan without creating or deleting snapshots")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", workflow.OutputFormatTable, "Plan output format for --dry-run (table, json, yaml)")
+}
+
+// addReportFlag registers the run report flag on a one-off workflow command.
+func addReportFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&reportPath, "report", "", "Write a JSON run report to this file ('-' for stdout)")
+}
+
+// addConcurrencyFlag registers the worker pool size on a command running the snapshot workflow.
+func addConcurrencyFlag(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&concurrency, "concurrency", 1, "Number of VM groups and standalone volumes processed in parallel")
+}
+
+// addRateLimitFlag registers the client-side API rate limit on a workflow command.
+func addRateLimitFlag(cmd *cobra.Command) {
+	cmd.Flags().Float64Var(&rateLimit, "rate-limit", 0, "Maximum OpenStack API requests per second, shared by all workers (0 = unlimited)")
+}
+
+// addHookFlags registers what quiesce hooks may run on a command running the snapshot workflow.
+func addHookFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&hookDir, "hook-dir", "", "Directory of the executables that quiesce hooks may run (empty = command hooks disabled)")
+	cmd.Flags().BoolVar(&allowHTTPHooks, "allow-http-hooks", false, "Allow quiesce hooks to call http(s) URLs")
+}
+
+// addDefaultsFlag registers the project defaults file on a command running the snapshot or expiry workflow.
+func addDefaultsFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&defaultsFile, "defaults-file", "", "Default policies for volumes without a policy of their own (YAML or JSON)")
+}
+
+// addBlackoutFlags registers the project blackout windows on a command running the snapshot workflow.
+func addBlackoutFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&blackouts, "blackout", nil, "Blackout window without snapshot creation: '<weekday|daily> HH:MM-HH:MM' or '<RFC3339>/<RFC3339>' (repeatable)")
+	cmd.Flags().StringVar(&blackoutTimeZone, "blackout-timezone", "UTC", "Timezone of the recurring --blackout windows")
+}
+
+// addCreationTimeoutFlags registers how long the snapshot workflow waits for created snapshots.
+func addCreationTimeoutFlags(cmd *cobra.Command) {
+	cmd.Flags().DurationVar(&creationTimeout, "creation-timeout", 10*time.Minute, "How long to wait for a created snapshot to become available before leaving it to the next run")
+	cmd.Flags().StringArrayVar(&creationTimeouts, "creation-timeout-for", nil, "Creation timeout of a volume type or backend: '<volume type|backend>=<duration>' (repeatable)")
+}
+
+// addStuckSnapshotFlag registers the stuck snapshot cleanup on a command running the expiry workflow.
+func addStuckSnapshotFlag(cmd *cobra.Command) {
+	cmd.Flags().DurationVar(&stuckSnapshotAge, "stuck-snapshot-age", 24*time.Hour, "Delete managed snapshots stuck in error or creating for longer than this (0 disables)")
+}
+
+// runOptions builds the workflow options from the command line flags.
+func runOptions() workflow.RunOptions {
+	return workflow.RunOptions{
+		DryRun:           dryRun,
+		OutputFormat:     outputFormat,
+		ReportPath:       reportPath,
+		Concurrency:      concurrency,
+		RateLimit:        rateLimit,
+		HookDir:          hookDir,
+		AllowHTTPHooks:   allowHTTPHooks,
+		DefaultsFile:     defaultsFile,
+		Blackouts:        blackouts,
+		BlackoutTimeZone: blackoutTimeZone,
+		CreationTimeout:  creationTimeout,
+		CreationTimeouts: creationTimeouts,
+		StuckSnapshotAge: stuckSnapshotAge,
+	}
+}
+
+// webhookProvider builds the generic webhook from the --webhook-* flags.
+func webhookProvider() (notifications.Webhook, error) {
+	headers := map[string]string{}
+	for _, h := range webhookHeaders {
+		key, value, ok := strings.Cut(h, ":")
+		if !ok || strings.TrimSpace(key) == "" {
+			return notifications.Webhook{}, fmt.Errorf("invalid webhook header '%s'; expected 'Name: value'", h)
+		}
+		headers[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+
+	return notifications.Webhook{
+		URL:         webhookURL,
+		Username:    webhookUsername,
+		Password:    webhookPassword,
+		BearerToken: webhookBearerToken,
+		Headers:     headers,
+	}, nil
+}
+
+// notifiers builds the notifiers of the --webhook-* flags and the --notifiers-config file,
+// each wrapped with retries, deduplication and the dead-letter spool.
+func notifiers() ([]*notifications.Delivery, error) {
+	var configured []notifications.Notifier
+
+	webhook, err := webhookProvider()
+	if err != nil {
+		return nil, err
+	}
+	if webhook.URL != "" {
+		filtered, err := notifications.WithEvents(&webhook, webhookEvents)
+		if err != nil {
+			return nil, fmt.Errorf("--webhook-events: %w", err)
+		}
+		configured = append(configured, filtered)
+	}
+
+	if notifiersConfig != "" {
+		fromFile, err := notifications.LoadConfig(notifiersConfig)
+		if err != nil {
+			return nil, err
+		}
+		configured = append(configured, fromFile...)
+	}
+
+	retry := notifications.DefaultRetryConfig
+	retry.MaxRetries = notifyMaxRetries
+
+	deliveries := make([]*notifications.Delivery, 0, len(configured))
+	names := map[string]bool{}
+	for _, n := range configured {
+		// Spooled events are matched back to their notifier by name on replay.
+		if names[n.Name()] {
+			return nil, fmt.Errorf("duplicate notifier name '%s'; give each notifier a unique name", n.Name())
+		}
+		names[n.Name()] = true
+		deliveries = append(deliveries, notifications.NewDelivery(n, retry, notifyDedupPeriod, notifySpoolDir))
+	}
+	return deliveries, nil
+}
+
+// combineNotifiers returns a single notifier for the workflows, or nil when none is configured.
+func combineNotifiers(deliveries []*notifications.Delivery) notifications.Notifier {
+	switch len(deliveries) {
+	case 0:
+		return nil
+	case 1:
+		return deliveries[0]
+	default:
+		multi := make(notifications.Multi, 0, len(deliveries))
+		for _, d := range deliveries {
+			multi = append(multi, d)
+		}
+		return multi
+	}
+}
+
+// notifier builds and combines the configured notifiers.
+func notifier() (notifications.Notifier, error) {
+	deliveries, err := notifiers()
+	if err != nil {
+		return nil, err
+	}
+	return combineNotifiers(deliveries), nil
+}
+
 func Execute() error {
 	return rootCommand.Execute()
 }
@@ -54,6 +232,13 @@ func init() {
 	rootCommand.PersistentFlags().StringVar(&webhookURL, "webhook-url", "", "Webhook URL for alerting")
 	rootCommand.PersistentFlags().StringVar(&webhookUsername, "webhook-username", "", "Webhook username for alerting")
 	rootCommand.PersistentFlags().StringVar(&webhookPassword, "webhook-password", "", "Webhook password for alerting")
+	rootCommand.PersistentFlags().StringVar(&webhookBearerToken, "webhook-bearer-token", "", "Webhook bearer token for alerting")
+	rootCommand.PersistentFlags().StringArrayVar(&webhookHeaders, "webhook-header", nil, "Additional webhook header as 'Name: value' (repeatable)")
+	rootCommand.PersistentFlags().StringSliceVar(&webhookEvents, "webhook-events", nil, "Event types sent to the webhook ('all' for every type). Defaults to failures only")
+	rootCommand.PersistentFlags().StringVar(&notifiersConfig, "notifiers-config", "", "Path to a notifiers file (webhook, slack, mattermost, teams, email, pagerduty)")
+	rootCommand.PersistentFlags().IntVar(&notifyMaxRetries, "notify-max-retries", notifications.DefaultRetryConfig.MaxRetries, "Retries of a failed notification delivery")
+	rootCommand.PersistentFlags().DurationVar(&notifyDedupPeriod, "notify-dedup-period", 6*time.Hour, "Suppress repeated notifications for the same volume and window within this period (0 disables)")
+	rootCommand.PersistentFlags().StringVar(&notifySpoolDir, "notify-spool-dir", "", "Directory for notifications that could not be delivered; the daemon replays them on startup")
 	// Bind to env vars
 	_ = viper.BindPFlag("cloud", rootCommand.PersistentFlags().Lookup("cloud"))
 	_ = viper.BindPFlag("timeout", rootCommand.PersistentFlags().Lookup("timeout"))
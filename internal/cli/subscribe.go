@@ -2,6 +2,8 @@ package cli
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/workflow"
 	"github.com/spf13/cobra"
@@ -9,20 +11,41 @@ import (
 
 // Flags for subscribe sub-commands
 var (
-	volumeID      string
-	enablePolicy  bool
-	retentionDays int
-	startTime     string
-	timeZone      string
-	weekDay       string // Weekly only
-	dayOfMonth    int    // Monthly only
-	intervalHours int    // Express only
+	volumeID        string
+	enablePolicy    bool
+	retentionDays   int
+	retentionCount  int
+	startTime       string
+	timeZone        string
+	weekDay         string        // Weekly only
+	dayOfMonth      int           // Monthly only
+	intervalHours   int           // Express only
+	intervalMinutes int           // Express only
+	cronExpression  string        // Cron only
+	groupType       string        // Group snapshot only
+	preHook         string        // Hooks only
+	postHook        string        // Hooks only
+	hookTimeout     int           // Hooks only
+	backupPolicy    string        // Backup only
+	incremental     bool          // Backup only
+	container       string        // Backup only
+	pauseUntil      string        // Pause only
+	pauseFor        time.Duration // Pause only
+	pauseReason     string        // Pause only
+
+	selectName         string   // Bulk subscription only
+	selectMetadata     []string // Bulk subscription only
+	selectServer       string   // Bulk subscription only
+	selectVolumeType   string   // Bulk subscription only
+	selectAZ           string   // Bulk subscription only
+	subscribeConfirmed bool     // Bulk subscription only
+	subscribeWorkers   int      // Bulk subscription only
 )
 
 var subscribeCommand = &cobra.Command{
 	Use:     "subscribe",
 	Short:   "Configure snapshot policies for a volume",
-	Long:    `Updates the metadata of a specific OpenStack volume to attach Daily, Weekly, or Monthly snapshot schedules. It validates the provided configuration (e.g., time formats, retention periods) and applies the changes immediately.`,
+	Long:    `Updates the metadata of a specific OpenStack volume to attach Express, Cron, Daily, Weekly, or Monthly snapshot schedules. It validates the provided configuration (e.g., time formats, retention periods) and applies the changes immediately. Instead of --volume-id, many volumes can be selected at once by name pattern, metadata, attached server, volume type or availability zone; the matched volumes are previewed and only updated with --yes.`,
 	GroupID: "snapsentry",
 }
 
@@ -32,8 +55,12 @@ var subscribeDailyCommand = &cobra.Command{
 	Long:  `Configures the target volume with a daily snapshot policy. This command updates the volume's metadata to enable daily backups, setting the specific retention period (in days) and the precise time of day (HH:MM) for the snapshot trigger.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		fmt.Println(headerStyle.Render("Snapsentry - Daily Subscription"))
+		target, err := subscriptionTarget(cmd)
+		if err != nil {
+			return err
+		}
 		return workflow.SubscribeVolumeDaily(
-			cloudProfile, logLevel, volumeID, enablePolicy, retentionDays, startTime, timeZone,
+			cloudProfile, logLevel, target, enablePolicy, retentionDays, retentionCount, startTime, timeZone,
 		)
 	},
 }
@@ -44,8 +71,12 @@ var subscribeWeeklyCmd = &cobra.Command{
 	Long:  `Configures the target volume with a weekly snapshot policy. This command updates the volume's metadata to enable weekly backups, allowing you to specify the exact day of the week (e.g., "Sunday"), the retention period, and the execution time.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		fmt.Println(headerStyle.Render("Snapsentry - Weekly Subscription"))
+		target, err := subscriptionTarget(cmd)
+		if err != nil {
+			return err
+		}
 		return workflow.SubscribeVolumeWeekly(
-			cloudProfile, logLevel, volumeID, enablePolicy, retentionDays, startTime, timeZone, weekDay,
+			cloudProfile, logLevel, target, enablePolicy, retentionDays, retentionCount, startTime, timeZone, weekDay,
 		)
 	},
 }
@@ -56,8 +87,12 @@ var subscribeMonthlyCmd = &cobra.Command{
 	Long:  `Configures the target volume with a monthly snapshot policy. This command updates the volume's metadata to enable monthly backups, allowing you to specify the calendar day (1-31) for execution, along with the retention period and start time.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		fmt.Println(headerStyle.Render("Snapsentry - Monthly Subscription"))
+		target, err := subscriptionTarget(cmd)
+		if err != nil {
+			return err
+		}
 		return workflow.SubscribeVolumeMonthly(
-			cloudProfile, logLevel, volumeID, enablePolicy, retentionDays, startTime, timeZone, dayOfMonth,
+			cloudProfile, logLevel, target, enablePolicy, retentionDays, retentionCount, startTime, timeZone, dayOfMonth,
 		)
 	},
 }
@@ -65,36 +100,190 @@ var subscribeMonthlyCmd = &cobra.Command{
 var subscribeExpressCmd = &cobra.Command{
 	Use:   "express",
 	Short: "Applies an express snapshot policy",
-	Long:  `Configures the target volume with an express (high-frequency) snapshot policy. This divides the day into fixed time buckets (e.g., every 6 hours) starting from midnight in the specified timezone. Valid intervals are 6, 8, or 12 hours.`,
+	Long:  `Configures the target volume with an express (high-frequency) snapshot policy. This divides the day into fixed time buckets (e.g., every 4 hours) starting from the anchor time (midnight by default) in the specified timezone. The interval must divide 24 hours: 1, 2, 3, 4, 6, 8 or 12 hours, or a minute based interval such as 15 or 30 minutes.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		fmt.Println(headerStyle.Render("Snapsentry - Express Subscription"))
+		target, err := subscriptionTarget(cmd)
+		if err != nil {
+			return err
+		}
 
 		return workflow.SubscribeVolumeExpress(
 			cloudProfile,
 			logLevel,
-			volumeID,
+			target,
 			enablePolicy,
 			retentionDays,
+			retentionCount,
 			timeZone,
 			intervalHours,
+			intervalMinutes,
+			startTime,
+		)
+	},
+}
+
+var subscribeCronCmd = &cobra.Command{
+	Use:   "cron",
+	Short: "Applies a cron expression snapshot schedule",
+	Long:  `Configures the target volume with a snapshot policy driven by a standard 5-field cron expression (minute hour day-of-month month day-of-week), e.g. "0 2,14 * * 1-5" for weekdays at 02:00 and 14:00. The expression is evaluated in the specified timezone; each fire time opens a window that lasts until the next one.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fmt.Println(headerStyle.Render("Snapsentry - Cron Subscription"))
+		target, err := subscriptionTarget(cmd)
+		if err != nil {
+			return err
+		}
+		return workflow.SubscribeVolumeCron(
+			cloudProfile, logLevel, target, enablePolicy, retentionDays, retentionCount, cronExpression, timeZone,
 		)
 	},
 }
 
+var subscribeGFSCmd = &cobra.Command{
+	Use:   "gfs",
+	Short: "Enables Grandfather-Father-Son promotion across policies",
+	Long:  `Configures the target volume to treat its daily, weekly and monthly policies as one Grandfather-Father-Son hierarchy. Only one snapshot is taken per window: a snapshot that satisfies several tiers is labelled with the highest one, and a daily snapshot taken inside the weekly (or monthly) window is promoted instead of creating a duplicate.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fmt.Println(headerStyle.Render("Snapsentry - GFS Subscription"))
+		target, err := subscriptionTarget(cmd)
+		if err != nil {
+			return err
+		}
+		return workflow.SubscribeVolumeGFS(cloudProfile, logLevel, target, enablePolicy)
+	},
+}
+
+var subscribeGroupSnapshotCmd = &cobra.Command{
+	Use:   "group-snapshot",
+	Short: "Snapshots the volume together with the other volumes of its server",
+	Long:  `Opts the target volume into crash-consistent group snapshots. When at least two volumes attached to the same server are opted in, they are placed in a Cinder generic volume group and each due policy window is served by a single group snapshot, so that multi-disk guests (LVM, RAID) are captured at the same point in time. Every member snapshot carries the usual Snapsentry tags. If the backend does not support groups, the volumes are snapshotted one by one.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fmt.Println(headerStyle.Render("Snapsentry - Group Snapshot Subscription"))
+		target, err := subscriptionTarget(cmd)
+		if err != nil {
+			return err
+		}
+		return workflow.SubscribeVolumeGroupSnapshot(cloudProfile, logLevel, target, enablePolicy, groupType)
+	},
+}
+
+var subscribeHooksCmd = &cobra.Command{
+	Use:   "hooks",
+	Short: "Quiesces the guest around the snapshots of its server",
+	Long:  `Configures pre and post snapshot hooks on the target volume. Before the first snapshot of the volume's server is created, the pre hook runs (e.g. fsfreeze through the qemu-guest-agent or a database flush); the post hook runs once all snapshots of the server are done, even if the pre hook or a snapshot failed. A hook is an http(s) URL or the name of an executable in the daemon's --hook-dir. Hooks set in the server metadata take precedence. With --enabled=false the hooks are removed.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fmt.Println(headerStyle.Render("Snapsentry - Quiesce Hook Subscription"))
+		target, err := subscriptionTarget(cmd)
+		if err != nil {
+			return err
+		}
+		return workflow.SubscribeVolumeHooks(cloudProfile, logLevel, target, enablePolicy, preHook, postHook, hookTimeout)
+	},
+}
+
+var subscribeBackupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Exports snapshots of a policy to Cinder backups",
+	Long:  `Configures the target volume to export the newest snapshot of one policy (e.g. weekly) to a Cinder backup, so that restore points survive the loss of the volume backend. Each snapshot is exported once, with the snapshot as source; the backup is kept for --retention days after the snapshot was taken and then deleted by expire-snapshots. With --incremental, backups after the first full backup are incremental.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fmt.Println(headerStyle.Render("Snapsentry - Backup Subscription"))
+		target, err := subscriptionTarget(cmd)
+		if err != nil {
+			return err
+		}
+		return workflow.SubscribeVolumeBackup(cloudProfile, logLevel, target, enablePolicy, backupPolicy, retentionDays, incremental, container)
+	},
+}
+
+var subscribePauseCmd = &cobra.Command{
+	Use:   "pause",
+	Short: "Pauses snapshot creation for the volume",
+	Long:  `Pauses the target volume without touching its policies: no snapshot or backup is created for it until the pause ends (--until or --for) or it is resumed with --enabled=false. Skipped policies are recorded in the run report with the --reason. Existing snapshots still expire.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fmt.Println(headerStyle.Render("Snapsentry - Pause"))
+		target, err := subscriptionTarget(cmd)
+		if err != nil {
+			return err
+		}
+
+		var until time.Time
+		switch {
+		case pauseUntil != "" && pauseFor > 0:
+			return fmt.Errorf("--until and --for cannot be combined")
+		case pauseUntil != "":
+			if until, err = time.Parse(time.RFC3339, pauseUntil); err != nil {
+				return fmt.Errorf("invalid --until '%s'; must be RFC3339 (e.g. 2026-01-31T06:00:00Z)", pauseUntil)
+			}
+		case pauseFor > 0:
+			until = time.Now().Add(pauseFor)
+		}
+		return workflow.SubscribeVolumePause(cloudProfile, logLevel, target, enablePolicy, until, pauseReason)
+	},
+}
+
+// subscriptionTarget builds the target volume, or the volume selector, from the shared subscribe flags.
+func subscriptionTarget(cmd *cobra.Command) (workflow.SubscriptionTarget, error) {
+	target := workflow.SubscriptionTarget{
+		VolumeID: volumeID,
+		Selector: workflow.VolumeSelector{
+			NameRegex:        selectName,
+			Server:           selectServer,
+			VolumeType:       selectVolumeType,
+			AvailabilityZone: selectAZ,
+		},
+		Confirmed:   subscribeConfirmed,
+		Concurrency: subscribeWorkers,
+		Output:      cmd.OutOrStdout(),
+	}
+
+	for _, pair := range selectMetadata {
+		key, value, found := strings.Cut(pair, "=")
+		if !found || key == "" {
+			return target, fmt.Errorf("invalid --select-metadata '%s'; expected key=value", pair)
+		}
+		if target.Selector.Metadata == nil {
+			target.Selector.Metadata = map[string]string{}
+		}
+		target.Selector.Metadata[key] = value
+	}
+
+	return target, target.Validate()
+}
+
+// addRetentionFlags registers the retention flags on a policy sub-command.
+// They are not shared on 'subscribe' itself because volume-level settings (e.g. 'subscribe gfs') have no retention.
+func addRetentionFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&retentionDays, "retention", 0, "Retention period in days (required)")
+	cmd.Flags().IntVar(&retentionCount, "retention-count", 0, "Keep only the last N snapshots of this policy instead of expiring by age (0 = time based retention)")
+	_ = cmd.MarkFlagRequired("retention")
+}
+
 func init() {
 
 	// Shared Flags
-	// These flags apply to 'subscribe daily', 'subscribe weekly', and 'subscribe monthly'
-	subscribeCommand.PersistentFlags().StringVar(&volumeID, "volume-id", "", "UUID of the OpenStack volume (required)")
+	// These flags apply to every 'subscribe' sub-command
+	subscribeCommand.PersistentFlags().StringVar(&volumeID, "volume-id", "", "UUID of the OpenStack volume (required unless a --select-* flag is given)")
 	subscribeCommand.PersistentFlags().BoolVar(&enablePolicy, "enabled", true, "Enable or disable this specific policy")
-	subscribeCommand.PersistentFlags().IntVar(&retentionDays, "retention", 0, "Retention period in days (required)")
 	subscribeCommand.PersistentFlags().StringVar(&timeZone, "timezone", "", "Timezone (e.g. 'UTC', 'America/New_York')")
 
-	_ = subscribeCommand.MarkPersistentFlagRequired("volume-id")
-	_ = subscribeCommand.MarkPersistentFlagRequired("retention")
+	// Bulk subscription flags
+	// Every --select-* flag that is given must match; they replace --volume-id
+	subscribeCommand.PersistentFlags().StringVar(&selectName, "select-name", "", "Select the volumes whose name matches this regular expression")
+	subscribeCommand.PersistentFlags().StringSliceVar(&selectMetadata, "select-metadata", nil, "Select the volumes with this metadata key=value (repeatable)")
+	subscribeCommand.PersistentFlags().StringVar(&selectServer, "select-server", "", "Select the volumes attached to this server (ID or name)")
+	subscribeCommand.PersistentFlags().StringVar(&selectVolumeType, "select-volume-type", "", "Select the volumes of this volume type")
+	subscribeCommand.PersistentFlags().StringVar(&selectAZ, "select-availability-zone", "", "Select the volumes in this availability zone")
+	subscribeCommand.PersistentFlags().BoolVar(&subscribeConfirmed, "yes", false, "Apply the subscription to the selected volumes; without it they are only previewed")
+	subscribeCommand.PersistentFlags().IntVar(&subscribeWorkers, "concurrency", 4, "Number of selected volumes updated in parallel")
+
+	for _, cmd := range []*cobra.Command{subscribeDailyCommand, subscribeWeeklyCmd, subscribeMonthlyCmd, subscribeExpressCmd, subscribeCronCmd} {
+		addRetentionFlags(cmd)
+	}
 
 	// Flags specific to 'subscribe express'
-	subscribeExpressCmd.PersistentFlags().IntVar(&intervalHours, "interval-hours", 6, "Time interval between snapshots.")
+	subscribeExpressCmd.PersistentFlags().IntVar(&intervalHours, "interval-hours", 6, "Time interval between snapshots. Must divide 24 (1, 2, 3, 4, 6, 8, 12)")
+	subscribeExpressCmd.PersistentFlags().IntVar(&intervalMinutes, "interval-minutes", 0, "Time interval between snapshots in minutes, overrides --interval-hours (e.g. 15, 30)")
+	subscribeExpressCmd.PersistentFlags().StringVar(&startTime, "start-time", "00:00", "Anchor time of the first slot in HH:MM format")
 
 	// Flags specific to 'subscribe daily'
 	subscribeDailyCommand.PersistentFlags().StringVar(&startTime, "start-time", "", "Snapshot trigger time in HH:MM format (required)")
@@ -112,9 +301,39 @@ func init() {
 	_ = subscribeMonthlyCmd.MarkFlagRequired("month-day")
 	_ = subscribeMonthlyCmd.MarkPersistentFlagRequired("start-time")
 
+	// Flags specific to 'subscribe cron'
+	subscribeCronCmd.Flags().StringVar(&cronExpression, "expression", "", "Cron expression in 5-field format, e.g. '0 2,14 * * 1-5' (required)")
+	_ = subscribeCronCmd.MarkFlagRequired("expression")
+
+	// Flags specific to 'subscribe group-snapshot'
+	subscribeGroupSnapshotCmd.Flags().StringVar(&groupType, "group-type", "", "Cinder group type of the volume group (default 'default_group_type')")
+
+	// Flags specific to 'subscribe hooks'
+	subscribeHooksCmd.Flags().StringVar(&preHook, "pre", "", "Hook run before the snapshots: an http(s) URL or an executable in --hook-dir, with optional arguments")
+	subscribeHooksCmd.Flags().StringVar(&postHook, "post", "", "Hook run after the snapshots: an http(s) URL or an executable in --hook-dir, with optional arguments")
+	subscribeHooksCmd.Flags().IntVar(&hookTimeout, "hook-timeout", 60, "Timeout of each hook in seconds")
+
+	// Flags specific to 'subscribe backup'
+	subscribeBackupCmd.Flags().StringVar(&backupPolicy, "policy", "weekly", "Policy whose snapshots are exported (express, cron, daily, weekly, monthly)")
+	subscribeBackupCmd.Flags().IntVar(&retentionDays, "retention", 0, "Retention period of the backups in days (required)")
+	subscribeBackupCmd.Flags().BoolVar(&incremental, "incremental", false, "Create incremental backups once a full backup exists")
+	subscribeBackupCmd.Flags().StringVar(&container, "container", "", "Backup container (default: the Cinder backup default)")
+	_ = subscribeBackupCmd.MarkFlagRequired("retention")
+
+	// Flags specific to 'subscribe pause'
+	subscribePauseCmd.Flags().StringVar(&pauseUntil, "until", "", "End of the pause in RFC3339 (default: until resumed)")
+	subscribePauseCmd.Flags().DurationVar(&pauseFor, "for", 0, "Duration of the pause, e.g. 48h (default: until resumed)")
+	subscribePauseCmd.Flags().StringVar(&pauseReason, "reason", "", "Reason recorded with every skipped policy, e.g. a change ticket")
+
 	rootCommand.AddCommand(subscribeCommand)
 	subscribeCommand.AddCommand(subscribeDailyCommand)
 	subscribeCommand.AddCommand(subscribeWeeklyCmd)
 	subscribeCommand.AddCommand(subscribeMonthlyCmd)
 	subscribeCommand.AddCommand(subscribeExpressCmd)
+	subscribeCommand.AddCommand(subscribeCronCmd)
+	subscribeCommand.AddCommand(subscribeGFSCmd)
+	subscribeCommand.AddCommand(subscribeGroupSnapshotCmd)
+	subscribeCommand.AddCommand(subscribeHooksCmd)
+	subscribeCommand.AddCommand(subscribeBackupCmd)
+	subscribeCommand.AddCommand(subscribePauseCmd)
 }
@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/policy"
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+var unsubscribeCommand = &cobra.Command{
+	Use:       "unsubscribe <policy>... | all",
+	Short:     "Remove snapshot policies from a volume",
+	Long:      `Removes the metadata keys of the given policies or settings (express, cron, daily, weekly, monthly, gfs, group-snapshot, hooks, backup, pause) from the target volume, or of all of them with 'all'. Keys are deleted one by one through the Cinder metadata-key API, so unrelated metadata is never rewritten. Once no setting is left, the x-snapsentry-managed tag is removed as well. Existing snapshots are kept and still expire according to their own metadata.`,
+	GroupID:   "snapsentry",
+	Args:      cobra.MatchAll(cobra.MinimumNArgs(1), cobra.OnlyValidArgs),
+	ValidArgs: append(policy.SubscriptionNames(), "all"),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fmt.Println(headerStyle.Render("Snapsentry - Unsubscribe"))
+		return workflow.UnsubscribeVolume(cloudProfile, logLevel, volumeID, args)
+	},
+}
+
+func init() {
+	unsubscribeCommand.Flags().StringVar(&volumeID, "volume-id", "", "UUID of the OpenStack volume (required)")
+	_ = unsubscribeCommand.MarkFlagRequired("volume-id")
+
+	rootCommand.AddCommand(unsubscribeCommand)
+}
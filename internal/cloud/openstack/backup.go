@@ -0,0 +1,136 @@
+package openstack
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/policy"
+	"github.com/gophercloud/gophercloud/v2"
+	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/backups"
+)
+
+// backupMicroversion is the Cinder API microversion used for backups; metadata on backups needs 3.43.
+const backupMicroversion = "3.43"
+
+// backupServiceClient returns a copy of the Block Storage client pinned to the backup microversion.
+func (c *Client) backupServiceClient() *gophercloud.ServiceClient {
+	sc := *c.BlockStorageClient
+	sc.Microversion = backupMicroversion
+	return &sc
+}
+
+// CreateManagedBackup exports a snapshot to a Cinder backup.
+//
+// Behavior:
+//   - Source: The backup is taken from the snapshot, so in-use volumes are not touched.
+//   - Asynchronous: Backups can take hours, so this returns once the request is accepted. Backups still
+//     "creating" are found again by ListManagedBackups on the next run.
+//   - Metadata: Applies the provided backup tags (e.g. Expiry Date, Source Snapshot) at creation time.
+//
+// Returns:
+//   - CreatedBackup: The accepted backup.
+//   - RequestID: The OpenStack tracing ID.
+func (c *Client) CreateManagedBackup(
+	ctx context.Context,
+	volumeID string,
+	snapshotID string,
+	name string,
+	incremental bool,
+	container string,
+	metadata map[string]string,
+) (CreatedBackup backups.Backup, RequestID string, Error error) {
+	sc := c.backupServiceClient()
+
+	var requestID string
+	var createdBackup backups.Backup
+
+	createOperation := func(innerCtx context.Context) error {
+		opts := backups.CreateOpts{
+			VolumeID:    volumeID,
+			SnapshotID:  snapshotID,
+			Name:        name,
+			Description: "Created and managed by Snapsentry",
+			Incremental: incremental,
+			Container:   container,
+			Metadata:    metadata,
+		}
+
+		result := backups.Create(innerCtx, sc, opts)
+		requestID = result.Header.Get("X-Openstack-Request-Id")
+
+		backup, err := result.Extract()
+		if err != nil {
+			return fmt.Errorf("Failed to create backup of snapshot %s - %w (Request ID: %s)", snapshotID, err, requestID)
+		}
+		createdBackup = *backup
+		return nil
+	}
+
+	if err := c.executeWithRetry(ctx, "CreateManagedBackup", createOperation); err != nil {
+		return createdBackup, requestID, err
+	}
+
+	return createdBackup, requestID, nil
+}
+
+// ListManagedBackups retrieves every backup in the project that is managed by SnapSentry, in any status.
+// Like ListManagedSnapshots, it filters client-side on the 'x-snapsentry-managed' tag.
+//
+// Note: Cinder returns backups sorted by creation date (Newest First).
+func (c *Client) ListManagedBackups(ctx context.Context) (ManagedBackups []backups.Backup, Error error) {
+	sc := c.backupServiceClient()
+	var managedBackups []backups.Backup
+
+	listOperation := func(innerCtx context.Context) error {
+		// Reset the slice on retry to avoid duplicates
+		managedBackups = []backups.Backup{}
+
+		pages, err := backups.ListDetail(sc, backups.ListDetailOpts{}).AllPages(innerCtx)
+		if err != nil {
+			return err
+		}
+		all, err := backups.ExtractBackups(pages)
+		if err != nil {
+			return err
+		}
+
+		for _, b := range all {
+			if b.Metadata == nil {
+				continue
+			}
+			metadata := policy.BackupMetadata{}
+			// We ignore errors here; if metadata is missing/malformed, it's simply not a managed backup.
+			_ = metadata.ParseFromMetadata(*b.Metadata)
+
+			if metadata.Managed {
+				managedBackups = append(managedBackups, b)
+			}
+		}
+		return nil
+	}
+
+	if err := c.executeWithRetry(ctx, "ListManagedBackups", listOperation); err != nil {
+		return []backups.Backup{}, fmt.Errorf("failed to list managed backups: %w", err)
+	}
+
+	return managedBackups, nil
+}
+
+// DeleteBackup removes a backup from the backup backend.
+// Like DeleteSnapshot, it returns once the delete request is accepted.
+// Cinder rejects deleting a backup that incremental backups depend on.
+func (c *Client) DeleteBackup(ctx context.Context, backupID string) (RequestID string, Error error) {
+	var requestID string
+
+	deleteOperation := func(innerCtx context.Context) error {
+		result := backups.Delete(innerCtx, c.BlockStorageClient, backupID)
+		requestID = result.Header.Get("X-Openstack-Request-Id")
+		return result.Err
+	}
+
+	if err := c.executeWithRetry(ctx, "DeleteBackup", deleteOperation); err != nil {
+		return requestID, err
+	}
+
+	return requestID, nil
+}
@@ -10,7 +10,9 @@ import (
 	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/cloud"
 	"github.com/gophercloud/gophercloud/v2"
 	"github.com/gophercloud/gophercloud/v2/openstack"
+	"github.com/gophercloud/gophercloud/v2/openstack/identity/v3/tokens"
 	"github.com/gophercloud/utils/v2/openstack/clientconfig"
+	"golang.org/x/time/rate"
 )
 
 // Client manages the connection and service clients for OpenStack interactions.
@@ -20,6 +22,13 @@ type Client struct {
 	ProfileName string
 	// RetryConfig defines the behavior for transient error handling
 	RetryConfig cloud.RetryConfig
+	// RateLimiter optionally throttles every HTTP request (including retries, pagination and polling) made
+	// through this client. It is installed on the provider by NewClient. The client is shared by all workers of
+	// a run, so the limit applies to the run as a whole. Nil disables it.
+	RateLimiter *rate.Limiter
+	// SnapshotIndex optionally serves the managed snapshot listings from memory (see BuildSnapshotIndex).
+	// Like the RateLimiter, it is shared by all workers of a run and kept current by the client. Nil lists from the API.
+	SnapshotIndex *SnapshotIndex
 
 	// Internal service clients
 	ComputeClient      *gophercloud.ServiceClient
@@ -28,6 +37,8 @@ type Client struct {
 
 	Region    string
 	Interface string
+	// ProjectID is the project the token is scoped to (empty for an unscoped or domain scoped token).
+	ProjectID string
 }
 
 // executeWithRetry is a helper to run any operation using the client's retry configuration.
@@ -73,6 +84,7 @@ func (c *Client) NewClient() error {
 				Transport: transport,
 			}
 		}
+		rateLimitHTTPClient(&p.HTTPClient, c.RateLimiter)
 
 		ao, err := clientconfig.AuthOptions(opts)
 		if err != nil {
@@ -135,6 +147,11 @@ func (c *Client) NewClient() error {
 	c.IdentityClient = identity
 	c.Region = cloudConfig.RegionName
 	c.Interface = cloudConfig.EndpointType
+	if result, ok := provider.GetAuthResult().(tokens.CreateResult); ok {
+		if project, err := result.ExtractProject(); err == nil && project != nil {
+			c.ProjectID = project.ID
+		}
+	}
 
 	return nil
 }
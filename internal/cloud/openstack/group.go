@@ -0,0 +1,507 @@
+package openstack
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/policy"
+	"github.com/gophercloud/gophercloud/v2"
+	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/snapshots"
+	"github.com/gophercloud/gophercloud/v2/pagination"
+)
+
+// groupMicroversion is the Cinder API microversion used for generic volume groups.
+// Groups need 3.13, group snapshots 3.14 and listing the member volumes of a group 3.25.
+const groupMicroversion = "3.25"
+
+// VolumeGroup is a Cinder generic volume group.
+type VolumeGroup struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Status  string   `json:"status"`
+	Volumes []string `json:"volumes"`
+}
+
+// GroupSnapshot is a snapshot of every volume of a group, taken at the same point in time.
+type GroupSnapshot struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Status      string `json:"status"`
+	GroupID     string `json:"group_id"`
+}
+
+// groupSnapshotMember is the part of a snapshot needed to map it back to its group snapshot.
+// group_snapshot_id is only returned from microversion 3.14 onwards.
+type groupSnapshotMember struct {
+	ID              string `json:"id"`
+	VolumeID        string `json:"volume_id"`
+	GroupSnapshotID string `json:"group_snapshot_id"`
+}
+
+// groupServiceClient returns a copy of the Block Storage client pinned to the group microversion.
+func (c *Client) groupServiceClient() *gophercloud.ServiceClient {
+	sc := *c.BlockStorageClient
+	sc.Microversion = groupMicroversion
+	return &sc
+}
+
+// EnsureVolumeGroup makes sure a generic volume group with the given name exists and contains exactly volumeIDs.
+//
+// Behavior:
+//   - Lookup: The group is found by name, so every run reuses the group of the same server.
+//   - Creation: A missing group is created with the group type, volume types and availability zone of its members.
+//   - Membership: Volumes that joined the server are added, volumes that left are removed.
+//   - Synchronous Wait: Creation and updates block until the group is "available" again.
+//
+// Returns an error if the backend does not support groups, e.g. when the group type does not include a member's
+// volume type or a volume already belongs to another group.
+func (c *Client) EnsureVolumeGroup(
+	ctx context.Context,
+	name string,
+	groupType string,
+	volumeTypes []string,
+	availabilityZone string,
+	volumeIDs []string,
+) (Group VolumeGroup, RequestID string, Error error) {
+	sc := c.groupServiceClient()
+
+	var requestID string
+	var group VolumeGroup
+
+	ensureOperation := func(innerCtx context.Context) error {
+		// 1. Lookup by name
+		var list struct {
+			Groups []VolumeGroup `json:"groups"`
+		}
+		if _, err := sc.Get(innerCtx, sc.ServiceURL("groups", "detail")+"?list_volume=True", &list, nil); err != nil {
+			return fmt.Errorf("failed to list volume groups: %w", err)
+		}
+
+		idx := slices.IndexFunc(list.Groups, func(g VolumeGroup) bool { return g.Name == name })
+
+		// 2. Create when missing
+		if idx < 0 {
+			body := map[string]any{
+				"name":         name,
+				"description":  "Created and managed by Snapsentry",
+				"group_type":   groupType,
+				"volume_types": volumeTypes,
+			}
+			if availabilityZone != "" {
+				body["availability_zone"] = availabilityZone
+			}
+
+			var created struct {
+				Group VolumeGroup `json:"group"`
+			}
+			resp, err := sc.Post(innerCtx, sc.ServiceURL("groups"), map[string]any{"group": body}, &created, &gophercloud.RequestOpts{
+				OkCodes: []int{202},
+			})
+			if resp != nil {
+				requestID = resp.Header.Get("X-Openstack-Request-Id")
+			}
+			if err != nil {
+				return fmt.Errorf("failed to create volume group %s: %w (Request ID: %s)", name, err, requestID)
+			}
+
+			if err := waitForGroupResource(innerCtx, sc, "groups", "group", created.Group.ID, "available"); err != nil {
+				return fmt.Errorf("failed waiting for volume group %s to become available: %w (Request ID: %s)", created.Group.ID, err, requestID)
+			}
+
+			list.Groups = append(list.Groups, created.Group)
+			idx = len(list.Groups) - 1
+		}
+
+		current := list.Groups[idx]
+
+		// 3. Reconcile membership
+		var add, remove []string
+		for _, id := range volumeIDs {
+			if !slices.Contains(current.Volumes, id) {
+				add = append(add, id)
+			}
+		}
+		for _, id := range current.Volumes {
+			if !slices.Contains(volumeIDs, id) {
+				remove = append(remove, id)
+			}
+		}
+
+		if len(add) > 0 || len(remove) > 0 {
+			reqID, err := updateGroupMembers(innerCtx, sc, current.ID, add, remove)
+			if reqID != "" {
+				requestID = reqID
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		current.Volumes = slices.Clone(volumeIDs)
+		group = current
+		return nil
+	}
+
+	if err := c.executeWithRetry(ctx, "EnsureVolumeGroup", ensureOperation); err != nil {
+		return group, requestID, err
+	}
+
+	return group, requestID, nil
+}
+
+// ListVolumeGroups returns the generic volume groups of the project whose name starts with namePrefix,
+// together with their member volumes.
+func (c *Client) ListVolumeGroups(ctx context.Context, namePrefix string) (Groups []VolumeGroup, Error error) {
+	sc := c.groupServiceClient()
+	var groups []VolumeGroup
+
+	listOperation := func(innerCtx context.Context) error {
+		var list struct {
+			Groups []VolumeGroup `json:"groups"`
+		}
+		if _, err := sc.Get(innerCtx, sc.ServiceURL("groups", "detail")+"?list_volume=True", &list, nil); err != nil {
+			return fmt.Errorf("failed to list volume groups: %w", err)
+		}
+
+		groups = nil
+		for _, g := range list.Groups {
+			if strings.HasPrefix(g.Name, namePrefix) {
+				groups = append(groups, g)
+			}
+		}
+		return nil
+	}
+
+	if err := c.executeWithRetry(ctx, "ListVolumeGroups", listOperation); err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+// RemoveVolumeGroupMembers removes volumes from a generic volume group and blocks until the group is "available"
+// again. The volumes and their snapshots are kept.
+func (c *Client) RemoveVolumeGroupMembers(ctx context.Context, groupID string, volumeIDs []string) (RequestID string, Error error) {
+	sc := c.groupServiceClient()
+	var requestID string
+
+	removeOperation := func(innerCtx context.Context) error {
+		reqID, err := updateGroupMembers(innerCtx, sc, groupID, nil, volumeIDs)
+		requestID = reqID
+		return err
+	}
+
+	if err := c.executeWithRetry(ctx, "RemoveVolumeGroupMembers", removeOperation); err != nil {
+		return requestID, err
+	}
+
+	return requestID, nil
+}
+
+// DeleteVolumeGroup deletes an empty generic volume group. Like DeleteSnapshot, it returns once the request is
+// accepted.
+//
+// Cinder refuses to delete a group that still has member volumes or group snapshots.
+func (c *Client) DeleteVolumeGroup(ctx context.Context, groupID string) (RequestID string, Error error) {
+	sc := c.groupServiceClient()
+	var requestID string
+
+	deleteOperation := func(innerCtx context.Context) error {
+		body := map[string]any{"delete": map[string]any{"delete-volumes": false}}
+		resp, err := sc.Post(innerCtx, sc.ServiceURL("groups", groupID, "action"), body, nil, &gophercloud.RequestOpts{
+			OkCodes: []int{202},
+		})
+		if resp != nil {
+			requestID = resp.Header.Get("X-Openstack-Request-Id")
+		}
+		return err
+	}
+
+	if err := c.executeWithRetry(ctx, "DeleteVolumeGroup", deleteOperation); err != nil {
+		return requestID, err
+	}
+
+	return requestID, nil
+}
+
+// updateGroupMembers adds and removes member volumes of a group and waits until the group is "available" again.
+func updateGroupMembers(ctx context.Context, sc *gophercloud.ServiceClient, groupID string, add []string, remove []string) (string, error) {
+	var requestID string
+
+	update := map[string]any{}
+	if len(add) > 0 {
+		update["add_volumes"] = strings.Join(add, ",")
+	}
+	if len(remove) > 0 {
+		update["remove_volumes"] = strings.Join(remove, ",")
+	}
+
+	resp, err := sc.Put(ctx, sc.ServiceURL("groups", groupID), map[string]any{"group": update}, nil, &gophercloud.RequestOpts{
+		OkCodes: []int{202},
+	})
+	if resp != nil {
+		requestID = resp.Header.Get("X-Openstack-Request-Id")
+	}
+	if err != nil {
+		return requestID, fmt.Errorf("failed to update the members of volume group %s: %w (Request ID: %s)", groupID, err, requestID)
+	}
+
+	if err := waitForGroupResource(ctx, sc, "groups", "group", groupID, "available"); err != nil {
+		return requestID, fmt.Errorf("failed waiting for volume group %s to become available: %w (Request ID: %s)", groupID, err, requestID)
+	}
+	return requestID, nil
+}
+
+// CreateGroupSnapshot snapshots every volume of a group at the same point in time and returns as soon as Cinder
+// accepted it. Cinder creates the member snapshots together with the group snapshot, so they are looked up per
+// volume of group.Volumes right away, while still "creating". Waiting is left to AwaitGroupSnapshot.
+//
+// Group snapshots carry no metadata, so the window key (policy.SnapshotWindowKey of the group) is written into
+// the description. Like CreateManagedSnapshot, every create attempt first looks for a group snapshot of the group
+// with that description and adopts it instead of creating a second one, unless it no longer counts for its window.
+//
+// Returns:
+//   - GroupSnapshot: The accepted group snapshot. Its ID is set even if the member lookup failed,
+//     so that the caller can clean it up.
+//   - MemberSnapshotIDs: The member snapshot of every volume of the group, keyed by volume ID.
+//   - RequestID: The OpenStack tracing ID of the create request.
+func (c *Client) CreateGroupSnapshot(ctx context.Context, group VolumeGroup, name string, windowKey string) (
+	CreatedGroupSnapshot GroupSnapshot, MemberSnapshotIDs map[string]string, RequestID string, Error error,
+) {
+	sc := c.groupServiceClient()
+
+	var requestID string
+	var groupSnapshot GroupSnapshot
+	members := map[string]string{}
+
+	createOperation := func(innerCtx context.Context) error {
+		description := groupSnapshotDescription(windowKey)
+
+		// 1. Idempotency Check
+		// Adopts the group snapshot of this window, e.g. one created by an earlier attempt whose response was lost.
+		if groupSnapshot.ID == "" && windowKey != "" {
+			existing, found, err := findWindowGroupSnapshot(innerCtx, sc, group.ID, description)
+			if err != nil {
+				return fmt.Errorf("failed to look up group snapshot %s by its window key before creating it: %w", name, err)
+			}
+			if found {
+				groupSnapshot = existing
+			}
+		}
+
+		// 2. Trigger Creation
+		// A retry after a failed lookup must not create a second group snapshot.
+		if groupSnapshot.ID == "" {
+			body := map[string]any{
+				"group_snapshot": map[string]any{
+					"group_id":    group.ID,
+					"name":        name,
+					"description": description,
+				},
+			}
+
+			var created struct {
+				GroupSnapshot GroupSnapshot `json:"group_snapshot"`
+			}
+			resp, err := sc.Post(innerCtx, sc.ServiceURL("group_snapshots"), body, &created, &gophercloud.RequestOpts{
+				OkCodes: []int{202},
+			})
+			if resp != nil {
+				requestID = resp.Header.Get("X-Openstack-Request-Id")
+			}
+			if err != nil {
+				return fmt.Errorf("Failed to create group snapshot - %w (Request ID: %s)", err, requestID)
+			}
+			groupSnapshot = created.GroupSnapshot
+		}
+
+		// 3. Find the member snapshots
+		for _, volumeID := range group.Volumes {
+			pager := snapshots.ListDetail(sc, snapshots.ListOpts{VolumeID: volumeID})
+			err := pager.EachPage(innerCtx, func(ctx context.Context, page pagination.Page) (bool, error) {
+				var snaps []groupSnapshotMember
+				if err := page.(snapshots.SnapshotPage).ExtractIntoSlicePtr(&snaps, "snapshots"); err != nil {
+					return false, err
+				}
+				for _, s := range snaps {
+					if s.GroupSnapshotID == groupSnapshot.ID {
+						members[s.VolumeID] = s.ID
+						return false, nil
+					}
+				}
+				return true, nil
+			})
+			if err != nil {
+				return fmt.Errorf("failed to list the member snapshots of group snapshot %s: %w", groupSnapshot.ID, err)
+			}
+		}
+		return nil
+	}
+
+	if err := c.executeWithRetry(ctx, "CreateGroupSnapshot", createOperation); err != nil {
+		return groupSnapshot, members, requestID, err
+	}
+
+	return groupSnapshot, members, requestID, nil
+}
+
+// groupSnapshotDescription returns the description of a group snapshot, which carries its window key.
+func groupSnapshotDescription(windowKey string) string {
+	if windowKey == "" {
+		return "Created and managed by Snapsentry"
+	}
+	return fmt.Sprintf("Created and managed by Snapsentry (window key %s)", windowKey)
+}
+
+// findWindowGroupSnapshot returns the group snapshot of a group with the given description that still counts for
+// its window (see policy.SnapshotSatisfiesWindow).
+func findWindowGroupSnapshot(ctx context.Context, sc *gophercloud.ServiceClient, groupID string, description string) (GroupSnapshot, bool, error) {
+	var list struct {
+		GroupSnapshots []GroupSnapshot `json:"group_snapshots"`
+	}
+	if _, err := sc.Get(ctx, sc.ServiceURL("group_snapshots", "detail")+"?group_id="+url.QueryEscape(groupID), &list, nil); err != nil {
+		return GroupSnapshot{}, false, err
+	}
+
+	// The group is checked again on the client, since older Cinder releases ignore the filter.
+	for _, gs := range list.GroupSnapshots {
+		if gs.GroupID == groupID && gs.Description == description && policy.SnapshotSatisfiesWindow(gs.Status) {
+			return gs, true, nil
+		}
+	}
+	return GroupSnapshot{}, false, nil
+}
+
+// AwaitGroupSnapshot polls a group snapshot until it leaves the "creating" state, or until the context is done.
+// Like AwaitSnapshot, every status check runs with the client's retry configuration.
+//
+// Returns the last seen group snapshot; on a context timeout it is still "creating" and the error wraps ctx.Err().
+// The member snapshots are not refreshed in the snapshot index; use AwaitSnapshot on them for that.
+func (c *Client) AwaitGroupSnapshot(ctx context.Context, groupSnapshotID string) (Snapshot GroupSnapshot, Error error) {
+	sc := c.groupServiceClient()
+	var groupSnapshot GroupSnapshot
+
+	ticker := time.NewTicker(snapshotPollInterval)
+	defer ticker.Stop()
+
+	for {
+		getOperation := func(innerCtx context.Context) error {
+			var body struct {
+				GroupSnapshot GroupSnapshot `json:"group_snapshot"`
+			}
+			if _, err := sc.Get(innerCtx, sc.ServiceURL("group_snapshots", groupSnapshotID), &body, nil); err != nil {
+				return err
+			}
+			groupSnapshot = body.GroupSnapshot
+			return nil
+		}
+		if err := c.executeWithRetry(ctx, "GetGroupSnapshot", getOperation); err != nil {
+			return groupSnapshot, err
+		}
+		if groupSnapshot.Status != policy.SnapshotStatusCreating {
+			return groupSnapshot, nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return groupSnapshot, fmt.Errorf("group snapshot %s is still %s: %w", groupSnapshotID, groupSnapshot.Status, ctx.Err())
+		}
+	}
+}
+
+// LabelGroupSnapshotMember names a member snapshot of a group snapshot and applies the policy tags,
+// so that it is evaluated and expired like any other managed snapshot.
+func (c *Client) LabelGroupSnapshotMember(ctx context.Context, snapshotID string, name string, metadata map[string]string) (RequestID string, Error error) {
+	var requestID string
+
+	labelOperation := func(innerCtx context.Context) error {
+		updateResult := snapshots.Update(innerCtx, c.BlockStorageClient, snapshotID, snapshots.UpdateOpts{Name: &name})
+		requestID = updateResult.Header.Get("X-Openstack-Request-Id")
+		if updateResult.Err != nil {
+			return updateResult.Err
+		}
+
+		tags := make(map[string]any, len(metadata))
+		for k, v := range metadata {
+			tags[k] = v
+		}
+
+		metadataResult := snapshots.UpdateMetadata(innerCtx, c.BlockStorageClient, snapshotID, snapshots.UpdateMetadataOpts{
+			Metadata: tags,
+		})
+		requestID = metadataResult.Header.Get("X-Openstack-Request-Id")
+		if metadataResult.Err != nil {
+			return metadataResult.Err
+		}
+
+		// The member is new to the snapshot index, so it is fetched once to be found by later lookups of the run.
+		if c.SnapshotIndex != nil {
+			snap, err := snapshots.Get(innerCtx, c.BlockStorageClient, snapshotID).Extract()
+			if err != nil {
+				return err
+			}
+			c.SnapshotIndex.Put(*snap)
+		}
+		return nil
+	}
+
+	if err := c.executeWithRetry(ctx, "LabelGroupSnapshotMember", labelOperation); err != nil {
+		return requestID, err
+	}
+
+	return requestID, nil
+}
+
+// DeleteGroupSnapshot removes a group snapshot together with all of its member snapshots.
+// Like DeleteSnapshot, it returns once the request is accepted.
+func (c *Client) DeleteGroupSnapshot(ctx context.Context, groupSnapshotID string) (RequestID string, Error error) {
+	sc := c.groupServiceClient()
+	var requestID string
+
+	deleteOperation := func(innerCtx context.Context) error {
+		resp, err := sc.Delete(innerCtx, sc.ServiceURL("group_snapshots", groupSnapshotID), &gophercloud.RequestOpts{
+			OkCodes: []int{202},
+		})
+		if resp != nil {
+			requestID = resp.Header.Get("X-Openstack-Request-Id")
+		}
+		return err
+	}
+
+	if err := c.executeWithRetry(ctx, "DeleteGroupSnapshot", deleteOperation); err != nil {
+		return requestID, err
+	}
+
+	if c.SnapshotIndex != nil {
+		c.SnapshotIndex.RemoveGroupSnapshot(groupSnapshotID)
+	}
+	return requestID, nil
+}
+
+// waitForGroupResource polls a group or group snapshot until it reaches the given status.
+// An "error" status fails immediately instead of waiting for the timeout.
+func waitForGroupResource(ctx context.Context, sc *gophercloud.ServiceClient, resource, key, id, status string) error {
+	return gophercloud.WaitFor(ctx, func(ctx context.Context) (bool, error) {
+		var body map[string]struct {
+			Status string `json:"status"`
+		}
+		if _, err := sc.Get(ctx, sc.ServiceURL(resource, id), &body, nil); err != nil {
+			return false, err
+		}
+
+		switch current := body[key].Status; current {
+		case status:
+			return true, nil
+		case "error":
+			return false, fmt.Errorf("%s %s is in error state", key, id)
+		default:
+			return false, nil
+		}
+	})
+}
@@ -0,0 +1,73 @@
+package openstack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gophercloud/gophercloud/v2"
+)
+
+func TestFindWindowGroupSnapshot(t *testing.T) {
+	description := groupSnapshotDescription("0123456789abcdef0123456789abcdef")
+
+	tests := []struct {
+		name      string
+		snapshots []GroupSnapshot
+		wantID    string
+		wantFound bool
+	}{
+		{
+			name: "Adopts Snapshot Of The Window",
+			snapshots: []GroupSnapshot{
+				{ID: "gs-old", GroupID: "group-1", Description: groupSnapshotDescription("ffffffffffffffffffffffffffffffff"), Status: "available"},
+				{ID: "gs-1", GroupID: "group-1", Description: description, Status: "creating"},
+			},
+			wantID:    "gs-1",
+			wantFound: true,
+		},
+		{
+			name: "Skips Failed Snapshot",
+			snapshots: []GroupSnapshot{
+				{ID: "gs-1", GroupID: "group-1", Description: description, Status: "error"},
+				{ID: "gs-2", GroupID: "group-1", Description: description, Status: "deleting"},
+			},
+		},
+		{
+			name: "Skips Other Group",
+			snapshots: []GroupSnapshot{
+				{ID: "gs-1", GroupID: "group-2", Description: description, Status: "available"},
+			},
+		},
+		{
+			name: "Skips Snapshot Without Window Key",
+			snapshots: []GroupSnapshot{
+				{ID: "gs-1", GroupID: "group-1", Description: groupSnapshotDescription(""), Status: "available"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/group_snapshots/detail" || r.URL.Query().Get("group_id") != "group-1" {
+					t.Errorf("unexpected request %s", r.URL)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(map[string]any{"group_snapshots": tt.snapshots})
+			}))
+			defer server.Close()
+
+			sc := &gophercloud.ServiceClient{ProviderClient: &gophercloud.ProviderClient{}, Endpoint: server.URL + "/"}
+			got, found, err := findWindowGroupSnapshot(context.Background(), sc, "group-1", description)
+			if err != nil {
+				t.Fatalf("findWindowGroupSnapshot() error = %v", err)
+			}
+			if found != tt.wantFound || got.ID != tt.wantID {
+				t.Errorf("findWindowGroupSnapshot() = %q, %v, want %q, %v", got.ID, found, tt.wantID, tt.wantFound)
+			}
+		})
+	}
+}
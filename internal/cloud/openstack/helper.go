@@ -8,10 +8,13 @@ import (
 	"math"
 	"math/rand"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/cloud"
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/metrics"
 	"github.com/gophercloud/gophercloud/v2"
+	"golang.org/x/time/rate"
 )
 
 // isRetryable determines if an error is transient and warrants a retry.
@@ -40,6 +43,55 @@ func isRetryable(err error) bool {
 	return true
 }
 
+// retryStatusCode returns the HTTP status code of a Gophercloud error as a metric label,
+// or "network" for errors without an HTTP response.
+func retryStatusCode(err error) string {
+	var gopherErrors gophercloud.ErrUnexpectedResponseCode
+	if errors.As(err, &gopherErrors) {
+		return strconv.Itoa(gopherErrors.Actual)
+	}
+	return "network"
+}
+
+// NewRateLimiter returns a client-side limiter for Client.RateLimiter allowing requestsPerSecond
+// API requests per second, with bursts of up to one second worth of calls. It returns nil (no limit) for values <= 0.
+func NewRateLimiter(requestsPerSecond float64) *rate.Limiter {
+	if requestsPerSecond <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(requestsPerSecond), max(1, int(requestsPerSecond)))
+}
+
+// rateLimitedTransport is an http.RoundTripper that waits for a token of limiter before every request.
+type rateLimitedTransport struct {
+	base    http.RoundTripper
+	limiter *rate.Limiter
+}
+
+// RoundTrip waits for a token and sends the request with the base transport.
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.Wait(req.Context()); err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, fmt.Errorf("rate limit wait aborted: %w", err)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// rateLimitHTTPClient makes every request sent by hc wait for a token of limiter, so that pagination, polling
+// and retries are throttled like single calls. A nil limiter leaves hc unchanged.
+func rateLimitHTTPClient(hc *http.Client, limiter *rate.Limiter) {
+	if limiter == nil {
+		return
+	}
+	base := hc.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	hc.Transport = &rateLimitedTransport{base: base, limiter: limiter}
+}
+
 // ExecuteAction wraps a function with robust retry logic, including exponential backoff,
 // jitter, and context timeouts.
 //
@@ -80,6 +132,7 @@ func ExecuteAction(ctx context.Context, cfg cloud.RetryConfig, opName string, op
 			"attempt", attempt+1,
 			"max_retries", cfg.MaxRetries,
 			"error", lastErr)
+		metrics.APIRetries.WithLabelValues(opName, retryStatusCode(lastErr)).Inc()
 
 		// 4. Calculate Backoff (Exponential + Jitter)
 		// Formula: BaseDelay * 2^attempt
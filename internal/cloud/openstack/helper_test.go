@@ -0,0 +1,115 @@
+package openstack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gophercloud/gophercloud/v2"
+	"golang.org/x/time/rate"
+)
+
+func TestRetryStatusCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "Server Error", err: gophercloud.ErrUnexpectedResponseCode{Actual: http.StatusServiceUnavailable}, want: "503"},
+		{name: "Rate Limited", err: gophercloud.ErrUnexpectedResponseCode{Actual: http.StatusTooManyRequests}, want: "429"},
+		{
+			name: "Wrapped Response Error",
+			err:  fmt.Errorf("create snapshot: %w", gophercloud.ErrUnexpectedResponseCode{Actual: http.StatusInternalServerError}),
+			want: "500",
+		},
+		{name: "Network Error", err: errors.New("connection reset by peer"), want: "network"},
+		{name: "Timeout", err: context.DeadlineExceeded, want: "network"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryStatusCode(tt.err); got != tt.want {
+				t.Errorf("retryStatusCode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRateLimiter(t *testing.T) {
+	tests := []struct {
+		name      string
+		rps       float64
+		wantNil   bool
+		wantLimit rate.Limit
+		wantBurst int
+	}{
+		{name: "Disabled", rps: 0, wantNil: true},
+		{name: "Negative", rps: -1, wantNil: true},
+		{name: "Fractional", rps: 0.5, wantLimit: 0.5, wantBurst: 1},
+		{name: "Burst Of One Second", rps: 10, wantLimit: 10, wantBurst: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewRateLimiter(tt.rps)
+			if (limiter == nil) != tt.wantNil {
+				t.Fatalf("NewRateLimiter(%v) = %v, want nil %v", tt.rps, limiter, tt.wantNil)
+			}
+			if limiter == nil {
+				return
+			}
+			if limiter.Limit() != tt.wantLimit || limiter.Burst() != tt.wantBurst {
+				t.Errorf("limit, burst = %v, %d, want %v, %d", limiter.Limit(), limiter.Burst(), tt.wantLimit, tt.wantBurst)
+			}
+		})
+	}
+}
+
+func TestRateLimitHTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	t.Run("Throttles Every Request", func(t *testing.T) {
+		// A burst of one at 20 requests per second spaces four requests at least 150ms apart in total.
+		hc := &http.Client{}
+		rateLimitHTTPClient(hc, rate.NewLimiter(20, 1))
+
+		start := time.Now()
+		for range 4 {
+			resp, err := hc.Get(server.URL)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			resp.Body.Close()
+		}
+		if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+			t.Errorf("4 requests took %v, want at least 150ms", elapsed)
+		}
+	})
+
+	t.Run("Cancelled Wait", func(t *testing.T) {
+		limiter := rate.NewLimiter(0.1, 1)
+		limiter.Allow()
+		hc := &http.Client{}
+		rateLimitHTTPClient(hc, limiter)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		if _, err := hc.Transport.RoundTrip(req); !errors.Is(err, context.Canceled) {
+			t.Errorf("RoundTrip() error = %v, want it aborted while waiting for the limiter", err)
+		}
+	})
+
+	t.Run("Nil Limiter", func(t *testing.T) {
+		hc := &http.Client{}
+		rateLimitHTTPClient(hc, nil)
+		if hc.Transport != nil {
+			t.Errorf("transport = %T, want the client unchanged", hc.Transport)
+		}
+	})
+}
@@ -0,0 +1,190 @@
+package openstack
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/policy"
+	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/snapshots"
+)
+
+// SnapshotIndex is an in-memory copy of the managed snapshots of a project, indexed by volume and policy type.
+// It is built with a single paginated listing at the start of a run (see BuildSnapshotIndex) and kept
+// current by the client as snapshots are created, relabelled and deleted, so per-volume lookups cost no API call.
+//
+// Snapshots are kept newest first, matching the order of ListManagedVolumeSnapshots.
+// The index is safe for concurrent use by the workers of a run.
+type SnapshotIndex struct {
+	mu       sync.RWMutex
+	byVolume map[string][]snapshots.Snapshot
+	// byPolicy holds the snapshots of byVolume per volume ID and policy type.
+	byPolicy map[string]map[string][]snapshots.Snapshot
+	// volumeOf maps a snapshot ID to its volume ID, so updates of one snapshot only touch its volume.
+	volumeOf map[string]string
+}
+
+// NewSnapshotIndex returns an index holding the given snapshots.
+func NewSnapshotIndex(snaps []snapshots.Snapshot) *SnapshotIndex {
+	idx := &SnapshotIndex{
+		byVolume: map[string][]snapshots.Snapshot{},
+		byPolicy: map[string]map[string][]snapshots.Snapshot{},
+		volumeOf: map[string]string{},
+	}
+	for _, snap := range snaps {
+		idx.byVolume[snap.VolumeID] = append(idx.byVolume[snap.VolumeID], snap)
+		idx.volumeOf[snap.ID] = snap.VolumeID
+	}
+	for volumeID := range idx.byVolume {
+		idx.reindex(volumeID)
+	}
+	return idx
+}
+
+// BuildSnapshotIndex lists the managed snapshots of the project once and indexes them.
+// Set the result as Client.SnapshotIndex to serve the listing methods from memory for the rest of the run.
+func (c *Client) BuildSnapshotIndex(ctx context.Context) (*SnapshotIndex, error) {
+	snaps, err := c.listManagedSnapshots(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	return NewSnapshotIndex(snaps), nil
+}
+
+// Len returns the number of indexed snapshots.
+func (idx *SnapshotIndex) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	n := 0
+	for _, snaps := range idx.byVolume {
+		n += len(snaps)
+	}
+	return n
+}
+
+// All returns every indexed snapshot, newest first per volume and ordered by volume ID.
+func (idx *SnapshotIndex) All() []snapshots.Snapshot {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	var all []snapshots.Snapshot
+	for _, volumeID := range slices.Sorted(maps.Keys(idx.byVolume)) {
+		all = append(all, cloneSnapshots(idx.byVolume[volumeID])...)
+	}
+	return all
+}
+
+// VolumeSnapshots returns the snapshots of a volume with the given policy type, newest first.
+// An empty policyType returns every snapshot of the volume.
+func (idx *SnapshotIndex) VolumeSnapshots(volumeID, policyType string) []snapshots.Snapshot {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	if policyType == "" {
+		return cloneSnapshots(idx.byVolume[volumeID])
+	}
+	return cloneSnapshots(idx.byPolicy[volumeID][policyType])
+}
+
+// Put adds a snapshot to the index, replacing an indexed snapshot with the same ID.
+func (idx *SnapshotIndex) Put(snap snapshots.Snapshot) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	// A snapshot never moves to another volume, but drop a stale entry just in case.
+	if volumeID, ok := idx.volumeOf[snap.ID]; ok && volumeID != snap.VolumeID {
+		idx.deleteFromVolume(volumeID, snap.ID)
+	}
+	snaps := slices.DeleteFunc(idx.byVolume[snap.VolumeID], func(s snapshots.Snapshot) bool { return s.ID == snap.ID })
+	idx.byVolume[snap.VolumeID] = append(snaps, snap)
+	idx.volumeOf[snap.ID] = snap.VolumeID
+	idx.reindex(snap.VolumeID)
+}
+
+// MergeMetadata merges tags into the metadata of an indexed snapshot, like UpdateManagedSnapshotMetadata
+// does in Cinder. Unknown snapshots are ignored.
+func (idx *SnapshotIndex) MergeMetadata(snapshotID string, metadata map[string]string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	volumeID, ok := idx.volumeOf[snapshotID]
+	if !ok {
+		return
+	}
+	snaps := idx.byVolume[volumeID]
+	i := slices.IndexFunc(snaps, func(s snapshots.Snapshot) bool { return s.ID == snapshotID })
+	if i < 0 {
+		return
+	}
+	merged := maps.Clone(snaps[i].Metadata)
+	if merged == nil {
+		merged = map[string]string{}
+	}
+	maps.Copy(merged, metadata)
+	snaps[i].Metadata = merged
+	idx.reindex(volumeID)
+}
+
+// Remove drops a snapshot from the index.
+func (idx *SnapshotIndex) Remove(snapshotID string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if volumeID, ok := idx.volumeOf[snapshotID]; ok {
+		idx.deleteFromVolume(volumeID, snapshotID)
+	}
+}
+
+// RemoveGroupSnapshot drops the members of a group snapshot from the index.
+func (idx *SnapshotIndex) RemoveGroupSnapshot(groupSnapshotID string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	for volumeID, snaps := range idx.byVolume {
+		for _, snap := range snaps {
+			if snap.Metadata["x-snapsentry-snapshot-group-snapshot-id"] == groupSnapshotID {
+				idx.deleteFromVolume(volumeID, snap.ID)
+				break
+			}
+		}
+	}
+}
+
+// deleteFromVolume drops a snapshot of a volume. The caller must hold the write lock.
+func (idx *SnapshotIndex) deleteFromVolume(volumeID, snapshotID string) {
+	delete(idx.volumeOf, snapshotID)
+	idx.byVolume[volumeID] = slices.DeleteFunc(idx.byVolume[volumeID], func(s snapshots.Snapshot) bool { return s.ID == snapshotID })
+	idx.reindex(volumeID)
+}
+
+// reindex sorts the snapshots of a volume newest first and rebuilds its policy type entries.
+// It only touches the given volume. The caller must hold the write lock.
+func (idx *SnapshotIndex) reindex(volumeID string) {
+	snaps := idx.byVolume[volumeID]
+	if len(snaps) == 0 {
+		delete(idx.byVolume, volumeID)
+		delete(idx.byPolicy, volumeID)
+		return
+	}
+	slices.SortStableFunc(snaps, func(a, b snapshots.Snapshot) int { return b.CreatedAt.Compare(a.CreatedAt) })
+
+	byPolicy := map[string][]snapshots.Snapshot{}
+	for _, snap := range snaps {
+		metadata := policy.SnapshotMetadata{}
+		_ = metadata.ParseFromMetadata(snap.Metadata)
+		byPolicy[metadata.PolicyType] = append(byPolicy[metadata.PolicyType], snap)
+	}
+	idx.byPolicy[volumeID] = byPolicy
+}
+
+// cloneSnapshots copies the snapshots and their metadata, so callers may modify them without touching the index.
+func cloneSnapshots(snaps []snapshots.Snapshot) []snapshots.Snapshot {
+	cloned := make([]snapshots.Snapshot, len(snaps))
+	for i, snap := range snaps {
+		snap.Metadata = maps.Clone(snap.Metadata)
+		cloned[i] = snap
+	}
+	return cloned
+}
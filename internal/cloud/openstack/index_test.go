@@ -0,0 +1,218 @@
+package openstack
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/snapshots"
+)
+
+var indexBase = time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+// indexSnapshot builds a managed snapshot created 'age' hours before indexBase.
+func indexSnapshot(id, volumeID, policyType string, age int, extra ...string) snapshots.Snapshot {
+	metadata := map[string]string{
+		"x-snapsentry-managed":              "true",
+		"x-snapsentry-snapshot-policy-type": policyType,
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		metadata[extra[i]] = extra[i+1]
+	}
+	return snapshots.Snapshot{
+		ID:        id,
+		VolumeID:  volumeID,
+		Status:    "available",
+		CreatedAt: indexBase.Add(-time.Duration(age) * time.Hour),
+		Metadata:  metadata,
+	}
+}
+
+func snapshotIDs(snaps []snapshots.Snapshot) []string {
+	ids := []string{}
+	for _, s := range snaps {
+		ids = append(ids, s.ID)
+	}
+	return ids
+}
+
+func TestSnapshotIndex_VolumeSnapshots(t *testing.T) {
+	idx := NewSnapshotIndex([]snapshots.Snapshot{
+		indexSnapshot("d-old", "vol-1", "daily", 48),
+		indexSnapshot("w-1", "vol-1", "weekly", 30),
+		indexSnapshot("d-new", "vol-1", "daily", 2),
+		indexSnapshot("d-mid", "vol-1", "daily", 24),
+		indexSnapshot("other", "vol-2", "daily", 1),
+	})
+
+	tests := []struct {
+		name       string
+		volumeID   string
+		policyType string
+		want       []string
+	}{
+		{name: "Policy Type Newest First", volumeID: "vol-1", policyType: "daily", want: []string{"d-new", "d-mid", "d-old"}},
+		{name: "Other Policy Type", volumeID: "vol-1", policyType: "weekly", want: []string{"w-1"}},
+		{name: "All Policy Types Newest First", volumeID: "vol-1", policyType: "", want: []string{"d-new", "d-mid", "w-1", "d-old"}},
+		{name: "Other Volume", volumeID: "vol-2", policyType: "daily", want: []string{"other"}},
+		{name: "Unknown Policy Type", volumeID: "vol-1", policyType: "monthly", want: []string{}},
+		{name: "Unknown Volume", volumeID: "vol-3", policyType: "", want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := snapshotIDs(idx.VolumeSnapshots(tt.volumeID, tt.policyType)); !slices.Equal(got, tt.want) {
+				t.Errorf("VolumeSnapshots(%q, %q) = %v, want %v", tt.volumeID, tt.policyType, got, tt.want)
+			}
+		})
+	}
+
+	if got := idx.Len(); got != 5 {
+		t.Errorf("Len() = %d, want 5", got)
+	}
+}
+
+func TestSnapshotIndex_Updates(t *testing.T) {
+	tests := []struct {
+		name       string
+		update     func(idx *SnapshotIndex)
+		volumeID   string
+		policyType string
+		want       []string
+		wantLen    int
+	}{
+		{
+			name:       "Put Adds In Order",
+			update:     func(idx *SnapshotIndex) { idx.Put(indexSnapshot("d-3", "vol-1", "daily", 12)) },
+			volumeID:   "vol-1",
+			policyType: "daily",
+			want:       []string{"d-1", "d-3", "d-2"},
+			wantLen:    5,
+		},
+		{
+			name: "Put Replaces Same ID",
+			update: func(idx *SnapshotIndex) {
+				snap := indexSnapshot("d-1", "vol-1", "daily", 1)
+				snap.Status = "creating"
+				idx.Put(snap)
+			},
+			volumeID:   "vol-1",
+			policyType: "daily",
+			want:       []string{"d-1", "d-2"},
+			wantLen:    4,
+		},
+		{
+			name:       "Put New Volume",
+			update:     func(idx *SnapshotIndex) { idx.Put(indexSnapshot("n-1", "vol-9", "daily", 1)) },
+			volumeID:   "vol-9",
+			policyType: "daily",
+			want:       []string{"n-1"},
+			wantLen:    5,
+		},
+		{
+			name: "MergeMetadata Moves Policy Type",
+			update: func(idx *SnapshotIndex) {
+				idx.MergeMetadata("d-2", map[string]string{"x-snapsentry-snapshot-policy-type": "weekly"})
+			},
+			volumeID:   "vol-1",
+			policyType: "weekly",
+			want:       []string{"d-2"},
+			wantLen:    4,
+		},
+		{
+			name:       "MergeMetadata Unknown Snapshot",
+			update:     func(idx *SnapshotIndex) { idx.MergeMetadata("missing", map[string]string{"k": "v"}) },
+			volumeID:   "vol-1",
+			policyType: "daily",
+			want:       []string{"d-1", "d-2"},
+			wantLen:    4,
+		},
+		{
+			name:       "Remove",
+			update:     func(idx *SnapshotIndex) { idx.Remove("d-1") },
+			volumeID:   "vol-1",
+			policyType: "daily",
+			want:       []string{"d-2"},
+			wantLen:    3,
+		},
+		{
+			name:       "Remove Last Of Volume",
+			update:     func(idx *SnapshotIndex) { idx.Remove("o-1") },
+			volumeID:   "vol-2",
+			policyType: "",
+			want:       []string{},
+			wantLen:    3,
+		},
+		{
+			name:       "RemoveGroupSnapshot",
+			update:     func(idx *SnapshotIndex) { idx.RemoveGroupSnapshot("group-1") },
+			volumeID:   "vol-1",
+			policyType: "",
+			want:       []string{"d-1", "d-2"},
+			wantLen:    2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idx := NewSnapshotIndex([]snapshots.Snapshot{
+				indexSnapshot("d-1", "vol-1", "daily", 2),
+				indexSnapshot("d-2", "vol-1", "daily", 26),
+				indexSnapshot("g-1", "vol-1", "hourly", 1, "x-snapsentry-snapshot-group-snapshot-id", "group-1"),
+				indexSnapshot("o-1", "vol-2", "daily", 3, "x-snapsentry-snapshot-group-snapshot-id", "group-1"),
+			})
+			tt.update(idx)
+
+			if got := snapshotIDs(idx.VolumeSnapshots(tt.volumeID, tt.policyType)); !slices.Equal(got, tt.want) {
+				t.Errorf("VolumeSnapshots(%q, %q) = %v, want %v", tt.volumeID, tt.policyType, got, tt.want)
+			}
+			if got := idx.Len(); got != tt.wantLen {
+				t.Errorf("Len() = %d, want %d", got, tt.wantLen)
+			}
+		})
+	}
+}
+
+func TestSnapshotIndex_ReturnsCopies(t *testing.T) {
+	idx := NewSnapshotIndex([]snapshots.Snapshot{indexSnapshot("d-1", "vol-1", "daily", 1)})
+
+	snaps := idx.VolumeSnapshots("vol-1", "daily")
+	snaps[0].Metadata["x-snapsentry-snapshot-policy-type"] = "weekly"
+	snaps[0].Status = "error"
+
+	got := idx.VolumeSnapshots("vol-1", "daily")
+	if len(got) != 1 || got[0].Status != "available" || got[0].Metadata["x-snapsentry-snapshot-policy-type"] != "daily" {
+		t.Errorf("index was modified through a returned snapshot: %+v", got)
+	}
+}
+
+// TestSnapshotIndex_Concurrent exercises the index from many workers at once; run it with -race.
+func TestSnapshotIndex_Concurrent(t *testing.T) {
+	idx := NewSnapshotIndex(nil)
+
+	var wg sync.WaitGroup
+	for w := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			volumeID := fmt.Sprintf("vol-%d", w%2)
+			for i := range 50 {
+				id := fmt.Sprintf("snap-%d-%d", w, i)
+				idx.Put(indexSnapshot(id, volumeID, "daily", i))
+				idx.MergeMetadata(id, map[string]string{"x-snapsentry-snapshot-pending": "true"})
+				_ = idx.VolumeSnapshots(volumeID, "daily")
+				_ = idx.All()
+				if i%2 == 0 {
+					idx.Remove(id)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := idx.Len(); got != 8*25 {
+		t.Errorf("Len() = %d, want %d", got, 8*25)
+	}
+}
@@ -35,3 +35,20 @@ func (c *Client) ListSubscribedProjects(ctx context.Context) ([]projects.Project
 
 	return projectResult, nil
 }
+
+// GetProject fetches a single project, e.g. the project of the token (Client.ProjectID) to read its tags.
+func (c *Client) GetProject(ctx context.Context, projectID string) (Project projects.Project, Error error) {
+	getOP := func(innerCtx context.Context) error {
+		p, err := projects.Get(innerCtx, c.IdentityClient, projectID).Extract()
+		if err != nil {
+			return err
+		}
+		Project = *p
+		return nil
+	}
+
+	if err := c.executeWithRetry(ctx, "GetProject", getOP); err != nil {
+		return Project, err
+	}
+	return Project, nil
+}
@@ -0,0 +1,59 @@
+package openstack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gophercloud/gophercloud/v2"
+	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/quotasets"
+)
+
+// GetBlockStorageQuotaUsage fetches the block storage quota limits and usage of the project of the token
+// (Client.ProjectID).
+//
+// Returns:
+//   - Usage: The limits (-1 for unlimited) and the in use and reserved amounts of every quota.
+//   - RequestID: The OpenStack tracing ID.
+//   - Error: Returns an error if the token has no project or the request fails after retries.
+func (c *Client) GetBlockStorageQuotaUsage(ctx context.Context) (Usage quotasets.QuotaUsageSet, RequestID string, Error error) {
+	if c.ProjectID == "" {
+		return Usage, "", fmt.Errorf("the token is not scoped to a project")
+	}
+
+	getOperation := func(innerCtx context.Context) error {
+		result := quotasets.GetUsage(innerCtx, c.BlockStorageClient, c.ProjectID)
+		RequestID = result.Header.Get("X-Openstack-Request-Id")
+
+		usage, err := result.Extract()
+		if err != nil {
+			return fmt.Errorf("Failed to get the quota usage of project %s - %w (Request ID: %s)", c.ProjectID, err, RequestID)
+		}
+		Usage = usage
+		return nil
+	}
+
+	if err := c.executeWithRetry(ctx, "GetQuotaUsage", getOperation); err != nil {
+		return Usage, RequestID, err
+	}
+	return Usage, RequestID, nil
+}
+
+// IsQuotaExceeded reports whether Cinder rejected a request because it exceeds a project quota:
+// HTTP 413 (e.g. SnapshotLimitExceeded, VolumeSizeExceedsAvailableQuota), or HTTP 400 naming the quota.
+func IsQuotaExceeded(err error) bool {
+	var gopherErrors gophercloud.ErrUnexpectedResponseCode
+	if !errors.As(err, &gopherErrors) {
+		return false
+	}
+	switch gopherErrors.Actual {
+	case http.StatusRequestEntityTooLarge:
+		return true
+	case http.StatusBadRequest:
+		return strings.Contains(strings.ToLower(string(gopherErrors.Body)), "quota")
+	default:
+		return false
+	}
+}
@@ -0,0 +1,153 @@
+package openstack
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gophercloud/gophercloud/v2"
+	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/snapshots"
+	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/volumes"
+)
+
+// revertMicroversion is the Cinder API microversion that introduced revert-to-snapshot.
+const revertMicroversion = "3.40"
+
+// GetSnapshot returns a single snapshot by ID.
+func (c *Client) GetSnapshot(ctx context.Context, snapshotID string) (Snapshot snapshots.Snapshot, Error error) {
+	var snapshot snapshots.Snapshot
+
+	getOperation := func(innerCtx context.Context) error {
+		snap, err := snapshots.Get(innerCtx, c.BlockStorageClient, snapshotID).Extract()
+		if err != nil {
+			return err
+		}
+		snapshot = *snap
+		return nil
+	}
+
+	if err := c.executeWithRetry(ctx, "GetSnapshot", getOperation); err != nil {
+		return snapshot, fmt.Errorf("failed to get snapshot %s: %w", snapshotID, err)
+	}
+
+	return snapshot, nil
+}
+
+// GetVolume returns a single volume by ID.
+func (c *Client) GetVolume(ctx context.Context, volumeID string) (Volume volumes.Volume, Error error) {
+	var volume volumes.Volume
+
+	getOperation := func(innerCtx context.Context) error {
+		vol, err := volumes.Get(innerCtx, c.BlockStorageClient, volumeID).Extract()
+		if err != nil {
+			return err
+		}
+		volume = *vol
+		return nil
+	}
+
+	if err := c.executeWithRetry(ctx, "GetVolume", getOperation); err != nil {
+		return volume, fmt.Errorf("failed to get volume %s: %w", volumeID, err)
+	}
+
+	return volume, nil
+}
+
+// CreateVolumeFromSnapshot creates a new volume from a snapshot and waits for it to become available.
+//
+// Behavior:
+//   - Size: 0 uses the size of the snapshot. Volume type and availability zone default to the Cinder defaults.
+//   - Synchronous Wait: Blocks until the volume is "available", so the caller can attach it right away.
+//   - Retries: A retry after a failed wait does not create a second volume.
+//
+// Returns:
+//   - RestoredVolume: The new volume. Its ID is set even if it ended up in an "error" state.
+//   - RequestID: The OpenStack tracing ID of the create request.
+func (c *Client) CreateVolumeFromSnapshot(
+	ctx context.Context,
+	snapshotID string,
+	name string,
+	size int,
+	volumeType string,
+	availabilityZone string,
+	metadata map[string]string,
+) (RestoredVolume volumes.Volume, RequestID string, Error error) {
+	var requestID string
+	var restoredVolume volumes.Volume
+
+	createOperation := func(innerCtx context.Context) error {
+		// 1. Trigger Creation
+		if restoredVolume.ID == "" {
+			opts := volumes.CreateOpts{
+				SnapshotID:       snapshotID,
+				Name:             name,
+				Size:             size,
+				VolumeType:       volumeType,
+				AvailabilityZone: availabilityZone,
+				Description:      "Restored by Snapsentry",
+				Metadata:         metadata,
+			}
+
+			result := volumes.Create(innerCtx, c.BlockStorageClient, opts, nil)
+			requestID = result.Header.Get("X-Openstack-Request-Id")
+
+			vol, err := result.Extract()
+			if err != nil {
+				return fmt.Errorf("Failed to create volume from snapshot %s - %w (Request ID: %s)", snapshotID, err, requestID)
+			}
+			restoredVolume = *vol
+		}
+
+		// 2. Wait for Completion
+		if err := volumes.WaitForStatus(innerCtx, c.BlockStorageClient, restoredVolume.ID, "available"); err != nil {
+			return fmt.Errorf("failed waiting for volume %s to become available: %w (Request ID: %s)", restoredVolume.ID, err, requestID)
+		}
+		return nil
+	}
+
+	if err := c.executeWithRetry(ctx, "CreateVolumeFromSnapshot", createOperation); err != nil {
+		return restoredVolume, requestID, err
+	}
+
+	return restoredVolume, requestID, nil
+}
+
+// RevertVolumeToSnapshot reverts a volume in place to a snapshot and waits for it to become available again.
+//
+// Cinder only reverts a detached ("available") volume to its latest snapshot, and not every backend
+// supports it; such requests are rejected by the API.
+func (c *Client) RevertVolumeToSnapshot(ctx context.Context, volumeID string, snapshotID string) (RequestID string, Error error) {
+	sc := *c.BlockStorageClient
+	sc.Microversion = revertMicroversion
+
+	var requestID string
+	reverted := false
+
+	revertOperation := func(innerCtx context.Context) error {
+		// 1. Trigger Revert
+		if !reverted {
+			body := map[string]any{"revert": map[string]any{"snapshot_id": snapshotID}}
+			resp, err := sc.Post(innerCtx, sc.ServiceURL("volumes", volumeID, "action"), body, nil, &gophercloud.RequestOpts{
+				OkCodes: []int{202},
+			})
+			if resp != nil {
+				requestID = resp.Header.Get("X-Openstack-Request-Id")
+			}
+			if err != nil {
+				return fmt.Errorf("failed to revert volume %s to snapshot %s: %w (Request ID: %s)", volumeID, snapshotID, err, requestID)
+			}
+			reverted = true
+		}
+
+		// 2. Wait for Completion
+		if err := volumes.WaitForStatus(innerCtx, &sc, volumeID, "available"); err != nil {
+			return fmt.Errorf("failed waiting for volume %s to become available: %w (Request ID: %s)", volumeID, err, requestID)
+		}
+		return nil
+	}
+
+	if err := c.executeWithRetry(ctx, "RevertVolumeToSnapshot", revertOperation); err != nil {
+		return requestID, err
+	}
+
+	return requestID, nil
+}
@@ -0,0 +1,57 @@
+package openstack
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/gophercloud/gophercloud/v2/openstack/compute/v2/servers"
+)
+
+// GetServerMetadata returns the metadata of a Nova server, e.g. to read the quiesce hooks configured for it.
+func (c *Client) GetServerMetadata(ctx context.Context, serverID string) (Metadata map[string]string, Error error) {
+	var metadata map[string]string
+
+	getOperation := func(innerCtx context.Context) error {
+		var err error
+		metadata, err = servers.Metadata(innerCtx, c.ComputeClient, serverID).Extract()
+		return err
+	}
+
+	if err := c.executeWithRetry(ctx, "GetServerMetadata", getOperation); err != nil {
+		return nil, fmt.Errorf("failed to get the metadata of server %s: %w", serverID, err)
+	}
+
+	return metadata, nil
+}
+
+// FindServerIDs returns the IDs of the servers of the project named exactly name.
+// Nova matches the name filter as a regular expression, so the name is quoted and anchored.
+func (c *Client) FindServerIDs(ctx context.Context, name string) (ServerIDs []string, Error error) {
+	var serverIDs []string
+
+	listOperation := func(innerCtx context.Context) error {
+		// Reset slice on every retry attempt to avoid duplicate data if a retry happens halfway
+		serverIDs = []string{}
+
+		opts := servers.ListOpts{Name: "^" + regexp.QuoteMeta(name) + "$"}
+		pages, err := servers.List(c.ComputeClient, opts).AllPages(innerCtx)
+		if err != nil {
+			return err
+		}
+		found, err := servers.ExtractServers(pages)
+		if err != nil {
+			return err
+		}
+		for _, srv := range found {
+			serverIDs = append(serverIDs, srv.ID)
+		}
+		return nil
+	}
+
+	if err := c.executeWithRetry(ctx, "FindServerIDs", listOperation); err != nil {
+		return nil, fmt.Errorf("failed to look up server %s: %w", name, err)
+	}
+
+	return serverIDs, nil
+}
@@ -3,25 +3,34 @@ package openstack
 import (
 	"context"
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/policy"
+	"github.com/gophercloud/gophercloud/v2"
 	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/snapshots"
+	"github.com/gophercloud/gophercloud/v2/pagination"
 )
 
-// CreateManagedSnapshot triggers the creation of a new snapshot and waits for it to become available.
+// CreateManagedSnapshot triggers the creation of a new snapshot and returns as soon as Cinder accepted it.
 //
 // Behavior:
 //   - Force Creation: Uses the `Force: true` flag, allowing snapshots to be taken even if the
 //     volume is currently attached ("in-use") by an instance.
-//   - Synchronous Wait: This method blocks until the snapshot reaches the "available" state
-//     in OpenStack (or until the context times out). This ensures the snapshot is fully
-//     persisted before the function returns.
+//   - Asynchronous: The snapshot is usually still "creating" when this returns. Large volumes on slow
+//     backends can take far longer than the retry OperationTimeout, so waiting is left to AwaitSnapshot
+//     (see the reconciliation of the snapshot workflow).
+//   - No Double Create: If the metadata carries a window key (policy.WindowKeyTag), every create attempt
+//     first looks for a snapshot of the volume with that key and adopts it instead of creating a second one.
+//     The first attempt is served by the snapshot index, if any; retries always ask Cinder, since a request
+//     may have been accepted even though its response was lost. Snapshots that no longer count for their
+//     window ("error", "deleting") are not adopted.
 //   - Metadata: Applies the provided policy tags (e.g., Expiry Date, Policy Type) at creation time.
 //
 // Returns:
-//   - CreatedSnapshot: The struct containing details of the finished snapshot.
+//   - CreatedSnapshot: The accepted snapshot (typically in status "creating").
 //   - RequestID: The OpenStack tracing ID.
-//   - Error: Returns an error if the API call fails or if the snapshot ends up in an "error" state.
+//   - Error: Returns an error if the create request fails after retries.
 func (c *Client) CreateManagedSnapshot(
 	ctx context.Context,
 	volumeID string,
@@ -33,8 +42,24 @@ func (c *Client) CreateManagedSnapshot(
 
 	var requestID string
 	var createdSnapshot snapshots.Snapshot
+	attempted := false
 
 	createOperation := func(innerCtx context.Context) error {
+		// 1. Idempotency Check
+		// Adopts the snapshot of this window, e.g. one created by an earlier attempt whose response was lost.
+		if windowKey := metadata[policy.WindowKeyTag]; windowKey != "" {
+			existing, found, err := c.findWindowSnapshot(innerCtx, volumeID, windowKey, attempted)
+			if err != nil {
+				return fmt.Errorf("failed to look up snapshot %s by its window key before creating it: %w", name, err)
+			}
+			if found {
+				createdSnapshot = existing
+				return nil
+			}
+		}
+		attempted = true
+
+		// 2. Trigger Creation
 		opts := snapshots.CreateOpts{
 			VolumeID:    volumeID,
 			Force:       true, // Allows snapshotting 'in-use' volumes
@@ -43,28 +68,14 @@ func (c *Client) CreateManagedSnapshot(
 			Metadata:    metadata,
 		}
 
-		// 1. Trigger Creation
 		result := snapshots.Create(innerCtx, c.BlockStorageClient, opts)
 		requestID = result.Header.Get("X-Openstack-Request-Id")
 
 		snap, err := result.Extract()
-
-		if snap != nil {
-			createdSnapshot = *snap
-		} else {
-			snap = &snapshots.Snapshot{}
-		}
-
 		if err != nil {
-			return fmt.Errorf("Failed to create snapshot %s - %w (Request ID: %s)", snap.ID, err, requestID)
+			return fmt.Errorf("Failed to create snapshot %s - %w (Request ID: %s)", name, err, requestID)
 		}
-
-		// 2. Wait for Completion
-		// We block here until the snapshot is ready or the context times out.
-		if err := snapshots.WaitForStatus(innerCtx, c.BlockStorageClient, snap.ID, "available"); err != nil {
-			return fmt.Errorf("failed waiting for snapshot %s to become available: %w (Request ID: %s)", snap.ID, err, requestID)
-		}
-
+		createdSnapshot = *snap
 		return nil
 	}
 
@@ -72,9 +83,83 @@ func (c *Client) CreateManagedSnapshot(
 		return createdSnapshot, requestID, err
 	}
 
+	if c.SnapshotIndex != nil {
+		c.SnapshotIndex.Put(createdSnapshot)
+	}
 	return createdSnapshot, requestID, nil
 }
 
+// snapshotPollInterval is the delay between two status checks of AwaitSnapshot.
+const snapshotPollInterval = 5 * time.Second
+
+// AwaitSnapshot polls a snapshot until it leaves the "creating" state, or until the context is done.
+// Every status check runs with the client's retry configuration, so the wait itself is only bounded by ctx.
+//
+// Returns the last seen snapshot; on a context timeout it is still "creating" and the error wraps ctx.Err().
+// The snapshot index, if any, is updated with the last seen status.
+func (c *Client) AwaitSnapshot(ctx context.Context, snapshotID string) (Snapshot snapshots.Snapshot, Error error) {
+	var snap snapshots.Snapshot
+
+	ticker := time.NewTicker(snapshotPollInterval)
+	defer ticker.Stop()
+
+	for {
+		getOperation := func(innerCtx context.Context) error {
+			s, err := snapshots.Get(innerCtx, c.BlockStorageClient, snapshotID).Extract()
+			if err != nil {
+				return err
+			}
+			snap = *s
+			return nil
+		}
+		if err := c.executeWithRetry(ctx, "GetVolumeSnapshot", getOperation); err != nil {
+			return snap, err
+		}
+		if c.SnapshotIndex != nil {
+			c.SnapshotIndex.Put(snap)
+		}
+		if snap.Status != policy.SnapshotStatusCreating {
+			return snap, nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return snap, fmt.Errorf("snapshot %s is still %s: %w", snapshotID, snap.Status, ctx.Err())
+		}
+	}
+}
+
+// findWindowSnapshot returns the snapshot of a volume carrying the given window key that still counts for its
+// window (see policy.SnapshotSatisfiesWindow). Unless fresh is set, the snapshot index is used if there is one.
+func (c *Client) findWindowSnapshot(ctx context.Context, volumeID string, windowKey string, fresh bool) (snapshots.Snapshot, bool, error) {
+	var candidates []snapshots.Snapshot
+	if c.SnapshotIndex != nil && !fresh {
+		candidates = c.SnapshotIndex.VolumeSnapshots(volumeID, "")
+	} else {
+		opts := managedSnapshotListOpts{
+			VolumeID: volumeID,
+			Metadata: map[string]string{policy.WindowKeyTag: windowKey},
+			Limit:    snapshotListPageSize,
+		}
+		pages, err := snapshots.ListDetail(c.BlockStorageClient, opts).AllPages(ctx)
+		if err != nil {
+			return snapshots.Snapshot{}, false, err
+		}
+		if candidates, err = snapshots.ExtractSnapshots(pages); err != nil {
+			return snapshots.Snapshot{}, false, err
+		}
+	}
+
+	// The key is checked again on the client, since older Cinder releases ignore unknown filters.
+	for _, snap := range candidates {
+		if snap.Metadata[policy.WindowKeyTag] == windowKey && policy.SnapshotSatisfiesWindow(snap.Status) {
+			return snap, true, nil
+		}
+	}
+	return snapshots.Snapshot{}, false, nil
+}
+
 // DeleteSnapshot removes a snapshot from the backend storage.
 //
 // Behavior:
@@ -114,6 +199,57 @@ func (c *Client) DeleteSnapshot(ctx context.Context, snapshotID string) (Request
 		return requestID, err
 	}
 
+	if c.SnapshotIndex != nil {
+		c.SnapshotIndex.Remove(snapshotID)
+	}
+	return requestID, nil
+}
+
+// UpdateManagedSnapshotMetadata merges the provided tags into the metadata of an existing snapshot.
+// It is used by GFS promotion to relabel a snapshot (policy type, expiry) without creating a new one.
+//
+// Concurrency & Safety:
+// Cinder replaces the whole metadata map on update, so this follows the same "Read-Modify-Write"
+// strategy as CreateVolumeSubscription: GET the snapshot, merge the new tags, PUT the merged map.
+//
+// Returns:
+//   - RequestID: The OpenStack tracing ID of the update request.
+//   - Error: Any error encountered during the process (after retries).
+func (c *Client) UpdateManagedSnapshotMetadata(ctx context.Context, snapshotID string, metadata map[string]string) (RequestID string, Error error) {
+	var requestID string
+
+	updateOperation := func(innerCtx context.Context) error {
+		// 1. Get current snapshot metadata
+		snap, err := snapshots.Get(innerCtx, c.BlockStorageClient, snapshotID).Extract()
+		if err != nil {
+			return err
+		}
+
+		// 2. Merge new tags into existing tags
+		merged := make(map[string]any, len(snap.Metadata)+len(metadata))
+		for k, v := range snap.Metadata {
+			merged[k] = v
+		}
+		for k, v := range metadata {
+			merged[k] = v
+		}
+
+		// 3. Execute Update
+		result := snapshots.UpdateMetadata(innerCtx, c.BlockStorageClient, snapshotID, snapshots.UpdateMetadataOpts{
+			Metadata: merged,
+		})
+		requestID = result.Header.Get("X-Openstack-Request-Id")
+
+		return result.Err
+	}
+
+	if err := c.executeWithRetry(ctx, "UpdateSnapshotMetadata", updateOperation); err != nil {
+		return requestID, err
+	}
+
+	if c.SnapshotIndex != nil {
+		c.SnapshotIndex.MergeMetadata(snapshotID, metadata)
+	}
 	return requestID, nil
 }
 
@@ -122,112 +258,128 @@ func (c *Client) DeleteSnapshot(ctx context.Context, snapshotID string) (Request
 // Parameters:
 //   - volumeID: The UUID of the volume to inspect.
 //   - policyType: The policy identifier to filter by (e.g., "daily", "weekly").
-//   - lastSnapshotOnly: Optimization flag. If true, the function stops after finding the
-//     first match. This is used during the "Evaluate" phase to quickly find the most
-//     recent snapshot for idempotency checks.
+//     An empty policyType returns every managed snapshot of the volume (used by GFS evaluation).
+//   - lastSnapshotOnly: If true, only the most recent match is returned. This is used during the
+//     "Evaluate" phase to quickly find the most recent snapshot for idempotency checks.
 //
-// Note: This relies on the OpenStack API returning snapshots sorted by creation date (Newest First),
-// which is the default behavior for Cinder.
+// The history holds the snapshots that satisfy their window (see policy.SnapshotSatisfiesWindow): available ones
+// and ones still in flight, e.g. "creating". Callers that need a usable snapshot must check its status.
+// With a SnapshotIndex on the client, the history is served from the index without an API call.
+// Snapshots are returned newest first.
 func (c *Client) ListManagedVolumeSnapshots(ctx context.Context, volumeID string, policyType string, lastSnapshotOnly bool) (
 	ManagedSnapshots []snapshots.Snapshot, Error error,
 ) {
-	// TODO (aravindh-murugesan): Refactor this method with a helper func to reduce code duplication with ListManagedSnapshots.
 	var managedSnapshots []snapshots.Snapshot
 
-	listOperation := func(innerCtx context.Context) error {
-		// Reset the slice on retry to avoid duplicates
-		managedSnapshots = []snapshots.Snapshot{}
-
-		// Constuct opts to list all the snapshot
-		opts := snapshots.ListOpts{
-			AllTenants: false,
-			Status:     "available",
-			VolumeID:   volumeID,
-		}
-
-		pages, err := snapshots.List(c.BlockStorageClient, opts).AllPages(innerCtx)
+	var snaps []snapshots.Snapshot
+	if c.SnapshotIndex != nil {
+		snaps = c.SnapshotIndex.VolumeSnapshots(volumeID, policyType)
+	} else {
+		listed, err := c.listManagedSnapshots(ctx, volumeID)
 		if err != nil {
-			return err
+			return []snapshots.Snapshot{}, err
 		}
-		snaps, err := snapshots.ExtractSnapshots(pages)
-		if err != nil {
-			return err
-		}
-
-		// Filter by Metadata Policy Type
-		for _, snap := range snaps {
+		for _, snap := range listed {
 			metadata := policy.SnapshotMetadata{}
-			// We ignore errors here; if metadata is missing/malformed, it's simply not a managed snapshot.
 			_ = metadata.ParseFromMetadata(snap.Metadata)
-
-			if metadata.PolicyType == policyType {
-				managedSnapshots = append(managedSnapshots, snap)
-
-				// Optimization: Relying on API default sort order.
-				if lastSnapshotOnly {
-					return nil
-				}
+			if policyType == "" || metadata.PolicyType == policyType {
+				snaps = append(snaps, snap)
 			}
 		}
-
-		return nil
 	}
 
-	if err := c.executeWithRetry(ctx, "ListManagedSnapshots", listOperation); err != nil {
-		return []snapshots.Snapshot{}, err
+	for _, snap := range snaps {
+		if policy.SnapshotSatisfiesWindow(snap.Status) {
+			managedSnapshots = append(managedSnapshots, snap)
+		}
 	}
 
+	if lastSnapshotOnly && len(managedSnapshots) > 1 {
+		managedSnapshots = managedSnapshots[:1]
+	}
 	return managedSnapshots, nil
 }
 
-// ListManagedSnapshots retrieves every snapshot in the project that is managed by SnapSentry.
-// This is primarily used by the Expiry/Cleanup workflow to find candidates for deletion.
-//
-// Filtering:
-// Since OpenStack API filtering is limited for custom metadata keys, this method performs
-// "Client-Side Filtering": it fetches all 'available' snapshots and iterates through them,
-// parsing the metadata to find those with the 'x-snapsentry-managed' tag set to true.
+// ListManagedSnapshots retrieves every snapshot in the project that is managed by SnapSentry, in any status.
+// This is primarily used by the Expiry/Cleanup workflow to find candidates for deletion and stuck snapshots.
+// With a SnapshotIndex on the client, the snapshots are served from the index without an API call.
 func (c *Client) ListManagedSnapshots(ctx context.Context) (
 	ManagedSnapshots []snapshots.Snapshot, Error error,
 ) {
-	// TODO (aravindh-murugesan): Refactor this method with a helper func to reduce code duplication with ListManagedVolumeSnapshots.
+	if c.SnapshotIndex != nil {
+		return c.SnapshotIndex.All(), nil
+	}
+
+	managedSnapshots, err := c.listManagedSnapshots(ctx, "")
+	if err != nil {
+		return []snapshots.Snapshot{}, err
+	}
+	return managedSnapshots, nil
+}
+
+// snapshotListPageSize is the number of snapshots requested per page when listing managed snapshots.
+const snapshotListPageSize = 1000
+
+// managedSnapshotListOpts extends snapshots.ListOpts with the metadata filter of the Cinder API,
+// which gophercloud does not expose for snapshots.
+type managedSnapshotListOpts struct {
+	VolumeID string            `q:"volume_id"`
+	Metadata map[string]string `q:"metadata"`
+	Sort     string            `q:"sort"`
+	Limit    int               `q:"limit"`
+}
+
+// ToSnapshotListQuery formats the options into a query string.
+func (opts managedSnapshotListOpts) ToSnapshotListQuery() (string, error) {
+	q, err := gophercloud.BuildQueryString(opts)
+	if err != nil {
+		return "", err
+	}
+	return q.String(), nil
+}
+
+// listManagedSnapshots lists the managed snapshots of the project, or of one volume if volumeID is set, newest first.
+// Snapshots in every status are listed, so that in-flight and failed snapshots are not invisible to the workflows.
+//
+// Filtering:
+// The managed tag is filtered by Cinder (metadata filter on the detail listing) and pages are fetched with
+// a large page size, so a whole project takes only a few requests. The tag is checked again on the client,
+// since older Cinder releases ignore unknown filters.
+func (c *Client) listManagedSnapshots(ctx context.Context, volumeID string) ([]snapshots.Snapshot, error) {
 	var managedSnapshots []snapshots.Snapshot
 
 	listOperation := func(innerCtx context.Context) error {
 		// Reset the slice on retry to avoid duplicates
 		managedSnapshots = []snapshots.Snapshot{}
 
-		// Constuct opts to list all the snapshot
-		opts := snapshots.ListOpts{
-			AllTenants: false,
-			Status:     "available",
+		opts := managedSnapshotListOpts{
+			VolumeID: volumeID,
+			Metadata: map[string]string{policy.ManagedTag: "true"},
+			Sort:     "created_at:desc",
+			Limit:    snapshotListPageSize,
 		}
 
-		pages, err := snapshots.List(c.BlockStorageClient, opts).AllPages(innerCtx)
-		if err != nil {
-			return err
-		}
-		snaps, err := snapshots.ExtractSnapshots(pages)
-		if err != nil {
-			return err
-		}
-
-		// Filter by Metadata Policy Type
-		for _, snap := range snaps {
-			metadata := policy.SnapshotMetadata{}
-			// We ignore errors here; if metadata is missing/malformed, it's simply not a managed snapshot.
-			_ = metadata.ParseFromMetadata(snap.Metadata)
-
-			if metadata.Managed {
-				managedSnapshots = append(managedSnapshots, snap)
+		return snapshots.ListDetail(c.BlockStorageClient, opts).EachPage(innerCtx, func(_ context.Context, page pagination.Page) (bool, error) {
+			snaps, err := snapshots.ExtractSnapshots(page)
+			if err != nil {
+				return false, err
 			}
-		}
-		return nil
+			for _, snap := range snaps {
+				metadata := policy.SnapshotMetadata{}
+				// We ignore errors here; if metadata is missing/malformed, it's simply not a managed snapshot.
+				_ = metadata.ParseFromMetadata(snap.Metadata)
+				if metadata.Managed {
+					managedSnapshots = append(managedSnapshots, snap)
+				}
+			}
+			return true, nil
+		})
 	}
 
 	if err := c.executeWithRetry(ctx, "ListManagedSnapshots", listOperation); err != nil {
-		return []snapshots.Snapshot{}, err
+		return nil, err
 	}
 
+	slices.SortStableFunc(managedSnapshots, func(a, b snapshots.Snapshot) int { return b.CreatedAt.Compare(a.CreatedAt) })
 	return managedSnapshots, nil
 }
@@ -4,8 +4,11 @@ import (
 	"context"
 	"fmt"
 	"maps"
+	"net/http"
+	"net/url"
 
 	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/policy"
+	"github.com/gophercloud/gophercloud/v2"
 	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/volumes"
 	"github.com/gophercloud/gophercloud/v2/pagination"
 )
@@ -76,6 +79,43 @@ func (c *Client) CreateVolumeSubscription(
 	return subscribedVolume, requestID, nil
 }
 
+// DeleteVolumeMetadataKeys removes the given metadata keys from a volume, one key at a time.
+//
+// Unlike CreateVolumeSubscription, it uses the Cinder metadata-key API (DELETE .../metadata/{key}) instead
+// of rewriting the whole map, so keys written concurrently by other tools are never lost.
+// A key that is already gone counts as deleted, which makes retries safe.
+//
+// Returns:
+//   - RequestID: The X-Openstack-Request-Id header of the last delete request.
+//   - Error: Any error encountered during the process (after retries).
+func (c *Client) DeleteVolumeMetadataKeys(ctx context.Context, volumeID string, keys []string) (RequestID string, Error error) {
+	var requestID string
+	deleted := 0
+
+	deleteOperation := func(innerCtx context.Context) error {
+		// Resume after the keys already deleted by a previous attempt.
+		for _, key := range keys[deleted:] {
+			resp, err := c.BlockStorageClient.Delete(innerCtx, c.BlockStorageClient.ServiceURL("volumes", volumeID, "metadata", url.PathEscape(key)), &gophercloud.RequestOpts{
+				OkCodes: []int{200},
+			})
+			if resp != nil {
+				requestID = resp.Header.Get("X-Openstack-Request-Id")
+			}
+			if err != nil && !gophercloud.ResponseCodeIs(err, http.StatusNotFound) {
+				return fmt.Errorf("failed to delete metadata key %s: %w (Request ID: %s)", key, err, requestID)
+			}
+			deleted++
+		}
+		return nil
+	}
+
+	if err := c.executeWithRetry(ctx, "DeleteVolumeMetadataKeys", deleteOperation); err != nil {
+		return requestID, err
+	}
+
+	return requestID, nil
+}
+
 // ListSubscribedVolumes discovers all volumes that are currently managed by SnapSentry.
 // It filters the OpenStack volume list by checking for the presence of the
 // generic management tag (defined in policy.ManagedTag).
@@ -88,6 +128,16 @@ func (c *Client) CreateVolumeSubscription(
 //   - SubscribedVolumes: A slice containing every volume with the managed tag.
 //   - Error: Detailed error if the operation fails after max retries.
 func (c *Client) ListSubscribedVolumes(ctx context.Context) (SubscribedVolumes []volumes.Volume, Error error) {
+	allVolumes, err := c.ListVolumes(ctx, map[string]string{policy.ManagedTag: "true"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscribed volumes: %w", err)
+	}
+	return allVolumes, nil
+}
+
+// ListVolumes lists every volume of the project whose metadata contains all the given key/value pairs
+// (every volume if metadata is empty). The filter is applied by Cinder; all pages are traversed.
+func (c *Client) ListVolumes(ctx context.Context, metadata map[string]string) (Volumes []volumes.Volume, Error error) {
 	var allVolumes []volumes.Volume
 
 	// Define the operation to be wrapped in the retry loop
@@ -96,9 +146,7 @@ func (c *Client) ListSubscribedVolumes(ctx context.Context) (SubscribedVolumes [
 		allVolumes = []volumes.Volume{}
 
 		opts := volumes.ListOpts{
-			Metadata: map[string]string{
-				policy.ManagedTag: "true",
-			},
+			Metadata: metadata,
 		}
 
 		pager := volumes.List(c.BlockStorageClient, opts)
@@ -119,8 +167,8 @@ func (c *Client) ListSubscribedVolumes(ctx context.Context) (SubscribedVolumes [
 	}
 
 	// Execute with resilience
-	if err := c.executeWithRetry(ctx, "ListSubscribedVolumes", listOperation); err != nil {
-		return nil, fmt.Errorf("failed to list subscribed volumes: %w", err)
+	if err := c.executeWithRetry(ctx, "ListVolumes", listOperation); err != nil {
+		return nil, fmt.Errorf("failed to list volumes: %w", err)
 	}
 
 	return allVolumes, nil
@@ -0,0 +1,142 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Hook phases.
+const (
+	PhasePre  = "pre"
+	PhasePost = "post"
+)
+
+// maxOutput limits how much hook output is kept for error messages.
+const maxOutput = 1024
+
+// Target describes the snapshots a hook is called for.
+// HTTP hooks receive it as JSON; command hooks as SNAPSENTRY_* environment variables.
+type Target struct {
+	Phase     string   `json:"phase"`
+	RunID     string   `json:"snapsentry_id"`
+	ServerID  string   `json:"server_id"`
+	VolumeIDs []string `json:"volume_ids"`
+}
+
+// Runner executes quiesce hooks.
+//
+// Hook specs come from server or volume metadata, which project members can edit. The runner therefore
+// only executes what the operator allowed:
+//   - Commands: The first word of the spec names an executable inside Dir; further words are passed as
+//     arguments. Paths are rejected. An empty Dir disables command hooks.
+//   - HTTP: Specs starting with http:// or https:// are sent a POST request with the Target as JSON.
+//     They are only called with AllowHTTP.
+type Runner struct {
+	Dir       string
+	AllowHTTP bool
+}
+
+// Enabled reports whether any kind of hook may run.
+func (r Runner) Enabled() bool {
+	return r.Dir != "" || r.AllowHTTP
+}
+
+// Run executes a hook spec and waits for it to finish, at most for timeout.
+// An empty spec is a no-op.
+func (r Runner) Run(ctx context.Context, spec string, timeout time.Duration, target Target) error {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil
+	}
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	if strings.HasPrefix(spec, "http://") || strings.HasPrefix(spec, "https://") {
+		if !r.AllowHTTP {
+			return fmt.Errorf("%s hook '%s' is an HTTP hook, but HTTP hooks are not allowed", target.Phase, spec)
+		}
+		return r.runHTTP(ctx, spec, target)
+	}
+
+	return r.runCommand(ctx, spec, target)
+}
+
+func (r Runner) runHTTP(ctx context.Context, url string, target Target) error {
+	body, err := json.Marshal(target)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%s hook: %w", target.Phase, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Snapsentry-Hook-Phase", target.Phase)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s hook request failed: %w", target.Phase, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxOutput))
+		return fmt.Errorf("%s hook returned %d %s", target.Phase, resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+func (r Runner) runCommand(ctx context.Context, spec string, target Target) error {
+	if r.Dir == "" {
+		return fmt.Errorf("%s hook '%s' is a command hook, but no hook directory is configured", target.Phase, spec)
+	}
+
+	args := strings.Fields(spec)
+	name := args[0]
+	if name != filepath.Base(name) || name == "." || name == ".." {
+		return fmt.Errorf("%s hook '%s' must name an executable in the hook directory, not a path", target.Phase, name)
+	}
+
+	cmd := exec.CommandContext(ctx, filepath.Join(r.Dir, name), args[1:]...)
+	cmd.Dir = r.Dir
+	cmd.Env = append(os.Environ(),
+		"SNAPSENTRY_HOOK_PHASE="+target.Phase,
+		"SNAPSENTRY_ID="+target.RunID,
+		"SNAPSENTRY_SERVER_ID="+target.ServerID,
+		"SNAPSENTRY_VOLUME_IDS="+strings.Join(target.VolumeIDs, ","),
+	)
+	// Children that keep the output pipes open must not outlive the timeout.
+	cmd.WaitDelay = time.Second
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("timed out: %w", ctx.Err())
+		}
+		return fmt.Errorf("%s hook '%s' failed: %w: %s", target.Phase, name, err, tail(output))
+	}
+	return nil
+}
+
+// tail returns the end of the hook output, where the error usually is.
+func tail(output []byte) string {
+	output = bytes.TrimSpace(output)
+	if len(output) > maxOutput {
+		output = output[len(output)-maxOutput:]
+	}
+	return string(output)
+}
@@ -0,0 +1,193 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeScript creates an executable shell script in dir.
+func writeScript(t *testing.T, dir, name, body string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+body+"\n"), 0o755); err != nil {
+		t.Fatalf("writing script: %v", err)
+	}
+}
+
+func testTarget(phase string) Target {
+	return Target{Phase: phase, RunID: "req-1", ServerID: "server-1", VolumeIDs: []string{"vol-a", "vol-b"}}
+}
+
+func TestRunner_Command(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	writeScript(t, dir, "record.sh", `echo "$SNAPSENTRY_HOOK_PHASE $SNAPSENTRY_SERVER_ID $SNAPSENTRY_VOLUME_IDS $*" > `+out)
+	writeScript(t, dir, "fail.sh", `echo "cannot freeze /data" >&2; exit 3`)
+	writeScript(t, dir, "slow.sh", `exec sleep 5`)
+
+	tests := []struct {
+		name    string
+		runner  Runner
+		spec    string
+		timeout time.Duration
+		wantErr string
+		wantOut string
+	}{
+		{
+			name:    "Success with Arguments and Environment",
+			runner:  Runner{Dir: dir},
+			spec:    "record.sh freeze /data",
+			wantOut: "pre server-1 vol-a,vol-b freeze /data",
+		},
+		{
+			name:   "Empty Spec",
+			runner: Runner{Dir: dir},
+			spec:   "  ",
+		},
+		{
+			name:    "Failure Includes Output",
+			runner:  Runner{Dir: dir},
+			spec:    "fail.sh",
+			wantErr: "cannot freeze /data",
+		},
+		{
+			name:    "Timeout",
+			runner:  Runner{Dir: dir},
+			spec:    "slow.sh",
+			timeout: 100 * time.Millisecond,
+			wantErr: "timed out",
+		},
+		{
+			name:    "Path Rejected",
+			runner:  Runner{Dir: dir},
+			spec:    "../record.sh",
+			wantErr: "not a path",
+		},
+		{
+			name:    "Absolute Path Rejected",
+			runner:  Runner{Dir: dir},
+			spec:    "/bin/true",
+			wantErr: "not a path",
+		},
+		{
+			name:    "Command Hooks Disabled",
+			runner:  Runner{AllowHTTP: true},
+			spec:    "record.sh",
+			wantErr: "no hook directory",
+		},
+		{
+			name:    "Missing Executable",
+			runner:  Runner{Dir: dir},
+			spec:    "missing.sh",
+			wantErr: "missing.sh",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_ = os.Remove(out)
+
+			err := tt.runner.Run(context.Background(), tt.spec, tt.timeout, testTarget(PhasePre))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Run() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Run() unexpected error: %v", err)
+			}
+
+			if tt.wantOut != "" {
+				got, err := os.ReadFile(out)
+				if err != nil {
+					t.Fatalf("hook did not run: %v", err)
+				}
+				if strings.TrimSpace(string(got)) != tt.wantOut {
+					t.Errorf("hook output = %q, want %q", strings.TrimSpace(string(got)), tt.wantOut)
+				}
+			}
+		})
+	}
+}
+
+func TestRunner_HTTP(t *testing.T) {
+	var received Target
+	var phaseHeader string
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			phaseHeader = r.Header.Get("X-Snapsentry-Hook-Phase")
+			_ = json.NewDecoder(r.Body).Decode(&received)
+		case "/slow":
+			select {
+			case <-r.Context().Done():
+			case <-release:
+			}
+		default:
+			http.Error(w, "database is busy", http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	tests := []struct {
+		name    string
+		runner  Runner
+		url     string
+		timeout time.Duration
+		wantErr string
+	}{
+		{
+			name:   "Success",
+			runner: Runner{AllowHTTP: true},
+			url:    srv.URL + "/ok",
+		},
+		{
+			name:    "Error Status",
+			runner:  Runner{AllowHTTP: true},
+			url:     srv.URL + "/busy",
+			wantErr: "503 database is busy",
+		},
+		{
+			name:    "Timeout",
+			runner:  Runner{AllowHTTP: true},
+			url:     srv.URL + "/slow",
+			timeout: 100 * time.Millisecond,
+			wantErr: "deadline exceeded",
+		},
+		{
+			name:    "HTTP Hooks Not Allowed",
+			runner:  Runner{Dir: t.TempDir()},
+			url:     srv.URL + "/ok",
+			wantErr: "not allowed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received, phaseHeader = Target{}, ""
+
+			err := tt.runner.Run(context.Background(), tt.url, tt.timeout, testTarget(PhasePost))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Run() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Run() unexpected error: %v", err)
+			}
+			if phaseHeader != PhasePost || received.ServerID != "server-1" || len(received.VolumeIDs) != 2 {
+				t.Errorf("hook received phase %q and %+v", phaseHeader, received)
+			}
+		})
+	}
+}
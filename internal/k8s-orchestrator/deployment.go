@@ -3,6 +3,8 @@ package k8sorchestrator
 import (
 	"context"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 
 	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/notifications"
@@ -185,6 +187,16 @@ func CreateSnapsentryDeployment(
 		snapsentryRunCommand = append(snapsentryRunCommand, "--webhook-password", webhookProvider.Password)
 	}
 
+	if webhookProvider.BearerToken != "" {
+		snapsentryRunCommand = append(snapsentryRunCommand, "--webhook-bearer-token", webhookProvider.BearerToken)
+	}
+
+	// Sorted to keep the container arguments deterministic.
+	headerNames := slices.Sorted(maps.Keys(webhookProvider.Headers))
+	for _, name := range headerNames {
+		snapsentryRunCommand = append(snapsentryRunCommand, "--webhook-header", name+": "+webhookProvider.Headers[name])
+	}
+
 	snapSentryDeployment := &appsv1.Deployment{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "apps/v1",
@@ -0,0 +1,166 @@
+// Package metrics holds the Prometheus collectors exposed by the daemon on /metrics.
+//
+// Collectors are package level so that the workflows and the OpenStack client can record
+// without threading a registry through every call. One-off CLI runs record into the same
+// collectors; they are simply never scraped.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "snapsentry"
+
+var (
+	// SnapshotsCreated counts snapshots created, by policy type.
+	SnapshotsCreated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "snapshots_created_total",
+		Help:      "Number of snapshots created, by policy type.",
+	}, []string{"policy_type"})
+
+	// SnapshotsFailed counts failed snapshot creations, by policy type.
+	SnapshotsFailed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "snapshots_failed_total",
+		Help:      "Number of failed snapshot creations, by policy type.",
+	}, []string{"policy_type"})
+
+	// SnapshotsQuotaExceeded counts due snapshots not created because the project quota was exhausted, by policy type.
+	SnapshotsQuotaExceeded = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "snapshots_quota_exceeded_total",
+		Help:      "Number of due snapshots not created because the project quota was exhausted, by policy type.",
+	}, []string{"policy_type"})
+
+	// SnapshotsExpired counts snapshots deleted by the expiry workflow, by policy type.
+	SnapshotsExpired = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "snapshots_expired_total",
+		Help:      "Number of snapshots deleted by the expiry workflow, by policy type.",
+	}, []string{"policy_type"})
+
+	// SnapshotsExpiryFailed counts expiry candidates that could not be deleted, by policy type.
+	SnapshotsExpiryFailed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "snapshots_expiry_failed_total",
+		Help:      "Number of expired snapshots that could not be deleted, by policy type.",
+	}, []string{"policy_type"})
+
+	// BackupsCreated counts snapshots exported to Cinder backups, by policy type.
+	BackupsCreated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "backups_created_total",
+		Help:      "Number of backups requested from snapshots, by policy type.",
+	}, []string{"policy_type"})
+
+	// BackupsFailed counts failed backup exports, by policy type.
+	BackupsFailed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "backups_failed_total",
+		Help:      "Number of failed backup exports, by policy type.",
+	}, []string{"policy_type"})
+
+	// BackupsExpired counts backups deleted by the expiry workflow, by policy type.
+	BackupsExpired = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "backups_expired_total",
+		Help:      "Number of backups deleted by the expiry workflow, by policy type.",
+	}, []string{"policy_type"})
+
+	// BackupsExpiryFailed counts expired backups that could not be deleted, by policy type.
+	BackupsExpiryFailed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "backups_expiry_failed_total",
+		Help:      "Number of expired backups that could not be deleted, by policy type.",
+	}, []string{"policy_type"})
+
+	// OrphanCleanups counts cleanups of snapshots left behind by failed creations, by result (cleaned, failed).
+	OrphanCleanups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "orphan_cleanups_total",
+		Help:      "Number of orphaned snapshot cleanups after failed creations, by result.",
+	}, []string{"result"})
+
+	// StuckSnapshotCleanups counts garbage collections of managed snapshots stuck in a failed or unfinished state,
+	// by the status they were stuck in and result (cleaned, failed).
+	StuckSnapshotCleanups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "stuck_snapshot_cleanups_total",
+		Help:      "Number of stuck snapshot cleanups by the expiry workflow, by status and result.",
+	}, []string{"status", "result"})
+
+	// APIRetries counts OpenStack API retries, by operation name and HTTP status code.
+	// Errors without an HTTP response (DNS, connection reset) use the status code "network".
+	APIRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "openstack_api_retries_total",
+		Help:      "Number of retried OpenStack API calls, by operation and status code.",
+	}, []string{"operation", "status_code"})
+
+	// WorkflowDuration observes the duration of workflow runs, by workflow.
+	WorkflowDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "workflow_duration_seconds",
+		Help:      "Duration of workflow runs, by workflow.",
+		Buckets:   []float64{1, 5, 15, 30, 60, 120, 300, 600, 1200, 1800, 3600},
+	}, []string{"workflow"})
+
+	// WorkflowLastSuccess is the unix timestamp of the last successful run, by workflow.
+	WorkflowLastSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "workflow_last_success_timestamp_seconds",
+		Help:      "Unix timestamp of the last successful run, by workflow.",
+	}, []string{"workflow"})
+
+	// SubscribedVolumes is the number of volumes found with the management tag during the last discovery.
+	SubscribedVolumes = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "subscribed_volumes",
+		Help:      "Number of volumes subscribed to SnapSentry during the last discovery.",
+	})
+)
+
+// Registry holds every SnapSentry collector plus the Go runtime and process collectors.
+var Registry = prometheus.NewRegistry()
+
+func init() {
+	Registry.MustRegister(
+		SnapshotsCreated,
+		SnapshotsFailed,
+		SnapshotsQuotaExceeded,
+		SnapshotsExpired,
+		SnapshotsExpiryFailed,
+		BackupsCreated,
+		BackupsFailed,
+		BackupsExpired,
+		BackupsExpiryFailed,
+		OrphanCleanups,
+		StuckSnapshotCleanups,
+		APIRetries,
+		WorkflowDuration,
+		WorkflowLastSuccess,
+		SubscribedVolumes,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// ObserveWorkflowRun records the duration of a workflow run and, if it succeeded,
+// moves the last success timestamp of that workflow to finishedAt.
+func ObserveWorkflowRun(workflow string, startedAt, finishedAt time.Time, succeeded bool) {
+	WorkflowDuration.WithLabelValues(workflow).Observe(finishedAt.Sub(startedAt).Seconds())
+	if succeeded {
+		WorkflowLastSuccess.WithLabelValues(workflow).Set(float64(finishedAt.Unix()))
+	}
+}
@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+// lastSuccess returns the last success timestamp of a workflow as gathered from the registry, if it is set.
+func lastSuccess(t *testing.T, workflow string) (float64, bool) {
+	t.Helper()
+
+	families, err := Registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != "snapsentry_workflow_last_success_timestamp_seconds" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if label.GetName() == "workflow" && label.GetValue() == workflow {
+					return m.GetGauge().GetValue(), true
+				}
+			}
+		}
+	}
+	return 0, false
+}
+
+func TestObserveWorkflowRun(t *testing.T) {
+	started := time.Date(2026, 10, 15, 2, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		runs      []bool
+		wantSet   bool
+		wantValue time.Time
+	}{
+		{name: "Failed Run", runs: []bool{false}, wantSet: false},
+		{name: "Successful Run", runs: []bool{true}, wantSet: true, wantValue: started.Add(time.Minute)},
+		{name: "Failure After Success", runs: []bool{true, false}, wantSet: true, wantValue: started.Add(time.Minute)},
+		{name: "Success After Failure", runs: []bool{false, true}, wantSet: true, wantValue: started.Add(2 * time.Minute)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow := "test-" + tt.name
+			for i, succeeded := range tt.runs {
+				ObserveWorkflowRun(workflow, started, started.Add(time.Duration(i+1)*time.Minute), succeeded)
+			}
+
+			got, set := lastSuccess(t, workflow)
+			if set != tt.wantSet {
+				t.Fatalf("last success set = %v, want %v", set, tt.wantSet)
+			}
+			if set && got != float64(tt.wantValue.Unix()) {
+				t.Errorf("last success = %v, want %v", got, tt.wantValue.Unix())
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package notifications
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Notifier types accepted in the notifiers configuration file.
+const (
+	TypeWebhook    = "webhook"
+	TypeSlack      = "slack"
+	TypeMattermost = "mattermost"
+	TypeTeams      = "teams"
+	TypeEmail      = "email"
+	TypePagerDuty  = "pagerduty"
+)
+
+// Config is the notifiers configuration file (YAML or JSON).
+//
+// Example:
+//
+//	notifiers:
+//	  - name: oncall
+//	    type: pagerduty
+//	    routing_key: ${PAGERDUTY_ROUTING_KEY}
+//	  - name: storage-team
+//	    type: slack
+//	    url: https://hooks.slack.com/services/...
+//	    events: [snapshot_creation_failure, daily_digest]
+type Config struct {
+	Notifiers []NotifierConfig `json:"notifiers"`
+}
+
+// NotifierConfig configures a single notifier. Only the fields of its type are used.
+type NotifierConfig struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+
+	// Event types delivered to this notifier ("all" for every type). Defaults to DefaultEvents.
+	Events []string `json:"events"`
+
+	// webhook, slack, mattermost, teams
+	URL string `json:"url"`
+
+	// webhook
+	Username    string            `json:"username"`
+	Password    string            `json:"password"`
+	BearerToken string            `json:"bearer_token"`
+	Headers     map[string]string `json:"headers"`
+
+	// slack, mattermost
+	Channel string `json:"channel"`
+	Sender  string `json:"sender"`
+
+	// email (username and password are shared with webhook)
+	Host string   `json:"host"`
+	Port int      `json:"port"`
+	From string   `json:"from"`
+	To   []string `json:"to"`
+
+	// pagerduty (url optionally overrides the Events API endpoint)
+	RoutingKey string `json:"routing_key"`
+}
+
+// envReference matches ${VAR} references. The bare $VAR form is deliberately not expanded,
+// since passwords and tokens may legitimately contain '$'.
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// LoadConfig reads a notifiers configuration file and builds its notifiers.
+// ${VAR} references are replaced with environment variables, so secrets can stay out of the file.
+func LoadConfig(path string) ([]Notifier, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notifiers config: %w", err)
+	}
+
+	expanded := envReference.ReplaceAllFunc(raw, func(ref []byte) []byte {
+		return []byte(os.Getenv(string(envReference.FindSubmatch(ref)[1])))
+	})
+
+	var cfg Config
+	if err := yaml.UnmarshalStrict(expanded, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse notifiers config %s: %w", path, err)
+	}
+
+	notifiers := make([]Notifier, 0, len(cfg.Notifiers))
+	for i, nc := range cfg.Notifiers {
+		n, err := nc.Build()
+		if err != nil {
+			return nil, fmt.Errorf("notifiers config %s: entry %d: %w", path, i+1, err)
+		}
+		notifiers = append(notifiers, n)
+	}
+	return notifiers, nil
+}
+
+// Build validates the configuration and returns the notifier of its type,
+// subscribed to the configured events.
+func (c NotifierConfig) Build() (Notifier, error) {
+	n, err := c.build()
+	if err != nil {
+		return nil, err
+	}
+	filtered, err := WithEvents(n, c.Events)
+	if err != nil {
+		return nil, err
+	}
+	return filtered, nil
+}
+
+// build returns the unfiltered notifier of the configured type.
+func (c NotifierConfig) build() (Notifier, error) {
+	switch c.Type {
+	case TypeWebhook:
+		if c.URL == "" {
+			return nil, fmt.Errorf("%s notifier requires a url", c.Type)
+		}
+		return &Webhook{
+			Label:       c.Name,
+			URL:         c.URL,
+			Username:    c.Username,
+			Password:    c.Password,
+			BearerToken: c.BearerToken,
+			Headers:     c.Headers,
+		}, nil
+
+	case TypeSlack, TypeMattermost:
+		if c.URL == "" {
+			return nil, fmt.Errorf("%s notifier requires a url", c.Type)
+		}
+		label := c.Name
+		if label == "" {
+			label = c.Type
+		}
+		return &Slack{Label: label, URL: c.URL, Channel: c.Channel, Username: c.Sender}, nil
+
+	case TypeTeams:
+		if c.URL == "" {
+			return nil, fmt.Errorf("%s notifier requires a url", c.Type)
+		}
+		return &Teams{Label: c.Name, URL: c.URL}, nil
+
+	case TypeEmail:
+		if c.Host == "" || c.From == "" || len(c.To) == 0 {
+			return nil, fmt.Errorf("%s notifier requires host, from and to", c.Type)
+		}
+		return &Email{
+			Label:    c.Name,
+			Host:     c.Host,
+			Port:     c.Port,
+			Username: c.Username,
+			Password: c.Password,
+			From:     c.From,
+			To:       c.To,
+		}, nil
+
+	case TypePagerDuty:
+		if c.RoutingKey == "" {
+			return nil, fmt.Errorf("%s notifier requires a routing_key", c.Type)
+		}
+		return &PagerDuty{Label: c.Name, RoutingKey: c.RoutingKey, URL: c.URL}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported notifier type '%s'; must be webhook, slack, mattermost, teams, email or pagerduty", c.Type)
+	}
+}
@@ -0,0 +1,332 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/cloud"
+)
+
+// DefaultRetryConfig is the retry policy of notification deliveries.
+var DefaultRetryConfig = cloud.RetryConfig{
+	MaxRetries:       3,
+	BaseDelay:        2 * time.Second,
+	MaxDelay:         30 * time.Second,
+	OperationTimeout: 2 * time.Minute,
+}
+
+// Delivery makes a notifier reliable.
+//
+// Behavior:
+//   - Retry: Failed deliveries are retried with exponential backoff and jitter, following the semantics
+//     of cloud.RetryConfig (MaxRetries additional attempts, BaseDelay * 2^attempt capped at MaxDelay,
+//     everything bounded by OperationTimeout). Rejections that cannot succeed later (4xx other than 408/429,
+//     permanent SMTP errors) fail fast.
+//   - Deduplication: Events with the same DedupKey (e.g. the same volume, policy and window) are delivered
+//     once per DedupPeriod. The state is kept in memory, so it spans the runs of a daemon.
+//   - Dead-Letter Spool: Events that still fail are written to SpoolDir and can be re-sent with ReplaySpool.
+//
+// Events the wrapped notifier is not subscribed to are dropped before any of the above.
+type Delivery struct {
+	Notifier
+	Retry       cloud.RetryConfig
+	DedupPeriod time.Duration
+	SpoolDir    string
+
+	mu   sync.Mutex
+	sent map[string]time.Time
+	now  func() time.Time
+}
+
+// NewDelivery wraps a notifier with retries, deduplication and an optional spool directory.
+// A zero DedupPeriod disables deduplication, an empty spoolDir disables the spool.
+func NewDelivery(n Notifier, retry cloud.RetryConfig, dedupPeriod time.Duration, spoolDir string) *Delivery {
+	return &Delivery{
+		Notifier:    n,
+		Retry:       retry,
+		DedupPeriod: dedupPeriod,
+		SpoolDir:    spoolDir,
+		sent:        map[string]time.Time{},
+		now:         time.Now,
+	}
+}
+
+// Accepts reports whether the wrapped notifier is subscribed to the event type.
+func (d *Delivery) Accepts(eventType string) bool {
+	return Accepts(d.Notifier, eventType)
+}
+
+func (d *Delivery) Notify(ctx context.Context, event Event) error {
+	if !d.Accepts(event.EventType()) {
+		return nil
+	}
+
+	if d.isDuplicate(event.DedupKey()) {
+		return nil
+	}
+
+	err := d.send(ctx, event)
+	if err == nil {
+		return nil
+	}
+
+	if d.SpoolDir == "" {
+		return err
+	}
+	path, spoolErr := d.spool(event, err)
+	if spoolErr != nil {
+		return errors.Join(err, spoolErr)
+	}
+	return fmt.Errorf("%w (spooled to %s for replay)", err, path)
+}
+
+// isDuplicate reports whether the key was delivered within the dedup period, and records it otherwise.
+// Failed deliveries count as delivered: they end up in the spool and are replayed from there.
+func (d *Delivery) isDuplicate(key string) bool {
+	if d.DedupPeriod <= 0 || key == "" {
+		return false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	for k, at := range d.sent {
+		if now.Sub(at) >= d.DedupPeriod {
+			delete(d.sent, k)
+		}
+	}
+
+	if _, ok := d.sent[key]; ok {
+		return true
+	}
+	d.sent[key] = now
+	return false
+}
+
+// send delivers the event with retries.
+func (d *Delivery) send(ctx context.Context, event Event) error {
+	cfg := d.Retry
+	if cfg.OperationTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.OperationTimeout)
+		defer cancel()
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= cfg.MaxRetries; attempt++ {
+		if ctx.Err() != nil {
+			return fmt.Errorf("notification timed out before attempt %d: %w", attempt+1, ctx.Err())
+		}
+
+		lastErr = d.Notifier.Notify(ctx, event)
+		if lastErr == nil {
+			return nil
+		}
+		if !isRetryableDelivery(lastErr) || attempt == cfg.MaxRetries {
+			break
+		}
+
+		// Exponential backoff with up to 50% jitter, capped at MaxDelay.
+		backoff := float64(cfg.BaseDelay) * math.Pow(2, float64(attempt))
+		sleepDuration := time.Duration(backoff)
+		if half := int64(backoff) / 2; half > 0 {
+			sleepDuration += time.Duration(rand.Int63n(half))
+		}
+		if cfg.MaxDelay > 0 {
+			sleepDuration = min(sleepDuration, cfg.MaxDelay)
+		}
+
+		select {
+		case <-time.After(sleepDuration):
+		case <-ctx.Done():
+			return fmt.Errorf("notification cancelled during backoff: %w", ctx.Err())
+		}
+	}
+
+	if cfg.MaxRetries > 0 && isRetryableDelivery(lastErr) {
+		return fmt.Errorf("notification failed after %d retries: %w", cfg.MaxRetries, lastErr)
+	}
+	return lastErr
+}
+
+// isRetryableDelivery classifies delivery errors. Network errors, timeouts, 408, 429, 5xx
+// and transient (4xx) SMTP replies are retried; every other rejection is permanent.
+func isRetryableDelivery(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		code := statusErr.StatusCode
+		return code == http.StatusRequestTimeout || code == http.StatusTooManyRequests || code >= 500
+	}
+
+	var smtpErr *textproto.Error
+	if errors.As(err, &smtpErr) {
+		return smtpErr.Code < 500
+	}
+
+	return true
+}
+
+// spoolEntry is the on-disk format of a dead-lettered event.
+type spoolEntry struct {
+	Notifier  string          `json:"notifier"`
+	EventType string          `json:"event_type"`
+	DedupKey  string          `json:"dedup_key"`
+	FailedAt  time.Time       `json:"failed_at"`
+	Error     string          `json:"error"`
+	Event     json.RawMessage `json:"event"`
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// spool writes the event to the spool directory and returns the file path.
+func (d *Delivery) spool(event Event, deliveryErr error) (string, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return "", fmt.Errorf("failed to spool notification: %w", err)
+	}
+
+	entry, err := json.MarshalIndent(spoolEntry{
+		Notifier:  d.Name(),
+		EventType: event.EventType(),
+		DedupKey:  event.DedupKey(),
+		FailedAt:  d.now().UTC(),
+		Error:     deliveryErr.Error(),
+		Event:     payload,
+	}, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to spool notification: %w", err)
+	}
+
+	if err := os.MkdirAll(d.SpoolDir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to spool notification: %w", err)
+	}
+
+	name := fmt.Sprintf("%d-%s-%s.json", d.now().UnixNano(), unsafeFileChars.ReplaceAllString(d.Name(), "_"), event.EventType())
+	path := filepath.Join(d.SpoolDir, name)
+
+	// Written to a temporary file first, so that a replay never picks up a partial entry.
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, entry, 0o600); err != nil {
+		return "", fmt.Errorf("failed to spool notification: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("failed to spool notification: %w", err)
+	}
+	return path, nil
+}
+
+// ReplayResult summarizes a spool replay.
+type ReplayResult struct {
+	Replayed int
+	Failed   int
+}
+
+// ReplaySpool re-sends the dead-lettered events in dir, oldest first, to the notifier they were spooled for.
+// Delivered entries are removed. Entries that fail again, or whose notifier is no longer configured,
+// stay in the spool for the next replay.
+func ReplaySpool(ctx context.Context, dir string, deliveries []*Delivery) (ReplayResult, error) {
+	result := ReplayResult{}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return result, err
+	}
+	// File names start with the spool time in nanoseconds.
+	sort.Strings(files)
+
+	byName := map[string]*Delivery{}
+	for _, d := range deliveries {
+		byName[d.Name()] = d
+	}
+
+	var errs error
+	for _, file := range files {
+		if err := replayEntry(ctx, file, byName); err != nil {
+			result.Failed++
+			errs = errors.Join(errs, fmt.Errorf("%s: %w", filepath.Base(file), err))
+			continue
+		}
+		result.Replayed++
+	}
+	return result, errs
+}
+
+func replayEntry(ctx context.Context, file string, byName map[string]*Delivery) error {
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	var entry spoolEntry
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return fmt.Errorf("invalid spool entry: %w", err)
+	}
+
+	d, ok := byName[entry.Notifier]
+	if !ok {
+		return fmt.Errorf("notifier '%s' is not configured", entry.Notifier)
+	}
+
+	event, err := DecodeEvent(entry.EventType, entry.Event)
+	if err != nil {
+		return err
+	}
+
+	// Replays bypass deduplication and the spool: the entry is the spool.
+	if err := d.send(ctx, event); err != nil {
+		return err
+	}
+	return os.Remove(file)
+}
+
+// DecodeEvent restores an event from its type and JSON payload.
+func DecodeEvent(eventType string, payload []byte) (Event, error) {
+	switch eventType {
+	case EventSnapshotCreationFailure:
+		return decodeEvent[SnapshotCreationFailure](payload)
+	case EventSnapshotExpiryFailure:
+		return decodeEvent[SnapshotExpiryFailure](payload)
+	case EventSnapshotCreated:
+		return decodeEvent[SnapshotCreated](payload)
+	case EventSnapshotExpired:
+		return decodeEvent[SnapshotExpired](payload)
+	case EventOrphanCleanedUp:
+		return decodeEvent[OrphanCleanedUp](payload)
+	case EventSnapshotStuck:
+		return decodeEvent[SnapshotStuck](payload)
+	case EventQuotaExhausted:
+		return decodeEvent[QuotaExhausted](payload)
+	case EventPolicyMisconfigured:
+		return decodeEvent[PolicyMisconfigured](payload)
+	case EventBackupFailure:
+		return decodeEvent[BackupFailure](payload)
+	case EventRunSummary:
+		return decodeEvent[RunSummary](payload)
+	case EventDailyDigest:
+		return decodeEvent[DailyDigest](payload)
+	default:
+		return nil, fmt.Errorf("unknown event type '%s'", eventType)
+	}
+}
+
+func decodeEvent[T Event](payload []byte) (Event, error) {
+	var event T
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("invalid %s payload: %w", event.EventType(), err)
+	}
+	return event, nil
+}
@@ -0,0 +1,150 @@
+package notifications
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/cloud"
+)
+
+// fastRetry keeps the backoff short enough for tests.
+var fastRetry = cloud.RetryConfig{
+	MaxRetries:       2,
+	BaseDelay:        time.Millisecond,
+	MaxDelay:         5 * time.Millisecond,
+	OperationTimeout: 5 * time.Second,
+}
+
+// statusSequenceServer answers with the given status codes in order, then with the last one.
+func statusSequenceServer(t *testing.T, codes ...int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1)) - 1
+		w.WriteHeader(codes[min(n, len(codes)-1)])
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func TestDelivery_Retry(t *testing.T) {
+	tests := []struct {
+		name      string
+		codes     []int
+		wantErr   bool
+		wantCalls int32
+	}{
+		{
+			name:      "Transient Errors then Success",
+			codes:     []int{http.StatusBadGateway, http.StatusTooManyRequests, http.StatusOK},
+			wantErr:   false,
+			wantCalls: 3,
+		},
+		{
+			name:      "Retries Exhausted",
+			codes:     []int{http.StatusServiceUnavailable},
+			wantErr:   true,
+			wantCalls: 3, // 1 initial + 2 retries
+		},
+		{
+			name:      "Permanent Rejection (no retry)",
+			codes:     []int{http.StatusUnauthorized},
+			wantErr:   true,
+			wantCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, calls := statusSequenceServer(t, tt.codes...)
+			d := NewDelivery(&Webhook{URL: srv.URL}, fastRetry, 0, "")
+
+			err := d.Notify(context.Background(), testEvent)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Notify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestDelivery_Dedup(t *testing.T) {
+	srv, calls := statusSequenceServer(t, http.StatusOK)
+	d := NewDelivery(&Webhook{URL: srv.URL}, fastRetry, time.Hour, "")
+
+	now := time.Date(2025, 12, 21, 8, 0, 0, 0, time.UTC)
+	d.now = func() time.Time { return now }
+
+	otherWindow := testEvent
+	otherWindow.Window.StartTime = testEvent.Window.StartTime.Add(24 * time.Hour)
+
+	steps := []struct {
+		name      string
+		advance   time.Duration
+		event     Event
+		wantCalls int32
+	}{
+		{name: "First Failure", event: testEvent, wantCalls: 1},
+		{name: "Same Volume/Window 10 Minutes Later", advance: 10 * time.Minute, event: testEvent, wantCalls: 1},
+		{name: "Different Window", event: otherWindow, wantCalls: 2},
+		{name: "Same Volume/Window After the Period", advance: time.Hour, event: testEvent, wantCalls: 3},
+	}
+
+	for _, step := range steps {
+		now = now.Add(step.advance)
+		if err := d.Notify(context.Background(), step.event); err != nil {
+			t.Fatalf("%s: Notify() unexpected error: %v", step.name, err)
+		}
+		if got := calls.Load(); got != step.wantCalls {
+			t.Errorf("%s: calls = %d, want %d", step.name, got, step.wantCalls)
+		}
+	}
+}
+
+func TestDelivery_SpoolAndReplay(t *testing.T) {
+	spoolDir := t.TempDir()
+
+	// 1. The receiver is down: the event ends up in the spool.
+	down, _ := statusSequenceServer(t, http.StatusServiceUnavailable)
+	d := NewDelivery(&Webhook{Label: "ops", URL: down.URL}, fastRetry, 0, spoolDir)
+	if err := d.Notify(context.Background(), testEvent); err == nil {
+		t.Fatal("Notify() expected an error while the receiver is down")
+	}
+
+	files, _ := filepath.Glob(filepath.Join(spoolDir, "*.json"))
+	if len(files) != 1 {
+		t.Fatalf("spool contains %d entries, want 1", len(files))
+	}
+
+	// 2. Replay with an unknown notifier keeps the entry.
+	other := NewDelivery(&Webhook{Label: "other", URL: down.URL}, fastRetry, 0, spoolDir)
+	result, err := ReplaySpool(context.Background(), spoolDir, []*Delivery{other})
+	if err == nil || result.Failed != 1 {
+		t.Fatalf("ReplaySpool() = %+v, %v; want 1 failed entry", result, err)
+	}
+
+	// 3. The receiver is back: the replay delivers the original event and empties the spool.
+	srv := newCaptureServer(t, http.StatusOK)
+	d = NewDelivery(&Webhook{Label: "ops", URL: srv.URL}, fastRetry, 0, spoolDir)
+	result, err = ReplaySpool(context.Background(), spoolDir, []*Delivery{d})
+	if err != nil || result.Replayed != 1 {
+		t.Fatalf("ReplaySpool() = %+v, %v; want 1 replayed entry", result, err)
+	}
+	if got := srv.body["volume_id"]; got != testEvent.VolumeID {
+		t.Errorf("replayed volume_id = %v, want %s", got, testEvent.VolumeID)
+	}
+	if got := srv.header.Get("X-Snapsentry-Event"); got != EventSnapshotCreationFailure {
+		t.Errorf("replayed event type = %q, want %s", got, EventSnapshotCreationFailure)
+	}
+	if _, err := os.Stat(files[0]); !os.IsNotExist(err) {
+		t.Errorf("replayed entry was not removed from the spool")
+	}
+}
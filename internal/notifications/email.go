@@ -0,0 +1,135 @@
+package notifications
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Email sends events as plain text mails through an SMTP relay.
+//
+// Behavior:
+//   - STARTTLS is used whenever the server offers it.
+//   - Authentication (PLAIN) is only attempted when a username is set. Go's SMTP client refuses to send
+//     credentials over an unencrypted connection, except to localhost.
+//
+// Fields:
+//   - Label: Name of the notifier in logs. Defaults to "email".
+//   - Host/Port: SMTP relay. Port defaults to 587.
+//   - Username/Password: Optional SMTP credentials.
+//   - From: Sender address.
+//   - To: Recipient addresses.
+type Email struct {
+	Label    string
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+func (e *Email) Name() string {
+	if e.Label != "" {
+		return e.Label
+	}
+	return "email"
+}
+
+func (e *Email) Notify(ctx context.Context, event Event) error {
+	if len(e.To) == 0 {
+		return fmt.Errorf("Failed to send notification: no email recipients configured")
+	}
+
+	port := e.Port
+	if port == 0 {
+		port = 587
+	}
+	addr := net.JoinHostPort(e.Host, strconv.Itoa(port))
+
+	ctx, cancel := context.WithTimeout(ctx, httpTimeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("Failed to send notification: %w", err)
+	}
+	// net/smtp has no context support; the deadline bounds the whole conversation instead.
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, e.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("Failed to send notification: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: e.Host}); err != nil {
+			return fmt.Errorf("Failed to send notification: starttls: %w", err)
+		}
+	}
+
+	if e.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", e.Username, e.Password, e.Host)); err != nil {
+			return fmt.Errorf("Failed to send notification: auth: %w", err)
+		}
+	}
+
+	if err := client.Mail(e.From); err != nil {
+		return fmt.Errorf("Failed to send notification: %w", err)
+	}
+	for _, rcpt := range e.To {
+		if err := client.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("Failed to send notification: recipient %s: %w", rcpt, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("Failed to send notification: %w", err)
+	}
+	if _, err := w.Write(e.message(event)); err != nil {
+		w.Close()
+		return fmt.Errorf("Failed to send notification: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("Failed to send notification: %w", err)
+	}
+
+	return client.Quit()
+}
+
+// message renders the RFC 5322 mail for an event.
+func (e *Email) message(event Event) []byte {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "From: %s\r\n", e.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(e.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", sanitizeHeader(event.Summary()))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&b, "X-Snapsentry-Event: %s\r\n", event.EventType())
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+
+	b.WriteString(event.Summary() + "\r\n\r\n")
+	for _, d := range event.Details() {
+		fmt.Fprintf(&b, "%s: %s\r\n", d.Title, d.Value)
+	}
+
+	return []byte(b.String())
+}
+
+// sanitizeHeader strips line breaks so that event content cannot inject mail headers.
+func sanitizeHeader(s string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+}
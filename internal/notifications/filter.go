@@ -0,0 +1,66 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// EventsAll subscribes a notifier to every event type.
+const EventsAll = "all"
+
+// DefaultEvents are delivered to notifiers that do not list their events: failures only,
+// as before success and digest events existed.
+var DefaultEvents = []string{EventSnapshotCreationFailure, EventSnapshotExpiryFailure, EventSnapshotStuck, EventQuotaExhausted, EventBackupFailure}
+
+// Filtered delivers only the subscribed event types to the wrapped notifier.
+// Every other event is dropped silently.
+type Filtered struct {
+	Notifier
+	Events []string
+}
+
+// WithEvents subscribes a notifier to the given event types (DefaultEvents if empty).
+// It fails on unknown event types, so a typo does not silently mute a notifier.
+func WithEvents(n Notifier, events []string) (*Filtered, error) {
+	if len(events) == 0 {
+		events = DefaultEvents
+	}
+	for _, e := range events {
+		if e != EventsAll && !slices.Contains(EventTypes, e) {
+			return nil, fmt.Errorf("unknown event type '%s'; must be '%s' or one of %v", e, EventsAll, EventTypes)
+		}
+	}
+	return &Filtered{Notifier: n, Events: events}, nil
+}
+
+// Accepts reports whether the notifier is subscribed to the event type.
+func (f *Filtered) Accepts(eventType string) bool {
+	return slices.Contains(f.Events, EventsAll) || slices.Contains(f.Events, eventType)
+}
+
+func (f *Filtered) Notify(ctx context.Context, event Event) error {
+	if !f.Accepts(event.EventType()) {
+		return nil
+	}
+	return f.Notifier.Notify(ctx, event)
+}
+
+// Accepts reports whether at least one of the notifiers is subscribed to the event type.
+func (m Multi) Accepts(eventType string) bool {
+	for _, n := range m {
+		if Accepts(n, eventType) {
+			return true
+		}
+	}
+	return false
+}
+
+// Accepts reports whether a notifier would deliver the event type.
+// Notifiers without a subscription filter accept every event.
+func Accepts(n Notifier, eventType string) bool {
+	if f, ok := n.(interface{ Accepts(string) bool }); ok {
+		return f.Accepts(eventType)
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Severity levels of a notification event. They match the PagerDuty Events v2 severities.
+const (
+	SeverityCritical = "critical"
+	SeverityError    = "error"
+	SeverityWarning  = "warning"
+	SeverityInfo     = "info"
+)
+
+// Notifier delivers notification events to a single destination (webhook, chat, email, pager).
+type Notifier interface {
+	// Name identifies the notifier in logs and errors.
+	Name() string
+	// Notify delivers the event. It returns an error if the destination did not accept it.
+	Notify(ctx context.Context, event Event) error
+}
+
+// Event is a notification payload.
+//
+// Formatting backends (Slack, Teams, Email, PagerDuty) render the summary and details,
+// while the generic webhook posts the event itself as JSON.
+type Event interface {
+	// EventType is a stable identifier of the event kind, e.g. "snapshot_creation_failure".
+	EventType() string
+	// Severity is one of the Severity* constants.
+	Severity() string
+	// Summary is a single line description of the event.
+	Summary() string
+	// Details are the ordered key/value facts rendered below the summary.
+	Details() []Detail
+	// DedupKey identifies repeated occurrences of the same problem (e.g. same volume and window).
+	DedupKey() string
+}
+
+// Detail is a single key/value fact of an event.
+type Detail struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+// Multi fans an event out to several notifiers.
+// Every notifier is attempted; failures are joined into a single error.
+type Multi []Notifier
+
+// Name returns the names of the wrapped notifiers.
+func (m Multi) Name() string {
+	name := "multi("
+	for i, n := range m {
+		if i > 0 {
+			name += ","
+		}
+		name += n.Name()
+	}
+	return name + ")"
+}
+
+// Notify sends the event to every notifier.
+func (m Multi) Notify(ctx context.Context, event Event) error {
+	var errs error
+	for _, n := range m {
+		if err := n.Notify(ctx, event); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("%s: %w", n.Name(), err))
+		}
+	}
+	return errs
+}
+
+// nonEmptyDetails drops details without a value so that renderers do not print empty rows.
+func nonEmptyDetails(details []Detail) []Detail {
+	filtered := make([]Detail, 0, len(details))
+	for _, d := range details {
+		if d.Value != "" {
+			filtered = append(filtered, d)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,443 @@
+package notifications
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/policy"
+)
+
+// testEvent is a snapshot creation failure shared by the backend tests.
+var testEvent = SnapshotCreationFailure{
+	Service:    "snapsentry",
+	VolumeID:   "vol-1",
+	PolicyType: "daily",
+	Message:    "quota exceeded",
+	Window: policy.SnapshotPolicyWindow{
+		StartTime: time.Date(2025, 12, 21, 8, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2025, 12, 22, 8, 0, 0, 0, time.UTC),
+	},
+}
+
+// captureServer records the last request received and answers with the given status code.
+type captureServer struct {
+	*httptest.Server
+	header http.Header
+	body   map[string]any
+}
+
+func newCaptureServer(t *testing.T, status int) *captureServer {
+	t.Helper()
+	c := &captureServer{}
+	c.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.header = r.Header.Clone()
+		c.body = map[string]any{}
+		if err := json.NewDecoder(r.Body).Decode(&c.body); err != nil {
+			t.Errorf("request body is not JSON: %v", err)
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(c.Close)
+	return c
+}
+
+func TestWebhook_Notify(t *testing.T) {
+	tests := []struct {
+		name       string
+		webhook    Webhook
+		status     int
+		wantErr    bool
+		wantHeader map[string]string
+	}{
+		{
+			name:    "Bearer Token and Custom Headers",
+			webhook: Webhook{BearerToken: "s3cret", Headers: map[string]string{"X-Api-Key": "abc"}},
+			status:  http.StatusOK,
+			wantHeader: map[string]string{
+				"Authorization":      "Bearer s3cret",
+				"X-Api-Key":          "abc",
+				"X-Snapsentry-Event": EventSnapshotCreationFailure,
+				"Content-Type":       "application/json",
+			},
+		},
+		{
+			name:    "Basic Auth",
+			webhook: Webhook{Username: "user", Password: "pass"},
+			status:  http.StatusAccepted,
+			wantHeader: map[string]string{
+				"Authorization": "Basic dXNlcjpwYXNz",
+			},
+		},
+		{
+			name:    "Rejected by Receiver",
+			webhook: Webhook{},
+			status:  http.StatusInternalServerError,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newCaptureServer(t, tt.status)
+			tt.webhook.URL = srv.URL
+
+			err := tt.webhook.Notify(context.Background(), testEvent)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Notify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			for key, want := range tt.wantHeader {
+				if got := srv.header.Get(key); got != want {
+					t.Errorf("header %s = %q, want %q", key, got, want)
+				}
+			}
+			// The generic webhook keeps the raw event payload.
+			if got := srv.body["volume_id"]; got != "vol-1" {
+				t.Errorf("volume_id = %v, want vol-1", got)
+			}
+		})
+	}
+}
+
+func TestSlack_Notify(t *testing.T) {
+	srv := newCaptureServer(t, http.StatusOK)
+	slack := Slack{URL: srv.URL, Channel: "#storage"}
+
+	if err := slack.Notify(context.Background(), testEvent); err != nil {
+		t.Fatalf("Notify() unexpected error: %v", err)
+	}
+
+	if got := srv.body["text"]; got != testEvent.Summary() {
+		t.Errorf("text = %v, want %s", got, testEvent.Summary())
+	}
+	if got := srv.body["channel"]; got != "#storage" {
+		t.Errorf("channel = %v, want #storage", got)
+	}
+	attachments, _ := srv.body["attachments"].([]any)
+	if len(attachments) != 1 {
+		t.Fatalf("attachments = %v, want 1 attachment", srv.body["attachments"])
+	}
+	fields, _ := attachments[0].(map[string]any)["fields"].([]any)
+	if len(fields) != len(testEvent.Details()) {
+		t.Errorf("fields = %d, want %d", len(fields), len(testEvent.Details()))
+	}
+}
+
+func TestTeams_Notify(t *testing.T) {
+	srv := newCaptureServer(t, http.StatusAccepted)
+	teams := Teams{URL: srv.URL}
+
+	if err := teams.Notify(context.Background(), testEvent); err != nil {
+		t.Fatalf("Notify() unexpected error: %v", err)
+	}
+
+	attachments, _ := srv.body["attachments"].([]any)
+	if len(attachments) != 1 {
+		t.Fatalf("attachments = %v, want 1 attachment", srv.body["attachments"])
+	}
+	attachment := attachments[0].(map[string]any)
+	if got := attachment["contentType"]; got != "application/vnd.microsoft.card.adaptive" {
+		t.Errorf("contentType = %v", got)
+	}
+	if got := attachment["content"].(map[string]any)["type"]; got != "AdaptiveCard" {
+		t.Errorf("content.type = %v, want AdaptiveCard", got)
+	}
+}
+
+func TestPagerDuty_Notify(t *testing.T) {
+	srv := newCaptureServer(t, http.StatusAccepted)
+	pd := PagerDuty{RoutingKey: "R0UT1NG", URL: srv.URL}
+
+	if err := pd.Notify(context.Background(), testEvent); err != nil {
+		t.Fatalf("Notify() unexpected error: %v", err)
+	}
+
+	if got := srv.body["routing_key"]; got != "R0UT1NG" {
+		t.Errorf("routing_key = %v, want R0UT1NG", got)
+	}
+	if got := srv.body["event_action"]; got != "trigger" {
+		t.Errorf("event_action = %v, want trigger", got)
+	}
+	if got := srv.body["dedup_key"]; got != testEvent.DedupKey() {
+		t.Errorf("dedup_key = %v, want %s", got, testEvent.DedupKey())
+	}
+	payload := srv.body["payload"].(map[string]any)
+	if got := payload["severity"]; got != SeverityError {
+		t.Errorf("payload.severity = %v, want %s", got, SeverityError)
+	}
+}
+
+func TestMulti_Notify(t *testing.T) {
+	ok := newCaptureServer(t, http.StatusOK)
+	failing := newCaptureServer(t, http.StatusBadGateway)
+
+	multi := Multi{
+		&Webhook{Label: "broken", URL: failing.URL},
+		&Slack{URL: ok.URL},
+	}
+
+	err := multi.Notify(context.Background(), testEvent)
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Fatalf("Notify() error = %v, want an error naming the failing notifier", err)
+	}
+	// A failing notifier must not prevent delivery to the others.
+	if ok.body["text"] == nil {
+		t.Errorf("slack notifier was not called after the webhook failed")
+	}
+}
+
+// fakeSMTPServer accepts a single mail and returns its DATA section on the channel.
+func fakeSMTPServer(t *testing.T) (host string, port int, data <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	out := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+		reply("220 localhost ESMTP")
+
+		var body strings.Builder
+		inData := false
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if inData {
+				if line == ".\r\n" {
+					inData = false
+					out <- body.String()
+					reply("250 OK")
+					continue
+				}
+				body.WriteString(line)
+				continue
+			}
+
+			switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 localhost")
+			case cmd == "DATA":
+				inData = true
+				reply("354 End data with <CR><LF>.<CR><LF>")
+			case cmd == "QUIT":
+				reply("221 Bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	return "127.0.0.1", addr.Port, out
+}
+
+func TestEmail_Notify(t *testing.T) {
+	host, port, data := fakeSMTPServer(t)
+	email := Email{Host: host, Port: port, From: "snapsentry@example.com", To: []string{"oncall@example.com"}}
+
+	if err := email.Notify(context.Background(), testEvent); err != nil {
+		t.Fatalf("Notify() unexpected error: %v", err)
+	}
+
+	select {
+	case msg := <-data:
+		if !strings.Contains(msg, "Subject: "+testEvent.Summary()) {
+			t.Errorf("mail does not carry the summary as subject:\n%s", msg)
+		}
+		if !strings.Contains(msg, "Volume ID: vol-1") {
+			t.Errorf("mail does not list the event details:\n%s", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("SMTP server did not receive a mail")
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("SNAPSENTRY_TEST_ROUTING_KEY", "from-env")
+
+	tests := []struct {
+		name      string
+		config    string
+		wantErr   bool
+		wantNames []string
+	}{
+		{
+			name: "Several Notifiers",
+			config: `
+notifiers:
+  - name: oncall
+    type: pagerduty
+    routing_key: ${SNAPSENTRY_TEST_ROUTING_KEY}
+  - type: slack
+    url: https://hooks.slack.com/services/x
+  - type: mattermost
+    url: https://mattermost.example.com/hooks/x
+  - type: teams
+    url: https://example.webhook.office.com/x
+  - type: email
+    host: smtp.example.com
+    from: snapsentry@example.com
+    to: [oncall@example.com]
+  - type: webhook
+    url: https://example.com/hook
+    headers:
+      X-Api-Key: abc
+`,
+			wantNames: []string{"oncall", "slack", "mattermost", "teams", "email", "webhook"},
+		},
+		{
+			name:    "Unsupported Type",
+			config:  "notifiers:\n  - type: carrier-pigeon\n",
+			wantErr: true,
+		},
+		{
+			name:    "Missing URL",
+			config:  "notifiers:\n  - type: slack\n",
+			wantErr: true,
+		},
+		{
+			name:    "Unknown Event Type",
+			config:  "notifiers:\n  - type: slack\n    url: https://x\n    events: [snapshot_create]\n",
+			wantErr: true,
+		},
+		{
+			name:    "Unknown Field",
+			config:  "notifiers:\n  - type: slack\n    url: https://x\n    chanel: typo\n",
+			wantErr: true,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "notifiers-"+strconv.Itoa(i)+".yaml")
+			if err := os.WriteFile(path, []byte(tt.config), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			notifiers, err := LoadConfig(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if len(notifiers) != len(tt.wantNames) {
+				t.Fatalf("LoadConfig() returned %d notifiers, want %d", len(notifiers), len(tt.wantNames))
+			}
+			for i, n := range notifiers {
+				if n.Name() != tt.wantNames[i] {
+					t.Errorf("notifier %d name = %s, want %s", i, n.Name(), tt.wantNames[i])
+				}
+			}
+			if pd := notifiers[0].(*Filtered).Notifier.(*PagerDuty); pd.RoutingKey != "from-env" {
+				t.Errorf("RoutingKey = %s, want the value of the environment variable", pd.RoutingKey)
+			}
+		})
+	}
+}
+
+func TestWithEvents(t *testing.T) {
+	tests := []struct {
+		name       string
+		events     []string
+		wantErr    bool
+		wantAccept map[string]bool
+	}{
+		{
+			name:   "Defaults to Failures",
+			events: nil,
+			wantAccept: map[string]bool{
+				EventSnapshotCreationFailure: true,
+				EventSnapshotExpiryFailure:   true,
+				EventSnapshotStuck:           true,
+				EventQuotaExhausted:          true,
+				EventBackupFailure:           true,
+				EventSnapshotCreated:         false,
+				EventDailyDigest:             false,
+			},
+		},
+		{
+			name:   "Opt-in to Digest Only",
+			events: []string{EventDailyDigest},
+			wantAccept: map[string]bool{
+				EventSnapshotCreationFailure: false,
+				EventDailyDigest:             true,
+			},
+		},
+		{
+			name:   "All Events",
+			events: []string{EventsAll},
+			wantAccept: map[string]bool{
+				EventSnapshotCreated:     true,
+				EventPolicyMisconfigured: true,
+			},
+		},
+		{
+			name:    "Unknown Event",
+			events:  []string{"snapshot_deleted"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := WithEvents(&Webhook{}, tt.events)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("WithEvents() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for eventType, want := range tt.wantAccept {
+				if got := Accepts(f, eventType); got != want {
+					t.Errorf("Accepts(%s) = %v, want %v", eventType, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestFiltered_Notify(t *testing.T) {
+	srv := newCaptureServer(t, http.StatusOK)
+	f, err := WithEvents(&Webhook{URL: srv.URL}, []string{EventSnapshotCreated})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Not subscribed: dropped without a request.
+	if err := f.Notify(context.Background(), testEvent); err != nil {
+		t.Fatalf("Notify() unexpected error: %v", err)
+	}
+	if srv.header != nil {
+		t.Fatalf("unsubscribed event was delivered")
+	}
+
+	created := SnapshotCreated{VolumeID: "vol-1", PolicyType: "daily", SnapshotID: "snap-1"}
+	if err := f.Notify(context.Background(), created); err != nil {
+		t.Fatalf("Notify() unexpected error: %v", err)
+	}
+	if got := srv.header.Get("X-Snapsentry-Event"); got != EventSnapshotCreated {
+		t.Errorf("X-Snapsentry-Event = %q, want %s", got, EventSnapshotCreated)
+	}
+}
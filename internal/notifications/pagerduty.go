@@ -0,0 +1,81 @@
+package notifications
+
+import (
+	"context"
+)
+
+// PagerDutyEventsURL is the PagerDuty Events API v2 endpoint.
+const PagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDuty triggers PagerDuty incidents through the Events API v2.
+// Repeated failures of the same volume and window share a dedup_key and therefore a single incident.
+//
+// Fields:
+//   - Label: Name of the notifier in logs. Defaults to "pagerduty".
+//   - RoutingKey: Integration key of the PagerDuty service.
+//   - URL: Events API endpoint. Defaults to PagerDutyEventsURL.
+type PagerDuty struct {
+	Label      string
+	RoutingKey string
+	URL        string
+}
+
+type pagerDutyEvent struct {
+	RoutingKey  string           `json:"routing_key"`
+	EventAction string           `json:"event_action"`
+	DedupKey    string           `json:"dedup_key,omitempty"`
+	Payload     pagerDutyPayload `json:"payload"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	Component     string            `json:"component"`
+	Class         string            `json:"class"`
+	CustomDetails map[string]string `json:"custom_details"`
+}
+
+func (p *PagerDuty) Name() string {
+	if p.Label != "" {
+		return p.Label
+	}
+	return "pagerduty"
+}
+
+func (p *PagerDuty) Notify(ctx context.Context, event Event) error {
+	url := p.URL
+	if url == "" {
+		url = PagerDutyEventsURL
+	}
+
+	details := map[string]string{}
+	for _, d := range event.Details() {
+		details[d.Title] = d.Value
+	}
+
+	msg := pagerDutyEvent{
+		RoutingKey:  p.RoutingKey,
+		EventAction: "trigger",
+		DedupKey:    event.DedupKey(),
+		Payload: pagerDutyPayload{
+			// The Events API rejects summaries above 1024 characters.
+			Summary:       truncate(event.Summary(), 1024),
+			Source:        "snapsentry",
+			Severity:      event.Severity(),
+			Component:     "cinder",
+			Class:         event.EventType(),
+			CustomDetails: details,
+		},
+	}
+
+	return postJSON(ctx, url, msg, nil)
+}
+
+// truncate shortens s to at most n bytes.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
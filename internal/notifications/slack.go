@@ -0,0 +1,80 @@
+package notifications
+
+import (
+	"context"
+)
+
+// Slack posts events to a Slack incoming webhook.
+// Mattermost incoming webhooks accept the same payload, so this notifier serves both.
+//
+// Fields:
+//   - Label: Name of the notifier in logs. Defaults to "slack".
+//   - URL: Incoming webhook URL.
+//   - Channel: Optional channel override (only honoured by Mattermost and legacy Slack webhooks).
+//   - Username: Optional sender name override.
+type Slack struct {
+	Label    string
+	URL      string
+	Channel  string
+	Username string
+}
+
+type slackMessage struct {
+	Text        string            `json:"text"`
+	Channel     string            `json:"channel,omitempty"`
+	Username    string            `json:"username,omitempty"`
+	Attachments []slackAttachment `json:"attachments,omitempty"`
+}
+
+type slackAttachment struct {
+	Fallback string       `json:"fallback"`
+	Color    string       `json:"color"`
+	Fields   []slackField `json:"fields"`
+}
+
+type slackField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+func (s *Slack) Name() string {
+	if s.Label != "" {
+		return s.Label
+	}
+	return "slack"
+}
+
+func (s *Slack) Notify(ctx context.Context, event Event) error {
+	details := event.Details()
+	fields := make([]slackField, 0, len(details))
+	for _, d := range details {
+		// Long values (messages) get a full row, identifiers share a row.
+		fields = append(fields, slackField{Title: d.Title, Value: d.Value, Short: len(d.Value) <= 40})
+	}
+
+	msg := slackMessage{
+		Text:     event.Summary(),
+		Channel:  s.Channel,
+		Username: s.Username,
+		Attachments: []slackAttachment{{
+			Fallback: event.Summary(),
+			Color:    severityColor(event.Severity()),
+			Fields:   fields,
+		}},
+	}
+
+	return postJSON(ctx, s.URL, msg, nil)
+}
+
+// severityColor maps a severity to the hex color of chat attachments and cards.
+func severityColor(severity string) string {
+	switch severity {
+	case SeverityCritical, SeverityError:
+		return "#d00000"
+	case SeverityWarning:
+		return "#ffb000"
+	default:
+		return "#2eb67d"
+	}
+}
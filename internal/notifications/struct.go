@@ -1,12 +1,44 @@
 package notifications
 
-import "github.com/aravindh-murugesan/openstack-snapsentry-go/internal/policy"
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
-type Webhook struct {
-	URL      string
-	Username string
-	Password string
-	Verify   bool
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/policy"
+)
+
+// Event types.
+const (
+	EventSnapshotCreationFailure = "snapshot_creation_failure"
+	EventSnapshotExpiryFailure   = "snapshot_expiry_failure"
+	EventSnapshotCreated         = "snapshot_created"
+	EventSnapshotExpired         = "snapshot_expired"
+	EventOrphanCleanedUp         = "orphan_cleaned_up"
+	EventSnapshotStuck           = "snapshot_stuck"
+	EventQuotaExhausted          = "quota_exhausted"
+	EventPolicyMisconfigured     = "policy_misconfigured"
+	EventBackupFailure           = "backup_failure"
+	EventRunSummary              = "run_summary"
+	EventDailyDigest             = "daily_digest"
+)
+
+// EventTypes lists every event type, in the order they are documented.
+var EventTypes = []string{
+	EventSnapshotCreationFailure,
+	EventSnapshotExpiryFailure,
+	EventSnapshotCreated,
+	EventSnapshotExpired,
+	EventOrphanCleanedUp,
+	EventSnapshotStuck,
+	EventQuotaExhausted,
+	EventPolicyMisconfigured,
+	EventBackupFailure,
+	EventRunSummary,
+	EventDailyDigest,
 }
 
 type SnapshotCreationFailure struct {
@@ -15,10 +47,36 @@ type SnapshotCreationFailure struct {
 	VMID       string                      `json:"virtual_machine_id"`
 	VolumeID   string                      `json:"volume_id"`
 	SnapshotID string                      `json:"snapshot_id"`
+	PolicyType string                      `json:"policy_type,omitempty"`
 	Message    string                      `json:"message"`
 	Window     policy.SnapshotPolicyWindow `json:"snapshot_window"`
 }
 
+func (e SnapshotCreationFailure) EventType() string { return EventSnapshotCreationFailure }
+
+func (e SnapshotCreationFailure) Severity() string { return SeverityError }
+
+func (e SnapshotCreationFailure) Summary() string {
+	return fmt.Sprintf("SnapSentry: %s snapshot creation failed for volume %s", e.PolicyType, e.VolumeID)
+}
+
+func (e SnapshotCreationFailure) Details() []Detail {
+	return nonEmptyDetails([]Detail{
+		{Title: "Volume ID", Value: e.VolumeID},
+		{Title: "Virtual Machine", Value: e.VMName},
+		{Title: "Virtual Machine ID", Value: e.VMID},
+		{Title: "Policy", Value: e.PolicyType},
+		{Title: "Window Start", Value: formatEventTime(e.Window.StartTime)},
+		{Title: "Window End", Value: formatEventTime(e.Window.EndTime)},
+		{Title: "Snapshot ID", Value: e.SnapshotID},
+		{Title: "Message", Value: e.Message},
+	})
+}
+
+func (e SnapshotCreationFailure) DedupKey() string {
+	return fmt.Sprintf("snapsentry/%s/%s/%s/%s", e.EventType(), e.VolumeID, e.PolicyType, formatEventTime(e.Window.StartTime))
+}
+
 type SnapshotExpiryFailure struct {
 	Service          string                  `json:"service"`
 	SnapshotID       string                  `json:"snapshot_id"`
@@ -26,3 +84,434 @@ type SnapshotExpiryFailure struct {
 	SnapshotMetadata policy.SnapshotMetadata `json:"snapshot_metadata"`
 	Message          string                  `json:"message"`
 }
+
+func (e SnapshotExpiryFailure) EventType() string { return EventSnapshotExpiryFailure }
+
+func (e SnapshotExpiryFailure) Severity() string { return SeverityError }
+
+func (e SnapshotExpiryFailure) Summary() string {
+	return fmt.Sprintf("SnapSentry: failed to delete expired snapshot %s of volume %s", e.SnapshotID, e.VolumeID)
+}
+
+func (e SnapshotExpiryFailure) Details() []Detail {
+	details := []Detail{
+		{Title: "Snapshot ID", Value: e.SnapshotID},
+		{Title: "Volume ID", Value: e.VolumeID},
+		{Title: "Policy", Value: e.SnapshotMetadata.PolicyType},
+		{Title: "Retention", Value: e.SnapshotMetadata.RetentionType},
+		{Title: "Expiry Date", Value: formatEventTime(e.SnapshotMetadata.ExpiryDate)},
+		{Title: "Message", Value: e.Message},
+	}
+	if e.SnapshotMetadata.RetentionCount > 0 {
+		details[3].Value += " (" + strconv.Itoa(e.SnapshotMetadata.RetentionCount) + ")"
+	}
+	return nonEmptyDetails(details)
+}
+
+func (e SnapshotExpiryFailure) DedupKey() string {
+	return fmt.Sprintf("snapsentry/%s/%s", e.EventType(), e.SnapshotID)
+}
+
+type SnapshotCreated struct {
+	Service      string                      `json:"service"`
+	VolumeID     string                      `json:"volume_id"`
+	VolumeName   string                      `json:"volume_name"`
+	PolicyType   string                      `json:"policy_type"`
+	SnapshotID   string                      `json:"snapshot_id"`
+	SnapshotName string                      `json:"snapshot_name"`
+	ExpiryDate   time.Time                   `json:"expiry_date"`
+	Window       policy.SnapshotPolicyWindow `json:"snapshot_window"`
+}
+
+func (e SnapshotCreated) EventType() string { return EventSnapshotCreated }
+
+func (e SnapshotCreated) Severity() string { return SeverityInfo }
+
+func (e SnapshotCreated) Summary() string {
+	return fmt.Sprintf("SnapSentry: %s snapshot created for volume %s", e.PolicyType, volumeLabel(e.VolumeID, e.VolumeName))
+}
+
+func (e SnapshotCreated) Details() []Detail {
+	return nonEmptyDetails([]Detail{
+		{Title: "Volume ID", Value: e.VolumeID},
+		{Title: "Volume Name", Value: e.VolumeName},
+		{Title: "Policy", Value: e.PolicyType},
+		{Title: "Snapshot ID", Value: e.SnapshotID},
+		{Title: "Snapshot Name", Value: e.SnapshotName},
+		{Title: "Window Start", Value: formatEventTime(e.Window.StartTime)},
+		{Title: "Expiry Date", Value: formatEventTime(e.ExpiryDate)},
+	})
+}
+
+func (e SnapshotCreated) DedupKey() string {
+	return fmt.Sprintf("snapsentry/%s/%s", e.EventType(), e.SnapshotID)
+}
+
+type SnapshotExpired struct {
+	Service          string                  `json:"service"`
+	SnapshotID       string                  `json:"snapshot_id"`
+	VolumeID         string                  `json:"volume_id"`
+	SnapshotMetadata policy.SnapshotMetadata `json:"snapshot_metadata"`
+	Reason           string                  `json:"reason"`
+}
+
+func (e SnapshotExpired) EventType() string { return EventSnapshotExpired }
+
+func (e SnapshotExpired) Severity() string { return SeverityInfo }
+
+func (e SnapshotExpired) Summary() string {
+	return fmt.Sprintf("SnapSentry: expired snapshot %s of volume %s deleted", e.SnapshotID, e.VolumeID)
+}
+
+func (e SnapshotExpired) Details() []Detail {
+	return nonEmptyDetails([]Detail{
+		{Title: "Snapshot ID", Value: e.SnapshotID},
+		{Title: "Volume ID", Value: e.VolumeID},
+		{Title: "Policy", Value: e.SnapshotMetadata.PolicyType},
+		{Title: "Expiry Date", Value: formatEventTime(e.SnapshotMetadata.ExpiryDate)},
+		{Title: "Reason", Value: e.Reason},
+	})
+}
+
+func (e SnapshotExpired) DedupKey() string {
+	return fmt.Sprintf("snapsentry/%s/%s", e.EventType(), e.SnapshotID)
+}
+
+type OrphanCleanedUp struct {
+	Service    string `json:"service"`
+	VolumeID   string `json:"volume_id"`
+	PolicyType string `json:"policy_type"`
+	SnapshotID string `json:"snapshot_id"`
+	RequestID  string `json:"request_id"`
+	Message    string `json:"message"`
+}
+
+func (e OrphanCleanedUp) EventType() string { return EventOrphanCleanedUp }
+
+func (e OrphanCleanedUp) Severity() string { return SeverityWarning }
+
+func (e OrphanCleanedUp) Summary() string {
+	return fmt.Sprintf("SnapSentry: orphaned snapshot %s of volume %s cleaned up", e.SnapshotID, e.VolumeID)
+}
+
+func (e OrphanCleanedUp) Details() []Detail {
+	return nonEmptyDetails([]Detail{
+		{Title: "Volume ID", Value: e.VolumeID},
+		{Title: "Policy", Value: e.PolicyType},
+		{Title: "Snapshot ID", Value: e.SnapshotID},
+		{Title: "Request ID", Value: e.RequestID},
+		{Title: "Message", Value: e.Message},
+	})
+}
+
+func (e OrphanCleanedUp) DedupKey() string {
+	return fmt.Sprintf("snapsentry/%s/%s", e.EventType(), e.SnapshotID)
+}
+
+// SnapshotStuck reports a managed snapshot left in a failed or unfinished state (e.g. "error", "creating")
+// past the stuck snapshot age, and the outcome of its garbage collection.
+type SnapshotStuck struct {
+	Service    string    `json:"service"`
+	SnapshotID string    `json:"snapshot_id"`
+	VolumeID   string    `json:"volume_id"`
+	PolicyType string    `json:"policy_type"`
+	Status     string    `json:"status"`
+	CreatedAt  time.Time `json:"created_at"`
+	Deleted    bool      `json:"deleted"`
+	RequestID  string    `json:"request_id"`
+	Message    string    `json:"message"`
+}
+
+func (e SnapshotStuck) EventType() string { return EventSnapshotStuck }
+
+func (e SnapshotStuck) Severity() string {
+	if e.Deleted {
+		return SeverityWarning
+	}
+	return SeverityError
+}
+
+func (e SnapshotStuck) Summary() string {
+	if e.Deleted {
+		return fmt.Sprintf("SnapSentry: snapshot %s of volume %s stuck in %s was deleted", e.SnapshotID, e.VolumeID, e.Status)
+	}
+	return fmt.Sprintf("SnapSentry: snapshot %s of volume %s is stuck in %s", e.SnapshotID, e.VolumeID, e.Status)
+}
+
+func (e SnapshotStuck) Details() []Detail {
+	return nonEmptyDetails([]Detail{
+		{Title: "Snapshot ID", Value: e.SnapshotID},
+		{Title: "Volume ID", Value: e.VolumeID},
+		{Title: "Policy", Value: e.PolicyType},
+		{Title: "Status", Value: e.Status},
+		{Title: "Created At", Value: formatEventTime(e.CreatedAt)},
+		{Title: "Request ID", Value: e.RequestID},
+		{Title: "Message", Value: e.Message},
+	})
+}
+
+func (e SnapshotStuck) DedupKey() string {
+	return fmt.Sprintf("snapsentry/%s/%s/%t", e.EventType(), e.SnapshotID, e.Deleted)
+}
+
+// QuotaExhausted reports, once per run, that the Cinder quota of the project could not serve every due snapshot.
+// Limits of -1 are unlimited.
+type QuotaExhausted struct {
+	Service          string   `json:"service"`
+	ProjectID        string   `json:"project_id"`
+	RunID            string   `json:"snapsentry_id"`
+	SnapshotLimit    int      `json:"snapshot_limit"`
+	SnapshotsInUse   int      `json:"snapshots_in_use"`
+	GigabytesLimit   int      `json:"gigabytes_limit"`
+	GigabytesInUse   int      `json:"gigabytes_in_use"`
+	SnapshotsSkipped int      `json:"snapshots_skipped"`
+	VolumeIDs        []string `json:"volume_ids"`
+	Message          string   `json:"message"`
+}
+
+func (e QuotaExhausted) EventType() string { return EventQuotaExhausted }
+
+func (e QuotaExhausted) Severity() string { return SeverityError }
+
+func (e QuotaExhausted) Summary() string {
+	return fmt.Sprintf("SnapSentry: snapshot quota of project %s exhausted; %d snapshot(s) not created", e.ProjectID, e.SnapshotsSkipped)
+}
+
+func (e QuotaExhausted) Details() []Detail {
+	return nonEmptyDetails([]Detail{
+		{Title: "Project ID", Value: e.ProjectID},
+		{Title: "Run ID", Value: e.RunID},
+		{Title: "Snapshots", Value: formatQuotaUsage(e.SnapshotsInUse, e.SnapshotLimit)},
+		{Title: "Gigabytes", Value: formatQuotaUsage(e.GigabytesInUse, e.GigabytesLimit)},
+		{Title: "Skipped Snapshots", Value: strconv.Itoa(e.SnapshotsSkipped)},
+		{Title: "Volumes", Value: strings.Join(e.VolumeIDs, ", ")},
+		{Title: "Message", Value: e.Message},
+	})
+}
+
+func (e QuotaExhausted) DedupKey() string {
+	return fmt.Sprintf("snapsentry/%s/%s", e.EventType(), e.ProjectID)
+}
+
+// formatQuotaUsage renders a quota as "<in use> / <limit>".
+func formatQuotaUsage(inUse, limit int) string {
+	if limit < 0 {
+		return fmt.Sprintf("%d / unlimited", inUse)
+	}
+	return fmt.Sprintf("%d / %d", inUse, limit)
+}
+
+// PolicyMisconfigured reports volume metadata that fails policy validation, so the policy never runs.
+type PolicyMisconfigured struct {
+	Service    string `json:"service"`
+	VolumeID   string `json:"volume_id"`
+	VolumeName string `json:"volume_name"`
+	PolicyType string `json:"policy_type"`
+	Message    string `json:"message"`
+}
+
+func (e PolicyMisconfigured) EventType() string { return EventPolicyMisconfigured }
+
+func (e PolicyMisconfigured) Severity() string { return SeverityWarning }
+
+func (e PolicyMisconfigured) Summary() string {
+	return fmt.Sprintf("SnapSentry: %s policy of volume %s is misconfigured", e.PolicyType, volumeLabel(e.VolumeID, e.VolumeName))
+}
+
+func (e PolicyMisconfigured) Details() []Detail {
+	return nonEmptyDetails([]Detail{
+		{Title: "Volume ID", Value: e.VolumeID},
+		{Title: "Volume Name", Value: e.VolumeName},
+		{Title: "Policy", Value: e.PolicyType},
+		{Title: "Message", Value: e.Message},
+	})
+}
+
+func (e PolicyMisconfigured) DedupKey() string {
+	return fmt.Sprintf("snapsentry/%s/%s/%s", e.EventType(), e.VolumeID, e.PolicyType)
+}
+
+// Backup operations reported by BackupFailure.
+const (
+	BackupOperationExport = "export"
+	BackupOperationExpiry = "expiry"
+)
+
+// BackupFailure reports a snapshot that could not be exported to a backup, or an expired backup that could not be deleted.
+type BackupFailure struct {
+	Service    string    `json:"service"`
+	Operation  string    `json:"operation"`
+	VolumeID   string    `json:"volume_id"`
+	SnapshotID string    `json:"snapshot_id"`
+	BackupID   string    `json:"backup_id"`
+	PolicyType string    `json:"policy_type"`
+	ExpiryDate time.Time `json:"expiry_date"`
+	Message    string    `json:"message"`
+}
+
+func (e BackupFailure) EventType() string { return EventBackupFailure }
+
+func (e BackupFailure) Severity() string { return SeverityError }
+
+func (e BackupFailure) Summary() string {
+	if e.Operation == BackupOperationExpiry {
+		return fmt.Sprintf("SnapSentry: failed to delete expired backup %s of volume %s", e.BackupID, e.VolumeID)
+	}
+	return fmt.Sprintf("SnapSentry: %s backup export failed for volume %s", e.PolicyType, e.VolumeID)
+}
+
+func (e BackupFailure) Details() []Detail {
+	return nonEmptyDetails([]Detail{
+		{Title: "Volume ID", Value: e.VolumeID},
+		{Title: "Policy", Value: e.PolicyType},
+		{Title: "Snapshot ID", Value: e.SnapshotID},
+		{Title: "Backup ID", Value: e.BackupID},
+		{Title: "Expiry Date", Value: formatEventTime(e.ExpiryDate)},
+		{Title: "Message", Value: e.Message},
+	})
+}
+
+func (e BackupFailure) DedupKey() string {
+	if e.Operation == BackupOperationExpiry {
+		return fmt.Sprintf("snapsentry/%s/%s/%s", e.EventType(), e.Operation, e.BackupID)
+	}
+	return fmt.Sprintf("snapsentry/%s/%s/%s", e.EventType(), e.Operation, e.SnapshotID)
+}
+
+// RunSummary is sent at the end of every workflow run.
+type RunSummary struct {
+	Service          string         `json:"service"`
+	RunID            string         `json:"snapsentry_id"`
+	Workflow         string         `json:"workflow"`
+	StartedAt        time.Time      `json:"started_at"`
+	FinishedAt       time.Time      `json:"finished_at"`
+	Error            string         `json:"error,omitempty"`
+	VolumesProcessed int            `json:"volumes_processed"`
+	VolumesSucceeded int            `json:"volumes_succeeded"`
+	VolumesFailed    int            `json:"volumes_failed"`
+	SnapshotsFound   int            `json:"snapshots_found"`
+	Outcomes         map[string]int `json:"outcomes"`
+}
+
+func (e RunSummary) EventType() string { return EventRunSummary }
+
+func (e RunSummary) Severity() string {
+	if e.Error != "" || e.VolumesFailed > 0 || e.Outcomes["failed"] > 0 {
+		return SeverityWarning
+	}
+	return SeverityInfo
+}
+
+func (e RunSummary) Summary() string {
+	if e.Error != "" {
+		return fmt.Sprintf("SnapSentry: %s run %s failed: %s", e.Workflow, e.RunID, e.Error)
+	}
+	return fmt.Sprintf("SnapSentry: %s run completed (%s)", e.Workflow, formatOutcomes(e.Outcomes))
+}
+
+func (e RunSummary) Details() []Detail {
+	details := []Detail{
+		{Title: "Run ID", Value: e.RunID},
+		{Title: "Workflow", Value: e.Workflow},
+		{Title: "Started At", Value: formatEventTime(e.StartedAt)},
+		{Title: "Duration", Value: e.FinishedAt.Sub(e.StartedAt).Round(time.Second).String()},
+	}
+	if e.Workflow == "expire-snapshots" {
+		details = append(details, Detail{Title: "Snapshots Found", Value: strconv.Itoa(e.SnapshotsFound)})
+	} else {
+		details = append(details,
+			Detail{Title: "Volumes Processed", Value: strconv.Itoa(e.VolumesProcessed)},
+			Detail{Title: "Volumes Failed", Value: strconv.Itoa(e.VolumesFailed)},
+		)
+	}
+	details = append(details,
+		Detail{Title: "Outcomes", Value: formatOutcomes(e.Outcomes)},
+		Detail{Title: "Error", Value: e.Error},
+	)
+	return nonEmptyDetails(details)
+}
+
+func (e RunSummary) DedupKey() string {
+	return fmt.Sprintf("snapsentry/%s/%s", e.EventType(), e.RunID)
+}
+
+// DailyDigest aggregates the runs of a daemon over a period (usually the last day).
+type DailyDigest struct {
+	Service           string    `json:"service"`
+	PeriodStart       time.Time `json:"period_start"`
+	PeriodEnd         time.Time `json:"period_end"`
+	Runs              int       `json:"runs"`
+	FailedRuns        int       `json:"failed_runs"`
+	SubscribedVolumes int       `json:"subscribed_volumes"`
+	VolumesBackedUp   int       `json:"volumes_backed_up"`
+	VolumesFailed     int       `json:"volumes_failed"`
+	SnapshotsCreated  int       `json:"snapshots_created"`
+	SnapshotsPromoted int       `json:"snapshots_promoted"`
+	SnapshotsFailed   int       `json:"snapshots_failed"`
+	SnapshotsExpired  int       `json:"snapshots_expired"`
+	ExpiryFailures    int       `json:"expiry_failures"`
+}
+
+func (e DailyDigest) EventType() string { return EventDailyDigest }
+
+func (e DailyDigest) Severity() string {
+	if e.FailedRuns > 0 || e.VolumesFailed > 0 || e.ExpiryFailures > 0 {
+		return SeverityWarning
+	}
+	return SeverityInfo
+}
+
+func (e DailyDigest) Summary() string {
+	summary := fmt.Sprintf("SnapSentry: %d volumes were backed up since %s", e.VolumesBackedUp, e.PeriodStart.UTC().Format("2006-01-02 15:04 MST"))
+	if e.VolumesFailed > 0 {
+		summary += fmt.Sprintf(", %d volumes had failures", e.VolumesFailed)
+	}
+	return summary
+}
+
+func (e DailyDigest) Details() []Detail {
+	return []Detail{
+		{Title: "Period", Value: formatEventTime(e.PeriodStart) + " - " + formatEventTime(e.PeriodEnd)},
+		{Title: "Runs", Value: fmt.Sprintf("%d (%d failed)", e.Runs, e.FailedRuns)},
+		{Title: "Subscribed Volumes", Value: strconv.Itoa(e.SubscribedVolumes)},
+		{Title: "Volumes Backed Up", Value: strconv.Itoa(e.VolumesBackedUp)},
+		{Title: "Volumes With Failures", Value: strconv.Itoa(e.VolumesFailed)},
+		{Title: "Snapshots Created", Value: strconv.Itoa(e.SnapshotsCreated)},
+		{Title: "Snapshots Promoted", Value: strconv.Itoa(e.SnapshotsPromoted)},
+		{Title: "Snapshot Failures", Value: strconv.Itoa(e.SnapshotsFailed)},
+		{Title: "Snapshots Expired", Value: strconv.Itoa(e.SnapshotsExpired)},
+		{Title: "Expiry Failures", Value: strconv.Itoa(e.ExpiryFailures)},
+	}
+}
+
+func (e DailyDigest) DedupKey() string {
+	return fmt.Sprintf("snapsentry/%s/%s", e.EventType(), formatEventTime(e.PeriodEnd))
+}
+
+// volumeLabel prefers the volume name for human readable summaries.
+func volumeLabel(id, name string) string {
+	if name != "" {
+		return name
+	}
+	return id
+}
+
+// formatOutcomes renders outcome counts as "created=3, skipped=11", sorted by outcome.
+func formatOutcomes(outcomes map[string]int) string {
+	if len(outcomes) == 0 {
+		return "nothing to do"
+	}
+	parts := make([]string, 0, len(outcomes))
+	for _, outcome := range slices.Sorted(maps.Keys(outcomes)) {
+		parts = append(parts, fmt.Sprintf("%s=%d", outcome, outcomes[outcome]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatEventTime renders a timestamp for notifications, leaving zero times blank.
+func formatEventTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
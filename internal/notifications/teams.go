@@ -0,0 +1,76 @@
+package notifications
+
+import (
+	"context"
+)
+
+// Teams posts events as Adaptive Cards to a Microsoft Teams incoming webhook
+// (or a Power Automate "post to a channel when a webhook request is received" workflow).
+//
+// Fields:
+//   - Label: Name of the notifier in logs. Defaults to "teams".
+//   - URL: Incoming webhook or workflow URL.
+type Teams struct {
+	Label string
+	URL   string
+}
+
+type teamsMessage struct {
+	Type        string            `json:"type"`
+	Attachments []teamsAttachment `json:"attachments"`
+}
+
+type teamsAttachment struct {
+	ContentType string    `json:"contentType"`
+	Content     teamsCard `json:"content"`
+}
+
+type teamsCard struct {
+	Schema  string           `json:"$schema"`
+	Type    string           `json:"type"`
+	Version string           `json:"version"`
+	Body    []map[string]any `json:"body"`
+}
+
+func (t *Teams) Name() string {
+	if t.Label != "" {
+		return t.Label
+	}
+	return "teams"
+}
+
+func (t *Teams) Notify(ctx context.Context, event Event) error {
+	// Adaptive Cards only know a few named colors.
+	color := "Good"
+	switch event.Severity() {
+	case SeverityCritical, SeverityError:
+		color = "Attention"
+	case SeverityWarning:
+		color = "Warning"
+	}
+
+	facts := []map[string]string{}
+	for _, d := range event.Details() {
+		facts = append(facts, map[string]string{"title": d.Title, "value": d.Value})
+	}
+
+	card := teamsCard{
+		Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+		Type:    "AdaptiveCard",
+		Version: "1.4",
+		Body: []map[string]any{
+			{"type": "TextBlock", "text": event.Summary(), "weight": "Bolder", "size": "Medium", "color": color, "wrap": true},
+			{"type": "FactSet", "facts": facts},
+		},
+	}
+
+	msg := teamsMessage{
+		Type: "message",
+		Attachments: []teamsAttachment{{
+			ContentType: "application/vnd.microsoft.card.adaptive",
+			Content:     card,
+		}},
+	}
+
+	return postJSON(ctx, t.URL, msg, nil)
+}
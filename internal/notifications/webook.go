@@ -2,44 +2,102 @@ package notifications
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"time"
 )
 
-func (w *Webhook) Notify(notification any) error {
+// httpTimeout bounds every notification request so a slow receiver cannot stall a workflow.
+const httpTimeout = 30 * time.Second
 
-	payload, err := json.Marshal(notification)
+// Webhook posts the raw JSON of an event to a URL.
+// The event type is sent in the X-Snapsentry-Event header.
+//
+// Fields:
+//   - Label: Name of the notifier in logs. Defaults to "webhook".
+//   - Username/Password: Optional basic auth.
+//   - BearerToken: Optional "Authorization: Bearer" token. Takes precedence over basic auth.
+//   - Headers: Additional request headers (e.g. an API key header).
+type Webhook struct {
+	Label       string
+	URL         string
+	Username    string
+	Password    string
+	BearerToken string
+	Headers     map[string]string
+	Verify      bool
+}
+
+func (w *Webhook) Name() string {
+	if w.Label != "" {
+		return w.Label
+	}
+	return "webhook"
+}
+
+func (w *Webhook) Notify(ctx context.Context, event Event) error {
+	return postJSON(ctx, w.URL, event, func(req *http.Request) {
+		req.Header.Set("X-Snapsentry-Event", event.EventType())
+
+		if w.Username != "" || w.Password != "" {
+			req.SetBasicAuth(w.Username, w.Password)
+		}
+		if w.BearerToken != "" {
+			req.Header.Set("Authorization", "Bearer "+w.BearerToken)
+		}
+		for key, value := range w.Headers {
+			req.Header.Set(key, value)
+		}
+	})
+}
+
+// postJSON sends payload as JSON to url and expects a 2xx response.
+// decorate, if set, adds authentication or extra headers to the request.
+func postJSON(ctx context.Context, url string, payload any, decorate func(req *http.Request)) error {
+	body, err := json.Marshal(payload)
 	if err != nil {
 		return err
 	}
 
 	client := http.Client{
-		Timeout: 30 * time.Second,
+		Timeout: httpTimeout,
 	}
 
-	req, err := http.NewRequest("POST", w.URL, bytes.NewBuffer(payload))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(body))
 	if err != nil {
 		return err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-
-	if w.Username != "" || w.Password != "" {
-		req.SetBasicAuth(w.Username, w.Password)
+	if decorate != nil {
+		decorate(req)
 	}
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return fmt.Errorf("Failed to send notification via Webhook: %w", err)
+		return fmt.Errorf("Failed to send notification: %w", err)
 	}
 
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("Failed to send notification via Webhook: %d", resp.StatusCode)
+		// Include the start of the response body; chat APIs explain rejected payloads there.
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &StatusError{StatusCode: resp.StatusCode, Body: string(bytes.TrimSpace(msg))}
 	}
 
 	return nil
 }
+
+// StatusError is returned when a notification endpoint answers with a non-2xx status code.
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("Failed to send notification: %d %s", e.StatusCode, e.Body)
+}
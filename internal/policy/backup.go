@@ -0,0 +1,108 @@
+package policy
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// BackupPolicyTypes are the policy types whose snapshots can be exported to Cinder backups.
+var BackupPolicyTypes = []string{"express", "cron", "daily", "weekly", "monthly"}
+
+// BackupConfig exports the snapshots of one policy type to Cinder backups, which are stored on the backup
+// backend (e.g. Swift, S3, Ceph) and therefore survive the loss of the volume backend.
+//
+// Behavior:
+//   - Export: The newest snapshot of PolicyType is backed up once, with the snapshot as source, e.g. every
+//     weekly snapshot also becomes a backup.
+//   - Retention: The backup expires RetentionDays after its source snapshot was taken, independent of the
+//     snapshot's retention.
+//   - Incremental: Once a full backup of the volume exists, further backups are incremental.
+//   - Container: Optional backup container (e.g. Swift container); empty uses the Cinder default.
+type BackupConfig struct {
+	Enabled       bool   `json:"x-snapsentry-backup-enabled"`
+	PolicyType    string `json:"x-snapsentry-backup-policy"`
+	RetentionDays int    `json:"x-snapsentry-backup-retention"`
+	Incremental   bool   `json:"x-snapsentry-backup-incremental"`
+	Container     string `json:"x-snapsentry-backup-container"`
+}
+
+// ParseFromMetadata hydrates the backup configuration from a volume metadata map.
+func (b *BackupConfig) ParseFromMetadata(metadata map[string]string) error {
+	parsed, err := ParseSnapSentryMetadataFromSDK[BackupConfig](metadata)
+	if err != nil {
+		return err
+	}
+	*b = *parsed
+	return nil
+}
+
+// ToOpenstackMetadata serializes the backup configuration into OpenStack Volume metadata tags.
+func (b *BackupConfig) ToOpenstackMetadata() map[string]string {
+	return map[string]string{
+		ManagedTag:                        "true",
+		"x-snapsentry-backup-enabled":     strconv.FormatBool(b.Enabled),
+		"x-snapsentry-backup-policy":      b.PolicyType,
+		"x-snapsentry-backup-retention":   strconv.Itoa(b.RetentionDays),
+		"x-snapsentry-backup-incremental": strconv.FormatBool(b.Incremental),
+		"x-snapsentry-backup-container":   b.Container,
+	}
+}
+
+// Validate checks that an enabled configuration names a known policy type and a retention.
+func (b BackupConfig) Validate() error {
+	if !slices.Contains(BackupPolicyTypes, b.PolicyType) {
+		return fmt.Errorf("invalid backup policy '%s'; must be one of %v", b.PolicyType, BackupPolicyTypes)
+	}
+	if b.RetentionDays < 1 {
+		return fmt.Errorf("invalid backup retention %d; must be at least 1 day", b.RetentionDays)
+	}
+	return nil
+}
+
+// BackupMetadata defines the schema for the metadata stored on a backup exported by SnapSentry.
+// It is the backup counterpart of SnapshotMetadata and used by the expiry workflow in the same way.
+type BackupMetadata struct {
+	// Managed indicates if this backup is owned by SnapSentry.
+	Managed bool `json:"x-snapsentry-managed"`
+
+	// ExpiryDate is the computed timestamp after which this backup is eligible for deletion
+	// (creation time of the source snapshot + RetentionDays).
+	ExpiryDate time.Time `json:"x-snapsentry-backup-expiry-date"`
+
+	// PolicyType is the policy type of the source snapshot.
+	PolicyType string `json:"x-snapsentry-backup-policy-type"`
+
+	// RetentionDays is stored for reference/debugging to show how long the backup policy was configured for.
+	RetentionDays int `json:"x-snapsentry-backup-retention-days"`
+
+	// SourceSnapshotID is the snapshot the backup was created from. It prevents exporting a snapshot twice.
+	SourceSnapshotID string `json:"x-snapsentry-backup-source-snapshot-id"`
+}
+
+// ToOpenstackMetadata serializes the backup metadata into a string map suitable for the Cinder API.
+func (b BackupMetadata) ToOpenstackMetadata() map[string]string {
+	var expiryDateStr string
+	if !b.ExpiryDate.IsZero() {
+		expiryDateStr = b.ExpiryDate.UTC().Format(time.RFC3339)
+	}
+
+	return map[string]string{
+		"x-snapsentry-managed":                   strconv.FormatBool(b.Managed),
+		"x-snapsentry-backup-expiry-date":        expiryDateStr,
+		"x-snapsentry-backup-policy-type":        b.PolicyType,
+		"x-snapsentry-backup-retention-days":     strconv.Itoa(b.RetentionDays),
+		"x-snapsentry-backup-source-snapshot-id": b.SourceSnapshotID,
+	}
+}
+
+// ParseFromMetadata hydrates the BackupMetadata struct from a raw OpenStack metadata map.
+func (b *BackupMetadata) ParseFromMetadata(metadata map[string]string) error {
+	parsed, err := ParseSnapSentryMetadataFromSDK[BackupMetadata](metadata)
+	if err != nil {
+		return err
+	}
+	*b = *parsed
+	return nil
+}
@@ -0,0 +1,100 @@
+package policy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackupConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata map[string]string
+		wantErr  bool
+	}{
+		{
+			name: "Weekly Kept 90 Days",
+			metadata: map[string]string{
+				"x-snapsentry-backup-enabled":   "true",
+				"x-snapsentry-backup-policy":    "weekly",
+				"x-snapsentry-backup-retention": "90",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Unknown Policy",
+			metadata: map[string]string{
+				"x-snapsentry-backup-enabled":   "true",
+				"x-snapsentry-backup-policy":    "yearly",
+				"x-snapsentry-backup-retention": "90",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Missing Retention",
+			metadata: map[string]string{
+				"x-snapsentry-backup-enabled": "true",
+				"x-snapsentry-backup-policy":  "daily",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := BackupConfig{}
+			if err := b.ParseFromMetadata(tt.metadata); err != nil {
+				t.Fatalf("ParseFromMetadata() unexpected error: %v", err)
+			}
+			if !b.Enabled {
+				t.Errorf("Enabled = false, want true")
+			}
+			if err := b.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBackupConfig_RoundTrip(t *testing.T) {
+	original := BackupConfig{Enabled: true, PolicyType: "monthly", RetentionDays: 365, Incremental: true, Container: "offsite"}
+
+	parsed := BackupConfig{}
+	if err := parsed.ParseFromMetadata(original.ToOpenstackMetadata()); err != nil {
+		t.Fatalf("ParseFromMetadata() unexpected error: %v", err)
+	}
+	if parsed != original {
+		t.Errorf("round trip = %+v, want %+v", parsed, original)
+	}
+}
+
+func TestBackupMetadata_RoundTrip(t *testing.T) {
+	original := BackupMetadata{
+		Managed:          true,
+		ExpiryDate:       time.Date(2026, 4, 1, 2, 0, 0, 0, time.UTC),
+		PolicyType:       "weekly",
+		RetentionDays:    90,
+		SourceSnapshotID: "5c1d7c38-7b0e-4a57-8f7e-2f1e0f6e2d11",
+	}
+
+	parsed := BackupMetadata{}
+	if err := parsed.ParseFromMetadata(original.ToOpenstackMetadata()); err != nil {
+		t.Fatalf("ParseFromMetadata() unexpected error: %v", err)
+	}
+	if !parsed.ExpiryDate.Equal(original.ExpiryDate) {
+		t.Errorf("ExpiryDate = %v, want %v", parsed.ExpiryDate, original.ExpiryDate)
+	}
+	parsed.ExpiryDate = original.ExpiryDate
+	if parsed != original {
+		t.Errorf("round trip = %+v, want %+v", parsed, original)
+	}
+
+	// A snapshot's metadata must not be mistaken for a backup source.
+	snap := SnapshotMetadata{Managed: true, PolicyType: "weekly"}
+	fromSnapshot := BackupMetadata{}
+	if err := fromSnapshot.ParseFromMetadata(snap.ToOpenstackMetadata()); err != nil {
+		t.Fatalf("ParseFromMetadata() unexpected error: %v", err)
+	}
+	if fromSnapshot.SourceSnapshotID != "" || fromSnapshot.PolicyType != "" {
+		t.Errorf("snapshot metadata parsed as backup metadata: %+v", fromSnapshot)
+	}
+}
@@ -0,0 +1,138 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// BlackoutWindow is a period in which no snapshot may be created in the project, e.g. during storage maintenance.
+// Snapshots due inside a blackout are deferred: the policy window stays open, so they are created by the
+// first run after the blackout closes.
+//
+// A window is written as one of:
+//   - "<weekday> HH:MM-HH:MM": Every week on that day, e.g. "saturday 00:00-06:00".
+//   - "daily HH:MM-HH:MM": Every day, e.g. "daily 22:00-23:00".
+//   - "<RFC3339>/<RFC3339>": Once, e.g. "2026-10-17T00:00:00Z/2026-10-17T06:00:00Z".
+//
+// Recurring windows may cross midnight ("friday 22:00-02:00" ends on Saturday) and are read in the
+// time zone given to ParseBlackoutWindow.
+type BlackoutWindow struct {
+	Spec string
+
+	// Internal fields for calculation
+	loc        *time.Location
+	daily      bool
+	weekday    time.Weekday
+	start, end time.Time // Clock times of a recurring window
+	from, to   time.Time // Bounds of a one-time window
+}
+
+// ParseBlackoutWindows parses every window with ParseBlackoutWindow.
+func ParseBlackoutWindows(specs []string, timezone string) ([]BlackoutWindow, error) {
+	windows := make([]BlackoutWindow, 0, len(specs))
+	for _, spec := range specs {
+		w, err := ParseBlackoutWindow(spec, timezone)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
+
+// ParseBlackoutWindow parses a window spec. Recurring windows use the given IANA time zone (default UTC).
+func ParseBlackoutWindow(spec, timezone string) (BlackoutWindow, error) {
+	w := BlackoutWindow{Spec: strings.TrimSpace(spec)}
+
+	if from, to, ok := strings.Cut(w.Spec, "/"); ok {
+		var err error
+		if w.from, err = time.Parse(time.RFC3339, from); err != nil {
+			return w, fmt.Errorf("invalid blackout window '%s': start must be RFC3339", spec)
+		}
+		if w.to, err = time.Parse(time.RFC3339, to); err != nil {
+			return w, fmt.Errorf("invalid blackout window '%s': end must be RFC3339", spec)
+		}
+		if !w.to.After(w.from) {
+			return w, fmt.Errorf("invalid blackout window '%s': end must be after start", spec)
+		}
+		return w, nil
+	}
+
+	fields := strings.Fields(w.Spec)
+	if len(fields) != 2 {
+		return w, fmt.Errorf("invalid blackout window '%s'; expected '<weekday|daily> HH:MM-HH:MM' or '<start>/<end>' in RFC3339", spec)
+	}
+
+	if day := strings.ToLower(fields[0]); day == "daily" {
+		w.daily = true
+	} else {
+		weekday, err := helperNormalizeDay(day)
+		if err != nil {
+			return w, fmt.Errorf("invalid blackout window '%s': %w", spec, err)
+		}
+		w.weekday = weekday
+	}
+
+	start, end, ok := strings.Cut(fields[1], "-")
+	if !ok {
+		return w, fmt.Errorf("invalid blackout window '%s'; expected a time range HH:MM-HH:MM", spec)
+	}
+	var err error
+	if w.start, err = helperNormalizeStartTime(start); err != nil {
+		return w, fmt.Errorf("invalid blackout window '%s': %w", spec, err)
+	}
+	if w.end, err = helperNormalizeStartTime(end); err != nil {
+		return w, fmt.Errorf("invalid blackout window '%s': %w", spec, err)
+	}
+	if w.start.Equal(w.end) {
+		return w, fmt.Errorf("invalid blackout window '%s': start and end are equal", spec)
+	}
+
+	_, w.loc, err = helperNormalizeTimezone(timezone)
+	if err != nil {
+		return w, err
+	}
+	return w, nil
+}
+
+// ActiveAt reports whether the window covers the given time, and when the covering occurrence ends.
+func (w BlackoutWindow) ActiveAt(now time.Time) (time.Time, bool) {
+	if w.loc == nil {
+		return w.to, !now.Before(w.from) && now.Before(w.to)
+	}
+
+	// An occurrence that crosses midnight may have started the day before.
+	local := now.In(w.loc)
+	for _, daysAgo := range []int{0, 1} {
+		day := local.AddDate(0, 0, -daysAgo)
+		if !w.daily && day.Weekday() != w.weekday {
+			continue
+		}
+
+		start := time.Date(day.Year(), day.Month(), day.Day(), w.start.Hour(), w.start.Minute(), w.start.Second(), 0, w.loc)
+		endDay := day
+		if !w.end.After(w.start) {
+			endDay = day.AddDate(0, 0, 1)
+		}
+		end := time.Date(endDay.Year(), endDay.Month(), endDay.Day(), w.end.Hour(), w.end.Minute(), w.end.Second(), 0, w.loc)
+
+		if !now.Before(start) && now.Before(end) {
+			return end, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// ActiveBlackout returns the window covering the given time. If several windows overlap,
+// the one ending last is returned, together with its end.
+func ActiveBlackout(windows []BlackoutWindow, now time.Time) (BlackoutWindow, time.Time, bool) {
+	var active BlackoutWindow
+	var until time.Time
+	for _, w := range windows {
+		if end, ok := w.ActiveAt(now); ok && end.After(until) {
+			active, until = w, end
+		}
+	}
+	return active, until, !until.IsZero()
+}
@@ -0,0 +1,121 @@
+package policy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseBlackoutWindow(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		wantErr bool
+	}{
+		{name: "Weekly", spec: "saturday 00:00-06:00", wantErr: false},
+		{name: "Short Weekday", spec: "Sat 22:00-02:00", wantErr: false},
+		{name: "Daily", spec: "daily 22:00-23:30", wantErr: false},
+		{name: "One Time", spec: "2026-10-17T00:00:00Z/2026-10-17T06:00:00Z", wantErr: false},
+		{name: "One Time Reversed", spec: "2026-10-17T06:00:00Z/2026-10-17T00:00:00Z", wantErr: true},
+		{name: "Unknown Day", spec: "someday 00:00-06:00", wantErr: true},
+		{name: "Missing Range", spec: "saturday 00:00", wantErr: true},
+		{name: "Empty Range", spec: "saturday 06:00-06:00", wantErr: true},
+		{name: "Invalid Time", spec: "saturday 25:00-06:00", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseBlackoutWindow(tt.spec, "UTC")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseBlackoutWindow() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBlackoutWindow_ActiveAt(t *testing.T) {
+	// 2026-10-17 is a Saturday.
+	tests := []struct {
+		name      string
+		spec      string
+		timezone  string
+		now       time.Time
+		wantOK    bool
+		wantUntil time.Time
+	}{
+		{
+			name:      "Inside Weekly Window",
+			spec:      "saturday 00:00-06:00",
+			now:       time.Date(2026, 10, 17, 3, 0, 0, 0, time.UTC),
+			wantOK:    true,
+			wantUntil: time.Date(2026, 10, 17, 6, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "End Is Exclusive",
+			spec:   "saturday 00:00-06:00",
+			now:    time.Date(2026, 10, 17, 6, 0, 0, 0, time.UTC),
+			wantOK: false,
+		},
+		{
+			name:   "Other Weekday",
+			spec:   "saturday 00:00-06:00",
+			now:    time.Date(2026, 10, 18, 3, 0, 0, 0, time.UTC),
+			wantOK: false,
+		},
+		{
+			name:      "Crossing Midnight",
+			spec:      "friday 22:00-02:00",
+			now:       time.Date(2026, 10, 17, 1, 0, 0, 0, time.UTC),
+			wantOK:    true,
+			wantUntil: time.Date(2026, 10, 17, 2, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "Daily In Time Zone",
+			spec:      "daily 02:00-04:00",
+			timezone:  "Europe/Berlin",
+			now:       time.Date(2026, 10, 17, 1, 0, 0, 0, time.UTC),
+			wantOK:    true,
+			wantUntil: time.Date(2026, 10, 17, 2, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "Inside One Time Window",
+			spec:      "2026-10-17T00:00:00Z/2026-10-17T06:00:00Z",
+			now:       time.Date(2026, 10, 17, 5, 59, 0, 0, time.UTC),
+			wantOK:    true,
+			wantUntil: time.Date(2026, 10, 17, 6, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "Before One Time Window",
+			spec:   "2026-10-17T00:00:00Z/2026-10-17T06:00:00Z",
+			now:    time.Date(2026, 10, 16, 23, 59, 0, 0, time.UTC),
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := ParseBlackoutWindow(tt.spec, tt.timezone)
+			if err != nil {
+				t.Fatalf("ParseBlackoutWindow() error = %v", err)
+			}
+			until, ok := w.ActiveAt(tt.now)
+			if ok != tt.wantOK {
+				t.Fatalf("ActiveAt() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && !until.Equal(tt.wantUntil) {
+				t.Errorf("ActiveAt() until = %v, want %v", until, tt.wantUntil)
+			}
+		})
+	}
+}
+
+func TestActiveBlackout_Overlap(t *testing.T) {
+	windows, err := ParseBlackoutWindows([]string{"saturday 00:00-04:00", "2026-10-17T02:00:00Z/2026-10-17T08:00:00Z"}, "UTC")
+	if err != nil {
+		t.Fatalf("ParseBlackoutWindows() error = %v", err)
+	}
+
+	w, until, ok := ActiveBlackout(windows, time.Date(2026, 10, 17, 3, 0, 0, 0, time.UTC))
+	if !ok || w.Spec != "2026-10-17T02:00:00Z/2026-10-17T08:00:00Z" || !until.Equal(time.Date(2026, 10, 17, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("ActiveBlackout() = %q, %v, %v", w.Spec, until, ok)
+	}
+}
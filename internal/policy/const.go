@@ -1,5 +1,7 @@
 package policy
 
 const (
-	ManagedTag = "x-snapsentry-managed" // Indicates that the volume/snapshot is managed by SnapSentry.
+	ManagedTag   = "x-snapsentry-managed"             // Indicates that the volume/snapshot is managed by SnapSentry.
+	PendingTag   = "x-snapsentry-snapshot-pending"    // Marks a snapshot awaited by the next snapshot run (SnapshotMetadata.Pending).
+	WindowKeyTag = "x-snapsentry-snapshot-window-key" // Idempotency token of the window a snapshot was created for (SnapshotWindowKey).
 )
@@ -0,0 +1,222 @@
+package policy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// cronParser accepts the standard 5-field format (minute hour day-of-month month day-of-week).
+// Descriptors such as "@every 1h" are not accepted because they are not anchored to wall-clock time,
+// which makes the previous fire time (and therefore the window start) undefined.
+var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+
+// cronMaxLookback bounds the search for the previous fire time.
+// It must exceed the longest possible gap between two fire times ("0 0 29 2 *" fires every 4 years,
+// up to 8 years around non-leap centuries).
+const cronMaxLookback = 8 * 366 * 24 * time.Hour
+
+// SnapshotPolicyCron implements the SnapshotPolicy interface for schedules driven by a cron expression.
+// It covers shapes the fixed policies cannot express, e.g. "weekdays at 02:00 and 14:00" (`0 2,14 * * 1-5`).
+//
+// Behavior:
+//   - Window: The active window is [previous fire time, next fire time), so its duration varies with the
+//     expression (e.g. Friday 14:00 -> Monday 02:00 for the example above).
+//   - Idempotency: Checks if a snapshot already exists within the current window.
+//   - Timezone: The expression is evaluated in the configured TimeZone. "TZ=" / "CRON_TZ=" prefixes are rejected
+//     in favour of the timezone key so the two cannot disagree.
+//
+// Fields:
+//   - Enabled: Master switch.
+//   - Expression: Standard 5-field cron expression.
+//   - RetentionDays: How long to keep the snapshot. Defaults to 7 days.
+//   - TimeZone: IANA timezone (e.g., "Europe/Berlin"). Defaults to UTC.
+//
+// Internal Fields:
+//   - Loc: Parsed time.Location.
+//   - schedule: Parsed cron schedule.
+type SnapshotPolicyCron struct {
+	Enabled        bool   `json:"x-snapsentry-cron-enabled"`
+	Expression     string `json:"x-snapsentry-cron-expression"`
+	RetentionDays  int    `json:"x-snapsentry-cron-retention-days"`
+	RetentionType  string `json:"x-snapsentry-cron-retention-type"`
+	RetentionCount int    `json:"x-snapsentry-cron-retention-count"`
+	TimeZone       string `json:"x-snapsentry-cron-timezone"`
+
+	// Internal fields for calculation
+	Loc      *time.Location
+	schedule cron.Schedule
+}
+
+// IsEnabled checks if the cron policy is active.
+func (s *SnapshotPolicyCron) IsEnabled() bool {
+	return s.Enabled
+}
+
+// GetPolicyType returns the unique identifier "cron".
+func (s *SnapshotPolicyCron) GetPolicyType() string {
+	return "cron"
+}
+
+// GetPolicyRetention returns the configured retention period in days.
+func (s *SnapshotPolicyCron) GetPolicyRetention() int {
+	return s.RetentionDays
+}
+
+// ParseFromMetadata hydrates the policy struct from a map of OpenStack metadata.
+func (s *SnapshotPolicyCron) ParseFromMetadata(metadata map[string]string) error {
+	parsed, err := ParseSnapSentryMetadataFromSDK[SnapshotPolicyCron](metadata)
+	if err != nil {
+		return err
+	}
+	*s = *parsed
+	return nil
+}
+
+// ToOpenstackMetadata serializes the policy configuration into OpenStack Volume metadata tags.
+// Keys: x-snapsentry-cron-*
+func (s *SnapshotPolicyCron) ToOpenstackMetadata() map[string]string {
+	return map[string]string{
+		ManagedTag:                          "true",
+		"x-snapsentry-cron-enabled":         strconv.FormatBool(s.Enabled),
+		"x-snapsentry-cron-expression":      s.Expression,
+		"x-snapsentry-cron-retention-days":  strconv.Itoa(s.RetentionDays),
+		"x-snapsentry-cron-retention-type":  s.RetentionType,
+		"x-snapsentry-cron-retention-count": strconv.Itoa(s.RetentionCount),
+		"x-snapsentry-cron-timezone":        s.TimeZone,
+	}
+}
+
+// Normalize validates inputs and sets defaults.
+//  1. TimeZone -> time.Location (Def: UTC)
+//  2. Retention -> int (Def: 7), RetentionType -> time/count (Def: time)
+//  3. Expression -> cron.Schedule (Required, 5 fields)
+func (s *SnapshotPolicyCron) Normalize() error {
+	// 1. Normalize Timezone
+	timezone, loc, err := helperNormalizeTimezone(s.TimeZone)
+	if err != nil {
+		return err
+	}
+	s.Loc = loc
+	s.TimeZone = timezone
+
+	// 2. Normalize Retention Days (Default to 7 days)
+	s.RetentionDays = helperNormalizeRetentionDays(s.RetentionDays, 7)
+
+	retentionType, err := helperNormalizeRetentionType(s.RetentionType, s.RetentionCount)
+	if err != nil {
+		return err
+	}
+	s.RetentionType = retentionType
+
+	// 3. Normalize Expression
+	expression := strings.Join(strings.Fields(s.Expression), " ")
+	if expression == "" {
+		return fmt.Errorf("cron expression is required")
+	}
+	if strings.HasPrefix(expression, "TZ=") || strings.HasPrefix(expression, "CRON_TZ=") {
+		return fmt.Errorf("cron expression '%s' must not carry a timezone prefix; use the timezone setting instead", expression)
+	}
+
+	schedule, err := cronParser.Parse(expression)
+	if err != nil {
+		return fmt.Errorf("invalid cron expression '%s': %w", expression, err)
+	}
+	s.schedule = schedule
+	s.Expression = expression
+
+	return nil
+}
+
+// Evaluate determines if a snapshot is required.
+//
+// Logic:
+//  1. Localizes 'now'.
+//  2. Computes the previous fire time (<= now) and the next fire time (> now) of the expression.
+//  3. Passes [previous, next) to helperEvaluateWindow.
+func (s *SnapshotPolicyCron) Evaluate(now time.Time, lastSnapshot LastSnapshotInfo) (PolicyEvalResult, error) {
+
+	// Initialize a result struct with sane defaults
+	result := PolicyEvalResult{
+		ShouldSnapshot: false,
+		Metadata:       SnapshotMetadata{},
+		Window:         SnapshotPolicyWindow{},
+	}
+
+	if !s.Enabled {
+		result.Reason = "Cron Snapshot Policy is disabled"
+		return result, nil
+	}
+
+	if s.schedule == nil {
+		return result, fmt.Errorf("cron policy must be normalized before evaluation")
+	}
+
+	// 1. Localize current time
+	referenceTime := now.In(s.Loc)
+
+	// 2. Calculate window bounds
+	windowStart, err := helperCronPrevious(s.schedule, referenceTime)
+	if err != nil {
+		return result, fmt.Errorf("cron expression '%s': %w", s.Expression, err)
+	}
+	windowEnd := s.schedule.Next(referenceTime)
+	if windowEnd.IsZero() {
+		return result, fmt.Errorf("cron expression '%s' has no upcoming fire time", s.Expression)
+	}
+
+	// 3. Localize last snapshot
+	localizedSnap := lastSnapshot
+	if !lastSnapshot.CreatedAt.IsZero() {
+		localizedSnap.CreatedAt = lastSnapshot.CreatedAt.In(s.Loc)
+	}
+
+	// 4. Delegate to Helper
+	// windowStart is guaranteed <= referenceTime, so the helper's "too early" shift never applies.
+	result = helperEvaluateWindow(referenceTime, windowStart, windowEnd.Sub(windowStart), localizedSnap)
+
+	if !result.ShouldSnapshot {
+		return result, nil
+	}
+
+	// 5. Success
+	result.Metadata = SnapshotMetadata{
+		Managed:        true,
+		ExpiryDate:     result.Window.StartTime.AddDate(0, 0, s.RetentionDays),
+		PolicyType:     "cron",
+		RetentionDays:  s.RetentionDays,
+		RetentionType:  s.RetentionType,
+		RetentionCount: s.RetentionCount,
+	}
+
+	return result, nil
+}
+
+// helperCronPrevious returns the latest fire time of the schedule that is at or before 'now'.
+//
+// The cron library only computes forward (Next is strictly "after"), so we look back from 'now'
+// with a doubling lookback until a fire time is found, then walk forward to the last one <= now.
+func helperCronPrevious(schedule cron.Schedule, now time.Time) (time.Time, error) {
+	for lookback := time.Hour; lookback <= cronMaxLookback; lookback *= 2 {
+		fire := schedule.Next(now.Add(-lookback))
+		if fire.IsZero() {
+			break
+		}
+		if fire.After(now) {
+			continue
+		}
+
+		for {
+			next := schedule.Next(fire)
+			if next.IsZero() || next.After(now) {
+				return fire, nil
+			}
+			fire = next
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("no previous fire time found")
+}
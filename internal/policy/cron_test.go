@@ -0,0 +1,257 @@
+package policy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSnapshotPolicyCron_Normalize(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          SnapshotPolicyCron
+		wantErr        bool
+		wantExpression string
+		wantRetDays    int
+	}{
+		{
+			name: "Happy Path (Weekdays twice a day)",
+			input: SnapshotPolicyCron{
+				Enabled:       true,
+				Expression:    "0 2,14 * * 1-5",
+				RetentionDays: 14,
+				TimeZone:      "UTC",
+			},
+			wantErr:        false,
+			wantExpression: "0 2,14 * * 1-5",
+			wantRetDays:    14,
+		},
+		{
+			name: "Default Values and Whitespace Cleanup",
+			input: SnapshotPolicyCron{
+				Enabled:       true,
+				Expression:    "  30   1 * * *  ",
+				RetentionDays: 0, // Should default to 7
+			},
+			wantErr:        false,
+			wantExpression: "30 1 * * *",
+			wantRetDays:    7,
+		},
+		{
+			name: "Missing Expression",
+			input: SnapshotPolicyCron{
+				Enabled: true,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid Expression (Hour 25)",
+			input: SnapshotPolicyCron{
+				Expression: "0 25 * * *",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid Expression (6 fields)",
+			input: SnapshotPolicyCron{
+				Expression: "0 0 2 * * *",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Descriptor not allowed (@every)",
+			input: SnapshotPolicyCron{
+				Expression: "@every 1h",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Timezone Prefix not allowed",
+			input: SnapshotPolicyCron{
+				Expression: "CRON_TZ=Europe/Paris 0 2 * * *",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid Timezone",
+			input: SnapshotPolicyCron{
+				Expression: "0 2 * * *",
+				TimeZone:   "Mars/Phobos",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := tt.input
+			err := policy.Normalize()
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Normalize() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr {
+				if policy.Expression != tt.wantExpression {
+					t.Errorf("Expression = %q, want %q", policy.Expression, tt.wantExpression)
+				}
+				if policy.RetentionDays != tt.wantRetDays {
+					t.Errorf("RetentionDays = %d, want %d", policy.RetentionDays, tt.wantRetDays)
+				}
+			}
+		})
+	}
+}
+
+func TestSnapshotPolicyCron_Evaluate(t *testing.T) {
+	// Setup a fixed timezone for testing (Paris = UTC+1 in Winter)
+	loc, _ := time.LoadLocation("Europe/Paris")
+
+	// Weekdays at 02:00 and 14:00.
+	// Dec 19, 2025 is a Friday, Dec 22, 2025 is a Monday.
+	const expression = "0 2,14 * * 1-5"
+
+	tests := []struct {
+		name            string
+		now             time.Time
+		lastSnap        LastSnapshotInfo
+		wantSnapshot    bool
+		wantWindowStart time.Time
+		wantWindowEnd   time.Time
+	}{
+		{
+			name:            "Fresh Volume (Friday afternoon)",
+			now:             time.Date(2025, 12, 19, 15, 0, 0, 0, loc),
+			lastSnap:        LastSnapshotInfo{},
+			wantSnapshot:    true,
+			wantWindowStart: time.Date(2025, 12, 19, 14, 0, 0, 0, loc),
+			wantWindowEnd:   time.Date(2025, 12, 22, 2, 0, 0, 0, loc),
+		},
+		{
+			name: "Idempotency (Weekend covered by Friday snapshot)",
+			now:  time.Date(2025, 12, 20, 10, 0, 0, 0, loc),
+			lastSnap: LastSnapshotInfo{
+				CreatedAt: time.Date(2025, 12, 19, 14, 5, 0, 0, loc),
+			},
+			wantSnapshot:    false,
+			wantWindowStart: time.Date(2025, 12, 19, 14, 0, 0, 0, loc),
+			wantWindowEnd:   time.Date(2025, 12, 22, 2, 0, 0, 0, loc),
+		},
+		{
+			name: "Idempotency (Monday before the first fire time)",
+			now:  time.Date(2025, 12, 22, 1, 0, 0, 0, loc),
+			lastSnap: LastSnapshotInfo{
+				CreatedAt: time.Date(2025, 12, 19, 14, 5, 0, 0, loc),
+			},
+			wantSnapshot:    false,
+			wantWindowStart: time.Date(2025, 12, 19, 14, 0, 0, 0, loc),
+			wantWindowEnd:   time.Date(2025, 12, 22, 2, 0, 0, 0, loc),
+		},
+		{
+			name: "New Window (Monday morning)",
+			now:  time.Date(2025, 12, 22, 3, 0, 0, 0, loc),
+			lastSnap: LastSnapshotInfo{
+				CreatedAt: time.Date(2025, 12, 19, 14, 5, 0, 0, loc),
+			},
+			wantSnapshot:    true,
+			wantWindowStart: time.Date(2025, 12, 22, 2, 0, 0, 0, loc),
+			wantWindowEnd:   time.Date(2025, 12, 22, 14, 0, 0, 0, loc),
+		},
+		{
+			name:            "Exact Boundary Start (Monday 14:00)",
+			now:             time.Date(2025, 12, 22, 14, 0, 0, 0, loc),
+			lastSnap:        LastSnapshotInfo{},
+			wantSnapshot:    true,
+			wantWindowStart: time.Date(2025, 12, 22, 14, 0, 0, 0, loc),
+			wantWindowEnd:   time.Date(2025, 12, 23, 2, 0, 0, 0, loc),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := SnapshotPolicyCron{
+				Enabled:       true,
+				Expression:    expression,
+				RetentionDays: 7,
+				TimeZone:      "Europe/Paris",
+			}
+			if err := policy.Normalize(); err != nil {
+				t.Fatalf("Normalize() unexpected error: %v", err)
+			}
+
+			result, err := policy.Evaluate(tt.now, tt.lastSnap)
+
+			// 1. Check Technical Errors
+			if err != nil {
+				t.Fatalf("Evaluate() unexpected error: %v", err)
+			}
+
+			// 2. Check Decision
+			if result.ShouldSnapshot != tt.wantSnapshot {
+				t.Errorf("ShouldSnapshot = %v, want %v. Reason: %s", result.ShouldSnapshot, tt.wantSnapshot, result.Reason)
+			}
+
+			// 3. Check Window
+			if !result.Window.StartTime.Equal(tt.wantWindowStart) || !result.Window.EndTime.Equal(tt.wantWindowEnd) {
+				t.Errorf("Window = [%s, %s), want [%s, %s)",
+					result.Window.StartTime, result.Window.EndTime, tt.wantWindowStart, tt.wantWindowEnd)
+			}
+
+			// 4. Check Metadata
+			if tt.wantSnapshot && result.Metadata.PolicyType != "cron" {
+				t.Errorf("PolicyType = %s, want cron", result.Metadata.PolicyType)
+			}
+		})
+	}
+}
+
+func TestHelperCronPrevious(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		now        time.Time
+		want       time.Time
+	}{
+		{
+			name:       "Same Day",
+			expression: "30 2 * * *",
+			now:        time.Date(2025, 12, 21, 10, 0, 0, 0, time.UTC),
+			want:       time.Date(2025, 12, 21, 2, 30, 0, 0, time.UTC),
+		},
+		{
+			name:       "Exactly on a fire time",
+			expression: "30 2 * * *",
+			now:        time.Date(2025, 12, 21, 2, 30, 0, 0, time.UTC),
+			want:       time.Date(2025, 12, 21, 2, 30, 0, 0, time.UTC),
+		},
+		{
+			name:       "Yearly (far lookback)",
+			expression: "0 0 1 1 *",
+			now:        time.Date(2025, 12, 21, 10, 0, 0, 0, time.UTC),
+			want:       time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "Leap Day",
+			expression: "0 0 29 2 *",
+			now:        time.Date(2027, 12, 21, 10, 0, 0, 0, time.UTC),
+			want:       time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := cronParser.Parse(tt.expression)
+			if err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+
+			got, err := helperCronPrevious(schedule, tt.now)
+			if err != nil {
+				t.Fatalf("helperCronPrevious() unexpected error: %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("helperCronPrevious() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -25,11 +25,12 @@ import (
 //   - Loc: The parsed time.Location object for timezone calculations.
 //   - startHour/startMinute: Integers parsed from StartTime for date arithmetic.
 type SnapshotPolicyDaily struct {
-	Enabled       bool   `json:"x-snapsentry-daily-enabled"`
-	RetentionDays int    `json:"x-snapsentry-daily-retention-days"`
-	RetentionType string `json:"x-snapsentry-daily-retention-type"`
-	TimeZone      string `json:"x-snapsentry-daily-timezone"`
-	StartTime     string `json:"x-snapsentry-daily-start-time"`
+	Enabled        bool   `json:"x-snapsentry-daily-enabled"`
+	RetentionDays  int    `json:"x-snapsentry-daily-retention-days"`
+	RetentionType  string `json:"x-snapsentry-daily-retention-type"`
+	RetentionCount int    `json:"x-snapsentry-daily-retention-count"`
+	TimeZone       string `json:"x-snapsentry-daily-timezone"`
+	StartTime      string `json:"x-snapsentry-daily-start-time"`
 
 	Loc         *time.Location
 	startHour   int
@@ -56,7 +57,7 @@ func (s *SnapshotPolicyDaily) GetPolicyRetention() int {
 // Normalize validates and prepares the policy for evaluation.
 // It performs the following operations:
 //  1. Parses the TimeZone string into a time.Location (defaults to UTC).
-//  2. Validates RetentionDays (defaults to 2 if <= 0).
+//  2. Validates RetentionDays (defaults to 2 if <= 0) and RetentionType (defaults to "time").
 //  3. Parses StartTime string ("HH:MM") into internal hour/minute integers.
 //
 // Returns an error if the TimeZone or StartTime formats are invalid.
@@ -72,6 +73,13 @@ func (s *SnapshotPolicyDaily) Normalize() error {
 	// Normalize Retention Days
 	s.RetentionDays = helperNormalizeRetentionDays(s.RetentionDays, 2)
 
+	// Normalize Retention Type (time / count)
+	retentionType, err := helperNormalizeRetentionType(s.RetentionType, s.RetentionCount)
+	if err != nil {
+		return err
+	}
+	s.RetentionType = retentionType
+
 	// Normalize Start Time
 	starttime, err := helperNormalizeStartTime(s.StartTime)
 	if err != nil {
@@ -88,12 +96,13 @@ func (s *SnapshotPolicyDaily) Normalize() error {
 // This allows the policy state to be persisted directly on the storage volume.
 func (s *SnapshotPolicyDaily) ToOpenstackMetadata() map[string]string {
 	return map[string]string{
-		ManagedTag:                          "true",
-		"x-snapsentry-daily-enabled":        strconv.FormatBool(s.Enabled),
-		"x-snapsentry-daily-retention-days": strconv.Itoa(s.RetentionDays),
-		"x-snapsentry-daily-retention-type": s.RetentionType,
-		"x-snapsentry-daily-timezone":       s.TimeZone,
-		"x-snapsentry-daily-start-time":     s.StartTime,
+		ManagedTag:                           "true",
+		"x-snapsentry-daily-enabled":         strconv.FormatBool(s.Enabled),
+		"x-snapsentry-daily-retention-days":  strconv.Itoa(s.RetentionDays),
+		"x-snapsentry-daily-retention-type":  s.RetentionType,
+		"x-snapsentry-daily-retention-count": strconv.Itoa(s.RetentionCount),
+		"x-snapsentry-daily-timezone":        s.TimeZone,
+		"x-snapsentry-daily-start-time":      s.StartTime,
 	}
 }
 
@@ -154,10 +163,12 @@ func (s *SnapshotPolicyDaily) Evaluate(now time.Time, lastSnapshot LastSnapshotI
 	}
 
 	result.Metadata = SnapshotMetadata{
-		Managed:       true,
-		ExpiryDate:    result.Window.StartTime.AddDate(0, 0, s.RetentionDays),
-		PolicyType:    "daily",
-		RetentionDays: s.RetentionDays,
+		Managed:        true,
+		ExpiryDate:     result.Window.StartTime.AddDate(0, 0, s.RetentionDays),
+		PolicyType:     "daily",
+		RetentionDays:  s.RetentionDays,
+		RetentionType:  s.RetentionType,
+		RetentionCount: s.RetentionCount,
 	}
 
 	return result, nil
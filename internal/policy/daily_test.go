@@ -134,6 +134,17 @@ func TestSnapshotPolicyDaily_Evaluate(t *testing.T) {
 			wantSnapshot:   false,
 			wantReasonPart: "already exists",
 		},
+		{
+			name: "Idempotency: Snapshot Still Creating (15:00 Paris)",
+			now:  time.Date(2025, 12, 21, 15, 0, 0, 0, loc),
+			lastSnap: LastSnapshotInfo{
+				CreatedAt: time.Date(2025, 12, 21, 14, 5, 0, 0, loc),
+				ID:        "snap-123",
+				Status:    SnapshotStatusCreating,
+			},
+			wantSnapshot:   false,
+			wantReasonPart: "still creating",
+		},
 		{
 			name: "Recovery Mode: Early Today, But Missed Yesterday",
 			// It is 10:00 AM (Early for today's 14:00 slot)
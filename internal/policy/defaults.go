@@ -0,0 +1,136 @@
+package policy
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Defaults is a file of default policies (YAML or JSON) for volumes that have no snapshot policy of their own.
+// The snapshot workflow evaluates such volumes as if the policies of the first matching entry were set on them,
+// without writing any metadata. A volume with an explicit policy key (see HasPolicy) keeps its own policies;
+// setting e.g. `x-snapsentry-daily-enabled=false` opts a volume out of the defaults.
+//
+// Example:
+//
+//	defaults:
+//	  - name: prod
+//	    projects: {tags: [prod]}
+//	    daily: {startTime: "02:00", timezone: Europe/Berlin, retention: 7}
+//	  - name: scratch-volumes
+//	    selector: {names: ["scratch-*"]}
+//	    express: {intervalHours: 4, retention: 1}
+type Defaults struct {
+	Defaults []DefaultsEntry `json:"defaults"`
+}
+
+// DefaultsEntry is a DocumentEntry that may also be limited to projects. Unlike in a Document, the volume
+// selector is optional: an entry without one applies to every volume of the matching projects.
+type DefaultsEntry struct {
+	DocumentEntry
+	Projects ProjectSelector `json:"projects,omitempty"`
+}
+
+// ProjectSelector matches the project a run is scoped to by ID, name or Keystone tag (any of them).
+// An empty selector matches every project.
+type ProjectSelector struct {
+	IDs   []string `json:"ids,omitempty"`
+	Names []string `json:"names,omitempty"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+// ProjectInfo is the project a run is scoped to, as matched by a ProjectSelector.
+type ProjectInfo struct {
+	ID   string
+	Name string
+	Tags []string
+}
+
+// ParseDefaults decodes and validates a defaults file. Unknown fields are rejected to catch typos.
+func ParseDefaults(data []byte) (Defaults, error) {
+	d := Defaults{}
+	if err := yaml.UnmarshalStrict(data, &d); err != nil {
+		return d, fmt.Errorf("failed to parse defaults file: %w", err)
+	}
+	if err := d.Validate(); err != nil {
+		return d, err
+	}
+	return d, nil
+}
+
+// Validate checks that entry names are unique, selectors are well-formed and every policy normalizes.
+func (d Defaults) Validate() error {
+	names := map[string]bool{}
+	for i, entry := range d.Defaults {
+		if entry.Name == "" {
+			return fmt.Errorf("defaults entry %d has no name", i+1)
+		}
+		if names[entry.Name] {
+			return fmt.Errorf("defaults entry name '%s' is used more than once", entry.Name)
+		}
+		names[entry.Name] = true
+
+		if !entry.Selector.IsEmpty() {
+			if err := entry.Selector.Validate(); err != nil {
+				return fmt.Errorf("defaults entry '%s': %w", entry.Name, err)
+			}
+		}
+		settings, err := entry.SettingsMetadata()
+		if err != nil {
+			return fmt.Errorf("defaults entry '%s': %w", entry.Name, err)
+		}
+		if len(settings) == 0 {
+			return fmt.Errorf("defaults entry '%s' sets no policy", entry.Name)
+		}
+	}
+	return nil
+}
+
+// NeedsProject reports whether an entry is limited to projects, i.e. whether the project must be looked up.
+func (d Defaults) NeedsProject() bool {
+	return slices.ContainsFunc(d.Defaults, func(e DefaultsEntry) bool { return !e.Projects.IsEmpty() })
+}
+
+// EffectiveMetadata returns the metadata a volume is evaluated with, and the name of the applied entry.
+// Volumes with an explicit policy, or matched by no entry, get their own metadata back and an empty name.
+// Otherwise the entry's settings are merged under the volume metadata, so keys set on the volume still win.
+func (d Defaults) EffectiveMetadata(project ProjectInfo, volumeID, volumeName string, metadata map[string]string) (map[string]string, string, error) {
+	if HasPolicy(metadata) {
+		return metadata, "", nil
+	}
+
+	for _, entry := range d.Defaults {
+		if !entry.Projects.Matches(project) || !entry.Selector.Matches(volumeID, volumeName, metadata) {
+			continue
+		}
+
+		settings, err := entry.SettingsMetadata()
+		if err != nil {
+			return metadata, "", fmt.Errorf("defaults entry '%s': %w", entry.Name, err)
+		}
+		effective := map[string]string{}
+		for _, m := range settings {
+			maps.Copy(effective, m)
+		}
+		maps.Copy(effective, metadata)
+		return effective, entry.Name, nil
+	}
+	return metadata, "", nil
+}
+
+// IsEmpty reports whether the selector has no criterion.
+func (s ProjectSelector) IsEmpty() bool {
+	return len(s.IDs) == 0 && len(s.Names) == 0 && len(s.Tags) == 0
+}
+
+// Matches reports whether the project is selected.
+func (s ProjectSelector) Matches(p ProjectInfo) bool {
+	if s.IsEmpty() {
+		return true
+	}
+	return slices.Contains(s.IDs, p.ID) ||
+		slices.Contains(s.Names, p.Name) ||
+		slices.ContainsFunc(s.Tags, func(tag string) bool { return slices.Contains(p.Tags, tag) })
+}
@@ -0,0 +1,194 @@
+package policy
+
+import (
+	"testing"
+)
+
+func TestParseDefaults(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		wantErr bool
+	}{
+		{
+			name: "Valid Without Selector",
+			doc: `
+defaults:
+  - name: prod
+    projects: {tags: [prod]}
+    daily: {startTime: "02:00", timezone: Europe/Berlin, retention: 7}
+`,
+			wantErr: false,
+		},
+		{
+			name: "Valid With Volume Selector",
+			doc: `
+defaults:
+  - name: scratch
+    selector: {names: ["scratch-*"]}
+    express: {intervalHours: 4, retention: 1}
+`,
+			wantErr: false,
+		},
+		{
+			name: "Unknown Field",
+			doc: `
+defaults:
+  - name: prod
+    project: {tags: [prod]}
+    daily: {startTime: "02:00", retention: 7}
+`,
+			wantErr: true,
+		},
+		{
+			name: "No Policy",
+			doc: `
+defaults:
+  - name: prod
+    projects: {tags: [prod]}
+`,
+			wantErr: true,
+		},
+		{
+			name: "Duplicate Name",
+			doc: `
+defaults:
+  - name: prod
+    daily: {startTime: "02:00", retention: 7}
+  - name: prod
+    weekly: {startTime: "03:00", retention: 28}
+`,
+			wantErr: true,
+		},
+		{
+			name: "Invalid Glob",
+			doc: `
+defaults:
+  - name: broken
+    selector: {names: ["db-["]}
+    daily: {startTime: "02:00", retention: 7}
+`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseDefaults([]byte(tt.doc))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseDefaults() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDefaults_EffectiveMetadata(t *testing.T) {
+	defaults, err := ParseDefaults([]byte(`
+defaults:
+  - name: prod
+    projects: {tags: [prod]}
+    daily: {startTime: "02:00", retention: 7}
+  - name: scratch
+    selector: {names: ["scratch-*"]}
+    express: {intervalHours: 4, retention: 1}
+`))
+	if err != nil {
+		t.Fatalf("ParseDefaults() error = %v", err)
+	}
+
+	prod := ProjectInfo{ID: "p-1", Name: "shop", Tags: []string{"snapsentry-enabled", "prod"}}
+	dev := ProjectInfo{ID: "p-2", Name: "shop-dev", Tags: []string{"snapsentry-enabled"}}
+
+	tests := []struct {
+		name        string
+		project     ProjectInfo
+		volumeName  string
+		metadata    map[string]string
+		wantEntry   string
+		wantKey     string
+		wantValue   string
+		wantMissing string
+	}{
+		{
+			name:       "Project Tag Applies",
+			project:    prod,
+			volumeName: "data",
+			metadata:   map[string]string{},
+			wantEntry:  "prod",
+			wantKey:    "x-snapsentry-daily-retention-days",
+			wantValue:  "7",
+		},
+		{
+			name:        "First Matching Entry Wins",
+			project:     prod,
+			volumeName:  "scratch-1",
+			metadata:    map[string]string{},
+			wantEntry:   "prod",
+			wantKey:     "x-snapsentry-daily-enabled",
+			wantValue:   "true",
+			wantMissing: "x-snapsentry-express-enabled",
+		},
+		{
+			name:       "Volume Selector In Other Project",
+			project:    dev,
+			volumeName: "scratch-1",
+			metadata:   map[string]string{},
+			wantEntry:  "scratch",
+			wantKey:    "x-snapsentry-express-enabled",
+			wantValue:  "true",
+		},
+		{
+			name:        "No Entry Matches",
+			project:     dev,
+			volumeName:  "data",
+			metadata:    map[string]string{},
+			wantEntry:   "",
+			wantMissing: "x-snapsentry-daily-enabled",
+		},
+		{
+			name:        "Explicit Policy Overrides",
+			project:     prod,
+			volumeName:  "data",
+			metadata:    map[string]string{"x-snapsentry-weekly-enabled": "true"},
+			wantEntry:   "",
+			wantMissing: "x-snapsentry-daily-enabled",
+		},
+		{
+			name:        "Disabled Policy Opts Out",
+			project:     prod,
+			volumeName:  "data",
+			metadata:    map[string]string{"x-snapsentry-daily-enabled": "false"},
+			wantEntry:   "",
+			wantKey:     "x-snapsentry-daily-enabled",
+			wantValue:   "false",
+			wantMissing: "x-snapsentry-daily-retention-days",
+		},
+		{
+			name:       "Other Settings Are Combined",
+			project:    prod,
+			volumeName: "data",
+			metadata:   map[string]string{"x-snapsentry-gfs-enabled": "true"},
+			wantEntry:  "prod",
+			wantKey:    "x-snapsentry-gfs-enabled",
+			wantValue:  "true",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, entry, err := defaults.EffectiveMetadata(tt.project, "vol-1", tt.volumeName, tt.metadata)
+			if err != nil {
+				t.Fatalf("EffectiveMetadata() error = %v", err)
+			}
+			if entry != tt.wantEntry {
+				t.Errorf("EffectiveMetadata() entry = %q, want %q", entry, tt.wantEntry)
+			}
+			if tt.wantKey != "" && got[tt.wantKey] != tt.wantValue {
+				t.Errorf("EffectiveMetadata()[%s] = %q, want %q", tt.wantKey, got[tt.wantKey], tt.wantValue)
+			}
+			if _, ok := got[tt.wantMissing]; tt.wantMissing != "" && ok {
+				t.Errorf("EffectiveMetadata() has unexpected key %s", tt.wantMissing)
+			}
+		})
+	}
+}
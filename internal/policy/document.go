@@ -0,0 +1,214 @@
+package policy
+
+import (
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+
+	"sigs.k8s.io/yaml"
+)
+
+// DocumentSettings are the volume settings managed by a policy Document. Quiesce hooks, backups and
+// group snapshots stay with `subscribe` and are never changed or pruned by a Document.
+var DocumentSettings = []string{"express", "cron", "daily", "weekly", "monthly", "gfs"}
+
+// Document is a declarative policy file (YAML or JSON). Each entry maps a volume selector to the snapshot
+// policies its volumes should have; `apply -f` reconciles the x-snapsentry-* metadata of the volumes with it.
+//
+// Example:
+//
+//	policies:
+//	  - name: prod-databases
+//	    selector:
+//	      names: ["db-*"]
+//	      metadata: {env: prod}
+//	    daily: {startTime: "02:00", timezone: Europe/Berlin, retention: 7}
+//	    weekly: {startTime: "03:00", weekDay: sunday, retentionCount: 4}
+//	    gfs: true
+type Document struct {
+	Policies []DocumentEntry `json:"policies"`
+}
+
+// DocumentEntry assigns snapshot policies to the volumes matched by Selector. A policy that is not set
+// is not managed by the entry. A volume matched by several entries gets the policies of all of them,
+// but two entries cannot set the same policy on one volume.
+type DocumentEntry struct {
+	Name     string           `json:"name"`
+	Selector DocumentSelector `json:"selector"`
+	Express  *ScheduleSpec    `json:"express,omitempty"`
+	Cron     *ScheduleSpec    `json:"cron,omitempty"`
+	Daily    *ScheduleSpec    `json:"daily,omitempty"`
+	Weekly   *ScheduleSpec    `json:"weekly,omitempty"`
+	Monthly  *ScheduleSpec    `json:"monthly,omitempty"`
+	GFS      bool             `json:"gfs,omitempty"`
+}
+
+// DocumentSelector matches volumes by ID or name glob (any of them), and by metadata (all pairs).
+// At least one criterion is required, so an entry never matches every volume by accident.
+type DocumentSelector struct {
+	VolumeIDs []string          `json:"volumeIDs,omitempty"`
+	Names     []string          `json:"names,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+}
+
+// ScheduleSpec is the schedule and retention of one policy, with the same meaning as the `subscribe` flags.
+// Fields that do not apply to the policy type (e.g. WeekDay for a daily policy) are ignored.
+type ScheduleSpec struct {
+	StartTime       string `json:"startTime,omitempty"`
+	TimeZone        string `json:"timezone,omitempty"`
+	Retention       int    `json:"retention,omitempty"`
+	RetentionCount  int    `json:"retentionCount,omitempty"`
+	WeekDay         string `json:"weekDay,omitempty"`
+	MonthDay        int    `json:"monthDay,omitempty"`
+	IntervalHours   int    `json:"intervalHours,omitempty"`
+	IntervalMinutes int    `json:"intervalMinutes,omitempty"`
+	Expression      string `json:"expression,omitempty"`
+}
+
+// ParseDocument decodes and validates a policy document. Unknown fields are rejected to catch typos.
+func ParseDocument(data []byte) (Document, error) {
+	doc := Document{}
+	if err := yaml.UnmarshalStrict(data, &doc); err != nil {
+		return doc, fmt.Errorf("failed to parse policy document: %w", err)
+	}
+	if err := doc.Validate(); err != nil {
+		return doc, err
+	}
+	return doc, nil
+}
+
+// Validate checks that entry names are unique, selectors are usable and every policy normalizes.
+func (d Document) Validate() error {
+	names := map[string]bool{}
+	for i, entry := range d.Policies {
+		if entry.Name == "" {
+			return fmt.Errorf("policy entry %d has no name", i+1)
+		}
+		if names[entry.Name] {
+			return fmt.Errorf("policy entry name '%s' is used more than once", entry.Name)
+		}
+		names[entry.Name] = true
+
+		if err := entry.Selector.Validate(); err != nil {
+			return fmt.Errorf("policy entry '%s': %w", entry.Name, err)
+		}
+		settings, err := entry.SettingsMetadata()
+		if err != nil {
+			return fmt.Errorf("policy entry '%s': %w", entry.Name, err)
+		}
+		if len(settings) == 0 {
+			return fmt.Errorf("policy entry '%s' sets no policy", entry.Name)
+		}
+	}
+	return nil
+}
+
+// Validate checks that the selector has a criterion and that its name globs are well-formed.
+func (s DocumentSelector) Validate() error {
+	if s.IsEmpty() {
+		return fmt.Errorf("selector needs at least one of volumeIDs, names or metadata")
+	}
+	for _, glob := range s.Names {
+		if _, err := path.Match(glob, ""); err != nil {
+			return fmt.Errorf("invalid name glob '%s': %w", glob, err)
+		}
+	}
+	return nil
+}
+
+// IsEmpty reports whether the selector has no criterion.
+func (s DocumentSelector) IsEmpty() bool {
+	return len(s.VolumeIDs) == 0 && len(s.Names) == 0 && len(s.Metadata) == 0
+}
+
+// Matches reports whether a volume is selected.
+func (s DocumentSelector) Matches(volumeID, volumeName string, metadata map[string]string) bool {
+	if len(s.VolumeIDs) > 0 || len(s.Names) > 0 {
+		byName := slices.ContainsFunc(s.Names, func(glob string) bool {
+			matched, _ := path.Match(glob, volumeName)
+			return matched
+		})
+		if !byName && !slices.Contains(s.VolumeIDs, volumeID) {
+			return false
+		}
+	}
+	for key, value := range s.Metadata {
+		if actual, ok := metadata[key]; !ok || actual != value {
+			return false
+		}
+	}
+	return true
+}
+
+// SettingsMetadata returns the normalized volume metadata of every setting the entry manages, by setting name
+// (see DocumentSettings). The maps carry no ManagedTag.
+func (e DocumentEntry) SettingsMetadata() (map[string]map[string]string, error) {
+	var policies []SnapshotPolicy
+	if s := e.Express; s != nil {
+		policies = append(policies, &SnapshotPolicyExpress{
+			Enabled: true, RetentionDays: s.Retention, RetentionType: s.retentionType(), RetentionCount: s.RetentionCount,
+			IntervalHours: s.IntervalHours, IntervalMinutes: s.IntervalMinutes, StartTime: s.StartTime, TimeZone: s.TimeZone,
+		})
+	}
+	if s := e.Cron; s != nil {
+		policies = append(policies, &SnapshotPolicyCron{
+			Enabled: true, RetentionDays: s.Retention, RetentionType: s.retentionType(), RetentionCount: s.RetentionCount,
+			Expression: s.Expression, TimeZone: s.TimeZone,
+		})
+	}
+	if s := e.Daily; s != nil {
+		policies = append(policies, &SnapshotPolicyDaily{
+			Enabled: true, RetentionDays: s.Retention, RetentionType: s.retentionType(), RetentionCount: s.RetentionCount,
+			StartTime: s.StartTime, TimeZone: s.TimeZone,
+		})
+	}
+	if s := e.Weekly; s != nil {
+		weekDay := s.WeekDay
+		if weekDay == "" {
+			weekDay = "sunday"
+		}
+		policies = append(policies, &SnapshotPolicyWeekly{
+			Enabled: true, RetentionDays: s.Retention, RetentionType: s.retentionType(), RetentionCount: s.RetentionCount,
+			StartTime: s.StartTime, TimeZone: s.TimeZone, DayOfWeek: weekDay,
+		})
+	}
+	if s := e.Monthly; s != nil {
+		monthDay := s.MonthDay
+		if monthDay == 0 {
+			monthDay = 1
+		}
+		policies = append(policies, &SnapshotPolicyMonthly{
+			Enabled: true, RetentionDays: s.Retention, RetentionType: s.retentionType(), RetentionCount: s.RetentionCount,
+			StartTime: s.StartTime, TimeZone: s.TimeZone, DayOfMonth: monthDay,
+		})
+	}
+
+	settings := map[string]map[string]string{}
+	for _, p := range policies {
+		if err := p.Normalize(); err != nil {
+			return nil, fmt.Errorf("%s policy: %w", p.GetPolicyType(), err)
+		}
+		settings[p.GetPolicyType()] = withoutManagedTag(p.ToOpenstackMetadata())
+	}
+	if e.GFS {
+		gfs := GFSConfig{Enabled: true}
+		settings["gfs"] = withoutManagedTag(gfs.ToOpenstackMetadata())
+	}
+	return settings, nil
+}
+
+// retentionType picks count based retention when a retention count is set, like the `subscribe` commands.
+func (s ScheduleSpec) retentionType() string {
+	if s.RetentionCount > 0 {
+		return RetentionTypeCount
+	}
+	return RetentionTypeTime
+}
+
+// withoutManagedTag returns a copy of the metadata without ManagedTag.
+func withoutManagedTag(metadata map[string]string) map[string]string {
+	out := maps.Clone(metadata)
+	delete(out, ManagedTag)
+	return out
+}
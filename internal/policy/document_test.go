@@ -0,0 +1,166 @@
+package policy
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestParseDocument(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		wantErr bool
+	}{
+		{
+			name: "Valid YAML",
+			doc: `
+policies:
+  - name: prod-databases
+    selector:
+      names: ["db-*"]
+      metadata: {env: prod}
+    daily: {startTime: "02:00", timezone: Europe/Berlin, retention: 7}
+    weekly: {startTime: "03:00", weekDay: sunday, retentionCount: 4}
+    gfs: true
+`,
+			wantErr: false,
+		},
+		{
+			name:    "Valid JSON",
+			doc:     `{"policies": [{"name": "scratch", "selector": {"volumeIDs": ["vol-1"]}, "express": {"intervalHours": 4, "retention": 1}}]}`,
+			wantErr: false,
+		},
+		{
+			name: "Unknown Field",
+			doc: `
+policies:
+  - name: typo
+    selector: {names: ["db-*"]}
+    daily: {startTime: "02:00", retention: 7, retentionDays: 7}
+`,
+			wantErr: true,
+		},
+		{
+			name: "Empty Selector",
+			doc: `
+policies:
+  - name: everything
+    selector: {}
+    daily: {startTime: "02:00", retention: 7}
+`,
+			wantErr: true,
+		},
+		{
+			name: "Bad Name Glob",
+			doc: `
+policies:
+  - name: broken
+    selector: {names: ["db-["]}
+    daily: {startTime: "02:00", retention: 7}
+`,
+			wantErr: true,
+		},
+		{
+			name: "Duplicate Entry Name",
+			doc: `
+policies:
+  - name: dup
+    selector: {names: ["a-*"]}
+    daily: {startTime: "02:00", retention: 7}
+  - name: dup
+    selector: {names: ["b-*"]}
+    daily: {startTime: "02:00", retention: 7}
+`,
+			wantErr: true,
+		},
+		{
+			name: "Invalid Policy",
+			doc: `
+policies:
+  - name: bad-time
+    selector: {names: ["db-*"]}
+    daily: {startTime: "25:00", retention: 7}
+`,
+			wantErr: true,
+		},
+		{
+			name: "No Policy",
+			doc: `
+policies:
+  - name: nothing
+    selector: {names: ["db-*"]}
+`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseDocument([]byte(tt.doc))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseDocument() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDocumentSelector_Matches(t *testing.T) {
+	selector := DocumentSelector{
+		VolumeIDs: []string{"vol-1"},
+		Names:     []string{"db-*"},
+		Metadata:  map[string]string{"env": "prod"},
+	}
+
+	tests := []struct {
+		name     string
+		volumeID string
+		volName  string
+		metadata map[string]string
+		want     bool
+	}{
+		{name: "Name Glob and Metadata", volumeID: "vol-9", volName: "db-orders", metadata: map[string]string{"env": "prod"}, want: true},
+		{name: "Volume ID and Metadata", volumeID: "vol-1", volName: "scratch", metadata: map[string]string{"env": "prod"}, want: true},
+		{name: "Metadata Mismatch", volumeID: "vol-9", volName: "db-orders", metadata: map[string]string{"env": "dev"}, want: false},
+		{name: "Neither ID nor Name", volumeID: "vol-9", volName: "web-1", metadata: map[string]string{"env": "prod"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := selector.Matches(tt.volumeID, tt.volName, tt.metadata); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDocumentEntry_SettingsMetadata(t *testing.T) {
+	entry := DocumentEntry{
+		Name:     "prod",
+		Selector: DocumentSelector{Names: []string{"db-*"}},
+		Daily:    &ScheduleSpec{StartTime: "2:00", TimeZone: "Europe/Berlin", RetentionCount: 7},
+		GFS:      true,
+	}
+
+	settings, err := entry.SettingsMetadata()
+	if err != nil {
+		t.Fatalf("SettingsMetadata() unexpected error: %v", err)
+	}
+
+	// The document must produce exactly what `subscribe daily --retention-count 7` writes.
+	daily := SnapshotPolicyDaily{Enabled: true, RetentionType: RetentionTypeCount, RetentionCount: 7, StartTime: "02:00", TimeZone: "Europe/Berlin"}
+	if err := daily.Normalize(); err != nil {
+		t.Fatalf("Normalize() unexpected error: %v", err)
+	}
+	want := daily.ToOpenstackMetadata()
+	delete(want, ManagedTag)
+
+	if !maps.Equal(settings["daily"], want) {
+		t.Errorf("daily metadata = %v, want %v", settings["daily"], want)
+	}
+	if settings["gfs"]["x-snapsentry-gfs-enabled"] != "true" {
+		t.Errorf("gfs metadata = %v, want enabled", settings["gfs"])
+	}
+	if len(settings) != 2 {
+		t.Errorf("SettingsMetadata() returned %d settings, want 2", len(settings))
+	}
+}
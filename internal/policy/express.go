@@ -6,15 +6,35 @@ import (
 	"time"
 )
 
+// expressMinIntervalMinutes is the shortest supported express interval.
+// Shorter slots leave too little room for a run (including its API retries) to finish inside the slot.
+const expressMinIntervalMinutes = 15
+
+// SnapshotPolicyExpress implements the SnapshotPolicy interface for high-frequency snapshots.
+// It divides the day into fixed slots of IntervalHours (or IntervalMinutes) starting at StartTime.
+//
+// Behavior:
+//   - Window: Each slot is one window, e.g. every 4h anchored at 01:30 gives 01:30-05:30, 05:30-09:30, ...
+//   - Idempotency: Checks if a snapshot already exists within the current slot.
+//
+// Fields:
+//   - IntervalHours: Slot length in hours. Must divide 24 (1, 2, 3, 4, 6, 8, 12). Defaults to 6.
+//   - IntervalMinutes: Optional slot length in minutes, for sub-hour slots (e.g. 15, 30, 90).
+//     Takes precedence over IntervalHours when set and must divide 24h.
+//   - StartTime: Anchor of the slots in "HH:MM" format. Defaults to "00:00" (midnight).
 type SnapshotPolicyExpress struct {
-	Enabled       bool   `json:"x-snapsentry-express-enabled"`
-	IntervalHours int    `json:"x-snapsentry-express-interval-hours"`
-	RetentionDays int    `json:"x-snapsentry-express-retention-days"`
-	RetentionType string `json:"x-snapsentry-express-retention-type"`
-	TimeZone      string `json:"x-snapsentry-express-timezone"`
+	Enabled         bool   `json:"x-snapsentry-express-enabled"`
+	IntervalHours   int    `json:"x-snapsentry-express-interval-hours"`
+	IntervalMinutes int    `json:"x-snapsentry-express-interval-minutes"`
+	StartTime       string `json:"x-snapsentry-express-start-time"`
+	RetentionDays   int    `json:"x-snapsentry-express-retention-days"`
+	RetentionType   string `json:"x-snapsentry-express-retention-type"`
+	RetentionCount  int    `json:"x-snapsentry-express-retention-count"`
+	TimeZone        string `json:"x-snapsentry-express-timezone"`
 
 	// Internal fields that would be poluplated during normalize
 	Loc         *time.Location
+	interval    time.Duration
 	startHour   int
 	startMinute int
 }
@@ -24,7 +44,7 @@ func (s *SnapshotPolicyExpress) IsEnabled() bool {
 	return s.Enabled
 }
 
-// GetPolicyType returns the unique identifier "interval".
+// GetPolicyType returns the unique identifier "express".
 func (s *SnapshotPolicyExpress) GetPolicyType() string {
 	return "express"
 }
@@ -43,24 +63,46 @@ func (s *SnapshotPolicyExpress) Normalize() error {
 	s.Loc = loc
 	s.TimeZone = timezone
 
-	// 2. Normalize Interval Hours
-	if s.IntervalHours <= 0 {
-		s.IntervalHours = 6 // Default to 6
+	// 2. Normalize Interval (Hours, or Minutes for sub-hour slots)
+	if s.IntervalMinutes > 0 {
+		if s.IntervalMinutes < expressMinIntervalMinutes || s.IntervalMinutes >= 24*60 || (24*60)%s.IntervalMinutes != 0 {
+			return fmt.Errorf("express interval must divide 24 hours and be at least %d minutes; got %d minutes",
+				expressMinIntervalMinutes, s.IntervalMinutes)
+		}
+		// Minutes take precedence; the hours key is cleared so the two cannot disagree.
+		s.IntervalHours = 0
+		s.interval = time.Duration(s.IntervalMinutes) * time.Minute
+	} else {
+		s.IntervalMinutes = 0
+		if s.IntervalHours <= 0 {
+			s.IntervalHours = 6 // Default to 6
+		}
+
+		// 24 hours is rejected on purpose: that is the Daily policy.
+		if s.IntervalHours >= 24 || 24%s.IntervalHours != 0 {
+			return fmt.Errorf("express interval must divide 24 hours (1, 2, 3, 4, 6, 8, or 12); got %d", s.IntervalHours)
+		}
+		s.interval = time.Duration(s.IntervalHours) * time.Hour
 	}
 
-	switch s.IntervalHours {
-	case 6, 8, 12:
-		// Valid
-	case 0:
-		s.IntervalHours = 6
-	default:
-		return fmt.Errorf("express interval must be 6, 8, or 12 hours; got %d", s.IntervalHours)
+	// 3. Normalize Anchor Start Time (Default to midnight)
+	starttime, err := helperNormalizeStartTime(s.StartTime)
+	if err != nil {
+		return err
 	}
+	s.startHour = starttime.Hour()
+	s.startMinute = starttime.Minute()
+	s.StartTime = starttime.Format("15:04")
 
 	// 4. Normalize Retention Days (default to 1 day for high-frequency snapshots)
 	s.RetentionDays = helperNormalizeRetentionDays(s.RetentionDays, 1)
-	s.startHour = 00
-	s.startMinute = 00
+
+	// Normalize Retention Type (time / count)
+	retentionType, err := helperNormalizeRetentionType(s.RetentionType, s.RetentionCount)
+	if err != nil {
+		return err
+	}
+	s.RetentionType = retentionType
 
 	return nil
 }
@@ -69,12 +111,15 @@ func (s *SnapshotPolicyExpress) Normalize() error {
 // This allows the policy state to be persisted directly on the storage volume.
 func (s *SnapshotPolicyExpress) ToOpenstackMetadata() map[string]string {
 	return map[string]string{
-		ManagedTag:                            "true",
-		"x-snapsentry-express-enabled":        strconv.FormatBool(s.Enabled),
-		"x-snapsentry-express-retention-days": strconv.Itoa(s.RetentionDays),
-		"x-snapsentry-express-retention-type": s.RetentionType,
-		"x-snapsentry-express-timezone":       s.TimeZone,
-		"x-snapsentry-express-interval-hours": strconv.Itoa(s.IntervalHours),
+		ManagedTag:                              "true",
+		"x-snapsentry-express-enabled":          strconv.FormatBool(s.Enabled),
+		"x-snapsentry-express-retention-days":   strconv.Itoa(s.RetentionDays),
+		"x-snapsentry-express-retention-type":   s.RetentionType,
+		"x-snapsentry-express-retention-count":  strconv.Itoa(s.RetentionCount),
+		"x-snapsentry-express-timezone":         s.TimeZone,
+		"x-snapsentry-express-interval-hours":   strconv.Itoa(s.IntervalHours),
+		"x-snapsentry-express-interval-minutes": strconv.Itoa(s.IntervalMinutes),
+		"x-snapsentry-express-start-time":       s.StartTime,
 	}
 }
 
@@ -103,13 +148,19 @@ func (s *SnapshotPolicyExpress) Evaluate(now time.Time, lastSnapshot LastSnapsho
 
 	referenceTime := now.In(s.Loc)
 
-	// Calculate the current start time slot
-	year, month, day := referenceTime.Date()
-	midnight := time.Date(year, month, day, 0, 0, 0, 0, s.Loc)
-	currentHour := referenceTime.Hour()
+	if s.interval <= 0 {
+		return result, fmt.Errorf("express policy must be normalized before evaluation")
+	}
 
-	slotStartHour := (currentHour / s.IntervalHours) * s.IntervalHours
-	windowStart := midnight.Add(time.Duration(slotStartHour) * time.Hour)
+	// Calculate the current start time slot.
+	// Slots repeat every day because the interval divides 24h, so today's anchor plus the whole
+	// slots elapsed since then is the current slot. Before today's anchor, helperEvaluateWindow
+	// walks back into the slots that started yesterday.
+	year, month, day := referenceTime.Date()
+	windowStart := time.Date(year, month, day, s.startHour, s.startMinute, 0, 0, s.Loc)
+	if elapsed := referenceTime.Sub(windowStart); elapsed > 0 {
+		windowStart = windowStart.Add(elapsed / s.interval * s.interval)
+	}
 
 	// We must ensure lastSnapshot is also localized before passing, or handle it in helper.
 	// Let's localize here for safety.
@@ -118,17 +169,19 @@ func (s *SnapshotPolicyExpress) Evaluate(now time.Time, lastSnapshot LastSnapsho
 		localizedSnap.CreatedAt = lastSnapshot.CreatedAt.In(s.Loc)
 	}
 
-	result = helperEvaluateWindow(referenceTime, windowStart, time.Duration(s.IntervalHours)*time.Hour, localizedSnap)
+	result = helperEvaluateWindow(referenceTime, windowStart, s.interval, localizedSnap)
 
 	if !result.ShouldSnapshot {
 		return result, nil
 	}
 
 	result.Metadata = SnapshotMetadata{
-		Managed:       true,
-		ExpiryDate:    result.Window.StartTime.AddDate(0, 0, s.RetentionDays),
-		PolicyType:    "express",
-		RetentionDays: s.RetentionDays,
+		Managed:        true,
+		ExpiryDate:     result.Window.StartTime.AddDate(0, 0, s.RetentionDays),
+		PolicyType:     "express",
+		RetentionDays:  s.RetentionDays,
+		RetentionType:  s.RetentionType,
+		RetentionCount: s.RetentionCount,
 	}
 
 	return result, nil
@@ -7,11 +7,13 @@ import (
 
 func TestSnapshotPolicyExpress_Normalize(t *testing.T) {
 	tests := []struct {
-		name         string
-		input        SnapshotPolicyExpress
-		wantErr      bool
-		wantInterval int
-		wantRetDays  int
+		name          string
+		input         SnapshotPolicyExpress
+		wantErr       bool
+		wantInterval  int
+		wantMinutes   int
+		wantRetDays   int
+		wantStartTime string
 	}{
 		{
 			name: "Happy Path (6 Hours)",
@@ -53,7 +55,69 @@ func TestSnapshotPolicyExpress_Normalize(t *testing.T) {
 			name: "Invalid Interval (5 Hours)",
 			input: SnapshotPolicyExpress{
 				Enabled:       true,
-				IntervalHours: 5, // Not 6, 8, or 12
+				IntervalHours: 5, // Does not divide 24
+			},
+			wantErr: true,
+		},
+		{
+			name: "Happy Path (1 Hour)",
+			input: SnapshotPolicyExpress{
+				Enabled:       true,
+				IntervalHours: 1,
+				RetentionDays: 1,
+			},
+			wantErr:      false,
+			wantInterval: 1,
+			wantRetDays:  1,
+		},
+		{
+			name: "Happy Path (4 Hours, anchored at 01:30)",
+			input: SnapshotPolicyExpress{
+				Enabled:       true,
+				IntervalHours: 4,
+				StartTime:     "01:30",
+				RetentionDays: 2,
+			},
+			wantErr:       false,
+			wantInterval:  4,
+			wantRetDays:   2,
+			wantStartTime: "01:30",
+		},
+		{
+			name: "Sub-hour Interval (30 Minutes overrides Hours)",
+			input: SnapshotPolicyExpress{
+				Enabled:         true,
+				IntervalHours:   6,
+				IntervalMinutes: 30,
+				RetentionDays:   1,
+			},
+			wantErr:      false,
+			wantInterval: 0,
+			wantMinutes:  30,
+			wantRetDays:  1,
+		},
+		{
+			name: "Invalid Sub-hour Interval (25 Minutes does not divide 24h)",
+			input: SnapshotPolicyExpress{
+				Enabled:         true,
+				IntervalMinutes: 25,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid Sub-hour Interval (Below minimum)",
+			input: SnapshotPolicyExpress{
+				Enabled:         true,
+				IntervalMinutes: 5,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid Anchor Start Time",
+			input: SnapshotPolicyExpress{
+				Enabled:       true,
+				IntervalHours: 4,
+				StartTime:     "25:00",
 			},
 			wantErr: true,
 		},
@@ -88,9 +152,19 @@ func TestSnapshotPolicyExpress_Normalize(t *testing.T) {
 				if policy.IntervalHours != tt.wantInterval {
 					t.Errorf("IntervalHours = %d, want %d", policy.IntervalHours, tt.wantInterval)
 				}
+				if policy.IntervalMinutes != tt.wantMinutes {
+					t.Errorf("IntervalMinutes = %d, want %d", policy.IntervalMinutes, tt.wantMinutes)
+				}
 				if policy.RetentionDays != tt.wantRetDays {
 					t.Errorf("RetentionDays = %d, want %d", policy.RetentionDays, tt.wantRetDays)
 				}
+				wantStartTime := tt.wantStartTime
+				if wantStartTime == "" {
+					wantStartTime = "00:00"
+				}
+				if policy.StartTime != wantStartTime {
+					t.Errorf("StartTime = %s, want %s", policy.StartTime, wantStartTime)
+				}
 			}
 		})
 	}
@@ -101,12 +175,15 @@ func TestSnapshotPolicyExpress_Evaluate(t *testing.T) {
 	loc, _ := time.LoadLocation("Europe/Paris")
 
 	tests := []struct {
-		name           string
-		interval       int       // Configured Interval
-		now            time.Time // The "Current" time
-		lastSnap       LastSnapshotInfo
-		wantSnapshot   bool
-		wantReasonPart string
+		name            string
+		interval        int       // Configured Interval
+		intervalMinutes int       // Configured Sub-hour Interval (overrides interval)
+		startTime       string    // Configured Anchor
+		now             time.Time // The "Current" time
+		lastSnap        LastSnapshotInfo
+		wantSnapshot    bool
+		wantReasonPart  string
+		wantWindowStart time.Time // Checked when set
 	}{
 		// --- SCENARIO 1: 6 Hour Interval (Slots: 00-06, 06-12, 12-18, 18-24) ---
 		{
@@ -179,18 +256,86 @@ func TestSnapshotPolicyExpress_Evaluate(t *testing.T) {
 			wantSnapshot:   false,
 			wantReasonPart: "exists",
 		},
+
+		// --- SCENARIO 3: 4 Hour Interval anchored at 01:30 (Slots: 01:30-05:30, ..., 21:30-01:30) ---
+		{
+			name:      "4h@01:30 - Idempotency (Snapshot at 02:00)",
+			interval:  4,
+			startTime: "01:30",
+			// Now is 05:00. Bucket is 01:30 - 05:30.
+			now: time.Date(2025, 12, 21, 5, 0, 0, 0, loc),
+			lastSnap: LastSnapshotInfo{
+				CreatedAt: time.Date(2025, 12, 21, 2, 0, 0, 0, loc),
+			},
+			wantSnapshot:    false,
+			wantReasonPart:  "exists",
+			wantWindowStart: time.Date(2025, 12, 21, 1, 30, 0, 0, loc),
+		},
+		{
+			name:      "4h@01:30 - New Slot at 05:30",
+			interval:  4,
+			startTime: "01:30",
+			now:       time.Date(2025, 12, 21, 5, 30, 0, 0, loc),
+			lastSnap: LastSnapshotInfo{
+				CreatedAt: time.Date(2025, 12, 21, 2, 0, 0, 0, loc),
+			},
+			wantSnapshot:    true,
+			wantReasonPart:  "no existing snapshot",
+			wantWindowStart: time.Date(2025, 12, 21, 5, 30, 0, 0, loc),
+		},
+		{
+			name:      "4h@01:30 - Before Anchor (Slot started yesterday 21:30)",
+			interval:  4,
+			startTime: "01:30",
+			now:       time.Date(2025, 12, 21, 0, 45, 0, 0, loc),
+			lastSnap: LastSnapshotInfo{
+				CreatedAt: time.Date(2025, 12, 20, 21, 35, 0, 0, loc),
+			},
+			wantSnapshot:    false,
+			wantReasonPart:  "exists",
+			wantWindowStart: time.Date(2025, 12, 20, 21, 30, 0, 0, loc),
+		},
+
+		// --- SCENARIO 4: 1 Hour Interval anchored at 23:00 (several slots before the anchor) ---
+		{
+			name:            "1h@23:00 - Early Morning",
+			interval:        1,
+			startTime:       "23:00",
+			now:             time.Date(2025, 12, 21, 1, 30, 0, 0, loc),
+			lastSnap:        LastSnapshotInfo{},
+			wantSnapshot:    true,
+			wantReasonPart:  "no existing snapshot",
+			wantWindowStart: time.Date(2025, 12, 21, 1, 0, 0, 0, loc),
+		},
+
+		// --- SCENARIO 5: 30 Minute Interval ---
+		{
+			name:            "30m - Second Half of the Hour",
+			intervalMinutes: 30,
+			now:             time.Date(2025, 12, 21, 10, 45, 0, 0, loc),
+			lastSnap: LastSnapshotInfo{
+				CreatedAt: time.Date(2025, 12, 21, 10, 15, 0, 0, loc),
+			},
+			wantSnapshot:    true,
+			wantReasonPart:  "no existing snapshot",
+			wantWindowStart: time.Date(2025, 12, 21, 10, 30, 0, 0, loc),
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Construct policy on the fly based on test case
 			policy := SnapshotPolicyExpress{
-				Enabled:       true,
-				IntervalHours: tt.interval,
-				RetentionDays: 1,
-				TimeZone:      "Europe/Paris",
+				Enabled:         true,
+				IntervalHours:   tt.interval,
+				IntervalMinutes: tt.intervalMinutes,
+				StartTime:       tt.startTime,
+				RetentionDays:   1,
+				TimeZone:        "Europe/Paris",
+			}
+			if err := policy.Normalize(); err != nil {
+				t.Fatalf("Normalize() unexpected error: %v", err)
 			}
-			_ = policy.Normalize()
 
 			result, err := policy.Evaluate(tt.now, tt.lastSnap)
 
@@ -203,6 +348,11 @@ func TestSnapshotPolicyExpress_Evaluate(t *testing.T) {
 			if result.ShouldSnapshot != tt.wantSnapshot {
 				t.Errorf("ShouldSnapshot = %v, want %v. Reason: %s", result.ShouldSnapshot, tt.wantSnapshot, result.Reason)
 			}
+
+			// 3. Check Window Alignment
+			if !tt.wantWindowStart.IsZero() && !result.Window.StartTime.Equal(tt.wantWindowStart) {
+				t.Errorf("Window.StartTime = %s, want %s", result.Window.StartTime, tt.wantWindowStart)
+			}
 		})
 	}
 }
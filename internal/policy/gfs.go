@@ -0,0 +1,88 @@
+package policy
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// GFSConfig enables Grandfather-Father-Son retention across the Daily, Weekly and Monthly
+// policies of a single volume.
+//
+// Behavior:
+//   - One Snapshot per Window: When several tiers are due at the same time, a single snapshot is
+//     created and labelled with the highest tier. It records every tier it satisfies in its metadata.
+//   - Promotion: When a higher tier becomes due and a lower tier snapshot was already taken inside
+//     the higher tier's window, that snapshot is relabelled (policy type, expiry) instead of
+//     creating a duplicate.
+//   - Express and Cron policies are not part of the hierarchy and keep their own snapshot series.
+type GFSConfig struct {
+	Enabled bool `json:"x-snapsentry-gfs-enabled"`
+}
+
+// ParseFromMetadata hydrates the GFS configuration from a volume metadata map.
+func (g *GFSConfig) ParseFromMetadata(metadata map[string]string) error {
+	parsed, err := ParseSnapSentryMetadataFromSDK[GFSConfig](metadata)
+	if err != nil {
+		return err
+	}
+	*g = *parsed
+	return nil
+}
+
+// ToOpenstackMetadata serializes the GFS configuration into OpenStack Volume metadata tags.
+func (g *GFSConfig) ToOpenstackMetadata() map[string]string {
+	return map[string]string{
+		ManagedTag:                 "true",
+		"x-snapsentry-gfs-enabled": strconv.FormatBool(g.Enabled),
+	}
+}
+
+// GFSRank returns the position of a policy type in the GFS hierarchy
+// (daily=1, weekly=2, monthly=3). Policy types outside the hierarchy return 0.
+func GFSRank(policyType string) int {
+	switch policyType {
+	case "daily":
+		return 1
+	case "weekly":
+		return 2
+	case "monthly":
+		return 3
+	default:
+		return 0
+	}
+}
+
+// CoveredPolicies returns every policy type this snapshot satisfies.
+// This is always the snapshot's own PolicyType plus the tiers recorded during GFS creation or promotion.
+func (s SnapshotMetadata) CoveredPolicies() []string {
+	covered := []string{}
+	if s.PolicyType != "" {
+		covered = append(covered, s.PolicyType)
+	}
+	for _, p := range strings.Split(s.GFSCovers, ",") {
+		p = strings.TrimSpace(p)
+		if p != "" && !slices.Contains(covered, p) {
+			covered = append(covered, p)
+		}
+	}
+	return covered
+}
+
+// CoversPolicy reports whether this snapshot satisfies the window of the given policy type.
+func (s SnapshotMetadata) CoversPolicy(policyType string) bool {
+	return slices.Contains(s.CoveredPolicies(), policyType)
+}
+
+// JoinCoveredPolicies builds the GFSCovers value for a set of policy types,
+// de-duplicated and ordered from the lowest to the highest tier.
+func JoinCoveredPolicies(policyTypes ...string) string {
+	unique := []string{}
+	for _, p := range policyTypes {
+		if p != "" && !slices.Contains(unique, p) {
+			unique = append(unique, p)
+		}
+	}
+	slices.SortFunc(unique, func(a, b string) int { return GFSRank(a) - GFSRank(b) })
+	return strings.Join(unique, ",")
+}
@@ -0,0 +1,100 @@
+package policy
+
+import (
+	"testing"
+)
+
+func TestSnapshotMetadata_CoversPolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		metadata   SnapshotMetadata
+		policyType string
+		want       bool
+	}{
+		{
+			name:       "Own Policy Type",
+			metadata:   SnapshotMetadata{PolicyType: "daily"},
+			policyType: "daily",
+			want:       true,
+		},
+		{
+			name:       "Plain Snapshot does not cover other tiers",
+			metadata:   SnapshotMetadata{PolicyType: "weekly"},
+			policyType: "daily",
+			want:       false,
+		},
+		{
+			name:       "Promoted Weekly covers Daily",
+			metadata:   SnapshotMetadata{PolicyType: "weekly", GFSCovers: "daily,weekly", PromotedFrom: "daily"},
+			policyType: "daily",
+			want:       true,
+		},
+		{
+			name:       "Whitespace in covers list",
+			metadata:   SnapshotMetadata{PolicyType: "monthly", GFSCovers: " daily , weekly "},
+			policyType: "weekly",
+			want:       true,
+		},
+		{
+			name:       "Express is never covered",
+			metadata:   SnapshotMetadata{PolicyType: "monthly", GFSCovers: "daily,weekly,monthly"},
+			policyType: "express",
+			want:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.metadata.CoversPolicy(tt.policyType); got != tt.want {
+				t.Errorf("CoversPolicy(%s) = %v, want %v", tt.policyType, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJoinCoveredPolicies(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []string
+		want  string
+	}{
+		{name: "Sorted by tier", input: []string{"monthly", "daily", "weekly"}, want: "daily,weekly,monthly"},
+		{name: "Duplicates removed", input: []string{"weekly", "daily", "weekly", ""}, want: "daily,weekly"},
+		{name: "Empty", input: []string{}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := JoinCoveredPolicies(tt.input...); got != tt.want {
+				t.Errorf("JoinCoveredPolicies() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSnapshotMetadata_GFSRoundTrip(t *testing.T) {
+	original := SnapshotMetadata{
+		Managed:       true,
+		PolicyType:    "weekly",
+		RetentionDays: 28,
+		RetentionType: RetentionTypeTime,
+		GFSCovers:     "daily,weekly",
+		PromotedFrom:  "daily",
+	}
+
+	parsed := SnapshotMetadata{}
+	if err := parsed.ParseFromMetadata(original.ToOpenstackMetadata()); err != nil {
+		t.Fatalf("ParseFromMetadata() unexpected error: %v", err)
+	}
+
+	if parsed.GFSCovers != original.GFSCovers || parsed.PromotedFrom != original.PromotedFrom {
+		t.Errorf("Parsed GFS fields = (%q, %q), want (%q, %q)",
+			parsed.GFSCovers, parsed.PromotedFrom, original.GFSCovers, original.PromotedFrom)
+	}
+
+	// Plain snapshots must not carry empty GFS keys.
+	plain := SnapshotMetadata{Managed: true, PolicyType: "daily"}.ToOpenstackMetadata()
+	if _, ok := plain["x-snapsentry-snapshot-gfs-covers"]; ok {
+		t.Errorf("plain snapshot metadata unexpectedly contains x-snapsentry-snapshot-gfs-covers")
+	}
+}
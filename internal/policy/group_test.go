@@ -0,0 +1,73 @@
+package policy
+
+import "testing"
+
+func TestGroupSnapshotConfig_ParseFromMetadata(t *testing.T) {
+	tests := []struct {
+		name          string
+		metadata      map[string]string
+		wantEnabled   bool
+		wantGroupType string
+	}{
+		{
+			name:          "Not Configured",
+			metadata:      map[string]string{ManagedTag: "true"},
+			wantEnabled:   false,
+			wantGroupType: DefaultGroupType,
+		},
+		{
+			name:          "Enabled with Default Group Type",
+			metadata:      map[string]string{"x-snapsentry-group-snapshot-enabled": "true"},
+			wantEnabled:   true,
+			wantGroupType: DefaultGroupType,
+		},
+		{
+			name: "Enabled with Custom Group Type",
+			metadata: map[string]string{
+				"x-snapsentry-group-snapshot-enabled": "true",
+				"x-snapsentry-group-type":             "consistent-ceph",
+			},
+			wantEnabled:   true,
+			wantGroupType: "consistent-ceph",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := GroupSnapshotConfig{}
+			if err := g.ParseFromMetadata(tt.metadata); err != nil {
+				t.Fatalf("ParseFromMetadata() unexpected error: %v", err)
+			}
+			if g.Enabled != tt.wantEnabled {
+				t.Errorf("Enabled = %v, want %v", g.Enabled, tt.wantEnabled)
+			}
+			if got := g.EffectiveGroupType(); got != tt.wantGroupType {
+				t.Errorf("EffectiveGroupType() = %q, want %q", got, tt.wantGroupType)
+			}
+		})
+	}
+}
+
+func TestSnapshotMetadata_GroupSnapshotRoundTrip(t *testing.T) {
+	original := SnapshotMetadata{
+		Managed:         true,
+		PolicyType:      "daily",
+		RetentionDays:   7,
+		RetentionType:   RetentionTypeTime,
+		GroupSnapshotID: "2b7c3a52-6c1e-4b55-9d0b-0d3c5e7f8a91",
+	}
+
+	parsed := SnapshotMetadata{}
+	if err := parsed.ParseFromMetadata(original.ToOpenstackMetadata()); err != nil {
+		t.Fatalf("ParseFromMetadata() unexpected error: %v", err)
+	}
+	if parsed.GroupSnapshotID != original.GroupSnapshotID {
+		t.Errorf("GroupSnapshotID = %q, want %q", parsed.GroupSnapshotID, original.GroupSnapshotID)
+	}
+
+	// Plain snapshots must not carry an empty group snapshot key.
+	plain := SnapshotMetadata{Managed: true, PolicyType: "daily"}.ToOpenstackMetadata()
+	if _, ok := plain["x-snapsentry-snapshot-group-snapshot-id"]; ok {
+		t.Errorf("plain snapshot metadata unexpectedly contains x-snapsentry-snapshot-group-snapshot-id")
+	}
+}
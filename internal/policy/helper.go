@@ -2,6 +2,7 @@ package policy
 
 import (
 	"fmt"
+	"reflect"
 	"time"
 
 	"github.com/go-viper/mapstructure/v2"
@@ -10,6 +11,7 @@ import (
 // ParseSnapSentryMetadataFromSDK is a generic helper to unmarshal a map[string]string
 // into a strongly-typed policy struct using JSON tags.
 // It uses weak typing to handle string-to-int/bool conversions.
+// Empty timestamp values (written for a zero time, see SnapshotMetadata.ToOpenstackMetadata) decode to the zero time.
 func ParseSnapSentryMetadataFromSDK[T any](metadata map[string]string) (*T, error) {
 	var result T
 
@@ -18,6 +20,7 @@ func ParseSnapSentryMetadataFromSDK[T any](metadata map[string]string) (*T, erro
 		WeaklyTypedInput: true,
 		TagName:          "json",
 		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			helperEmptyStringToZeroTimeHook,
 			mapstructure.StringToTimeHookFunc(time.RFC3339),
 		),
 	}
@@ -34,6 +37,15 @@ func ParseSnapSentryMetadataFromSDK[T any](metadata map[string]string) (*T, erro
 	return &result, nil
 }
 
+// helperEmptyStringToZeroTimeHook maps an empty string to time.Time{} so that optional
+// timestamps do not fail the RFC3339 parsing hook.
+func helperEmptyStringToZeroTimeHook(from reflect.Type, to reflect.Type, data any) (any, error) {
+	if from.Kind() == reflect.String && to == reflect.TypeOf(time.Time{}) && data.(string) == "" {
+		return time.Time{}, nil
+	}
+	return data, nil
+}
+
 // helperNormalizeTimezone loads a Time Location from a string name.
 // It defaults to UTC if the timezone string is empty.
 func helperNormalizeTimezone(timezone string) (string, *time.Location, error) {
@@ -143,14 +155,25 @@ func helperEvaluateWindow(
 		Window:         SnapshotPolicyWindow{},
 	}
 
+	if duration <= 0 {
+		result.Reason = fmt.Sprintf("Invalid window duration %s", duration)
+		return result
+	}
+
 	// 1. Determine Window Bounds
 	// If "Now" is before the "Potential Start", it means we haven't reached this cycle's start time yet.
-	// Therefore, the *active* window is actually the previous cycle's window.
+	// Therefore, the *active* window is actually a previous cycle's window.
 	// Example: Policy is Daily 14:00. Now is 10:00.
 	// Potential Start = Today 14:00. Now < Potential.
 	// Active Window Start = Yesterday 14:00.
+	// For windows shorter than the distance to the potential start (e.g. Express 1h slots anchored at 23:00,
+	// Now is 01:30), we step back as many whole cycles as needed: Active Window Start = 01:00.
 	if now.Before(potentialStart) {
-		result.Window.StartTime = potentialStart.Add(-duration)
+		cycles := potentialStart.Sub(now) / duration
+		if potentialStart.Sub(now)%duration != 0 {
+			cycles++
+		}
+		result.Window.StartTime = potentialStart.Add(-cycles * duration)
 	} else {
 		result.Window.StartTime = potentialStart
 	}
@@ -192,6 +215,10 @@ func helperEvaluateWindow(
 		result.ShouldSnapshot = false
 		result.Reason = fmt.Sprintf("Snapshot already exists in active window (ID: %s created at %s)",
 			lastSnapshot.ID, lastSnapshot.CreatedAt.Format("2006-01-02 15:04"))
+		// An in-flight snapshot satisfies the window as well; a second one must not be started next to it.
+		if lastSnapshot.Status != "" && lastSnapshot.Status != SnapshotStatusAvailable {
+			result.Reason += fmt.Sprintf(", still %s", lastSnapshot.Status)
+		}
 		return result // Stop: Idempotency check failed
 	}
 
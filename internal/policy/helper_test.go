@@ -0,0 +1,87 @@
+package policy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHelperEvaluateWindow(t *testing.T) {
+	day := func(hour, min int) time.Time {
+		return time.Date(2025, 12, 21, hour, min, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name            string
+		now             time.Time
+		potentialStart  time.Time
+		duration        time.Duration
+		lastSnap        LastSnapshotInfo
+		wantSnapshot    bool
+		wantWindowStart time.Time
+	}{
+		{
+			name:            "Inside Window",
+			now:             day(15, 0),
+			potentialStart:  day(14, 0),
+			duration:        24 * time.Hour,
+			wantSnapshot:    true,
+			wantWindowStart: day(14, 0),
+		},
+		{
+			name:            "Before Start (Previous cycle)",
+			now:             day(10, 0),
+			potentialStart:  day(14, 0),
+			duration:        24 * time.Hour,
+			wantSnapshot:    true,
+			wantWindowStart: day(14, 0).Add(-24 * time.Hour),
+		},
+		{
+			name:            "Before Start (Several cycles back)",
+			now:             day(1, 30),
+			potentialStart:  day(23, 0),
+			duration:        time.Hour,
+			wantSnapshot:    true,
+			wantWindowStart: day(1, 0),
+		},
+		{
+			name:            "Exactly one cycle before Start",
+			now:             day(19, 0),
+			potentialStart:  day(23, 0),
+			duration:        4 * time.Hour,
+			wantSnapshot:    true,
+			wantWindowStart: day(19, 0),
+		},
+		{
+			name:           "Idempotency (Snapshot in window)",
+			now:            day(1, 30),
+			potentialStart: day(23, 0),
+			duration:       time.Hour,
+			lastSnap: LastSnapshotInfo{
+				ID:        "snap-1",
+				CreatedAt: day(1, 5),
+			},
+			wantSnapshot:    false,
+			wantWindowStart: day(1, 0),
+		},
+		{
+			name:           "Invalid Duration",
+			now:            day(1, 30),
+			potentialStart: day(1, 0),
+			duration:       0,
+			wantSnapshot:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := helperEvaluateWindow(tt.now, tt.potentialStart, tt.duration, tt.lastSnap)
+
+			if result.ShouldSnapshot != tt.wantSnapshot {
+				t.Errorf("ShouldSnapshot = %v, want %v. Reason: %s", result.ShouldSnapshot, tt.wantSnapshot, result.Reason)
+			}
+			if !result.Window.StartTime.Equal(tt.wantWindowStart) {
+				t.Errorf("Window.StartTime = %s, want %s", result.Window.StartTime, tt.wantWindowStart)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package policy
+
+import (
+	"strconv"
+	"time"
+)
+
+// DefaultHookTimeout bounds a quiesce hook when no timeout is configured.
+const DefaultHookTimeout = 60 * time.Second
+
+// QuiesceHookConfig makes the snapshots of a server application-consistent by calling a hook before
+// the first snapshot of the server is created and after the last one completed (e.g. fsfreeze through
+// the qemu-guest-agent, or a database flush).
+//
+// It is read from the server metadata, or else from the metadata of its volumes.
+//
+// Fields:
+//   - Pre/Post: An http(s) URL, or the name of an executable in the hook directory followed by optional
+//     arguments. Either can be empty.
+//   - TimeoutSeconds: Limit for each hook. Defaults to DefaultHookTimeout.
+type QuiesceHookConfig struct {
+	Pre            string `json:"x-snapsentry-hook-pre"`
+	Post           string `json:"x-snapsentry-hook-post"`
+	TimeoutSeconds int    `json:"x-snapsentry-hook-timeout"`
+}
+
+// ParseFromMetadata hydrates the hook configuration from a server or volume metadata map.
+func (h *QuiesceHookConfig) ParseFromMetadata(metadata map[string]string) error {
+	parsed, err := ParseSnapSentryMetadataFromSDK[QuiesceHookConfig](metadata)
+	if err != nil {
+		return err
+	}
+	*h = *parsed
+	return nil
+}
+
+// ToOpenstackMetadata serializes the hook configuration into OpenStack Volume metadata tags.
+func (h *QuiesceHookConfig) ToOpenstackMetadata() map[string]string {
+	return map[string]string{
+		ManagedTag:                  "true",
+		"x-snapsentry-hook-pre":     h.Pre,
+		"x-snapsentry-hook-post":    h.Post,
+		"x-snapsentry-hook-timeout": strconv.Itoa(h.TimeoutSeconds),
+	}
+}
+
+// IsConfigured reports whether a pre or post hook is set.
+func (h QuiesceHookConfig) IsConfigured() bool {
+	return h.Pre != "" || h.Post != ""
+}
+
+// Timeout returns the configured hook timeout, or DefaultHookTimeout.
+func (h QuiesceHookConfig) Timeout() time.Duration {
+	if h.TimeoutSeconds <= 0 {
+		return DefaultHookTimeout
+	}
+	return time.Duration(h.TimeoutSeconds) * time.Second
+}
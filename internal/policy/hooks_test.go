@@ -0,0 +1,81 @@
+package policy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuiesceHookConfig_ParseFromMetadata(t *testing.T) {
+	tests := []struct {
+		name           string
+		metadata       map[string]string
+		wantConfigured bool
+		wantTimeout    time.Duration
+	}{
+		{
+			name:           "Not Configured",
+			metadata:       map[string]string{ManagedTag: "true"},
+			wantConfigured: false,
+			wantTimeout:    DefaultHookTimeout,
+		},
+		{
+			name:           "No Metadata",
+			metadata:       nil,
+			wantConfigured: false,
+			wantTimeout:    DefaultHookTimeout,
+		},
+		{
+			name: "Pre and Post with Timeout",
+			metadata: map[string]string{
+				"x-snapsentry-hook-pre":     "fsfreeze.sh freeze",
+				"x-snapsentry-hook-post":    "fsfreeze.sh thaw",
+				"x-snapsentry-hook-timeout": "15",
+			},
+			wantConfigured: true,
+			wantTimeout:    15 * time.Second,
+		},
+		{
+			name:           "Post Only",
+			metadata:       map[string]string{"x-snapsentry-hook-post": "https://db.example.com/unlock"},
+			wantConfigured: true,
+			wantTimeout:    DefaultHookTimeout,
+		},
+		{
+			name: "Disabled by Empty Hooks",
+			metadata: map[string]string{
+				"x-snapsentry-hook-pre":     "",
+				"x-snapsentry-hook-post":    "",
+				"x-snapsentry-hook-timeout": "0",
+			},
+			wantConfigured: false,
+			wantTimeout:    DefaultHookTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := QuiesceHookConfig{}
+			if err := h.ParseFromMetadata(tt.metadata); err != nil {
+				t.Fatalf("ParseFromMetadata() unexpected error: %v", err)
+			}
+			if got := h.IsConfigured(); got != tt.wantConfigured {
+				t.Errorf("IsConfigured() = %v, want %v", got, tt.wantConfigured)
+			}
+			if got := h.Timeout(); got != tt.wantTimeout {
+				t.Errorf("Timeout() = %v, want %v", got, tt.wantTimeout)
+			}
+		})
+	}
+}
+
+func TestQuiesceHookConfig_RoundTrip(t *testing.T) {
+	original := QuiesceHookConfig{Pre: "flush-db --all", Post: "unlock-db", TimeoutSeconds: 30}
+
+	parsed := QuiesceHookConfig{}
+	if err := parsed.ParseFromMetadata(original.ToOpenstackMetadata()); err != nil {
+		t.Fatalf("ParseFromMetadata() unexpected error: %v", err)
+	}
+	if parsed != original {
+		t.Errorf("round trip = %+v, want %+v", parsed, original)
+	}
+}
@@ -4,7 +4,7 @@ import (
 	"time"
 )
 
-// SnapshotPolicy defines the contract that all scheduling strategies (Daily, Weekly, Monthly) must implement.
+// SnapshotPolicy defines the contract that all scheduling strategies (Express, Cron, Daily, Weekly, Monthly) must implement.
 // It decouples the scheduling logic from the specific storage mechanism (OpenStack, oVirt, etc.).
 type SnapshotPolicy interface {
 	// Normalize validates the policy configuration and sets sane defaults
@@ -1,6 +1,8 @@
 package policy
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"strconv"
 	"time"
 )
@@ -23,6 +25,46 @@ type SnapshotMetadata struct {
 
 	// RetentionDays is stored for reference/debugging to show how long the policy was configured for.
 	RetentionDays int `json:"x-snapsentry-snapshot-retention-days"`
+
+	// RetentionType is either "time" (expire by ExpiryDate) or "count" (keep the last RetentionCount snapshots).
+	// Count based snapshots are removed by the count sweep while the volume keeps count retention for the
+	// policy type, and by their ExpiryDate once it no longer does.
+	RetentionType string `json:"x-snapsentry-snapshot-retention-type"`
+
+	// RetentionCount is the number of snapshots kept per volume for this policy type when RetentionType is "count".
+	RetentionCount int `json:"x-snapsentry-snapshot-retention-count"`
+
+	// GFSCovers lists (comma separated) the lower GFS tiers this snapshot also satisfies, e.g. "daily,weekly".
+	// It is only set on volumes with GFS enabled; see GFSConfig.
+	GFSCovers string `json:"x-snapsentry-snapshot-gfs-covers"`
+
+	// PromotedFrom records the original policy type of a snapshot that was relabelled by GFS promotion.
+	PromotedFrom string `json:"x-snapsentry-snapshot-promoted-from"`
+
+	// GroupSnapshotID is set on the members of a Cinder group snapshot (see GroupSnapshotConfig).
+	// Members cannot be deleted on their own, so expiry deletes the group snapshot once every member has expired.
+	GroupSnapshotID string `json:"x-snapsentry-snapshot-group-snapshot-id"`
+
+	// Pending marks a snapshot that was still "creating" when the run that created it stopped waiting for it.
+	// The next snapshot run confirms it once it is available (and clears the mark), or cleans it up.
+	Pending bool `json:"x-snapsentry-snapshot-pending"`
+
+	// WindowKey identifies the volume, policy type and window the snapshot was created for (see SnapshotWindowKey).
+	// Creation looks it up before every create request, so a retried request never creates a second snapshot.
+	WindowKey string `json:"x-snapsentry-snapshot-window-key"`
+}
+
+// SnapshotWindowKey returns the deterministic idempotency token of a policy window of a volume:
+// the hex encoded SHA-256 (truncated to 128 bits) of the volume ID, policy type and window start (UTC).
+// Group snapshots use the ID of their volume group instead of a volume ID.
+func SnapshotWindowKey(volumeID string, policyType string, windowStart time.Time) string {
+	sum := sha256.Sum256([]byte(volumeID + "|" + policyType + "|" + windowStart.UTC().Format(time.RFC3339)))
+	return hex.EncodeToString(sum[:16])
+}
+
+// IsCountRetention reports whether this snapshot is governed by count based retention.
+func (s SnapshotMetadata) IsCountRetention() bool {
+	return s.RetentionType == RetentionTypeCount && s.RetentionCount > 0
 }
 
 // ToOpenstackMetadata serializes the snapshot metadata into a string map
@@ -38,13 +80,34 @@ func (s SnapshotMetadata) ToOpenstackMetadata() map[string]string {
 		expiryDateStrTZ = s.ExpiryDate.Format(time.RFC3339)
 	}
 
-	return map[string]string{
+	metadata := map[string]string{
 		"x-snapsentry-managed":                      strconv.FormatBool(s.Managed),
 		"x-snapsentry-snapshot-expiry-date":         expiryDateStr,
 		"x-snapsentry-snapshot-expiry-date-user-tz": expiryDateStrTZ,
 		"x-snapsentry-snapshot-policy-type":         s.PolicyType,
 		"x-snapsentry-snapshot-retention-days":      strconv.Itoa(s.RetentionDays),
+		"x-snapsentry-snapshot-retention-type":      s.RetentionType,
+		"x-snapsentry-snapshot-retention-count":     strconv.Itoa(s.RetentionCount),
+	}
+
+	// GFS keys are only written when relevant to keep plain snapshots uncluttered.
+	if s.GFSCovers != "" {
+		metadata["x-snapsentry-snapshot-gfs-covers"] = s.GFSCovers
+	}
+	if s.PromotedFrom != "" {
+		metadata["x-snapsentry-snapshot-promoted-from"] = s.PromotedFrom
 	}
+	if s.GroupSnapshotID != "" {
+		metadata["x-snapsentry-snapshot-group-snapshot-id"] = s.GroupSnapshotID
+	}
+	if s.Pending {
+		metadata[PendingTag] = "true"
+	}
+	if s.WindowKey != "" {
+		metadata[WindowKeyTag] = s.WindowKey
+	}
+
+	return metadata
 }
 
 // ParseFromMetadata hydrates the SnapshotMetadata struct from a raw OpenStack metadata map.
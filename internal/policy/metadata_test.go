@@ -0,0 +1,53 @@
+package policy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSnapshotWindowKey(t *testing.T) {
+	start := time.Date(2026, 10, 17, 14, 0, 0, 0, time.UTC)
+	paris, _ := time.LoadLocation("Europe/Paris")
+	key := SnapshotWindowKey("vol-1", "daily", start)
+
+	tests := []struct {
+		name     string
+		got      string
+		wantSame bool
+	}{
+		{name: "Deterministic", got: SnapshotWindowKey("vol-1", "daily", start), wantSame: true},
+		{name: "Same Instant In Another Timezone", got: SnapshotWindowKey("vol-1", "daily", start.In(paris)), wantSame: true},
+		{name: "Other Volume", got: SnapshotWindowKey("vol-2", "daily", start), wantSame: false},
+		{name: "Other Policy Type", got: SnapshotWindowKey("vol-1", "weekly", start), wantSame: false},
+		{name: "Other Window", got: SnapshotWindowKey("vol-1", "daily", start.Add(24*time.Hour)), wantSame: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if (tt.got == key) != tt.wantSame {
+				t.Errorf("SnapshotWindowKey() = %q, base key %q, want same = %v", tt.got, key, tt.wantSame)
+			}
+		})
+	}
+
+	if len(key) != 32 {
+		t.Errorf("len(SnapshotWindowKey()) = %d, want 32", len(key))
+	}
+}
+
+func TestSnapshotMetadata_WindowKeyRoundTrip(t *testing.T) {
+	original := SnapshotMetadata{Managed: true, PolicyType: "daily", WindowKey: "0123456789abcdef0123456789abcdef"}
+
+	parsed := SnapshotMetadata{}
+	if err := parsed.ParseFromMetadata(original.ToOpenstackMetadata()); err != nil {
+		t.Fatalf("ParseFromMetadata() unexpected error: %v", err)
+	}
+	if parsed.WindowKey != original.WindowKey {
+		t.Errorf("WindowKey = %q, want %q", parsed.WindowKey, original.WindowKey)
+	}
+
+	// Snapshots created before window keys must not carry an empty key.
+	if _, ok := (SnapshotMetadata{Managed: true}).ToOpenstackMetadata()[WindowKeyTag]; ok {
+		t.Errorf("metadata unexpectedly contains %s", WindowKeyTag)
+	}
+}
@@ -16,12 +16,13 @@ import (
 //     (28, 29, 30, or 31 days) depending on the specific month.
 //   - Idempotency: Ensures only one snapshot is taken per calendar month cycle.
 type SnapshotPolicyMonthly struct {
-	Enabled       bool   `json:"x-snapsentry-monthly-enabled"`
-	RetentionDays int    `json:"x-snapsentry-monthly-retention-days"`
-	RetentionType string `json:"x-snapsentry-monthly-retention-type"`
-	TimeZone      string `json:"x-snapsentry-monthly-timezone"`
-	StartTime     string `json:"x-snapsentry-monthly-start-time"`
-	DayOfMonth    int    `json:"x-snapsentry-monthly-start-day-of-month"`
+	Enabled        bool   `json:"x-snapsentry-monthly-enabled"`
+	RetentionDays  int    `json:"x-snapsentry-monthly-retention-days"`
+	RetentionType  string `json:"x-snapsentry-monthly-retention-type"`
+	RetentionCount int    `json:"x-snapsentry-monthly-retention-count"`
+	TimeZone       string `json:"x-snapsentry-monthly-timezone"`
+	StartTime      string `json:"x-snapsentry-monthly-start-time"`
+	DayOfMonth     int    `json:"x-snapsentry-monthly-start-day-of-month"`
 
 	// Internal fields for calculation
 	Loc         *time.Location
@@ -57,19 +58,20 @@ func (s *SnapshotPolicyMonthly) ParseFromMetadata(metadata map[string]string) er
 // Keys: x-snapsentry-monthly-*
 func (s *SnapshotPolicyMonthly) ToOpenstackMetadata() map[string]string {
 	return map[string]string{
-		ManagedTag:                            "true",
-		"x-snapsentry-monthly-enabled":        strconv.FormatBool(s.Enabled),
-		"x-snapsentry-monthly-retention-days": strconv.Itoa(s.RetentionDays),
-		"x-snapsentry-monthly-retention-type": s.RetentionType,
-		"x-snapsentry-monthly-timezone":       s.TimeZone,
-		"x-snapsentry-monthly-start-time":     s.StartTime,
-		"x-snapsentry-monthly-day-of-month":   strconv.Itoa(s.DayOfMonth),
+		ManagedTag:                                "true",
+		"x-snapsentry-monthly-enabled":            strconv.FormatBool(s.Enabled),
+		"x-snapsentry-monthly-retention-days":     strconv.Itoa(s.RetentionDays),
+		"x-snapsentry-monthly-retention-type":     s.RetentionType,
+		"x-snapsentry-monthly-retention-count":    strconv.Itoa(s.RetentionCount),
+		"x-snapsentry-monthly-timezone":           s.TimeZone,
+		"x-snapsentry-monthly-start-time":         s.StartTime,
+		"x-snapsentry-monthly-start-day-of-month": strconv.Itoa(s.DayOfMonth),
 	}
 }
 
 // Normalize validates inputs and sets defaults.
 //  1. TimeZone -> time.Location (Def: UTC)
-//  2. Retention -> int (Def: 30)
+//  2. Retention -> int (Def: 30), RetentionType -> time/count (Def: time)
 //  3. StartTime -> HH:MM
 //  4. DayOfMonth -> Clamped to 1-31 range.
 func (s *SnapshotPolicyMonthly) Normalize() error {
@@ -84,6 +86,13 @@ func (s *SnapshotPolicyMonthly) Normalize() error {
 	// 2. Normalize Retention (Default to 30 days)
 	s.RetentionDays = helperNormalizeRetentionDays(s.RetentionDays, 30)
 
+	// Normalize Retention Type (time / count)
+	retentionType, err := helperNormalizeRetentionType(s.RetentionType, s.RetentionCount)
+	if err != nil {
+		return err
+	}
+	s.RetentionType = retentionType
+
 	// 3. Normalize Start Time
 	starttime, err := helperNormalizeStartTime(s.StartTime)
 	if err != nil {
@@ -190,10 +199,12 @@ func (s *SnapshotPolicyMonthly) Evaluate(now time.Time, lastSnapshot LastSnapsho
 
 	// 7. Success
 	result.Metadata = SnapshotMetadata{
-		Managed:       true,
-		ExpiryDate:    result.Window.StartTime.AddDate(0, 0, s.RetentionDays),
-		PolicyType:    "monthly",
-		RetentionDays: s.RetentionDays,
+		Managed:        true,
+		ExpiryDate:     result.Window.StartTime.AddDate(0, 0, s.RetentionDays),
+		PolicyType:     "monthly",
+		RetentionDays:  s.RetentionDays,
+		RetentionType:  s.RetentionType,
+		RetentionCount: s.RetentionCount,
 	}
 
 	return result, nil
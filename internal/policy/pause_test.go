@@ -0,0 +1,69 @@
+package policy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPauseConfig_IsActive(t *testing.T) {
+	now := time.Date(2026, 10, 17, 3, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		metadata map[string]string
+		want     bool
+	}{
+		{
+			name:     "Not Paused",
+			metadata: map[string]string{ManagedTag: "true"},
+			want:     false,
+		},
+		{
+			name:     "Paused Indefinitely",
+			metadata: map[string]string{"x-snapsentry-paused": "true", "x-snapsentry-paused-until": ""},
+			want:     true,
+		},
+		{
+			name:     "Paused Until Later",
+			metadata: map[string]string{"x-snapsentry-paused": "true", "x-snapsentry-paused-until": "2026-10-17T06:00:00Z"},
+			want:     true,
+		},
+		{
+			name:     "Pause Expired",
+			metadata: map[string]string{"x-snapsentry-paused": "true", "x-snapsentry-paused-until": "2026-10-17T02:00:00Z"},
+			want:     false,
+		},
+		{
+			name:     "Resumed",
+			metadata: map[string]string{"x-snapsentry-paused": "false", "x-snapsentry-paused-until": "2026-10-17T06:00:00Z"},
+			want:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := PauseConfig{}
+			if err := p.ParseFromMetadata(tt.metadata); err != nil {
+				t.Fatalf("ParseFromMetadata() error = %v", err)
+			}
+			if got := p.IsActive(now); got != tt.want {
+				t.Errorf("IsActive() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPauseConfig_RoundTrip(t *testing.T) {
+	want := PauseConfig{Paused: true, Until: time.Date(2026, 10, 20, 6, 0, 0, 0, time.UTC), Reason: "CHG-1234"}
+
+	got := PauseConfig{}
+	if err := got.ParseFromMetadata(want.ToOpenstackMetadata()); err != nil {
+		t.Fatalf("ParseFromMetadata() error = %v", err)
+	}
+	if got.Paused != want.Paused || !got.Until.Equal(want.Until) || got.Reason != want.Reason {
+		t.Errorf("round trip = %+v, want %+v", got, want)
+	}
+	if got.Describe() != "volume is paused until 2026-10-20T06:00:00Z: CHG-1234" {
+		t.Errorf("Describe() = %q", got.Describe())
+	}
+}
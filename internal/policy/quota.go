@@ -0,0 +1,109 @@
+package policy
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// PriorityTag sets the priority of a volume when the snapshot quota of its project cannot serve every due
+// snapshot of a run. Volumes with a higher priority are snapshotted first; the default is 0.
+const PriorityTag = "x-snapsentry-priority"
+
+// VolumePriority returns the priority (PriorityTag) of a volume from its metadata.
+// Missing or invalid values have priority 0.
+func VolumePriority(metadata map[string]string) int {
+	priority, err := strconv.Atoi(strings.TrimSpace(metadata[PriorityTag]))
+	if err != nil {
+		return 0
+	}
+	return priority
+}
+
+// SnapshotQuota is the Cinder quota left for new snapshots of a project during a run.
+// Snapshots count against the snapshot quota and, with the size of their volume, against the gigabytes quota.
+// A limit of -1 is unlimited. It is safe for concurrent use by the workers of a run.
+type SnapshotQuota struct {
+	mu        sync.Mutex
+	snapshots int
+	gigabytes int
+	exhausted bool
+}
+
+// NewSnapshotQuota returns the quota left from the limits and the usage (in use and reserved) of a project.
+func NewSnapshotQuota(snapshotLimit, snapshotsUsed, gigabytesLimit, gigabytesUsed int) *SnapshotQuota {
+	q := &SnapshotQuota{snapshots: -1, gigabytes: -1}
+	if snapshotLimit >= 0 {
+		q.snapshots = max(0, snapshotLimit-snapshotsUsed)
+	}
+	if gigabytesLimit >= 0 {
+		q.gigabytes = max(0, gigabytesLimit-gigabytesUsed)
+	}
+	return q
+}
+
+// Fits reports whether count snapshots of the given total size fit into the remaining quota.
+func (q *SnapshotQuota) Fits(count, gigabytes int) bool {
+	if q == nil {
+		return true
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.fits(count, gigabytes)
+}
+
+// Reserve takes count snapshots of the given total size from the remaining quota.
+// It returns false, and takes nothing, if they do not fit. A nil quota is unlimited.
+func (q *SnapshotQuota) Reserve(count, gigabytes int) bool {
+	if q == nil {
+		return true
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.fits(count, gigabytes) {
+		return false
+	}
+	if q.snapshots >= 0 {
+		q.snapshots -= count
+	}
+	if q.gigabytes >= 0 {
+		q.gigabytes -= gigabytes
+	}
+	return true
+}
+
+// Release returns a reservation whose snapshots were not created.
+func (q *SnapshotQuota) Release(count, gigabytes int) {
+	if q == nil {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.snapshots >= 0 {
+		q.snapshots += count
+	}
+	if q.gigabytes >= 0 {
+		q.gigabytes += gigabytes
+	}
+}
+
+// Exhaust marks the quota as used up, e.g. once Cinder rejected a snapshot for exceeding it. Every further
+// reservation fails.
+func (q *SnapshotQuota) Exhaust() {
+	if q == nil {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.exhausted = true
+}
+
+// fits reports whether a reservation fits. The caller must hold the lock.
+func (q *SnapshotQuota) fits(count, gigabytes int) bool {
+	if q.exhausted {
+		return false
+	}
+	return (q.snapshots < 0 || count <= q.snapshots) && (q.gigabytes < 0 || gigabytes <= q.gigabytes)
+}
@@ -0,0 +1,110 @@
+package policy
+
+import "testing"
+
+func TestVolumePriority(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata map[string]string
+		want     int
+	}{
+		{name: "Unset", metadata: map[string]string{}, want: 0},
+		{name: "Positive", metadata: map[string]string{PriorityTag: "100"}, want: 100},
+		{name: "Negative", metadata: map[string]string{PriorityTag: " -5 "}, want: -5},
+		{name: "Invalid", metadata: map[string]string{PriorityTag: "high"}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VolumePriority(tt.metadata); got != tt.want {
+				t.Errorf("VolumePriority() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSnapshotQuota_Reserve(t *testing.T) {
+	type reservation struct {
+		count     int
+		gigabytes int
+		want      bool
+	}
+
+	tests := []struct {
+		name         string
+		quota        *SnapshotQuota
+		reservations []reservation
+	}{
+		{
+			name:  "Unlimited",
+			quota: NewSnapshotQuota(-1, 500, -1, 10000),
+			reservations: []reservation{
+				{count: 100, gigabytes: 100000, want: true},
+			},
+		},
+		{
+			name:  "Snapshot Count Limit",
+			quota: NewSnapshotQuota(10, 8, -1, 0),
+			reservations: []reservation{
+				{count: 1, gigabytes: 100, want: true},
+				{count: 1, gigabytes: 100, want: true},
+				{count: 1, gigabytes: 100, want: false},
+			},
+		},
+		{
+			name:  "Gigabytes Limit Skips Large Volume Only",
+			quota: NewSnapshotQuota(-1, 0, 1000, 900),
+			reservations: []reservation{
+				{count: 1, gigabytes: 500, want: false},
+				{count: 1, gigabytes: 60, want: true},
+				{count: 1, gigabytes: 40, want: true},
+				{count: 1, gigabytes: 1, want: false},
+			},
+		},
+		{
+			name:  "Usage Above Limit",
+			quota: NewSnapshotQuota(10, 12, 100, 120),
+			reservations: []reservation{
+				{count: 1, gigabytes: 1, want: false},
+			},
+		},
+		{
+			name:  "Nil Is Unlimited",
+			quota: nil,
+			reservations: []reservation{
+				{count: 1, gigabytes: 1, want: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, r := range tt.reservations {
+				if got := tt.quota.Reserve(r.count, r.gigabytes); got != r.want {
+					t.Errorf("reservation %d: Reserve(%d, %d) = %v, want %v", i, r.count, r.gigabytes, got, r.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSnapshotQuota_ReleaseAndExhaust(t *testing.T) {
+	quota := NewSnapshotQuota(1, 0, 100, 0)
+
+	if !quota.Reserve(1, 50) {
+		t.Fatalf("Reserve() = false, want true")
+	}
+	if quota.Fits(1, 10) {
+		t.Errorf("Fits() = true after the last snapshot was reserved, want false")
+	}
+
+	quota.Release(1, 50)
+	if !quota.Fits(1, 100) {
+		t.Errorf("Fits() = false after Release(), want true")
+	}
+
+	quota.Exhaust()
+	if quota.Reserve(1, 1) {
+		t.Errorf("Reserve() = true after Exhaust(), want false")
+	}
+}
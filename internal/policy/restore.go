@@ -0,0 +1,66 @@
+package policy
+
+import "time"
+
+// Restore modes recorded in RestoreMetadata.
+const (
+	RestoreModeNewVolume = "new-volume"
+	RestoreModeRevert    = "revert"
+)
+
+// RestoreMetadata is the audit record SnapSentry writes on a volume it restored: the new volume of a
+// restore, or the volume reverted in place. A later restore of the same volume overwrites it.
+type RestoreMetadata struct {
+	// Mode is RestoreModeNewVolume or RestoreModeRevert.
+	Mode string `json:"x-snapsentry-restore-mode"`
+
+	// SourceSnapshotID and SourceVolumeID identify the restored snapshot and the volume it was taken of.
+	SourceSnapshotID string `json:"x-snapsentry-restore-source-snapshot-id"`
+	SourceVolumeID   string `json:"x-snapsentry-restore-source-volume-id"`
+
+	// PolicyType is the policy that created the snapshot, empty for snapshots not managed by SnapSentry.
+	PolicyType string `json:"x-snapsentry-restore-policy-type"`
+
+	// SnapshotCreatedAt is the point in time the volume was restored to.
+	SnapshotCreatedAt time.Time `json:"x-snapsentry-restore-snapshot-created-at"`
+
+	// RestoredAt is when the restore was requested.
+	RestoredAt time.Time `json:"x-snapsentry-restore-restored-at"`
+
+	// RequestedBy is the cloud profile used for the restore; Reason is free text such as a ticket number.
+	RequestedBy string `json:"x-snapsentry-restore-requested-by"`
+	Reason      string `json:"x-snapsentry-restore-reason"`
+}
+
+// ToOpenstackMetadata serializes the audit record into volume metadata tags.
+// It deliberately carries no ManagedTag, so a restored volume is not subscribed to any policy.
+func (r RestoreMetadata) ToOpenstackMetadata() map[string]string {
+	var createdAt, restoredAt string
+	if !r.SnapshotCreatedAt.IsZero() {
+		createdAt = r.SnapshotCreatedAt.UTC().Format(time.RFC3339)
+	}
+	if !r.RestoredAt.IsZero() {
+		restoredAt = r.RestoredAt.UTC().Format(time.RFC3339)
+	}
+
+	return map[string]string{
+		"x-snapsentry-restore-mode":                r.Mode,
+		"x-snapsentry-restore-source-snapshot-id":  r.SourceSnapshotID,
+		"x-snapsentry-restore-source-volume-id":    r.SourceVolumeID,
+		"x-snapsentry-restore-policy-type":         r.PolicyType,
+		"x-snapsentry-restore-snapshot-created-at": createdAt,
+		"x-snapsentry-restore-restored-at":         restoredAt,
+		"x-snapsentry-restore-requested-by":        r.RequestedBy,
+		"x-snapsentry-restore-reason":              r.Reason,
+	}
+}
+
+// ParseFromMetadata hydrates the audit record from a volume metadata map.
+func (r *RestoreMetadata) ParseFromMetadata(metadata map[string]string) error {
+	parsed, err := ParseSnapSentryMetadataFromSDK[RestoreMetadata](metadata)
+	if err != nil {
+		return err
+	}
+	*r = *parsed
+	return nil
+}
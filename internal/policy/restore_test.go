@@ -0,0 +1,32 @@
+package policy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRestoreMetadata_RoundTrip(t *testing.T) {
+	original := RestoreMetadata{
+		Mode:              RestoreModeNewVolume,
+		SourceSnapshotID:  "5c1d7c38-7b0e-4a57-8f7e-2f1e0f6e2d11",
+		SourceVolumeID:    "0f3b8a54-9a43-4d0c-a3b1-6c2a4f3e9d20",
+		PolicyType:        "daily",
+		SnapshotCreatedAt: time.Date(2026, 2, 28, 8, 0, 12, 0, time.UTC),
+		RestoredAt:        time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC),
+		RequestedBy:       "snapsentry-bot",
+		Reason:            "INC-1234",
+	}
+
+	metadata := original.ToOpenstackMetadata()
+	if _, ok := metadata[ManagedTag]; ok {
+		t.Errorf("ToOpenstackMetadata() sets %s; a restored volume must not be managed", ManagedTag)
+	}
+
+	parsed := RestoreMetadata{}
+	if err := parsed.ParseFromMetadata(metadata); err != nil {
+		t.Fatalf("ParseFromMetadata() unexpected error: %v", err)
+	}
+	if parsed != original {
+		t.Errorf("round trip = %+v, want %+v", parsed, original)
+	}
+}
@@ -0,0 +1,69 @@
+package policy
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+const (
+	RetentionTypeTime  = "time"  // Snapshots are deleted once their ExpiryDate has passed.
+	RetentionTypeCount = "count" // Only the last N snapshots of a volume/policy series are kept.
+)
+
+// helperNormalizeRetentionType validates the retention mode of a policy.
+// It defaults to "time" if the type is empty.
+//
+// Legacy metadata written by older `subscribe weekly|monthly` commands carries "count"
+// without a retention count. Those policies fall back to "time" so that the existing
+// RetentionDays keeps being honoured instead of failing validation.
+func helperNormalizeRetentionType(retentionType string, retentionCount int) (string, error) {
+	switch retentionType {
+	case "", RetentionTypeTime:
+		return RetentionTypeTime, nil
+	case RetentionTypeCount:
+		if retentionCount <= 0 {
+			return RetentionTypeTime, nil
+		}
+		return RetentionTypeCount, nil
+	default:
+		return retentionType, fmt.Errorf("invalid retention type '%s'; must be '%s' or '%s'",
+			retentionType, RetentionTypeTime, RetentionTypeCount)
+	}
+}
+
+// SnapshotPolicyTypes are the snapshot policies a volume can subscribe to, in evaluation order.
+var SnapshotPolicyTypes = []string{"express", "cron", "daily", "weekly", "monthly"}
+
+// HasCountRetention reports whether the volume metadata keeps count based retention for a policy type:
+// the policy is enabled with retention type "count" and a positive retention count.
+func HasCountRetention(metadata map[string]string, policyType string) bool {
+	prefix := "x-snapsentry-" + policyType
+	enabled, _ := strconv.ParseBool(metadata[prefix+"-enabled"])
+	retentionCount, _ := strconv.Atoi(metadata[prefix+"-retention-count"])
+	retentionType, err := helperNormalizeRetentionType(metadata[prefix+"-retention-type"], retentionCount)
+	return enabled && err == nil && retentionType == RetentionTypeCount
+}
+
+// SelectExcessSnapshots applies count based retention to a single snapshot series
+// (one volume, one policy type).
+//
+// The series is sorted by creation time (Newest First) and every snapshot beyond
+// the first 'keep' entries is returned as a deletion candidate, regardless of any
+// time based expiry date. The input slice is not modified.
+func SelectExcessSnapshots(series []LastSnapshotInfo, keep int) []LastSnapshotInfo {
+	if keep < 0 {
+		keep = 0
+	}
+	if len(series) <= keep {
+		return []LastSnapshotInfo{}
+	}
+
+	sorted := make([]LastSnapshotInfo, len(series))
+	copy(sorted, series)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt.After(sorted[j].CreatedAt)
+	})
+
+	return sorted[keep:]
+}
@@ -0,0 +1,194 @@
+package policy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHelperNormalizeRetentionType(t *testing.T) {
+	tests := []struct {
+		name           string
+		retentionType  string
+		retentionCount int
+		wantType       string
+		wantErr        bool
+	}{
+		{
+			name:     "Default (Empty -> time)",
+			wantType: RetentionTypeTime,
+		},
+		{
+			name:           "Count with N",
+			retentionType:  "count",
+			retentionCount: 7,
+			wantType:       RetentionTypeCount,
+		},
+		{
+			name:          "Legacy Count without N (falls back to time)",
+			retentionType: "count",
+			wantType:      RetentionTypeTime,
+		},
+		{
+			name:          "Invalid Type",
+			retentionType: "forever",
+			wantErr:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := helperNormalizeRetentionType(tt.retentionType, tt.retentionCount)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("helperNormalizeRetentionType() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got != tt.wantType {
+				t.Errorf("RetentionType = %s, want %s", got, tt.wantType)
+			}
+		})
+	}
+}
+
+func TestSelectExcessSnapshots(t *testing.T) {
+	base := time.Date(2025, 12, 21, 2, 0, 0, 0, time.UTC)
+
+	// Deliberately unsorted: snap-3 is the newest, snap-1 the oldest.
+	series := []LastSnapshotInfo{
+		{ID: "snap-2", CreatedAt: base.AddDate(0, 0, -1)},
+		{ID: "snap-1", CreatedAt: base.AddDate(0, 0, -2)},
+		{ID: "snap-3", CreatedAt: base},
+		{ID: "snap-0", CreatedAt: base.AddDate(0, 0, -3)},
+	}
+
+	tests := []struct {
+		name    string
+		keep    int
+		wantIDs []string
+	}{
+		{name: "Keep 2 (oldest two are excess)", keep: 2, wantIDs: []string{"snap-1", "snap-0"}},
+		{name: "Keep all", keep: 4, wantIDs: []string{}},
+		{name: "Keep more than available", keep: 10, wantIDs: []string{}},
+		{name: "Keep 0 (everything is excess)", keep: 0, wantIDs: []string{"snap-3", "snap-2", "snap-1", "snap-0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SelectExcessSnapshots(series, tt.keep)
+
+			if len(got) != len(tt.wantIDs) {
+				t.Fatalf("len(excess) = %d, want %d", len(got), len(tt.wantIDs))
+			}
+			for i, id := range tt.wantIDs {
+				if got[i].ID != id {
+					t.Errorf("excess[%d] = %s, want %s", i, got[i].ID, id)
+				}
+			}
+		})
+	}
+
+	// The caller's slice must keep its original order.
+	if series[0].ID != "snap-2" {
+		t.Errorf("input series was reordered; first element = %s", series[0].ID)
+	}
+}
+
+func TestCountRetention_SnapshotMetadata(t *testing.T) {
+	policy := SnapshotPolicyDaily{
+		Enabled:        true,
+		RetentionType:  "count",
+		RetentionCount: 7,
+		TimeZone:       "UTC",
+		StartTime:      "02:00",
+	}
+	if err := policy.Normalize(); err != nil {
+		t.Fatalf("Normalize() unexpected error: %v", err)
+	}
+
+	result, err := policy.Evaluate(time.Date(2025, 12, 21, 3, 0, 0, 0, time.UTC), LastSnapshotInfo{})
+	if err != nil {
+		t.Fatalf("Evaluate() unexpected error: %v", err)
+	}
+	if !result.ShouldSnapshot {
+		t.Fatalf("ShouldSnapshot = false, want true. Reason: %s", result.Reason)
+	}
+	if !result.Metadata.IsCountRetention() || result.Metadata.RetentionCount != 7 {
+		t.Errorf("Metadata = %+v, want count retention of 7", result.Metadata)
+	}
+	// The expiry date applies once the volume no longer keeps count retention for the policy.
+	wantExpiry := time.Date(2025, 12, 21, 2, 0, 0, 0, time.UTC).AddDate(0, 0, 2)
+	if !result.Metadata.ExpiryDate.Equal(wantExpiry) {
+		t.Errorf("ExpiryDate = %s, want %s", result.Metadata.ExpiryDate, wantExpiry)
+	}
+
+	// Round trip through OpenStack metadata.
+	parsed := SnapshotMetadata{}
+	if err := parsed.ParseFromMetadata(result.Metadata.ToOpenstackMetadata()); err != nil {
+		t.Fatalf("ParseFromMetadata() unexpected error: %v", err)
+	}
+	if !parsed.Managed || !parsed.IsCountRetention() || !parsed.ExpiryDate.Equal(wantExpiry) {
+		t.Errorf("Parsed metadata = %+v, want managed count retention expiring at %s", parsed, wantExpiry)
+	}
+}
+
+func TestHasCountRetention(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata map[string]string
+		want     bool
+	}{
+		{
+			name: "Count Retention",
+			metadata: map[string]string{
+				"x-snapsentry-daily-enabled":         "true",
+				"x-snapsentry-daily-retention-type":  "count",
+				"x-snapsentry-daily-retention-count": "7",
+			},
+			want: true,
+		},
+		{
+			name: "Time Retention",
+			metadata: map[string]string{
+				"x-snapsentry-daily-enabled":         "true",
+				"x-snapsentry-daily-retention-type":  "time",
+				"x-snapsentry-daily-retention-count": "7",
+			},
+			want: false,
+		},
+		{
+			name: "Policy Disabled",
+			metadata: map[string]string{
+				"x-snapsentry-daily-enabled":         "false",
+				"x-snapsentry-daily-retention-type":  "count",
+				"x-snapsentry-daily-retention-count": "7",
+			},
+			want: false,
+		},
+		{
+			name: "Legacy Count Without Retention Count",
+			metadata: map[string]string{
+				"x-snapsentry-daily-enabled":        "true",
+				"x-snapsentry-daily-retention-type": "count",
+			},
+			want: false,
+		},
+		{
+			name: "Other Policy Type",
+			metadata: map[string]string{
+				"x-snapsentry-weekly-enabled":         "true",
+				"x-snapsentry-weekly-retention-type":  "count",
+				"x-snapsentry-weekly-retention-count": "4",
+			},
+			want: false,
+		},
+		{name: "No Policy", metadata: map[string]string{}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HasCountRetention(tt.metadata, "daily"); got != tt.want {
+				t.Errorf("HasCountRetention() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package policy
+
+import "time"
+
+// Cinder snapshot statuses SnapSentry acts on.
+const (
+	SnapshotStatusAvailable     = "available"
+	SnapshotStatusCreating      = "creating"
+	SnapshotStatusDeleting      = "deleting"
+	SnapshotStatusError         = "error"
+	SnapshotStatusErrorDeleting = "error_deleting"
+)
+
+// SnapshotSatisfiesWindow reports whether a snapshot with the given status counts as the snapshot of its window.
+// Available snapshots and snapshots still in flight (e.g. "creating", "backing-up") do, so a run never creates a
+// second snapshot while the first one is still being written. Failed and deleting snapshots do not.
+func SnapshotSatisfiesWindow(status string) bool {
+	switch status {
+	case SnapshotStatusError, SnapshotStatusErrorDeleting, SnapshotStatusDeleting:
+		return false
+	default:
+		return true
+	}
+}
+
+// IsStuckSnapshot reports whether a snapshot has been in a failed ("error", "error_deleting") or unfinished
+// ("creating") state for longer than maxAge, measured from its creation. Such snapshots are garbage-collected
+// by the expiry workflow. A maxAge <= 0 never reports a snapshot as stuck.
+func IsStuckSnapshot(status string, createdAt, now time.Time, maxAge time.Duration) bool {
+	if maxAge <= 0 {
+		return false
+	}
+	switch status {
+	case SnapshotStatusError, SnapshotStatusErrorDeleting, SnapshotStatusCreating:
+		return now.Sub(createdAt) > maxAge
+	default:
+		return false
+	}
+}
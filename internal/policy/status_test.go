@@ -0,0 +1,117 @@
+package policy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSnapshotSatisfiesWindow(t *testing.T) {
+	tests := []struct {
+		status string
+		want   bool
+	}{
+		{status: SnapshotStatusAvailable, want: true},
+		{status: SnapshotStatusCreating, want: true},
+		{status: "backing-up", want: true},
+		{status: SnapshotStatusError, want: false},
+		{status: SnapshotStatusErrorDeleting, want: false},
+		{status: SnapshotStatusDeleting, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			if got := SnapshotSatisfiesWindow(tt.status); got != tt.want {
+				t.Errorf("SnapshotSatisfiesWindow(%q) = %v, want %v", tt.status, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsStuckSnapshot(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		status    string
+		createdAt time.Time
+		maxAge    time.Duration
+		want      bool
+	}{
+		{
+			name:      "Error Past Age",
+			status:    SnapshotStatusError,
+			createdAt: now.Add(-25 * time.Hour),
+			maxAge:    24 * time.Hour,
+			want:      true,
+		},
+		{
+			name:      "Creating Past Age",
+			status:    SnapshotStatusCreating,
+			createdAt: now.Add(-3 * time.Hour),
+			maxAge:    2 * time.Hour,
+			want:      true,
+		},
+		{
+			name:      "Error Deleting Past Age",
+			status:    SnapshotStatusErrorDeleting,
+			createdAt: now.Add(-25 * time.Hour),
+			maxAge:    24 * time.Hour,
+			want:      true,
+		},
+		{
+			name:      "Creating Within Age",
+			status:    SnapshotStatusCreating,
+			createdAt: now.Add(-30 * time.Minute),
+			maxAge:    2 * time.Hour,
+			want:      false,
+		},
+		{
+			name:      "Available Is Never Stuck",
+			status:    SnapshotStatusAvailable,
+			createdAt: now.Add(-90 * 24 * time.Hour),
+			maxAge:    24 * time.Hour,
+			want:      false,
+		},
+		{
+			name:      "Disabled",
+			status:    SnapshotStatusError,
+			createdAt: now.Add(-90 * 24 * time.Hour),
+			maxAge:    0,
+			want:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsStuckSnapshot(tt.status, tt.createdAt, now, tt.maxAge); got != tt.want {
+				t.Errorf("IsStuckSnapshot() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSnapshotMetadata_PendingRoundTrip(t *testing.T) {
+	original := SnapshotMetadata{Managed: true, PolicyType: "daily", Pending: true}
+
+	parsed := SnapshotMetadata{}
+	if err := parsed.ParseFromMetadata(original.ToOpenstackMetadata()); err != nil {
+		t.Fatalf("ParseFromMetadata() unexpected error: %v", err)
+	}
+	if !parsed.Pending {
+		t.Errorf("Pending = false, want true")
+	}
+
+	// Confirmed snapshots are written with "false", which must parse as not pending.
+	confirmed := SnapshotMetadata{}
+	if err := confirmed.ParseFromMetadata(map[string]string{PendingTag: "false"}); err != nil {
+		t.Fatalf("ParseFromMetadata() unexpected error: %v", err)
+	}
+	if confirmed.Pending {
+		t.Errorf("Pending = true, want false")
+	}
+
+	// Snapshots that never were pending must not carry the key.
+	if _, ok := (SnapshotMetadata{Managed: true}).ToOpenstackMetadata()[PendingTag]; ok {
+		t.Errorf("metadata unexpectedly contains x-snapsentry-snapshot-pending")
+	}
+}
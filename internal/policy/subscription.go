@@ -0,0 +1,79 @@
+package policy
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// SubscriptionKeys maps each volume setting that can be subscribed to the volume metadata keys it owns.
+// The keys are read from the json tags of the setting's struct, so they follow the struct definitions.
+var SubscriptionKeys = map[string][]string{
+	"express":        MetadataKeys[SnapshotPolicyExpress](),
+	"cron":           MetadataKeys[SnapshotPolicyCron](),
+	"daily":          MetadataKeys[SnapshotPolicyDaily](),
+	"weekly":         MetadataKeys[SnapshotPolicyWeekly](),
+	"monthly":        MetadataKeys[SnapshotPolicyMonthly](),
+	"gfs":            MetadataKeys[GFSConfig](),
+	"group-snapshot": MetadataKeys[GroupSnapshotConfig](),
+	"hooks":          MetadataKeys[QuiesceHookConfig](),
+	"backup":         MetadataKeys[BackupConfig](),
+	"pause":          MetadataKeys[PauseConfig](),
+}
+
+// RetiredSubscriptionKeys are keys that earlier releases wrote for a setting but that are no longer read.
+// They are removed together with the setting.
+var RetiredSubscriptionKeys = map[string][]string{
+	"monthly": {"x-snapsentry-monthly-day-of-month"},
+}
+
+// SubscriptionMetadataKeys returns every key a setting may have left on a volume, retired keys included.
+func SubscriptionMetadataKeys(name string) []string {
+	return slices.Concat(SubscriptionKeys[name], RetiredSubscriptionKeys[name])
+}
+
+// SubscriptionNames returns the names of SubscriptionKeys, sorted.
+func SubscriptionNames() []string {
+	return slices.Sorted(maps.Keys(SubscriptionKeys))
+}
+
+// MetadataKeys returns the metadata keys of a SnapSentry struct, taken from its json tags.
+// Fields without a json tag (e.g. the parsed time.Location of a policy) are not stored and are skipped.
+func MetadataKeys[T any]() []string {
+	var keys []string
+	t := reflect.TypeFor[T]()
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		keys = append(keys, name)
+	}
+	return keys
+}
+
+// HasSubscription reports whether the metadata still holds a key of any subscribed setting.
+func HasSubscription(metadata map[string]string) bool {
+	for name := range SubscriptionKeys {
+		for _, key := range SubscriptionMetadataKeys(name) {
+			if _, ok := metadata[key]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasPolicy reports whether the metadata holds a key of any snapshot policy (enabled or not).
+// Other settings, such as hooks or backups, do not count.
+func HasPolicy(metadata map[string]string) bool {
+	for _, name := range []string{"express", "cron", "daily", "weekly", "monthly"} {
+		for _, key := range SubscriptionMetadataKeys(name) {
+			if _, ok := metadata[key]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
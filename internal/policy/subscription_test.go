@@ -0,0 +1,68 @@
+package policy
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestSubscriptionKeys_MatchWrittenMetadata(t *testing.T) {
+	tests := []struct {
+		name    string
+		written map[string]string
+	}{
+		{name: "express", written: (&SnapshotPolicyExpress{}).ToOpenstackMetadata()},
+		{name: "cron", written: (&SnapshotPolicyCron{}).ToOpenstackMetadata()},
+		{name: "daily", written: (&SnapshotPolicyDaily{}).ToOpenstackMetadata()},
+		{name: "weekly", written: (&SnapshotPolicyWeekly{}).ToOpenstackMetadata()},
+		{name: "monthly", written: (&SnapshotPolicyMonthly{}).ToOpenstackMetadata()},
+		{name: "gfs", written: (&GFSConfig{}).ToOpenstackMetadata()},
+		{name: "group-snapshot", written: (&GroupSnapshotConfig{GroupType: "consistent"}).ToOpenstackMetadata()},
+		{name: "hooks", written: (&QuiesceHookConfig{}).ToOpenstackMetadata()},
+		{name: "backup", written: (&BackupConfig{}).ToOpenstackMetadata()},
+		{name: "pause", written: (&PauseConfig{}).ToOpenstackMetadata()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delete(tt.written, ManagedTag)
+			want := slices.Sorted(maps.Keys(tt.written))
+
+			got := slices.Sorted(slices.Values(SubscriptionKeys[tt.name]))
+			if !slices.Equal(got, want) {
+				t.Errorf("SubscriptionKeys[%q] = %v, want %v", tt.name, got, want)
+			}
+		})
+	}
+
+	if len(tests) != len(SubscriptionKeys) {
+		t.Errorf("SubscriptionKeys has %d settings, test covers %d", len(SubscriptionKeys), len(tests))
+	}
+}
+
+func TestHasSubscription(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata map[string]string
+		want     bool
+	}{
+		{
+			name:     "Policy Key Left",
+			metadata: map[string]string{ManagedTag: "true", "x-snapsentry-weekly-enabled": "false"},
+			want:     true,
+		},
+		{
+			name:     "Only Managed Tag and Foreign Keys",
+			metadata: map[string]string{ManagedTag: "true", "billing-code": "42"},
+			want:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HasSubscription(tt.metadata); got != tt.want {
+				t.Errorf("HasSubscription() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
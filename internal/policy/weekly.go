@@ -28,12 +28,13 @@ import (
 //   - startMinute: Parsed minute (0-59).
 //   - startDayWeekday: Parsed time.Weekday (0=Sunday, 6=Saturday).
 type SnapshotPolicyWeekly struct {
-	Enabled       bool   `json:"x-snapsentry-weekly-enabled"`
-	RetentionDays int    `json:"x-snapsentry-weekly-retention-days"`
-	RetentionType string `json:"x-snapsentry-weekly-retention-type"`
-	TimeZone      string `json:"x-snapsentry-weekly-timezone"`
-	StartTime     string `json:"x-snapsentry-weekly-start-time"`
-	DayOfWeek     string `json:"x-snapsentry-weekly-start-day-of-week"`
+	Enabled        bool   `json:"x-snapsentry-weekly-enabled"`
+	RetentionDays  int    `json:"x-snapsentry-weekly-retention-days"`
+	RetentionType  string `json:"x-snapsentry-weekly-retention-type"`
+	RetentionCount int    `json:"x-snapsentry-weekly-retention-count"`
+	TimeZone       string `json:"x-snapsentry-weekly-timezone"`
+	StartTime      string `json:"x-snapsentry-weekly-start-time"`
+	DayOfWeek      string `json:"x-snapsentry-weekly-start-day-of-week"`
 
 	// Internal fields for calculation
 	Loc             *time.Location
@@ -79,6 +80,7 @@ func (s *SnapshotPolicyWeekly) ToOpenstackMetadata() map[string]string {
 		"x-snapsentry-weekly-enabled":           strconv.FormatBool(s.Enabled),
 		"x-snapsentry-weekly-retention-days":    strconv.Itoa(s.RetentionDays),
 		"x-snapsentry-weekly-retention-type":    s.RetentionType,
+		"x-snapsentry-weekly-retention-count":   strconv.Itoa(s.RetentionCount),
 		"x-snapsentry-weekly-timezone":          s.TimeZone,
 		"x-snapsentry-weekly-start-time":        s.StartTime,
 		"x-snapsentry-weekly-start-day-of-week": s.DayOfWeek,
@@ -87,7 +89,7 @@ func (s *SnapshotPolicyWeekly) ToOpenstackMetadata() map[string]string {
 
 // Normalize validates inputs and sets defaults.
 //  1. TimeZone -> time.Location (Def: UTC)
-//  2. Retention -> int (Def: 7)
+//  2. Retention -> int (Def: 7), RetentionType -> time/count (Def: time)
 //  3. StartTime -> HH:MM
 //  4. DayOfWeek -> time.Weekday (Def: Sunday)
 func (s *SnapshotPolicyWeekly) Normalize() error {
@@ -102,6 +104,13 @@ func (s *SnapshotPolicyWeekly) Normalize() error {
 	// 2. Normalize Retention Days (Default to 7 days / 1 week)
 	s.RetentionDays = helperNormalizeRetentionDays(s.RetentionDays, 7)
 
+	// Normalize Retention Type (time / count)
+	retentionType, err := helperNormalizeRetentionType(s.RetentionType, s.RetentionCount)
+	if err != nil {
+		return err
+	}
+	s.RetentionType = retentionType
+
 	// 3. Normalize Start Time
 	starttime, err := helperNormalizeStartTime(s.StartTime)
 	if err != nil {
@@ -179,10 +188,12 @@ func (s *SnapshotPolicyWeekly) Evaluate(now time.Time, lastSnapshot LastSnapshot
 
 	// 5. Success
 	result.Metadata = SnapshotMetadata{
-		Managed:       true,
-		ExpiryDate:    result.Window.StartTime.AddDate(0, 0, s.RetentionDays),
-		PolicyType:    "weekly",
-		RetentionDays: s.RetentionDays,
+		Managed:        true,
+		ExpiryDate:     result.Window.StartTime.AddDate(0, 0, s.RetentionDays),
+		PolicyType:     "weekly",
+		RetentionDays:  s.RetentionDays,
+		RetentionType:  s.RetentionType,
+		RetentionCount: s.RetentionCount,
 	}
 
 	return result, nil
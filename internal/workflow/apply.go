@@ -0,0 +1,315 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/cloud/openstack"
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/policy"
+)
+
+// Actions of a metadata key in a PolicyPlan.
+const (
+	KeyActionAdd    = "add"
+	KeyActionChange = "change"
+	KeyActionRemove = "remove"
+)
+
+// KeyChange is one metadata key that differs between the policy document and the volume.
+type KeyChange struct {
+	Key     string `json:"key"`
+	Action  string `json:"action"`
+	Current string `json:"current,omitempty"`
+	Desired string `json:"desired,omitempty"`
+}
+
+// VolumeChange is the metadata update that brings one volume in line with the policy document.
+type VolumeChange struct {
+	VolumeID   string      `json:"volume_id"`
+	VolumeName string      `json:"volume_name"`
+	Entries    []string    `json:"entries"`
+	Changes    []KeyChange `json:"changes"`
+}
+
+// PolicyPlan lists the volumes whose metadata differs from the policy document.
+type PolicyPlan struct {
+	Prune     bool           `json:"prune"`
+	Volumes   []VolumeChange `json:"volumes"`
+	Unchanged int            `json:"unchanged"`
+}
+
+// set returns the keys to write, with their desired values.
+func (v VolumeChange) set() map[string]string {
+	set := map[string]string{}
+	for _, c := range v.Changes {
+		if c.Action != KeyActionRemove {
+			set[c.Key] = c.Desired
+		}
+	}
+	return set
+}
+
+// remove returns the keys to delete.
+func (v VolumeChange) remove() []string {
+	var remove []string
+	for _, c := range v.Changes {
+		if c.Action == KeyActionRemove {
+			remove = append(remove, c.Key)
+		}
+	}
+	return remove
+}
+
+// ApplyPolicyFile reconciles the volume metadata of the project with a policy document and prints the plan.
+// With prune, policies of the document's settings (policy.DocumentSettings) that the document does not assign
+// are removed, including from subscribed volumes no entry selects. During a dry-run only the plan is printed.
+func ApplyPolicyFile(cloudName, logLevel, path string, prune bool, opts RunOptions) error {
+	logger := SetupLogger(logLevel, cloudName).With("workflow", "apply", "file", path)
+
+	doc, err := loadPolicyDocument(path)
+	if err != nil {
+		logger.Error("Invalid policy document", "error", err)
+		return err
+	}
+
+	client, err := initClient(cloudName, logLevel)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	plan, err := planPolicyDocument(ctx, client, doc, prune)
+	if err != nil {
+		logger.Error("Planning failed", "error", err)
+		return err
+	}
+
+	if err := plan.Render(os.Stdout, opts.OutputFormat); err != nil {
+		return err
+	}
+	logger.Info("Plan computed", "volumes_to_update", len(plan.Volumes), "unchanged", plan.Unchanged, "prune", prune)
+
+	if opts.DryRun || len(plan.Volumes) == 0 {
+		return nil
+	}
+	return reconcilePolicyPlan(ctx, client, plan, logger)
+}
+
+// ReconcilePolicyFile is the daemon variant of ApplyPolicyFile: the plan is logged instead of printed.
+func ReconcilePolicyFile(cloudName, logLevel, path string, prune bool, dryRun bool) error {
+	logger := SetupLogger(logLevel, cloudName).With("workflow", "apply", "file", path)
+
+	// The file is read on every cycle, so a change merged in git is picked up without a restart.
+	doc, err := loadPolicyDocument(path)
+	if err != nil {
+		logger.Error("Invalid policy document; volumes are left unchanged", "error", err)
+		return err
+	}
+
+	client, err := initClient(cloudName, logLevel)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	plan, err := planPolicyDocument(ctx, client, doc, prune)
+	if err != nil {
+		logger.Error("Planning failed", "error", err)
+		return err
+	}
+
+	if len(plan.Volumes) == 0 {
+		logger.Debug("Volume metadata matches the policy document", "volumes", plan.Unchanged)
+		return nil
+	}
+	for _, v := range plan.Volumes {
+		logger.Info("Volume differs from the policy document", "volume_id", v.VolumeID, "entries", v.Entries, "changes", len(v.Changes), "dry_run", dryRun)
+	}
+	if dryRun {
+		return nil
+	}
+	return reconcilePolicyPlan(ctx, client, plan, logger)
+}
+
+// ValidatePolicyFile reads and validates a policy document without contacting the cloud.
+func ValidatePolicyFile(path string) error {
+	_, err := loadPolicyDocument(path)
+	return err
+}
+
+// loadPolicyDocument reads and validates a policy document file.
+func loadPolicyDocument(path string) (policy.Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return policy.Document{}, fmt.Errorf("failed to read policy document: %w", err)
+	}
+	return policy.ParseDocument(data)
+}
+
+// planPolicyDocument compares the desired metadata of every volume selected by the document with its actual metadata.
+//
+// Workflow (per volume of the project):
+//  1. Desired State: Merges the settings of every entry selecting the volume. Two entries setting the same
+//     policy on one volume is a conflict and fails the whole plan, so nothing is applied half-way.
+//  2. Diff: Keys whose value differs are added or changed; the managed tag is added when missing.
+//  3. Prune: Keys of document settings not in the desired state are removed, also on subscribed volumes no
+//     entry selects. When no setting at all is left, the managed tag is removed too.
+func planPolicyDocument(ctx context.Context, client *openstack.Client, doc policy.Document, prune bool) (PolicyPlan, error) {
+	plan := PolicyPlan{Prune: prune, Volumes: []VolumeChange{}}
+
+	entrySettings := make([]map[string]map[string]string, len(doc.Policies))
+	for i, entry := range doc.Policies {
+		settings, err := entry.SettingsMetadata()
+		if err != nil {
+			return plan, fmt.Errorf("policy entry '%s': %w", entry.Name, err)
+		}
+		entrySettings[i] = settings
+	}
+
+	vols, err := client.ListVolumes(ctx, nil)
+	if err != nil {
+		return plan, err
+	}
+
+	var conflicts error
+	for _, vol := range vols {
+		// 1. Desired State
+		desired := map[string]string{}
+		owners := map[string]string{}
+		var entries []string
+		for i, entry := range doc.Policies {
+			if !entry.Selector.Matches(vol.ID, vol.Name, vol.Metadata) {
+				continue
+			}
+			entries = append(entries, entry.Name)
+			for setting, metadata := range entrySettings[i] {
+				if owner, taken := owners[setting]; taken {
+					conflicts = errors.Join(conflicts, fmt.Errorf("volume %s (%s): %s policy is set by both '%s' and '%s'",
+						vol.ID, vol.Name, setting, owner, entry.Name))
+					continue
+				}
+				owners[setting] = entry.Name
+				maps.Copy(desired, metadata)
+			}
+		}
+
+		managed := vol.Metadata[policy.ManagedTag] == "true"
+		if len(entries) == 0 && !(prune && managed) {
+			continue
+		}
+
+		// 2. Diff
+		var changes []KeyChange
+		for _, key := range slices.Sorted(maps.Keys(desired)) {
+			current, ok := vol.Metadata[key]
+			switch {
+			case !ok:
+				changes = append(changes, KeyChange{Key: key, Action: KeyActionAdd, Desired: desired[key]})
+			case current != desired[key]:
+				changes = append(changes, KeyChange{Key: key, Action: KeyActionChange, Current: current, Desired: desired[key]})
+			}
+		}
+		if len(desired) > 0 && !managed {
+			action := KeyActionAdd
+			if _, ok := vol.Metadata[policy.ManagedTag]; ok {
+				action = KeyActionChange
+			}
+			changes = append(changes, KeyChange{Key: policy.ManagedTag, Action: action, Current: vol.Metadata[policy.ManagedTag], Desired: "true"})
+		}
+
+		// 3. Prune
+		if prune {
+			remaining := maps.Clone(vol.Metadata)
+			for _, setting := range policy.DocumentSettings {
+				for _, key := range policy.SubscriptionMetadataKeys(setting) {
+					if _, inDoc := desired[key]; inDoc {
+						continue
+					}
+					if current, ok := vol.Metadata[key]; ok {
+						changes = append(changes, KeyChange{Key: key, Action: KeyActionRemove, Current: current})
+						delete(remaining, key)
+					}
+				}
+			}
+			if managed && len(desired) == 0 && !policy.HasSubscription(remaining) {
+				changes = append(changes, KeyChange{Key: policy.ManagedTag, Action: KeyActionRemove, Current: vol.Metadata[policy.ManagedTag]})
+			}
+		}
+
+		if len(changes) == 0 {
+			plan.Unchanged++
+			continue
+		}
+		plan.Volumes = append(plan.Volumes, VolumeChange{VolumeID: vol.ID, VolumeName: vol.Name, Entries: entries, Changes: changes})
+	}
+
+	if conflicts != nil {
+		return plan, fmt.Errorf("policy document has conflicting entries: %w", conflicts)
+	}
+	return plan, nil
+}
+
+// reconcilePolicyPlan applies a plan volume by volume. Added and changed keys are merged into the metadata,
+// removed keys are deleted one by one. A failing volume does not stop the others.
+func reconcilePolicyPlan(ctx context.Context, client *openstack.Client, plan PolicyPlan, logger *slog.Logger) error {
+	var errs error
+	applied := 0
+
+	for _, v := range plan.Volumes {
+		if ctx.Err() != nil {
+			return errors.Join(errs, ctx.Err())
+		}
+		volLogger := logger.With("volume_id", v.VolumeID, "volume_name", v.VolumeName)
+
+		if set := v.set(); len(set) > 0 {
+			_, reqID, err := client.CreateVolumeSubscription(ctx, v.VolumeID, set)
+			if err != nil {
+				volLogger.Error("Failed to update volume metadata", "error", err, "request_id", reqID)
+				errs = errors.Join(errs, fmt.Errorf("volume %s: %w", v.VolumeID, err))
+				continue
+			}
+		}
+		if remove := v.remove(); len(remove) > 0 {
+			reqID, err := client.DeleteVolumeMetadataKeys(ctx, v.VolumeID, remove)
+			if err != nil {
+				volLogger.Error("Failed to remove volume metadata", "error", err, "request_id", reqID)
+				errs = errors.Join(errs, fmt.Errorf("volume %s: %w", v.VolumeID, err))
+				continue
+			}
+		}
+
+		applied++
+		volLogger.Info("Volume reconciled with the policy document", "changes", len(v.Changes))
+	}
+
+	if errs != nil {
+		logger.Error("Policy document applied with errors", "applied", applied, "failed", len(plan.Volumes)-applied)
+		return errs
+	}
+	logger.Info("Policy document applied successfully", "applied", applied)
+	return nil
+}
+
+// Render writes the plan to w in the requested format.
+func (p PolicyPlan) Render(w io.Writer, format string) error {
+	return renderListing(w, format, p, func(w io.Writer) error {
+		t := newStyledTable("VOLUME ID", "VOLUME NAME", "ENTRIES", "ACTION", "KEY", "CURRENT", "DESIRED")
+		for _, v := range p.Volumes {
+			for _, c := range v.Changes {
+				t.Row(v.VolumeID, v.VolumeName, strings.Join(v.Entries, ", "), c.Action, c.Key, c.Current, c.Desired)
+			}
+		}
+		if _, err := fmt.Fprintln(w, t); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintf(w, "%d volumes to update, %d unchanged\n", len(p.Volumes), p.Unchanged)
+		return err
+	})
+}
@@ -0,0 +1,345 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/cloud/openstack"
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/metrics"
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/notifications"
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/policy"
+	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/backups"
+	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/volumes"
+)
+
+// processBackupExports exports the newest snapshot of each backup enabled volume (policy.BackupConfig) to a
+// Cinder backup. It runs after snapshot creation, so a snapshot taken in this run is exported right away.
+//
+// Workflow (per volume, sequentially since backups are heavy on the backup service):
+//  1. Source: The newest available managed snapshot of the configured policy type.
+//  2. Idempotency: Skipped if a managed backup of that snapshot exists (unless it failed), or if another
+//     backup of the volume is still in progress, so that incremental chains stay in order.
+//  3. Export: Creates the backup with BackupMetadata. It is incremental if configured and an available
+//     backup of the volume exists. The request is asynchronous; completion is checked on the next run.
+//
+// Every backup enabled volume is recorded in the report. During a dry-run step 3 is skipped. Paused volumes
+// are skipped, and during a blackout window no backup is exported.
+func processBackupExports(
+	ctx context.Context,
+	client *openstack.Client,
+	vols []volumes.Volume,
+	notifyProvider notifications.Notifier,
+	report *RunReport,
+	logger *slog.Logger,
+) {
+	type exportTarget struct {
+		vol volumes.Volume
+		cfg policy.BackupConfig
+	}
+
+	var targets []exportTarget
+	for _, vol := range vols {
+		cfg := policy.BackupConfig{}
+		_ = cfg.ParseFromMetadata(vol.Metadata)
+		if !cfg.Enabled {
+			continue
+		}
+
+		pause := policy.PauseConfig{}
+		_ = pause.ParseFromMetadata(vol.Metadata)
+		if pause.IsActive(time.Now()) {
+			report.addBackup(BackupOutcome{VolumeID: vol.ID, VolumeName: vol.Name, PolicyType: cfg.PolicyType, Outcome: OutcomePaused, Reason: pause.Describe()})
+			continue
+		}
+
+		if err := cfg.Validate(); err != nil {
+			logger.Warn("Backup configuration is invalid", "volume_id", vol.ID, "err", err)
+			report.addBackup(BackupOutcome{VolumeID: vol.ID, VolumeName: vol.Name, PolicyType: cfg.PolicyType, Outcome: OutcomeFailed, Error: err.Error()})
+			sendNotification(ctx, notifyProvider, notifications.PolicyMisconfigured{
+				Service:    "snapsentry",
+				VolumeID:   vol.ID,
+				VolumeName: vol.Name,
+				PolicyType: "backup",
+				Message:    err.Error(),
+			}, logger)
+			continue
+		}
+		targets = append(targets, exportTarget{vol: vol, cfg: cfg})
+	}
+
+	if len(targets) == 0 {
+		return
+	}
+
+	if report.Blackout != "" {
+		logger.Info("Backup export deferred by blackout window", "blackout", report.Blackout, "volume_count", len(targets))
+		for _, t := range targets {
+			report.addBackup(BackupOutcome{VolumeID: t.vol.ID, VolumeName: t.vol.Name, PolicyType: t.cfg.PolicyType, Outcome: OutcomeDeferred, Reason: "deferred by blackout window " + report.Blackout})
+		}
+		return
+	}
+
+	logger.Info("Exporting snapshots to backups", "volume_count", len(targets))
+	managedBackups, err := client.ListManagedBackups(ctx)
+	if err != nil {
+		logger.Error("Backup discovery failed; skipping backup export", "error", err)
+		for _, t := range targets {
+			report.addBackup(BackupOutcome{VolumeID: t.vol.ID, VolumeName: t.vol.Name, PolicyType: t.cfg.PolicyType, Outcome: OutcomeFailed, Error: err.Error()})
+		}
+		return
+	}
+
+	byVolume := map[string][]backups.Backup{}
+	for _, b := range managedBackups {
+		byVolume[b.VolumeID] = append(byVolume[b.VolumeID], b)
+	}
+
+	for _, t := range targets {
+		if ctx.Err() != nil {
+			logger.Error("Workflow execution halted due to timeout or cancellation")
+			return
+		}
+		exportVolumeBackup(ctx, client, t.vol, t.cfg, byVolume[t.vol.ID], notifyProvider, report, logger)
+	}
+}
+
+// exportVolumeBackup exports the newest snapshot of the configured policy type of one volume.
+// existing are the managed backups of the volume.
+func exportVolumeBackup(
+	ctx context.Context,
+	client *openstack.Client,
+	vol volumes.Volume,
+	cfg policy.BackupConfig,
+	existing []backups.Backup,
+	notifyProvider notifications.Notifier,
+	report *RunReport,
+	logger *slog.Logger,
+) {
+	backupLogger := logger.With("volume_id", vol.ID, "volume_name", vol.Name, "policy_type", cfg.PolicyType)
+	outcome := BackupOutcome{VolumeID: vol.ID, VolumeName: vol.Name, PolicyType: cfg.PolicyType}
+	defer func() { report.addBackup(outcome) }()
+
+	// 1. Source
+	snaps, err := client.ListManagedVolumeSnapshots(ctx, vol.ID, cfg.PolicyType, true)
+	if err != nil {
+		backupLogger.Error("Snapshot history retrieval failed", "error", err)
+		outcome.Outcome = OutcomeFailed
+		outcome.Error = err.Error()
+		return
+	}
+	if len(snaps) == 0 {
+		backupLogger.Debug("No snapshot to export yet")
+		outcome.Outcome = OutcomeSkipped
+		outcome.Reason = "no snapshot of the policy yet"
+		return
+	}
+	source := snaps[0]
+	outcome.SnapshotID = source.ID
+	if source.Status != policy.SnapshotStatusAvailable {
+		backupLogger.Debug("Newest snapshot is not available yet", "snapshot_id", source.ID, "status", source.Status)
+		outcome.Outcome = OutcomeSkipped
+		outcome.Reason = fmt.Sprintf("snapshot is not available yet (%s)", source.Status)
+		return
+	}
+
+	// 2. Idempotency
+	blocking, reason, incremental := selectBackupExport(source.ID, existing, cfg.Incremental)
+	if blocking != nil {
+		meta := policy.BackupMetadata{}
+		if blocking.Metadata != nil {
+			_ = meta.ParseFromMetadata(*blocking.Metadata)
+		}
+
+		outcome.Outcome = OutcomeSkipped
+		outcome.Reason = reason
+		if meta.SourceSnapshotID == source.ID {
+			backupLogger.Debug("Snapshot is already exported", "backup_id", blocking.ID, "status", blocking.Status)
+			outcome.BackupID = blocking.ID
+			outcome.ExpiryDate = meta.ExpiryDate
+		} else {
+			backupLogger.Info("Another backup of the volume is in progress; export postponed", "backup_id", blocking.ID)
+		}
+		return
+	}
+
+	// 3. Export
+	meta := policy.BackupMetadata{
+		Managed:          true,
+		ExpiryDate:       source.CreatedAt.AddDate(0, 0, cfg.RetentionDays).UTC(),
+		PolicyType:       cfg.PolicyType,
+		RetentionDays:    cfg.RetentionDays,
+		SourceSnapshotID: source.ID,
+	}
+	outcome.BackupName = generateBackupName(cfg.PolicyType, source.CreatedAt, vol.ID)
+	outcome.Incremental = incremental
+	outcome.ExpiryDate = meta.ExpiryDate
+
+	if report.DryRun {
+		outcome.Outcome = OutcomeWouldCreate
+		backupLogger.Info("Dry-run: backup would be created", "snapshot_id", source.ID, "backup_name", outcome.BackupName, "incremental", incremental)
+		return
+	}
+
+	backupLogger.Info("Exporting snapshot to backup", "snapshot_id", source.ID, "backup_name", outcome.BackupName, "incremental", incremental)
+	created, reqID, err := client.CreateManagedBackup(ctx, vol.ID, source.ID, outcome.BackupName, incremental, cfg.Container, meta.ToOpenstackMetadata())
+	outcome.RequestID = reqID
+	if err != nil {
+		outcome.Outcome = OutcomeFailed
+		outcome.Error = err.Error()
+		metrics.BackupsFailed.WithLabelValues(cfg.PolicyType).Inc()
+		backupLogger.Error("Backup export failed", "error", err, "request_id", reqID, "snapshot_id", source.ID)
+		sendNotification(ctx, notifyProvider, notifications.BackupFailure{
+			Service:    "snapsentry",
+			Operation:  notifications.BackupOperationExport,
+			VolumeID:   vol.ID,
+			SnapshotID: source.ID,
+			PolicyType: cfg.PolicyType,
+			Message:    fmt.Sprintf("Snapsentry backup export has failed due to %s", err),
+		}, backupLogger)
+		return
+	}
+
+	outcome.Outcome = OutcomeCreated
+	outcome.BackupID = created.ID
+	metrics.BackupsCreated.WithLabelValues(cfg.PolicyType).Inc()
+	backupLogger.Info("Backup export requested", "backup_id", created.ID, "request_id", reqID, "snapshot_id", source.ID)
+}
+
+// selectBackupExport decides how the snapshot sourceID is exported, given the managed backups of its volume.
+//
+// Rules:
+//   - Exported: A backup of the snapshot exists that did not fail; the export is skipped.
+//   - In Progress: Another backup of the volume is still "creating"; the export is postponed to a later run.
+//   - Incremental: With incremental enabled, the backup builds on the chain only if an "available" backup of
+//     the volume exists; otherwise it is a full backup.
+//
+// Returns the backup the export is skipped for together with the reason, or nil and whether to export incrementally.
+func selectBackupExport(sourceID string, existing []backups.Backup, incremental bool) (*backups.Backup, string, bool) {
+	var inProgress *backups.Backup
+	hasAvailable := false
+	for i, b := range existing {
+		meta := policy.BackupMetadata{}
+		if b.Metadata != nil {
+			_ = meta.ParseFromMetadata(*b.Metadata)
+		}
+
+		switch {
+		case meta.SourceSnapshotID == sourceID && b.Status != "error":
+			return &existing[i], fmt.Sprintf("snapshot already exported (backup %s)", b.Status), false
+		case b.Status == "creating" && inProgress == nil:
+			inProgress = &existing[i]
+		case b.Status == "available":
+			hasAvailable = true
+		}
+	}
+
+	if inProgress != nil {
+		return inProgress, fmt.Sprintf("backup %s of the volume is still in progress", inProgress.ID), false
+	}
+	return nil, "", incremental && hasAvailable
+}
+
+// processBackupExpiry deletes managed backups whose expiry date has passed, and failed exports.
+//
+// Backups that incremental backups depend on cannot be deleted; they are kept (and reported as skipped)
+// until their dependents expired. Backups are processed newest first, so a chain is removed over
+// consecutive runs. Selected backups are recorded in the report; during a dry-run they are not deleted.
+func processBackupExpiry(
+	ctx context.Context,
+	client *openstack.Client,
+	now time.Time,
+	notifyProvider notifications.Notifier,
+	report *RunReport,
+	logger *slog.Logger,
+) error {
+	managedBackups, err := client.ListManagedBackups(ctx)
+	if err != nil {
+		logger.Error("Failed to fetch managed backups", "error", err)
+		return err
+	}
+	logger.Info("Found managed backups", "count", len(managedBackups))
+
+	slices.SortStableFunc(managedBackups, func(a, b backups.Backup) int { return b.CreatedAt.Compare(a.CreatedAt) })
+
+	for _, b := range managedBackups {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		backupLog := logger.With("backup_id", b.ID, "volume_id", b.VolumeID)
+		meta := policy.BackupMetadata{}
+		if err := meta.ParseFromMetadata(*b.Metadata); err != nil {
+			backupLog.Warn("Skipping backup: invalid metadata", "error", err)
+			continue
+		}
+
+		var reason string
+		switch {
+		case b.Status == "error":
+			reason = "backup export failed: " + b.FailReason
+		case b.Status != "available":
+			backupLog.Debug("Skipping backup: not available", "status", b.Status)
+			continue
+		case meta.ExpiryDate.IsZero() || now.Before(meta.ExpiryDate):
+			backupLog.Debug("Backup is in active retention period", "expires_at", meta.ExpiryDate)
+			continue
+		default:
+			reason = "expired"
+		}
+
+		outcome := BackupOutcome{
+			VolumeID:    b.VolumeID,
+			PolicyType:  meta.PolicyType,
+			SnapshotID:  meta.SourceSnapshotID,
+			BackupID:    b.ID,
+			BackupName:  b.Name,
+			Incremental: b.IsIncremental,
+			ExpiryDate:  meta.ExpiryDate,
+			Reason:      reason,
+			Outcome:     OutcomeDeleted,
+		}
+
+		if b.HasDependentBackups {
+			backupLog.Info("Backup has dependent incremental backups; kept until they are deleted", "expires_at", meta.ExpiryDate)
+			outcome.Outcome = OutcomeSkipped
+			outcome.Reason = reason + "; incremental backups depend on it"
+			report.addBackup(outcome)
+			continue
+		}
+
+		if report.DryRun {
+			outcome.Outcome = OutcomeWouldDelete
+			backupLog.Info("Dry-run: backup would be deleted", "expires_at", meta.ExpiryDate, "reason", reason)
+			report.addBackup(outcome)
+			continue
+		}
+
+		reqID, err := client.DeleteBackup(ctx, b.ID)
+		outcome.RequestID = reqID
+		if err != nil {
+			outcome.Outcome = OutcomeFailed
+			outcome.Error = err.Error()
+			metrics.BackupsExpiryFailed.WithLabelValues(meta.PolicyType).Inc()
+			backupLog.Error("Failed to delete backup", "error", err, "request_id", reqID, "expires_at", meta.ExpiryDate)
+			sendNotification(ctx, notifyProvider, notifications.BackupFailure{
+				Service:    "snapsentry",
+				Operation:  notifications.BackupOperationExpiry,
+				VolumeID:   b.VolumeID,
+				SnapshotID: meta.SourceSnapshotID,
+				BackupID:   b.ID,
+				PolicyType: meta.PolicyType,
+				ExpiryDate: meta.ExpiryDate,
+				Message:    fmt.Sprintf("Failed to delete backup due to %s", err),
+			}, backupLog)
+		} else {
+			metrics.BackupsExpired.WithLabelValues(meta.PolicyType).Inc()
+			backupLog.Info("Backup deleted successfully", "request_id", reqID, "expires_at", meta.ExpiryDate, "reason", reason)
+		}
+		report.addBackup(outcome)
+	}
+
+	return nil
+}
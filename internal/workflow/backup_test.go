@@ -0,0 +1,88 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/policy"
+	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/backups"
+)
+
+// managedBackup builds a managed backup of a snapshot with the given status.
+func managedBackup(id, sourceSnapshotID, status string) backups.Backup {
+	metadata := policy.BackupMetadata{Managed: true, PolicyType: "daily", SourceSnapshotID: sourceSnapshotID}.ToOpenstackMetadata()
+	return backups.Backup{ID: id, Status: status, Metadata: &metadata}
+}
+
+func TestSelectBackupExport(t *testing.T) {
+	tests := []struct {
+		name            string
+		existing        []backups.Backup
+		incremental     bool
+		wantBlocking    string
+		wantReason      string
+		wantIncremental bool
+	}{
+		{name: "First Backup", incremental: true},
+		{
+			name:         "Already Exported",
+			existing:     []backups.Backup{managedBackup("b-1", "snap-1", "available")},
+			incremental:  true,
+			wantBlocking: "b-1",
+			wantReason:   "snapshot already exported (backup available)",
+		},
+		{
+			name:         "Export In Progress",
+			existing:     []backups.Backup{managedBackup("b-1", "snap-1", "creating")},
+			wantBlocking: "b-1",
+			wantReason:   "snapshot already exported (backup creating)",
+		},
+		{
+			name:            "Failed Export Is Retried",
+			existing:        []backups.Backup{managedBackup("b-1", "snap-1", "error"), managedBackup("b-0", "snap-0", "available")},
+			incremental:     true,
+			wantIncremental: true,
+		},
+		{
+			name:         "Exported Wins Over Another In Progress",
+			existing:     []backups.Backup{managedBackup("b-2", "snap-2", "creating"), managedBackup("b-1", "snap-1", "available")},
+			wantBlocking: "b-1",
+			wantReason:   "snapshot already exported (backup available)",
+		},
+		{
+			name:         "Another Backup In Progress",
+			existing:     []backups.Backup{managedBackup("b-0", "snap-0", "available"), managedBackup("b-2", "snap-2", "creating")},
+			incremental:  true,
+			wantBlocking: "b-2",
+			wantReason:   "backup b-2 of the volume is still in progress",
+		},
+		{
+			name:            "Incremental On An Available Backup",
+			existing:        []backups.Backup{managedBackup("b-0", "snap-0", "available")},
+			incremental:     true,
+			wantIncremental: true,
+		},
+		{
+			name:     "Full When Incremental Is Disabled",
+			existing: []backups.Backup{managedBackup("b-0", "snap-0", "available")},
+		},
+		{
+			name:        "Full Without An Available Backup",
+			existing:    []backups.Backup{managedBackup("b-0", "snap-0", "error")},
+			incremental: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocking, reason, incremental := selectBackupExport("snap-1", tt.existing, tt.incremental)
+			blockingID := ""
+			if blocking != nil {
+				blockingID = blocking.ID
+			}
+			if blockingID != tt.wantBlocking || reason != tt.wantReason || incremental != tt.wantIncremental {
+				t.Errorf("selectBackupExport() = %q, %q, %v, want %q, %q, %v",
+					blockingID, reason, incremental, tt.wantBlocking, tt.wantReason, tt.wantIncremental)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/cloud/openstack"
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/policy"
+	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/volumes"
+)
+
+// ValidateDefaultsFile reads and validates a defaults file without contacting the cloud.
+func ValidateDefaultsFile(path string) error {
+	_, err := loadDefaultsFile(path)
+	return err
+}
+
+// loadDefaultsFile reads and validates a defaults file.
+func loadDefaultsFile(path string) (policy.Defaults, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return policy.Defaults{}, fmt.Errorf("failed to read defaults file: %w", err)
+	}
+	return policy.ParseDefaults(data)
+}
+
+// discoverVolumes returns the volumes the snapshot workflow processes.
+//
+// Without defaults, these are the subscribed volumes. With defaults, every volume of the project is listed:
+// volumes without a policy of their own that match a defaults entry are returned with their effective metadata
+// (see policy.Defaults.EffectiveMetadata), next to the subscribed volumes. Nothing is written to the volumes.
+func discoverVolumes(ctx context.Context, client *openstack.Client, defaults *policy.Defaults, logger *slog.Logger) ([]volumes.Volume, error) {
+	if defaults == nil {
+		logger.Debug("Querying for subscribed volumes", "tag", policy.ManagedTag)
+		return client.ListSubscribedVolumes(ctx)
+	}
+
+	project := policy.ProjectInfo{ID: client.ProjectID}
+	if defaults.NeedsProject() {
+		if client.ProjectID == "" {
+			return nil, fmt.Errorf("defaults are limited to projects, but the token is not scoped to a project")
+		}
+		p, err := client.GetProject(ctx, client.ProjectID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch project for the defaults: %w", err)
+		}
+		project = policy.ProjectInfo{ID: p.ID, Name: p.Name, Tags: p.Tags}
+	}
+
+	logger.Debug("Querying for all volumes to apply the defaults", "project_id", project.ID)
+	all, err := client.ListVolumes(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var discovered []volumes.Volume
+	inherited := 0
+	for _, vol := range all {
+		effective, entry, err := defaults.EffectiveMetadata(project, vol.ID, vol.Name, vol.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		if entry == "" {
+			if vol.Metadata[policy.ManagedTag] == "true" {
+				discovered = append(discovered, vol)
+			}
+			continue
+		}
+
+		logger.Debug("Volume inherits default policies", "volume_id", vol.ID, "defaults_entry", entry)
+		vol.Metadata = effective
+		discovered = append(discovered, vol)
+		inherited++
+	}
+
+	logger.Info("Default policies resolved", "inherited_count", inherited, "project_tags", project.Tags)
+	return discovered, nil
+}
@@ -0,0 +1,105 @@
+package workflow
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/notifications"
+)
+
+// DigestCollector aggregates the run reports of a daemon into a periodic digest
+// (e.g. a morning "your 14 volumes were backed up" message).
+//
+// Volumes are counted once per period, no matter how many runs touched them.
+// Dry-run reports are ignored. It is safe for concurrent use, since the create and expiry jobs run independently.
+type DigestCollector struct {
+	mu         sync.Mutex
+	digest     notifications.DailyDigest
+	backedUp   map[string]struct{}
+	failed     map[string]struct{}
+	subscribed int
+}
+
+// NewDigestCollector starts collecting a digest period at 'since'.
+func NewDigestCollector(since time.Time) *DigestCollector {
+	c := &DigestCollector{}
+	c.reset(since)
+	return c
+}
+
+func (c *DigestCollector) reset(since time.Time) {
+	c.digest = notifications.DailyDigest{Service: "snapsentry", PeriodStart: since}
+	c.backedUp = map[string]struct{}{}
+	c.failed = map[string]struct{}{}
+}
+
+// Add records a finished run report.
+func (c *DigestCollector) Add(report *RunReport) {
+	if report == nil || report.DryRun {
+		return
+	}
+
+	report.mu.Lock()
+	defer report.mu.Unlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.digest.Runs++
+	if report.Error != "" {
+		c.digest.FailedRuns++
+	}
+
+	if report.Workflow == "create-snapshots" && report.Error == "" {
+		c.subscribed = report.Summary.VolumesProcessed
+	}
+
+	for _, s := range report.Snapshots {
+		switch s.Outcome {
+		case OutcomeCreated:
+			c.digest.SnapshotsCreated++
+			c.backedUp[s.VolumeID] = struct{}{}
+		case OutcomePromoted:
+			c.digest.SnapshotsPromoted++
+			c.backedUp[s.VolumeID] = struct{}{}
+		case OutcomeFailed:
+			c.digest.SnapshotsFailed++
+			c.failed[s.VolumeID] = struct{}{}
+		}
+	}
+
+	for _, d := range report.Deletions {
+		switch d.Outcome {
+		case OutcomeDeleted:
+			c.digest.SnapshotsExpired++
+		case OutcomeFailed:
+			c.digest.ExpiryFailures++
+		}
+	}
+}
+
+// Flush returns the digest of the period ending at 'now' and starts a new period.
+func (c *DigestCollector) Flush(now time.Time) notifications.DailyDigest {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	digest := c.digest
+	digest.PeriodEnd = now
+	digest.SubscribedVolumes = c.subscribed
+	digest.VolumesBackedUp = len(c.backedUp)
+	digest.VolumesFailed = len(c.failed)
+
+	c.reset(now)
+	return digest
+}
+
+// SendDigest flushes the collector and sends the digest to the notifiers subscribed to it.
+func SendDigest(c *DigestCollector, notifyProvider notifications.Notifier, now time.Time, logger *slog.Logger) {
+	digest := c.Flush(now)
+	logger.Info("Daily digest completed",
+		"runs", digest.Runs,
+		"volumes_backed_up", digest.VolumesBackedUp,
+		"volumes_failed", digest.VolumesFailed)
+	sendNotification(context.Background(), notifyProvider, digest, logger)
+}
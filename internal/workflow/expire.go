@@ -4,14 +4,18 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"maps"
+	"slices"
 	"time"
 
 	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/cloud"
 	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/cloud/openstack"
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/metrics"
 	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/notifications"
 	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/policy"
 	"github.com/google/uuid"
 	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/snapshots"
+	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/volumes"
 )
 
 // RunProjectSnapshotExpiryWorkflow executes the retention enforcement process for a tenant.
@@ -20,18 +24,53 @@ import (
 //  1. Discovery: Retrieves *all* snapshots in the project that bear the SnapSentry management tag.
 //     This is a "Sweep" operation, independent of the source volumes (which might have been deleted).
 //  2. Evaluation: Checks the `ExpiryDate` metadata on each snapshot against the current reference time.
+//     Snapshots under count retention are instead grouped per volume and policy type, and everything
+//     beyond the last N snapshots of each series is selected, even if its expiry date is in the future.
+//     The last N are kept for as long as the volume has count retention for the policy type, however long
+//     the series is not growing; once it no longer has (policy switched, disabled or volume deleted), the
+//     snapshots expire by date like time based ones. With opts.DefaultsFile, count retention inherited from
+//     the project defaults counts as well.
 //  3. cleanup: Permanently deletes snapshots that have exceeded their retention period.
+//     Members of a group snapshot are deleted through their group snapshot, once all of them are due.
+//     With opts.DryRun nothing is deleted; the selected snapshots are rendered as a plan instead.
+//     Managed Cinder backups (see policy.BackupConfig) past their expiry date, and failed exports, are deleted too.
+//     Snapshots stuck in "error", "error_deleting" or "creating" for longer than opts.StuckSnapshotAge are
+//     deleted as well (force delete if needed) and reported through a SnapshotStuck notification. A stuck member
+//     of a group snapshot is deleted with its whole group snapshot.
+//  4. Reporting: Every selected snapshot and its deletion outcome is collected in the returned RunReport.
 //
 // Parameters:
 //   - now: The reference time for expiry (usually time.Now(), but injected for deterministic testing. UTC).
-func RunProjectSnapshotExpiryWorkflow(cloudName string, timeoutSeconds int, logLevel string, now time.Time, notifyProvider notifications.Webhook) error {
+func RunProjectSnapshotExpiryWorkflow(cloudName string, timeoutSeconds int, logLevel string, now time.Time, notifyProvider notifications.Notifier, opts RunOptions) (*RunReport, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
 	// 1. Setup Logger & Context
 	logger := SetupLogger(logLevel, cloudName).With("workflow", "expiry", "validation_time", now)
 	snapsentryRunID := fmt.Sprintf("req-%s", uuid.New().String())
 	logger = logger.With("snapsentry_id", snapsentryRunID)
 
+	report := NewRunReport(snapsentryRunID, "expire-snapshots", opts.DryRun)
+	if opts.DryRun {
+		logger = logger.With("dry_run", true)
+		// A plan has no side effects, notifications included.
+		notifyProvider = nil
+	}
+
 	logger.Info("Initializing snapshot lifecycle workflow - expiry")
 
+	// Volumes that inherit count retention from the defaults file keep their last N snapshots as well.
+	var defaults *policy.Defaults
+	if opts.DefaultsFile != "" {
+		d, err := loadDefaultsFile(opts.DefaultsFile)
+		if err != nil {
+			logger.Error("Invalid defaults file", "file", opts.DefaultsFile, "error", err)
+			return completeRun(report, opts, notifyProvider, logger, err)
+		}
+		defaults = &d
+	}
+
 	ctx := context.Background()
 	if timeoutSeconds > 0 {
 		var cancel context.CancelFunc
@@ -48,43 +87,206 @@ func RunProjectSnapshotExpiryWorkflow(cloudName string, timeoutSeconds int, logL
 			MaxDelay:         10 * time.Second,
 			OperationTimeout: 30 * time.Second,
 		},
+		RateLimiter: openstack.NewRateLimiter(opts.RateLimit),
 	}
 
 	if err := ostk.NewClient(); err != nil {
 		logger.Error("OpenStack client initialization failed", "error", err)
-		return fmt.Errorf("client init failed: %w", err)
+		return completeRun(report, opts, notifyProvider, logger, fmt.Errorf("client init failed: %w", err))
 	}
 	logger.Info("OpenStack connection established")
 
 	// 3. List Managed Snapshots
-	managedSnapshots, err := ostk.ListManagedSnapshots(ctx)
+	// The snapshots are listed once into an index, which is kept current as snapshots are deleted.
+	index, err := ostk.BuildSnapshotIndex(ctx)
 	if err != nil {
 		logger.Error("Failed to fetch managed snapshots", "error", err)
-		return err
+		return completeRun(report, opts, notifyProvider, logger, err)
 	}
+	ostk.SnapshotIndex = index
+	managedSnapshots := index.All()
 	logger.Info("Found managed snapshots", "count", len(managedSnapshots))
+	report.Summary.SnapshotsFound = len(managedSnapshots)
 
-	if len(managedSnapshots) == 0 {
-		return nil
+	// 4. Clean Up Stuck Snapshots
+	// Only available snapshots are evaluated for expiry. Failed or unfinished snapshots are deleted once they
+	// are older than the stuck snapshot age; younger ones may still complete and are left alone.
+	// Cinder only deletes members of a group snapshot with their group snapshot, so a stuck member takes the
+	// whole group snapshot with it, available members included.
+	var availableSnapshots []snapshots.Snapshot
+	stuckGroups := map[string][]snapshots.Snapshot{}
+	for _, snap := range managedSnapshots {
+		switch {
+		case snap.Status == policy.SnapshotStatusAvailable:
+			availableSnapshots = append(availableSnapshots, snap)
+		case policy.IsStuckSnapshot(snap.Status, snap.CreatedAt, now, opts.StuckSnapshotAge):
+			if groupSnapshotID := memberGroupSnapshotID(snap); groupSnapshotID != "" {
+				stuckGroups[groupSnapshotID] = append(stuckGroups[groupSnapshotID], snap)
+				continue
+			}
+			processStuckSnapshot(ctx, ostk, snap, notifyProvider, report, logger)
+		default:
+			logger.Debug("Skipping snapshot: not available", "snapshot_id", snap.ID, "status", snap.Status)
+		}
+	}
+	if len(stuckGroups) > 0 {
+		availableSnapshots = slices.DeleteFunc(availableSnapshots, func(snap snapshots.Snapshot) bool {
+			groupSnapshotID := memberGroupSnapshotID(snap)
+			if _, ok := stuckGroups[groupSnapshotID]; ok {
+				stuckGroups[groupSnapshotID] = append(stuckGroups[groupSnapshotID], snap)
+				return true
+			}
+			return false
+		})
+		for _, groupSnapshotID := range slices.Sorted(maps.Keys(stuckGroups)) {
+			processStuckGroupSnapshot(ctx, ostk, groupSnapshotID, stuckGroups[groupSnapshotID], notifyProvider, report, logger)
+		}
 	}
 
-	// 4. Process Snapshots Sequentially
-	for _, snap := range managedSnapshots {
+	// 5. Select Count Based Candidates
+	// This needs the full snapshot list up front since a series can only be ranked as a whole, and the current
+	// volume policies (defaults included) since only series the volumes still keep count retention for are ranked.
+	subscribedVolumes, err := discoverVolumes(ctx, &ostk, defaults, logger)
+	if err != nil {
+		logger.Error("Failed to fetch subscribed volumes", "error", err)
+		return completeRun(report, opts, notifyProvider, logger, err)
+	}
+	countRetained := selectCountRetention(availableSnapshots, activeCountSeries(subscribedVolumes))
+	logger.Info("Count based retention evaluated", "retained_count", countRetained.count(countKept), "excess_count", countRetained.count(countExcess))
+
+	// 6. Process Snapshots Sequentially
+	// Members of a group snapshot cannot be deleted on their own; they are handled per group snapshot below.
+	groupMembers := map[string][]snapshots.Snapshot{}
+	for _, snap := range availableSnapshots {
 		// Stop if global timeout is reached
 		if ctx.Err() != nil {
 			logger.Warn("Workflow timed out, stopping early")
-			return ctx.Err()
+			return completeRun(report, opts, notifyProvider, logger, ctx.Err())
 		}
 
-		processSnapshotExpiry(ctx, ostk, snap, now, notifyProvider, logger)
+		if groupSnapshotID := memberGroupSnapshotID(snap); groupSnapshotID != "" {
+			groupMembers[groupSnapshotID] = append(groupMembers[groupSnapshotID], snap)
+			continue
+		}
+
+		processSnapshotExpiry(ctx, ostk, snap, now, countRetained[snap.ID], notifyProvider, report, logger)
+	}
+
+	// 7. Process Group Snapshots
+	for _, groupSnapshotID := range slices.Sorted(maps.Keys(groupMembers)) {
+		if ctx.Err() != nil {
+			logger.Warn("Workflow timed out, stopping early")
+			return completeRun(report, opts, notifyProvider, logger, ctx.Err())
+		}
+
+		processGroupSnapshotExpiry(ctx, ostk, groupSnapshotID, groupMembers[groupSnapshotID], now, countRetained, notifyProvider, report, logger)
+	}
+
+	// 8. Process Backups
+	// Exported backups live independently of their source snapshot, so they are swept on their own.
+	if err := processBackupExpiry(ctx, &ostk, now, notifyProvider, report, logger); err != nil {
+		return completeRun(report, opts, notifyProvider, logger, err)
 	}
 
 	logger.Info("Expiry workflow completed")
-	return nil
+	return completeRun(report, opts, notifyProvider, logger, nil)
+}
+
+// countSeries identifies a count based retention series: the snapshots of one volume and policy type.
+type countSeries struct {
+	volumeID   string
+	policyType string
+}
+
+// countRetention is the count based retention decision for a single snapshot.
+type countRetention int
+
+const (
+	countNotRetained countRetention = iota // Not part of a count retained series; expires by date.
+	countKept                              // One of the last N snapshots of its series.
+	countExcess                            // Beyond the last N snapshots of its series.
+)
+
+// countDecisions maps snapshot IDs to their count based retention decision.
+// Snapshots without an entry are countNotRetained.
+type countDecisions map[string]countRetention
+
+// count returns the number of snapshots with the given decision.
+func (d countDecisions) count(decision countRetention) int {
+	n := 0
+	for _, v := range d {
+		if v == decision {
+			n++
+		}
+	}
+	return n
+}
+
+// activeCountSeries returns the series that the volumes currently keep count based retention for,
+// i.e. every enabled policy with retention type "count" (see policy.HasCountRetention).
+func activeCountSeries(vols []volumes.Volume) map[countSeries]bool {
+	active := make(map[countSeries]bool)
+	for _, vol := range vols {
+		for _, policyType := range policy.SnapshotPolicyTypes {
+			if policy.HasCountRetention(vol.Metadata, policyType) {
+				active[countSeries{volumeID: vol.ID, policyType: policyType}] = true
+			}
+		}
+	}
+	return active
 }
 
-// processSnapshotExpiry handles the logic for a single snapshot
-func processSnapshotExpiry(ctx context.Context, client openstack.Client, snap snapshots.Snapshot, now time.Time, notifyProvider notifications.Webhook, logger *slog.Logger) {
+// selectCountRetention groups count based snapshots by volume and policy type and ranks every series that
+// is still active: the last N snapshots are kept, everything beyond is excess. Snapshots of a series that is
+// no longer active are left out, so they expire by date.
+//
+// The retention count is taken from the newest snapshot of a series, so lowering the count on the
+// volume policy takes effect as soon as the next snapshot has been created.
+func selectCountRetention(snaps []snapshots.Snapshot, active map[countSeries]bool) countDecisions {
+	series := make(map[countSeries][]policy.LastSnapshotInfo)
+	keep := make(map[countSeries]int)
+	newest := make(map[countSeries]time.Time)
+
+	for _, snap := range snaps {
+		meta := policy.SnapshotMetadata{}
+		if err := meta.ParseFromMetadata(snap.Metadata); err != nil || !meta.IsCountRetention() {
+			continue
+		}
+
+		key := countSeries{volumeID: snap.VolumeID, policyType: meta.PolicyType}
+		if !active[key] {
+			continue
+		}
+		series[key] = append(series[key], policy.LastSnapshotInfo{
+			ID:        snap.ID,
+			CreatedAt: snap.CreatedAt,
+			Status:    snap.Status,
+			Metadata:  snap.Metadata,
+		})
+
+		if snap.CreatedAt.After(newest[key]) || newest[key].IsZero() {
+			newest[key] = snap.CreatedAt
+			keep[key] = meta.RetentionCount
+		}
+	}
+
+	decisions := make(countDecisions)
+	for key, s := range series {
+		for _, snap := range s {
+			decisions[snap.ID] = countKept
+		}
+		for _, excess := range policy.SelectExcessSnapshots(s, keep[key]) {
+			decisions[excess.ID] = countExcess
+		}
+	}
+
+	return decisions
+}
+
+// processSnapshotExpiry handles the logic for a single snapshot.
+// count is the count based retention decision of the snapshot (see selectCountRetention).
+// Selected snapshots are recorded in the report; during a dry-run they are not deleted.
+func processSnapshotExpiry(ctx context.Context, client openstack.Client, snap snapshots.Snapshot, now time.Time, count countRetention, notifyProvider notifications.Notifier, report *RunReport, logger *slog.Logger) {
 	snapLog := logger.With("snapshot_id", snap.ID, "volume_id", snap.VolumeID)
 
 	// A. Parse Metadata
@@ -95,31 +297,298 @@ func processSnapshotExpiry(ctx context.Context, client openstack.Client, snap sn
 	}
 
 	// B. Check Logic
-	if now.Before(meta.ExpiryDate) {
-		snapLog.Debug("Snapshot is in active retention peroid", "expires_at", meta.ExpiryDate)
-		return // Not expired yet
+	reason, expired := expiryReason(*meta, now, count, snapLog)
+	if !expired {
+		return
 	}
 
 	// C. Execute Deletion
-	snapLog.Info("Snapshot has expired", "expires_at", meta.ExpiryDate)
+	outcome := deletionOutcome(snap, *meta, reason)
+	defer func() { report.addDeletion(outcome) }()
+
+	if report.DryRun {
+		outcome.Outcome = OutcomeWouldDelete
+		snapLog.Info("Dry-run: snapshot would be deleted", "expires_at", meta.ExpiryDate, "reason", reason)
+		return
+	}
 
 	reqID, err := client.DeleteSnapshot(ctx, snap.ID)
+	outcome.RequestID = reqID
 	if err != nil {
+		outcome.Outcome = OutcomeFailed
+		outcome.Error = err.Error()
+		metrics.SnapshotsExpiryFailed.WithLabelValues(meta.PolicyType).Inc()
 		snapLog.Error("Failed to delete snapshot", "error", err, "request_id", reqID, "expires_at", meta.ExpiryDate)
-		if notifyProvider.URL != "" {
-			metadata := *meta
-			snapDelFailNotify := notifications.SnapshotExpiryFailure{
-				Service:          "snapsentry",
-				SnapshotID:       snap.ID,
-				VolumeID:         snap.VolumeID,
-				SnapshotMetadata: metadata,
-				Message:          fmt.Sprintf("Failed to delete snapshot due to %s", err),
-			}
-			notifyProvider.Notify(snapDelFailNotify)
+		snapDelFailNotify := notifications.SnapshotExpiryFailure{
+			Service:          "snapsentry",
+			SnapshotID:       snap.ID,
+			VolumeID:         snap.VolumeID,
+			SnapshotMetadata: *meta,
+			Message:          fmt.Sprintf("Failed to delete snapshot due to %s", err),
 		}
+		sendNotification(ctx, notifyProvider, snapDelFailNotify, snapLog)
 		return
 	}
 
 	// D. Success
+	metrics.SnapshotsExpired.WithLabelValues(meta.PolicyType).Inc()
 	snapLog.Info("Snapshot deleted successfully", "request_id", reqID, "expires_at", meta.ExpiryDate)
+	sendNotification(ctx, notifyProvider, notifications.SnapshotExpired{
+		Service:          "snapsentry",
+		SnapshotID:       snap.ID,
+		VolumeID:         snap.VolumeID,
+		SnapshotMetadata: *meta,
+		Reason:           reason,
+	}, snapLog)
+}
+
+// processStuckSnapshot deletes a managed snapshot stuck in a failed or unfinished state. DeleteSnapshot falls
+// back to a force delete, which Cinder requires for snapshots that are still "creating".
+// The snapshot is recorded in the report and reported through a SnapshotStuck notification;
+// during a dry-run it is not deleted.
+func processStuckSnapshot(ctx context.Context, client openstack.Client, snap snapshots.Snapshot, notifyProvider notifications.Notifier, report *RunReport, logger *slog.Logger) {
+	snapLog := logger.With("snapshot_id", snap.ID, "volume_id", snap.VolumeID, "status", snap.Status, "created_at", snap.CreatedAt)
+
+	meta := policy.SnapshotMetadata{}
+	_ = meta.ParseFromMetadata(snap.Metadata)
+
+	outcome := deletionOutcome(snap, meta, fmt.Sprintf("stuck in %s since %s", snap.Status, snap.CreatedAt.UTC().Format(time.RFC3339)))
+	defer func() { report.addDeletion(outcome) }()
+
+	if report.DryRun {
+		outcome.Outcome = OutcomeWouldDelete
+		snapLog.Info("Dry-run: stuck snapshot would be deleted")
+		return
+	}
+
+	event := notifications.SnapshotStuck{
+		Service:    "snapsentry",
+		SnapshotID: snap.ID,
+		VolumeID:   snap.VolumeID,
+		PolicyType: meta.PolicyType,
+		Status:     snap.Status,
+		CreatedAt:  snap.CreatedAt,
+	}
+
+	reqID, err := client.DeleteSnapshot(ctx, snap.ID)
+	outcome.RequestID = reqID
+	event.RequestID = reqID
+	if err != nil {
+		outcome.Outcome = OutcomeFailed
+		outcome.Error = err.Error()
+		metrics.StuckSnapshotCleanups.WithLabelValues(snap.Status, "failed").Inc()
+		snapLog.Error("Failed to delete stuck snapshot", "error", err, "request_id", reqID)
+		event.Message = fmt.Sprintf("Failed to delete stuck snapshot due to %s", err)
+		sendNotification(ctx, notifyProvider, event, snapLog)
+		return
+	}
+
+	metrics.StuckSnapshotCleanups.WithLabelValues(snap.Status, "cleaned").Inc()
+	snapLog.Warn("Stuck snapshot deleted", "request_id", reqID)
+	event.Deleted = true
+	event.Message = "Snapshot was deleted by the stuck snapshot cleanup"
+	sendNotification(ctx, notifyProvider, event, snapLog)
+}
+
+// processStuckGroupSnapshot deletes a group snapshot with stuck members through cleanupGroupSnapshot, once for
+// the whole group snapshot. members are its stuck members and its available ones, which go with it.
+// Every member is recorded in the report and every stuck member is reported through a SnapshotStuck notification;
+// during a dry-run nothing is deleted.
+func processStuckGroupSnapshot(
+	ctx context.Context,
+	client openstack.Client,
+	groupSnapshotID string,
+	members []snapshots.Snapshot,
+	notifyProvider notifications.Notifier,
+	report *RunReport,
+	logger *slog.Logger,
+) {
+	groupLog := logger.With("group_snapshot_id", groupSnapshotID)
+
+	outcomes := make([]DeletionOutcome, 0, len(members))
+	for _, snap := range members {
+		meta := policy.SnapshotMetadata{}
+		_ = meta.ParseFromMetadata(snap.Metadata)
+
+		reason := fmt.Sprintf("stuck in %s since %s", snap.Status, snap.CreatedAt.UTC().Format(time.RFC3339))
+		if snap.Status == policy.SnapshotStatusAvailable {
+			reason = fmt.Sprintf("member of group snapshot %s with stuck members", groupSnapshotID)
+		}
+		outcomes = append(outcomes, deletionOutcome(snap, meta, reason))
+	}
+
+	if report.DryRun {
+		for _, outcome := range outcomes {
+			outcome.Outcome = OutcomeWouldDelete
+			report.addDeletion(outcome)
+		}
+		groupLog.Info("Dry-run: group snapshot with stuck members would be deleted", "member_count", len(members))
+		return
+	}
+
+	reqID, err := cleanupGroupSnapshot(ctx, &client, groupSnapshotID, groupLog)
+	for i, snap := range members {
+		outcome := outcomes[i]
+		outcome.RequestID = reqID
+		if err != nil {
+			outcome.Outcome = OutcomeFailed
+			outcome.Error = err.Error()
+		}
+		report.addDeletion(outcome)
+
+		if snap.Status == policy.SnapshotStatusAvailable {
+			continue
+		}
+		snapLog := groupLog.With("snapshot_id", snap.ID, "volume_id", snap.VolumeID, "status", snap.Status, "created_at", snap.CreatedAt)
+		event := notifications.SnapshotStuck{
+			Service:    "snapsentry",
+			SnapshotID: snap.ID,
+			VolumeID:   snap.VolumeID,
+			PolicyType: outcome.PolicyType,
+			Status:     snap.Status,
+			CreatedAt:  snap.CreatedAt,
+			RequestID:  reqID,
+		}
+		if err != nil {
+			metrics.StuckSnapshotCleanups.WithLabelValues(snap.Status, "failed").Inc()
+			event.Message = fmt.Sprintf("Failed to delete the group snapshot %s of the stuck snapshot due to %s", groupSnapshotID, err)
+		} else {
+			metrics.StuckSnapshotCleanups.WithLabelValues(snap.Status, "cleaned").Inc()
+			event.Deleted = true
+			event.Message = fmt.Sprintf("Snapshot was deleted with its group snapshot %s by the stuck snapshot cleanup", groupSnapshotID)
+		}
+		sendNotification(ctx, notifyProvider, event, snapLog)
+	}
+}
+
+// memberGroupSnapshotID returns the group snapshot of a managed snapshot, or "" if it is not a group member.
+func memberGroupSnapshotID(snap snapshots.Snapshot) string {
+	meta := policy.SnapshotMetadata{}
+	if err := meta.ParseFromMetadata(snap.Metadata); err != nil {
+		return ""
+	}
+	return meta.GroupSnapshotID
+}
+
+// expiryReason decides whether a managed snapshot is due for deletion and why.
+// count is the count based retention decision of the snapshot (see selectCountRetention); snapshots outside
+// a count retained series expire by date.
+func expiryReason(meta policy.SnapshotMetadata, now time.Time, count countRetention, snapLog *slog.Logger) (string, bool) {
+	switch count {
+	case countKept:
+		snapLog.Debug("Snapshot is within the retained count", "retention_count", meta.RetentionCount, "policy_type", meta.PolicyType)
+		return "", false // Still one of the last N snapshots
+	case countExcess:
+		snapLog.Info("Snapshot exceeds the retention count", "retention_count", meta.RetentionCount, "policy_type", meta.PolicyType)
+		return fmt.Sprintf("exceeds retention count of %d", meta.RetentionCount), true
+	}
+
+	if meta.ExpiryDate.IsZero() {
+		snapLog.Warn("Skipping snapshot: time based retention without expiry date")
+		return "", false
+	}
+	if now.Before(meta.ExpiryDate) {
+		snapLog.Debug("Snapshot is in active retention peroid", "expires_at", meta.ExpiryDate)
+		return "", false // Not expired yet
+	}
+	snapLog.Info("Snapshot has expired", "expires_at", meta.ExpiryDate)
+	return "expired", true
+}
+
+// deletionOutcome builds the report entry for a snapshot selected for deletion.
+func deletionOutcome(snap snapshots.Snapshot, meta policy.SnapshotMetadata, reason string) DeletionOutcome {
+	return DeletionOutcome{
+		SnapshotID:      snap.ID,
+		SnapshotName:    snap.Name,
+		VolumeID:        snap.VolumeID,
+		PolicyType:      meta.PolicyType,
+		RetentionType:   meta.RetentionType,
+		RetentionCount:  meta.RetentionCount,
+		ExpiryDate:      meta.ExpiryDate,
+		GroupSnapshotID: meta.GroupSnapshotID,
+		Reason:          reason,
+		Outcome:         OutcomeDeleted,
+	}
+}
+
+// processGroupSnapshotExpiry handles the member snapshots of one group snapshot.
+//
+// Cinder only deletes members together with their group snapshot, so the group snapshot is deleted once
+// every managed member is due; until then all members are kept. Each member is recorded in the report.
+func processGroupSnapshotExpiry(
+	ctx context.Context,
+	client openstack.Client,
+	groupSnapshotID string,
+	members []snapshots.Snapshot,
+	now time.Time,
+	countRetained countDecisions,
+	notifyProvider notifications.Notifier,
+	report *RunReport,
+	logger *slog.Logger,
+) {
+	groupLog := logger.With("group_snapshot_id", groupSnapshotID)
+
+	// A. Every member must be due
+	outcomes := make([]DeletionOutcome, 0, len(members))
+	metas := make([]policy.SnapshotMetadata, 0, len(members))
+	for _, snap := range members {
+		snapLog := groupLog.With("snapshot_id", snap.ID, "volume_id", snap.VolumeID)
+
+		meta, err := policy.ParseSnapSentryMetadataFromSDK[policy.SnapshotMetadata](snap.Metadata)
+		if err != nil {
+			snapLog.Warn("Skipping group snapshot: invalid member metadata", "error", err)
+			return
+		}
+
+		reason, expired := expiryReason(*meta, now, countRetained[snap.ID], snapLog)
+		if !expired {
+			groupLog.Debug("Group snapshot is kept until every member is due", "member_count", len(members))
+			return
+		}
+		outcomes = append(outcomes, deletionOutcome(snap, *meta, reason))
+		metas = append(metas, *meta)
+	}
+
+	// B. Execute Deletion
+	if report.DryRun {
+		for _, outcome := range outcomes {
+			outcome.Outcome = OutcomeWouldDelete
+			report.addDeletion(outcome)
+		}
+		groupLog.Info("Dry-run: group snapshot would be deleted", "member_count", len(members))
+		return
+	}
+
+	reqID, err := client.DeleteGroupSnapshot(ctx, groupSnapshotID)
+	for i, outcome := range outcomes {
+		outcome.RequestID = reqID
+		snapLog := groupLog.With("snapshot_id", outcome.SnapshotID, "volume_id", outcome.VolumeID)
+
+		if err != nil {
+			outcome.Outcome = OutcomeFailed
+			outcome.Error = err.Error()
+			report.addDeletion(outcome)
+			metrics.SnapshotsExpiryFailed.WithLabelValues(outcome.PolicyType).Inc()
+			snapLog.Error("Failed to delete group snapshot", "error", err, "request_id", reqID, "expires_at", outcome.ExpiryDate)
+			sendNotification(ctx, notifyProvider, notifications.SnapshotExpiryFailure{
+				Service:          "snapsentry",
+				SnapshotID:       outcome.SnapshotID,
+				VolumeID:         outcome.VolumeID,
+				SnapshotMetadata: metas[i],
+				Message:          fmt.Sprintf("Failed to delete group snapshot %s due to %s", groupSnapshotID, err),
+			}, snapLog)
+			continue
+		}
+
+		report.addDeletion(outcome)
+		metrics.SnapshotsExpired.WithLabelValues(outcome.PolicyType).Inc()
+		snapLog.Info("Snapshot deleted successfully with its group snapshot", "request_id", reqID, "expires_at", outcome.ExpiryDate)
+		sendNotification(ctx, notifyProvider, notifications.SnapshotExpired{
+			Service:          "snapsentry",
+			SnapshotID:       outcome.SnapshotID,
+			VolumeID:         outcome.VolumeID,
+			SnapshotMetadata: metas[i],
+			Reason:           outcome.Reason,
+		}, snapLog)
+	}
 }
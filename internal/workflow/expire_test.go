@@ -0,0 +1,312 @@
+package workflow
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/cloud/openstack"
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/policy"
+	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/snapshots"
+	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/volumes"
+)
+
+var testLogger = slog.New(slog.DiscardHandler)
+
+// countSnapshot builds a managed snapshot of a count (count > 0) or time (count == 0) retention series.
+// Its expiry date is two days after its creation.
+func countSnapshot(id, volumeID, policyType string, createdAt time.Time, count int) snapshots.Snapshot {
+	meta := policy.SnapshotMetadata{
+		Managed:       true,
+		ExpiryDate:    createdAt.AddDate(0, 0, 2),
+		PolicyType:    policyType,
+		RetentionDays: 2,
+		RetentionType: policy.RetentionTypeTime,
+	}
+	if count > 0 {
+		meta.RetentionType = policy.RetentionTypeCount
+		meta.RetentionCount = count
+	}
+	return snapshots.Snapshot{ID: id, VolumeID: volumeID, CreatedAt: createdAt, Metadata: meta.ToOpenstackMetadata()}
+}
+
+func TestActiveCountSeries(t *testing.T) {
+	vols := []volumes.Volume{
+		{ID: "vol-1", Metadata: map[string]string{
+			"x-snapsentry-daily-enabled":          "true",
+			"x-snapsentry-daily-retention-type":   "count",
+			"x-snapsentry-daily-retention-count":  "7",
+			"x-snapsentry-weekly-enabled":         "true",
+			"x-snapsentry-weekly-retention-type":  "time",
+			"x-snapsentry-weekly-retention-count": "4",
+		}},
+		{ID: "vol-2", Metadata: map[string]string{
+			"x-snapsentry-express-enabled":         "false",
+			"x-snapsentry-express-retention-type":  "count",
+			"x-snapsentry-express-retention-count": "3",
+		}},
+	}
+
+	want := map[countSeries]bool{{volumeID: "vol-1", policyType: "daily"}: true}
+	if got := activeCountSeries(vols); !maps.Equal(got, want) {
+		t.Errorf("activeCountSeries() = %v, want %v", got, want)
+	}
+}
+
+func TestSelectCountRetention(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 10, d, 2, 0, 0, 0, time.UTC) }
+	daily := map[countSeries]bool{{volumeID: "vol-1", policyType: "daily"}: true}
+
+	tests := []struct {
+		name   string
+		snaps  []snapshots.Snapshot
+		active map[countSeries]bool
+		want   countDecisions
+	}{
+		{
+			name: "Keeps Last N Per Series",
+			snaps: []snapshots.Snapshot{
+				countSnapshot("d-1", "vol-1", "daily", day(1), 2),
+				countSnapshot("d-3", "vol-1", "daily", day(3), 2),
+				countSnapshot("d-2", "vol-1", "daily", day(2), 2),
+				countSnapshot("w-1", "vol-1", "weekly", day(1), 2),
+				countSnapshot("o-1", "vol-2", "daily", day(1), 2),
+			},
+			active: map[countSeries]bool{
+				{volumeID: "vol-1", policyType: "daily"}:  true,
+				{volumeID: "vol-1", policyType: "weekly"}: true,
+				{volumeID: "vol-2", policyType: "daily"}:  true,
+			},
+			want: countDecisions{"d-1": countExcess, "d-2": countKept, "d-3": countKept, "w-1": countKept, "o-1": countKept},
+		},
+		{
+			name: "Count Of Newest Snapshot Applies",
+			snaps: []snapshots.Snapshot{
+				countSnapshot("d-1", "vol-1", "daily", day(1), 3),
+				countSnapshot("d-2", "vol-1", "daily", day(2), 3),
+				countSnapshot("d-3", "vol-1", "daily", day(3), 1),
+			},
+			active: daily,
+			want:   countDecisions{"d-1": countExcess, "d-2": countExcess, "d-3": countKept},
+		},
+		{
+			// Snapshot creation paused for weeks: the expiry dates passed long ago, but the volume still keeps
+			// count retention, so the last N stay.
+			name: "Stalled Series Is Kept",
+			snaps: []snapshots.Snapshot{
+				countSnapshot("d-1", "vol-1", "daily", day(1).AddDate(0, -1, 0), 2),
+				countSnapshot("d-2", "vol-1", "daily", day(2).AddDate(0, -1, 0), 2),
+			},
+			active: daily,
+			want:   countDecisions{"d-1": countKept, "d-2": countKept},
+		},
+		{
+			name: "Time Retention Is Ignored",
+			snaps: []snapshots.Snapshot{
+				countSnapshot("t-1", "vol-1", "daily", day(1), 0),
+				countSnapshot("t-2", "vol-1", "daily", day(2), 0),
+			},
+			active: daily,
+			want:   countDecisions{},
+		},
+		{
+			// After a switch to time retention (or disabling the policy, or deleting the volume), the volume no
+			// longer keeps the series, so its snapshots expire by date.
+			name: "Series No Longer Retained",
+			snaps: []snapshots.Snapshot{
+				countSnapshot("c-1", "vol-1", "daily", day(1), 2),
+				countSnapshot("c-2", "vol-1", "daily", day(2), 2),
+				countSnapshot("t-3", "vol-1", "daily", day(3), 0),
+			},
+			active: map[countSeries]bool{},
+			want:   countDecisions{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := selectCountRetention(tt.snaps, tt.active); !maps.Equal(got, tt.want) {
+				t.Errorf("selectCountRetention() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpiryReason(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	timeBased := func(expiry time.Time) policy.SnapshotMetadata {
+		return policy.SnapshotMetadata{Managed: true, PolicyType: "daily", RetentionType: policy.RetentionTypeTime, ExpiryDate: expiry}
+	}
+	countBased := func(expiry time.Time) policy.SnapshotMetadata {
+		return policy.SnapshotMetadata{Managed: true, PolicyType: "daily", RetentionType: policy.RetentionTypeCount, RetentionCount: 3, ExpiryDate: expiry}
+	}
+
+	tests := []struct {
+		name       string
+		meta       policy.SnapshotMetadata
+		count      countRetention
+		wantReason string
+		wantExpire bool
+	}{
+		{name: "Expired", meta: timeBased(now.Add(-time.Hour)), wantReason: "expired", wantExpire: true},
+		{name: "Expires Now", meta: timeBased(now), wantReason: "expired", wantExpire: true},
+		{name: "Not Expired", meta: timeBased(now.Add(time.Hour))},
+		{name: "Missing Expiry Date", meta: timeBased(time.Time{})},
+		{name: "Count Kept", meta: countBased(now.Add(time.Hour)), count: countKept},
+		{name: "Count Kept Past Expiry", meta: countBased(now.AddDate(0, -1, 0)), count: countKept},
+		{name: "Count Excess Before Expiry", meta: countBased(now.Add(time.Hour)), count: countExcess, wantReason: "exceeds retention count of 3", wantExpire: true},
+		{name: "Count No Longer Retained", meta: countBased(now.Add(-time.Hour)), wantReason: "expired", wantExpire: true},
+		{name: "Count No Longer Retained Before Expiry", meta: countBased(now.Add(time.Hour))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, expire := expiryReason(tt.meta, now, tt.count, testLogger)
+			if reason != tt.wantReason || expire != tt.wantExpire {
+				t.Errorf("expiryReason() = %q, %v, want %q, %v", reason, expire, tt.wantReason, tt.wantExpire)
+			}
+		})
+	}
+}
+
+// groupMember builds an available member of a time retention group snapshot, expiring two days after its creation.
+func groupMember(id, volumeID, groupSnapshotID string, createdAt time.Time) snapshots.Snapshot {
+	meta := policy.SnapshotMetadata{
+		Managed:         true,
+		ExpiryDate:      createdAt.AddDate(0, 0, 2),
+		PolicyType:      "daily",
+		RetentionDays:   2,
+		RetentionType:   policy.RetentionTypeTime,
+		GroupSnapshotID: groupSnapshotID,
+	}
+	return snapshots.Snapshot{ID: id, VolumeID: volumeID, CreatedAt: createdAt, Metadata: meta.ToOpenstackMetadata()}
+}
+
+// deletionSummary renders the deletions of a report as "<snapshot id> <outcome> (<reason>)".
+func deletionSummary(report *RunReport) []string {
+	summary := []string{}
+	for _, d := range report.Deletions {
+		summary = append(summary, d.SnapshotID+" "+d.Outcome+" ("+d.Reason+")")
+	}
+	return summary
+}
+
+func TestExpiryDryRun(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	expired := now.AddDate(0, 0, -10)
+	recent := now.Add(-time.Hour)
+
+	t.Run("Snapshots", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			snap  snapshots.Snapshot
+			count countRetention
+			want  []string
+		}{
+			{name: "Expired", snap: countSnapshot("s-1", "vol-1", "daily", expired, 0), want: []string{"s-1 would_delete (expired)"}},
+			{name: "Not Expired", snap: countSnapshot("s-1", "vol-1", "daily", recent, 0), want: []string{}},
+			{
+				name:  "Count Excess",
+				snap:  countSnapshot("s-1", "vol-1", "daily", recent, 2),
+				count: countExcess,
+				want:  []string{"s-1 would_delete (exceeds retention count of 2)"},
+			},
+			{name: "Count Kept Past Expiry", snap: countSnapshot("s-1", "vol-1", "daily", expired, 2), count: countKept, want: []string{}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				report := NewRunReport("req-test", "expire-snapshots", true)
+				processSnapshotExpiry(context.Background(), openstack.Client{}, tt.snap, now, tt.count, nil, report, testLogger)
+				if got := deletionSummary(report); !slices.Equal(got, tt.want) {
+					t.Errorf("deletions = %v, want %v", got, tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("Group Snapshots", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			members []snapshots.Snapshot
+			want    []string
+		}{
+			{
+				name:    "Every Member Due",
+				members: []snapshots.Snapshot{groupMember("m-1", "vol-1", "gs-1", expired), groupMember("m-2", "vol-2", "gs-1", expired)},
+				want:    []string{"m-1 would_delete (expired)", "m-2 would_delete (expired)"},
+			},
+			{
+				name:    "Member Not Due",
+				members: []snapshots.Snapshot{groupMember("m-1", "vol-1", "gs-1", expired), groupMember("m-2", "vol-2", "gs-1", recent)},
+				want:    []string{},
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				report := NewRunReport("req-test", "expire-snapshots", true)
+				processGroupSnapshotExpiry(context.Background(), openstack.Client{}, "gs-1", tt.members, now, countDecisions{}, nil, report, testLogger)
+				if got := deletionSummary(report); !slices.Equal(got, tt.want) {
+					t.Errorf("deletions = %v, want %v", got, tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("Stuck Group Snapshot", func(t *testing.T) {
+		stuck := groupMember("m-1", "vol-1", "gs-1", expired)
+		stuck.Status = "error"
+		available := groupMember("m-2", "vol-2", "gs-1", expired)
+		available.Status = policy.SnapshotStatusAvailable
+
+		report := NewRunReport("req-test", "expire-snapshots", true)
+		processStuckGroupSnapshot(context.Background(), openstack.Client{}, "gs-1", []snapshots.Snapshot{stuck, available}, nil, report, testLogger)
+		want := []string{
+			"m-1 would_delete (stuck in error since 2026-10-07T12:00:00Z)",
+			"m-2 would_delete (member of group snapshot gs-1 with stuck members)",
+		}
+		if got := deletionSummary(report); !slices.Equal(got, want) {
+			t.Errorf("deletions = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Stuck Snapshot", func(t *testing.T) {
+		snap := countSnapshot("s-1", "vol-1", "daily", expired, 0)
+		snap.Status = "error"
+
+		report := NewRunReport("req-test", "expire-snapshots", true)
+		processStuckSnapshot(context.Background(), openstack.Client{}, snap, nil, report, testLogger)
+		want := []string{"s-1 would_delete (stuck in error since 2026-10-07T12:00:00Z)"}
+		if got := deletionSummary(report); !slices.Equal(got, want) {
+			t.Errorf("deletions = %v, want %v", got, want)
+		}
+	})
+}
+
+// TestProcessStuckGroupSnapshot checks that stuck members are deleted with one request for their group snapshot,
+// since Cinder rejects deleting a group snapshot member on its own.
+func TestProcessStuckGroupSnapshot(t *testing.T) {
+	created := time.Date(2026, 10, 1, 2, 0, 0, 0, time.UTC)
+	var members []snapshots.Snapshot
+	for _, id := range []string{"m-1", "m-2"} {
+		snap := groupMember(id, "vol-"+id, "gs-1", created)
+		snap.Status = "error"
+		members = append(members, snap)
+	}
+
+	fake := &fakeCinder{}
+	report := NewRunReport("req-test", "expire-snapshots", false)
+	processStuckGroupSnapshot(context.Background(), *fake.client(t), "gs-1", members, nil, report, testLogger)
+
+	if want := []string{"DELETE /group_snapshots/gs-1 null"}; !slices.Equal(fake.requests, want) {
+		t.Errorf("requests = %v, want %v", fake.requests, want)
+	}
+	want := []string{"m-1 deleted (stuck in error since 2026-10-01T02:00:00Z)", "m-2 deleted (stuck in error since 2026-10-01T02:00:00Z)"}
+	if got := deletionSummary(report); !slices.Equal(got, want) {
+		t.Errorf("deletions = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,259 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/cloud/openstack"
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/notifications"
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/policy"
+	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/snapshots"
+	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/volumes"
+)
+
+// gfsTier couples a due GFS policy with its evaluation result.
+type gfsTier struct {
+	policy policy.SnapshotPolicy
+	result policy.PolicyEvalResult
+}
+
+// processVolumeGFS evaluates the Daily/Weekly/Monthly policies of a GFS enabled volume as one hierarchy.
+//
+// Workflow:
+//  1. History Check: Lists every managed snapshot of the volume once. The "last snapshot" of a tier is the
+//     newest snapshot that covers it (its own policy type or one recorded in GFSCovers).
+//  2. Evaluation: Each tier is evaluated against that snapshot. Tiers with an open, unsatisfied window are "due".
+//  3. Promotion: If a lower tier snapshot was already taken inside the window of the highest due tier,
+//     it is relabelled (policy type, expiry) instead of creating a duplicate.
+//  4. Creation: Otherwise a single snapshot is created, labelled with the highest due tier and covering
+//     every due tier (it is taken "now", which lies inside all of their windows).
+//
+// Outcomes are recorded in the report. During a dry-run promotions and creations are only recorded.
+func processVolumeGFS(
+	ctx context.Context,
+	client *openstack.Client,
+	vol volumes.Volume,
+	tiers []policy.SnapshotPolicy,
+	create snapshotCreator,
+	notifyProvider notifications.Notifier,
+	report *RunReport,
+	logger *slog.Logger,
+) error {
+	var execErrors error
+	gfsLogger := logger.With("retention_mode", "gfs")
+
+	// A. Fetch History (all tiers at once)
+	gfsLogger.Debug("Fetching snapshot history for GFS tiers")
+	history, err := client.ListManagedVolumeSnapshots(ctx, vol.ID, "", false)
+	if err != nil {
+		gfsLogger.Error("Snapshot history retrieval failed", "error", err)
+		for _, p := range tiers {
+			report.addSnapshot(failedOutcome(vol, p.GetPolicyType(), err))
+		}
+		return fmt.Errorf("gfs snapshot history retrieval failed. %w", err)
+	}
+
+	// B. Evaluate every tier
+	now := time.Now()
+	due := []gfsTier{}
+
+	for _, p := range tiers {
+		policyType := p.GetPolicyType()
+		policyLogger := gfsLogger.With("policy_type", policyType)
+
+		lastSnapshotInfo := latestCoveringSnapshot(history, policyType)
+		result, err := p.Evaluate(now, lastSnapshotInfo)
+		if err != nil {
+			policyLogger.Error("Policy evaluation failed", "error", err)
+			execErrors = errors.Join(execErrors, fmt.Errorf("%s policy evaluation failed. %w", policyType, err))
+			report.addSnapshot(failedOutcome(vol, policyType, err))
+			continue
+		}
+
+		if !result.ShouldSnapshot {
+			policyLogger.Info("Snapshot creation skipped",
+				"reason", result.Reason,
+				"window_start", result.Window.StartTime,
+				"window_end", result.Window.EndTime,
+			)
+			report.addSnapshot(snapshotOutcome(vol, policyType, OutcomeSkipped, result))
+			continue
+		}
+
+		due = append(due, gfsTier{policy: p, result: result})
+	}
+
+	// Highest tier first.
+	slices.SortFunc(due, func(a, b gfsTier) int {
+		return policy.GFSRank(b.policy.GetPolicyType()) - policy.GFSRank(a.policy.GetPolicyType())
+	})
+
+	// C. Promote or Create
+	for len(due) > 0 {
+		top := due[0]
+		topType := top.policy.GetPolicyType()
+		policyLogger := gfsLogger.With("policy_type", topType)
+
+		candidate := findPromotionCandidate(history, top)
+
+		if candidate == nil {
+			covered := []string{}
+			for _, t := range due {
+				covered = append(covered, t.policy.GetPolicyType())
+			}
+			top.result.Metadata.GFSCovers = policy.JoinCoveredPolicies(covered...)
+
+			if report.Blackout != "" {
+				report.addSnapshot(deferredOutcome(vol, topType, top.result, report.Blackout))
+				policyLogger.Info("Snapshot creation deferred by blackout window",
+					"blackout", report.Blackout,
+					"gfs_covers", top.result.Metadata.GFSCovers)
+				break
+			}
+
+			if report.DryRun {
+				report.addSnapshot(snapshotOutcome(vol, topType, OutcomeWouldCreate, top.result))
+				policyLogger.Info("Dry-run: snapshot would be created",
+					"window_start", top.result.Window.StartTime,
+					"window_end", top.result.Window.EndTime,
+					"gfs_covers", top.result.Metadata.GFSCovers)
+				break
+			}
+
+			policyLogger.Info("Snapshot window active; initiating creation",
+				"window_start", top.result.Window.StartTime,
+				"window_end", top.result.Window.EndTime,
+				"gfs_covers", top.result.Metadata.GFSCovers,
+				"reason", top.result.Reason)
+
+			if err := create(ctx, client, vol, topType, top.result, notifyProvider, report, policyLogger); err != nil {
+				execErrors = errors.Join(execErrors, err)
+			}
+			break
+		}
+
+		// The candidate lies inside the top tier's window, and possibly inside other due windows too.
+		candidateMeta := policy.SnapshotMetadata{}
+		_ = candidateMeta.ParseFromMetadata(candidate.Metadata)
+
+		covered := candidateMeta.CoveredPolicies()
+		remaining := []gfsTier{}
+		for _, t := range due {
+			if windowContains(t.result.Window, candidate.CreatedAt) {
+				covered = append(covered, t.policy.GetPolicyType())
+			} else {
+				remaining = append(remaining, t)
+			}
+		}
+
+		promoted := top.result.Metadata
+		promoted.GFSCovers = policy.JoinCoveredPolicies(covered...)
+		promoted.PromotedFrom = candidateMeta.PolicyType
+		promotedTags := promoted.ToOpenstackMetadata()
+
+		promotion := snapshotOutcome(vol, topType, OutcomePromoted, top.result)
+		promotion.SnapshotName = candidate.Name
+		promotion.SnapshotID = candidate.ID
+		promotion.GFSCovers = promoted.GFSCovers
+		if report.DryRun {
+			promotion.Outcome = OutcomeWouldPromote
+			report.addSnapshot(promotion)
+			policyLogger.Info("Dry-run: snapshot would be promoted",
+				"snapshot_id", candidate.ID,
+				"promoted_from", candidateMeta.PolicyType,
+				"gfs_covers", promoted.GFSCovers)
+			maps.Copy(candidate.Metadata, promotedTags)
+			due = remaining
+			continue
+		}
+
+		policyLogger.Info("Snapshot window active; promoting existing snapshot",
+			"snapshot_id", candidate.ID,
+			"promoted_from", candidateMeta.PolicyType,
+			"window_start", top.result.Window.StartTime,
+			"window_end", top.result.Window.EndTime,
+			"gfs_covers", promoted.GFSCovers)
+
+		reqID, err := client.UpdateManagedSnapshotMetadata(ctx, candidate.ID, promotedTags)
+		promotion.RequestID = reqID
+		if err != nil {
+			promotion.Outcome = OutcomeFailed
+			promotion.Error = err.Error()
+			report.addSnapshot(promotion)
+			// The next run will find the same candidate and retry the promotion.
+			execErrors = errors.Join(execErrors, fmt.Errorf("%s policy snapshot promotion failed. %w", topType, err))
+			policyLogger.Error("Snapshot promotion failed",
+				"error", err,
+				"request_id", reqID,
+				"snapshot_id", candidate.ID,
+			)
+			break
+		}
+
+		report.addSnapshot(promotion)
+		policyLogger.Info("Snapshot successfully promoted",
+			"snapshot_id", candidate.ID,
+			"request_id", reqID,
+		)
+
+		// Keep the local history in sync so that the remaining tiers see the new label.
+		maps.Copy(candidate.Metadata, promotedTags)
+		due = remaining
+	}
+
+	return execErrors
+}
+
+// latestCoveringSnapshot returns the newest snapshot that satisfies the given GFS tier.
+// It relies on the history being sorted newest first, as returned by ListManagedVolumeSnapshots.
+func latestCoveringSnapshot(history []snapshots.Snapshot, policyType string) policy.LastSnapshotInfo {
+	for _, snap := range history {
+		meta := policy.SnapshotMetadata{}
+		if err := meta.ParseFromMetadata(snap.Metadata); err != nil {
+			continue
+		}
+
+		if meta.CoversPolicy(policyType) {
+			return policy.LastSnapshotInfo{
+				ID:        snap.ID,
+				CreatedAt: snap.CreatedAt,
+				Status:    snap.Status,
+				Metadata:  snap.Metadata,
+			}
+		}
+	}
+	return policy.LastSnapshotInfo{}
+}
+
+// findPromotionCandidate returns the newest lower tier snapshot created inside the window of the given tier,
+// or nil if there is none.
+func findPromotionCandidate(history []snapshots.Snapshot, tier gfsTier) *snapshots.Snapshot {
+	tierRank := policy.GFSRank(tier.policy.GetPolicyType())
+
+	for i := range history {
+		meta := policy.SnapshotMetadata{}
+		if err := meta.ParseFromMetadata(history[i].Metadata); err != nil {
+			continue
+		}
+
+		rank := policy.GFSRank(meta.PolicyType)
+		if rank == 0 || rank >= tierRank {
+			continue
+		}
+
+		if windowContains(tier.result.Window, history[i].CreatedAt) {
+			return &history[i]
+		}
+	}
+	return nil
+}
+
+// windowContains reports whether t lies inside [StartTime, EndTime).
+func windowContains(window policy.SnapshotPolicyWindow, t time.Time) bool {
+	return !t.Before(window.StartTime) && t.Before(window.EndTime)
+}
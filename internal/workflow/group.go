@@ -0,0 +1,603 @@
+package workflow
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/cloud/openstack"
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/hooks"
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/metrics"
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/notifications"
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/policy"
+	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/volumes"
+)
+
+// pendingSnapshot is a due policy window whose creation is deferred until all volumes of a server are evaluated.
+type pendingSnapshot struct {
+	vol        volumes.Volume
+	policyType string
+	result     policy.PolicyEvalResult
+	logger     *slog.Logger
+}
+
+// deferredSnapshots is the snapshotCreator of processDeferredVolumeGroup. It only collects the due windows.
+type deferredSnapshots struct {
+	mu      sync.Mutex
+	pending []pendingSnapshot
+}
+
+func (d *deferredSnapshots) create(
+	ctx context.Context,
+	client *openstack.Client,
+	vol volumes.Volume,
+	policyType string,
+	result policy.PolicyEvalResult,
+	notifyProvider notifications.Notifier,
+	report *RunReport,
+	policyLogger *slog.Logger,
+) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	policyLogger.Debug("Snapshot creation deferred until all volumes of the server are evaluated")
+	d.pending = append(d.pending, pendingSnapshot{vol: vol, policyType: policyType, result: result, logger: policyLogger})
+	return nil
+}
+
+// volumeErrors collects the errors of each volume of a group, since creation happens after evaluation.
+type volumeErrors struct {
+	mu   sync.Mutex
+	errs map[string]error
+}
+
+func (v *volumeErrors) add(volumeID string, err error) {
+	if err == nil {
+		return
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.errs[volumeID] = errors.Join(v.errs[volumeID], err)
+}
+
+// splitGroupSnapshotVolumes separates the volumes of a server that opted into group snapshots from the others.
+// Group snapshot mode needs at least two opted-in volumes; otherwise every volume is returned in 'rest'.
+// The group type is taken from the first opted-in volume (by ID), so it should be set consistently.
+func splitGroupSnapshotVolumes(vols []volumes.Volume) (grouped []volumes.Volume, rest []volumes.Volume, groupType string) {
+	for _, v := range vols {
+		cfg := policy.GroupSnapshotConfig{}
+		_ = cfg.ParseFromMetadata(v.Metadata)
+		if cfg.Enabled {
+			grouped = append(grouped, v)
+		} else {
+			rest = append(rest, v)
+		}
+	}
+
+	if len(grouped) < 2 {
+		return nil, vols, ""
+	}
+
+	slices.SortFunc(grouped, func(a, b volumes.Volume) int { return cmp.Compare(a.ID, b.ID) })
+	cfg := policy.GroupSnapshotConfig{}
+	_ = cfg.ParseFromMetadata(grouped[0].Metadata)
+	return grouped, rest, cfg.EffectiveGroupType()
+}
+
+// volumeGroupPrefix starts the name of the generic volume group of every server; the server ID follows.
+const volumeGroupPrefix = "snapsentry-"
+
+// volumeGroupName returns the name of the generic volume group of a server.
+func volumeGroupName(serverID string) string {
+	return volumeGroupPrefix + serverID
+}
+
+// volumeGroupChange is a change to an existing volume group, planned by planVolumeGroupReconcile.
+type volumeGroupChange struct {
+	group  openstack.VolumeGroup
+	remove []string
+	delete bool
+}
+
+// planVolumeGroupReconcile compares the volume groups of the project with the volumes eligible for group snapshots,
+// keyed by group name (see splitGroupSnapshotVolumes).
+//
+// Members that are no longer eligible (opted out, detached, or deleted with their server) are removed. A group with
+// fewer than two eligible members left is emptied and deleted. Eligible volumes missing from a group are not added
+// here; EnsureVolumeGroup adds them once a group snapshot is due.
+func planVolumeGroupReconcile(existing []openstack.VolumeGroup, eligible map[string][]string) []volumeGroupChange {
+	var changes []volumeGroupChange
+	for _, g := range existing {
+		members := eligible[g.Name]
+		change := volumeGroupChange{group: g}
+		for _, id := range g.Volumes {
+			if !slices.Contains(members, id) {
+				change.remove = append(change.remove, id)
+			}
+		}
+
+		if len(g.Volumes)-len(change.remove) < 2 {
+			change.remove = slices.Clone(g.Volumes)
+			change.delete = true
+		}
+		if len(change.remove) > 0 || change.delete {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// reconcileVolumeGroups keeps the membership of the Snapsentry volume groups current on every run, not only when
+// a group snapshot is due, so that a volume attached to another server is not held by the group of its old server.
+// See planVolumeGroupReconcile for the changes. During a dry-run, the changes are only logged.
+//
+// Failures are logged and retried on the next run; they do not fail the run.
+func reconcileVolumeGroups(ctx context.Context, client *openstack.Client, groups []volumeGroup, dryRun bool, logger *slog.Logger) {
+	eligible := map[string][]string{}
+	for _, g := range groups {
+		if g.vmID == "" {
+			continue
+		}
+		grouped, _, _ := splitGroupSnapshotVolumes(g.vols)
+		for _, v := range grouped {
+			eligible[volumeGroupName(g.vmID)] = append(eligible[volumeGroupName(g.vmID)], v.ID)
+		}
+	}
+
+	existing, err := client.ListVolumeGroups(ctx, volumeGroupPrefix)
+	if err != nil {
+		// Clouds without group support fail the listing on every run; that only matters once a volume opted in.
+		logLevel := slog.LevelDebug
+		if len(eligible) > 0 {
+			logLevel = slog.LevelWarn
+		}
+		logger.Log(ctx, logLevel, "Volume group listing failed; skipping volume group reconciliation", "error", err)
+		return
+	}
+
+	for _, change := range planVolumeGroupReconcile(existing, eligible) {
+		groupLogger := logger.With("group_id", change.group.ID, "group_name", change.group.Name)
+		if dryRun {
+			groupLogger.Info("Volume group would be reconciled", "remove_volumes", change.remove, "delete", change.delete)
+			continue
+		}
+
+		if len(change.remove) > 0 {
+			reqID, err := client.RemoveVolumeGroupMembers(ctx, change.group.ID, change.remove)
+			if err != nil {
+				groupLogger.Warn("Removing volumes from the volume group failed", "volume_ids", change.remove, "request_id", reqID, "error", err)
+				continue
+			}
+			groupLogger.Info("Volumes no longer eligible for group snapshots removed from the volume group",
+				"volume_ids", change.remove, "request_id", reqID)
+		}
+
+		if change.delete {
+			// Cinder keeps the group while it has group snapshots; it is deleted once they expired.
+			reqID, err := client.DeleteVolumeGroup(ctx, change.group.ID)
+			if err != nil {
+				groupLogger.Warn("Volume group deletion failed; retrying on the next run", "request_id", reqID, "error", err)
+				continue
+			}
+			groupLogger.Info("Volume group with fewer than two eligible volumes deleted", "request_id", reqID)
+		}
+	}
+}
+
+// splitDueGroupSnapshots separates the due windows of one policy type served by a group snapshot from those
+// created per volume. The group snapshot serves the grouped volumes only if the window is due on all of them;
+// if it is only due on some, they are created per volume and 'partial' is set.
+func splitDueGroupSnapshots(grouped []volumes.Volume, due []pendingSnapshot) (groupPending []pendingSnapshot, pending []pendingSnapshot, partial bool) {
+	for _, p := range due {
+		if slices.ContainsFunc(grouped, func(v volumes.Volume) bool { return v.ID == p.vol.ID }) {
+			groupPending = append(groupPending, p)
+		} else {
+			pending = append(pending, p)
+		}
+	}
+
+	if len(groupPending) > 0 && len(groupPending) != len(grouped) {
+		return nil, append(pending, groupPending...), true
+	}
+	return groupPending, pending, false
+}
+
+// processDeferredVolumeGroup is the counterpart of processVolumeGroup for servers whose snapshots must be
+// coordinated: volumes that opted into group snapshots, and servers with quiesce hooks.
+//
+// Workflow:
+//  1. Evaluation: Every volume is evaluated concurrently by processVolume, but due windows are only collected.
+//  2. Pre Hook: If hooks are configured and any window is due, the pre hook runs once for the server.
+//     If it fails, no snapshot is created and every due window is recorded as failed; the window stays
+//     open and is retried on the next run.
+//  3. Group Snapshot: For each policy type due on every volume in 'grouped', the volumes are placed in the
+//     server's generic volume group and a single group snapshot is created. Each member snapshot is named
+//     and labelled like a regular snapshot, plus the group snapshot ID.
+//  4. Fallback: All other due windows, policy types due on only some grouped volumes, and groups the backend
+//     rejects, are created per volume with createPolicySnapshot.
+//  5. Post Hook: Runs once all snapshots are handled and left "creating" (at most the hook timeout),
+//     and also if the pre hook failed.
+//
+// Success and error counters are updated per volume once all of its snapshots are handled.
+func processDeferredVolumeGroup(
+	ctx context.Context,
+	client *openstack.Client,
+	serverID string,
+	vols []volumes.Volume,
+	grouped []volumes.Volume,
+	groupType string,
+	quiesce *quiesceHooks,
+	successCounter *int32,
+	errorCounter *int32,
+	notifyProvider notifications.Notifier,
+	report *RunReport,
+	logger *slog.Logger,
+) {
+	groupLogger := logger
+	if len(grouped) > 0 {
+		groupLogger = groupLogger.With("snapshot_mode", "group", "group_type", groupType)
+	}
+	groupLogger.Debug("Starting to process volumes with deferred creation",
+		"volume_count", len(vols),
+		"group_snapshot_volume_count", len(grouped),
+		"quiesce_hooks", quiesce != nil)
+
+	deferred := &deferredSnapshots{}
+	volErrs := &volumeErrors{errs: map[string]error{}}
+
+	// 1. Evaluate
+	var evalWaitGroup sync.WaitGroup
+	evaluated := vols
+	for i, v := range vols {
+		if ctx.Err() != nil {
+			groupLogger.Error("Workflow execution halted due to timeout or cancellation")
+			evaluated = vols[:i]
+			break
+		}
+
+		evalWaitGroup.Add(1)
+		go func(vol volumes.Volume) {
+			defer evalWaitGroup.Done()
+			volLogger := groupLogger.With("volume_id", vol.ID, "volume_name", vol.Name)
+			volErrs.add(vol.ID, processVolume(ctx, client, vol, deferred.create, notifyProvider, report, volLogger))
+		}(v)
+	}
+	evalWaitGroup.Wait()
+
+	// 2.-5. Create, one policy type at a time
+	create := func() {
+		byPolicy := map[string][]pendingSnapshot{}
+		for _, p := range deferred.pending {
+			byPolicy[p.policyType] = append(byPolicy[p.policyType], p)
+		}
+
+		for _, policyType := range slices.Sorted(maps.Keys(byPolicy)) {
+			policyLogger := groupLogger.With("policy_type", policyType)
+
+			groupPending, pending, partial := splitDueGroupSnapshots(grouped, byPolicy[policyType])
+			if partial {
+				policyLogger.Info("Policy is only due on some volumes of the group; creating per-volume snapshots", "volume_count", len(grouped))
+			}
+			if len(groupPending) > 0 && !createGroupPolicySnapshot(ctx, client, serverID, groupType, policyType, groupPending, volErrs, notifyProvider, report, policyLogger) {
+				pending = append(pending, groupPending...)
+			}
+
+			createPendingSnapshots(ctx, client, pending, volErrs, notifyProvider, report)
+		}
+	}
+
+	switch {
+	case len(deferred.pending) == 0:
+	case quiesce == nil:
+		create()
+	default:
+		target := hooks.Target{RunID: report.RunID, ServerID: serverID}
+		for _, p := range deferred.pending {
+			if !slices.Contains(target.VolumeIDs, p.vol.ID) {
+				target.VolumeIDs = append(target.VolumeIDs, p.vol.ID)
+			}
+		}
+		slices.Sort(target.VolumeIDs)
+
+		preErr, postErr := quiesce.around(ctx, target, groupLogger, func() {
+			create()
+			// The guest stays quiesced until the backend has taken the snapshots, not just accepted them.
+			awaitQuiescedSnapshots(ctx, client, report, target.VolumeIDs, quiesce.config.Timeout(), groupLogger)
+		})
+		if preErr != nil {
+			groupLogger.Error("Pre-snapshot hook failed; no snapshot is created for the server", "error", preErr)
+			for _, p := range deferred.pending {
+				failHookedSnapshot(ctx, p, preErr, volErrs, notifyProvider, report)
+			}
+		}
+		if postErr != nil {
+			// The guest may still be quiesced (e.g. frozen file systems); this needs attention even though
+			// the snapshots exist.
+			groupLogger.Error("Post-snapshot hook failed; the guest may still be quiesced", "error", postErr)
+			for _, volumeID := range target.VolumeIDs {
+				volErrs.add(volumeID, fmt.Errorf("post-snapshot hook failed. %w", postErr))
+			}
+		}
+	}
+
+	// Count
+	for _, vol := range evaluated {
+		if err := volErrs.errs[vol.ID]; err != nil {
+			groupLogger.Error("Volume processing encountered an error", "volume_id", vol.ID, "volume_name", vol.Name, "error", err)
+			atomic.AddInt32(errorCounter, 1)
+		} else {
+			groupLogger.Debug("Volume processing completed successfully", "volume_id", vol.ID, "volume_name", vol.Name)
+			atomic.AddInt32(successCounter, 1)
+		}
+	}
+}
+
+// failHookedSnapshot records a due window that was not created because the pre-snapshot hook failed.
+func failHookedSnapshot(
+	ctx context.Context,
+	p pendingSnapshot,
+	hookErr error,
+	volErrs *volumeErrors,
+	notifyProvider notifications.Notifier,
+	report *RunReport,
+) {
+	outcome := snapshotOutcome(p.vol, p.policyType, OutcomeFailed, p.result)
+	outcome.Error = hookErr.Error()
+	report.addSnapshot(outcome)
+	metrics.SnapshotsFailed.WithLabelValues(p.policyType).Inc()
+
+	volErrs.add(p.vol.ID, fmt.Errorf("%s policy snapshot skipped; pre-snapshot hook failed. %w", p.policyType, hookErr))
+	sendNotification(ctx, notifyProvider, notifications.SnapshotCreationFailure{
+		Service:    "snapsentry",
+		VolumeID:   p.vol.ID,
+		Window:     p.result.Window,
+		PolicyType: p.policyType,
+		Message:    fmt.Sprintf("Snapsentry Snapshot was not created because the pre-snapshot hook failed: %s", hookErr),
+	}, p.logger)
+}
+
+// createPendingSnapshots creates the deferred snapshots one per volume, started together like processVolumeGroup.
+func createPendingSnapshots(
+	ctx context.Context,
+	client *openstack.Client,
+	pending []pendingSnapshot,
+	volErrs *volumeErrors,
+	notifyProvider notifications.Notifier,
+	report *RunReport,
+) {
+	var createWaitGroup sync.WaitGroup
+	for _, p := range pending {
+		createWaitGroup.Add(1)
+		go func(p pendingSnapshot) {
+			defer createWaitGroup.Done()
+			volErrs.add(p.vol.ID, createPolicySnapshot(ctx, client, p.vol, p.policyType, p.result, notifyProvider, report, p.logger))
+		}(p)
+	}
+	createWaitGroup.Wait()
+}
+
+// createGroupPolicySnapshot serves a policy window due on every volume of a server with one group snapshot.
+//
+// The group snapshot returns as soon as Cinder accepted it. Its members are named and labelled right away and
+// recorded as in flight; reconcileCreatedSnapshots confirms them, or cleans the group snapshot up, at the end
+// of the run.
+//
+// Failure Handling:
+//   - Unsupported: If the volume group cannot be set up (no group support, group type mismatch, volume already
+//     in another group) or no group snapshot was created, it returns false and the caller falls back to
+//     per-volume snapshots.
+//   - Quota: If the snapshot quota of the run has no room for every member, it returns false as well, so the
+//     volumes reserve the quota one by one.
+//   - Orphaned Group Snapshot: If the members cannot be found or labelled, the group snapshot is deleted
+//     (unlabelled members would never expire) and the caller falls back as well. If that cleanup fails,
+//     every volume is recorded as failed and notified; manual intervention is required.
+//
+// The group snapshot carries the window key of the group (see openstack.Client.CreateGroupSnapshot), so a retried
+// request adopts the group snapshot of the window instead of creating a second one.
+//
+// Returns true if the window was handled (accepted or failed) and must not be retried per volume.
+func createGroupPolicySnapshot(
+	ctx context.Context,
+	client *openstack.Client,
+	serverID string,
+	groupType string,
+	policyType string,
+	pending []pendingSnapshot,
+	volErrs *volumeErrors,
+	notifyProvider notifications.Notifier,
+	report *RunReport,
+	logger *slog.Logger,
+) bool {
+	volumeIDs := []string{}
+	volumeTypes := []string{}
+	for _, p := range pending {
+		volumeIDs = append(volumeIDs, p.vol.ID)
+		if p.vol.VolumeType != "" && !slices.Contains(volumeTypes, p.vol.VolumeType) {
+			volumeTypes = append(volumeTypes, p.vol.VolumeType)
+		}
+	}
+
+	// A. Volume Group
+	groupName := volumeGroupName(serverID)
+	group, reqID, err := client.EnsureVolumeGroup(ctx, groupName, groupType, volumeTypes, pending[0].vol.AvailabilityZone, volumeIDs)
+	if err != nil {
+		logger.Warn("Volume group is unavailable; falling back to per-volume snapshots",
+			"group_name", groupName,
+			"request_id", reqID,
+			"error", err)
+		return false
+	}
+
+	// B. Quota
+	// Every member counts as a snapshot; without room for all of them, the volumes are served one by one.
+	gigabytes := 0
+	for _, p := range pending {
+		gigabytes += p.vol.Size
+	}
+	if !report.reserveQuota(pending[0].vol.ID, len(pending), gigabytes) {
+		logger.Warn("Snapshot quota does not cover the group snapshot; falling back to per-volume snapshots",
+			"volume_count", len(pending),
+			"gigabytes", gigabytes)
+		return false
+	}
+
+	// C. Group Snapshot
+	window := pending[0].result.Window
+	snapName := generateGroupSnapshotName(policyType, window.StartTime, serverID)
+	windowKey := policy.SnapshotWindowKey(group.ID, policyType, window.StartTime)
+	logger.Info("Snapshot window active; initiating group snapshot",
+		"group_id", group.ID,
+		"group_snapshot_name", snapName,
+		"volume_count", len(pending),
+		"window_start", window.StartTime,
+		"window_end", window.EndTime,
+		"window_key", windowKey)
+
+	startedAt := time.Now()
+	groupSnap, members, reqID, err := client.CreateGroupSnapshot(ctx, group, snapName, windowKey)
+	if err == nil && len(members) != len(pending) {
+		err = fmt.Errorf("group snapshot %s has %d member snapshots, expected %d", groupSnap.ID, len(members), len(pending))
+	}
+
+	// D. Label Members
+	// Labelled members are found (and expired) like any other managed snapshot, also if this run stops waiting.
+	inflight := make([]inflightSnapshot, 0, len(pending))
+	for _, p := range pending {
+		result := p.result
+		result.Metadata.GroupSnapshotID = groupSnap.ID
+		result.Metadata.WindowKey = policy.SnapshotWindowKey(p.vol.ID, policyType, p.result.Window.StartTime)
+		outcome := snapshotOutcome(p.vol, policyType, OutcomeCreated, result)
+		outcome.SnapshotID = members[p.vol.ID]
+		outcome.RequestID = reqID
+		outcome.GroupSnapshotID = groupSnap.ID
+
+		if err == nil {
+			name := generateSnapshotName(policyType, p.result.Window.StartTime, p.vol.ID)
+			if labelReqID, labelErr := client.LabelGroupSnapshotMember(ctx, members[p.vol.ID], name, result.Metadata.ToOpenstackMetadata()); labelErr != nil {
+				err = errors.Join(err, fmt.Errorf("labelling member snapshot %s failed: %w (Request ID: %s)", members[p.vol.ID], labelErr, labelReqID))
+			}
+		}
+		inflight = append(inflight, inflightSnapshot{
+			vol:             p.vol,
+			policyType:      policyType,
+			result:          result,
+			snapshotID:      members[p.vol.ID],
+			groupSnapshotID: groupSnap.ID,
+			startedAt:       startedAt,
+			outcome:         outcome,
+			logger:          p.logger,
+		})
+	}
+
+	if err != nil {
+		report.quota.Release(len(pending), gigabytes)
+		if openstack.IsQuotaExceeded(err) {
+			report.quota.Exhaust()
+		}
+		logger.Error("Group snapshot creation failed",
+			"error", err,
+			"request_id", reqID,
+			"group_snapshot_id", groupSnap.ID)
+
+		if groupSnap.ID == "" {
+			logger.Warn("Falling back to per-volume snapshots")
+			return false
+		}
+
+		// SAFETY CHECK: Orphaned Resource Cleanup
+		delReqID, cleanupErr := cleanupGroupSnapshot(ctx, client, groupSnap.ID, logger)
+		if cleanupErr == nil {
+			logger.Warn("Falling back to per-volume snapshots")
+			return false
+		}
+
+		// CRITICAL: The group snapshot failed AND could not be removed.
+		for volumeID, failErr := range failGroupPolicySnapshot(ctx, groupSnap.ID, inflight, err, delReqID, cleanupErr, notifyProvider, report) {
+			volErrs.add(volumeID, failErr)
+		}
+		return true
+	}
+
+	// E. In Flight
+	logger.Info("Group snapshot creation accepted",
+		"group_snapshot_id", groupSnap.ID,
+		"status", groupSnap.Status,
+		"request_id", reqID)
+	for _, s := range inflight {
+		report.addInflight(s)
+	}
+	return true
+}
+
+// cleanupGroupSnapshot deletes a failed group snapshot together with its members (orphan cleanup).
+func cleanupGroupSnapshot(ctx context.Context, client *openstack.Client, groupSnapshotID string, logger *slog.Logger) (string, error) {
+	delReqID, err := client.DeleteGroupSnapshot(ctx, groupSnapshotID)
+	if err != nil {
+		metrics.OrphanCleanups.WithLabelValues("failed").Inc()
+		logger.Error("Orphaned group snapshot cleanup failed; manual intervention required",
+			"error", err,
+			"group_snapshot_id", groupSnapshotID,
+			"cleanup_request_id", delReqID)
+		return delReqID, err
+	}
+
+	metrics.OrphanCleanups.WithLabelValues("cleaned").Inc()
+	logger.Warn("Orphaned group snapshot cleaned up",
+		"group_snapshot_id", groupSnapshotID,
+		"cleanup_request_id", delReqID)
+	return delReqID, nil
+}
+
+// failGroupPolicySnapshot records every member of a failed group snapshot as failed, with the result of
+// cleanupGroupSnapshot, and notifies each volume.
+//
+// Returns the error of each volume, keyed by volume ID.
+func failGroupPolicySnapshot(
+	ctx context.Context,
+	groupSnapshotID string,
+	members []inflightSnapshot,
+	cause error,
+	delReqID string,
+	cleanupErr error,
+	notifyProvider notifications.Notifier,
+	report *RunReport,
+) map[string]error {
+	volumeErrs := map[string]error{}
+	for _, s := range members {
+		outcome := s.outcome
+		outcome.Outcome = OutcomeFailed
+		outcome.Error = cause.Error()
+		outcome.OrphanSnapshotID = s.snapshotID
+		outcome.OrphanCleanup = OrphanCleaned
+		outcome.CleanupRequestID = delReqID
+		message := fmt.Sprintf("Snapsentry group snapshot %s has failed due to %s. ", groupSnapshotID, cause)
+		volErr := fmt.Errorf("%s policy group snapshot creation failed. %w", s.policyType, cause)
+		if cleanupErr != nil {
+			outcome.OrphanCleanup = OrphanCleanupFailed
+			message += fmt.Sprintf("Orphaned group snapshot cleanup failed; manual intervention required (Request ID: %s)", delReqID)
+			volErr = errors.Join(volErr, fmt.Errorf("%s policy orphaned group snapshot cleanup failed; manual intervention required. %w", s.policyType, cleanupErr))
+		} else {
+			message += fmt.Sprintf("Orphaned group snapshot successfully clean up (Request ID: %s).", delReqID)
+		}
+		report.addSnapshot(outcome)
+		metrics.SnapshotsFailed.WithLabelValues(s.policyType).Inc()
+		volumeErrs[s.vol.ID] = volErr
+
+		sendNotification(ctx, notifyProvider, notifications.SnapshotCreationFailure{
+			Service:    "snapsentry",
+			VolumeID:   s.vol.ID,
+			Window:     s.result.Window,
+			SnapshotID: s.snapshotID,
+			PolicyType: s.policyType,
+			Message:    message,
+		}, s.logger)
+	}
+	return volumeErrs
+}
@@ -0,0 +1,339 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/cloud"
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/cloud/openstack"
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/policy"
+	"github.com/gophercloud/gophercloud/v2"
+	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/snapshots"
+	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/volumes"
+)
+
+// groupVolume builds a volume of 10 GB, opted into group snapshots with the given group type unless it is "-".
+func groupVolume(id string, groupType string) volumes.Volume {
+	vol := volumes.Volume{ID: id, Size: 10, Metadata: map[string]string{}}
+	if groupType != "-" {
+		cfg := policy.GroupSnapshotConfig{Enabled: true, GroupType: groupType}
+		vol.Metadata = cfg.ToOpenstackMetadata()
+	}
+	return vol
+}
+
+func volumeIDs(vols []volumes.Volume) []string {
+	ids := []string{}
+	for _, v := range vols {
+		ids = append(ids, v.ID)
+	}
+	return ids
+}
+
+func TestSplitGroupSnapshotVolumes(t *testing.T) {
+	tests := []struct {
+		name          string
+		vols          []volumes.Volume
+		wantGrouped   []string
+		wantRest      []string
+		wantGroupType string
+	}{
+		{
+			name:     "None Opted In",
+			vols:     []volumes.Volume{groupVolume("vol-1", "-"), groupVolume("vol-2", "-")},
+			wantRest: []string{"vol-1", "vol-2"},
+		},
+		{
+			name:     "Fewer Than Two Opted In",
+			vols:     []volumes.Volume{groupVolume("vol-1", ""), groupVolume("vol-2", "-")},
+			wantRest: []string{"vol-1", "vol-2"},
+		},
+		{
+			name:          "Sorted By ID",
+			vols:          []volumes.Volume{groupVolume("vol-3", ""), groupVolume("vol-2", "-"), groupVolume("vol-1", "")},
+			wantGrouped:   []string{"vol-1", "vol-3"},
+			wantRest:      []string{"vol-2"},
+			wantGroupType: policy.DefaultGroupType,
+		},
+		{
+			name:          "Mixed Group Types Use The First Volume",
+			vols:          []volumes.Volume{groupVolume("vol-b", "consistent-group"), groupVolume("vol-a", "replicated-group")},
+			wantGrouped:   []string{"vol-a", "vol-b"},
+			wantRest:      []string{},
+			wantGroupType: "replicated-group",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			grouped, rest, groupType := splitGroupSnapshotVolumes(tt.vols)
+			if tt.wantGrouped == nil && grouped != nil {
+				t.Errorf("grouped = %v, want nil", volumeIDs(grouped))
+			}
+			if got := volumeIDs(grouped); tt.wantGrouped != nil && !slices.Equal(got, tt.wantGrouped) {
+				t.Errorf("grouped = %v, want %v", got, tt.wantGrouped)
+			}
+			if got := volumeIDs(rest); !slices.Equal(got, tt.wantRest) {
+				t.Errorf("rest = %v, want %v", got, tt.wantRest)
+			}
+			if groupType != tt.wantGroupType {
+				t.Errorf("groupType = %q, want %q", groupType, tt.wantGroupType)
+			}
+		})
+	}
+}
+
+func TestPlanVolumeGroupReconcile(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing []openstack.VolumeGroup
+		eligible map[string][]string
+		want     []string
+	}{
+		{
+			name:     "Unchanged",
+			existing: []openstack.VolumeGroup{{ID: "g-1", Name: "snapsentry-vm-1", Volumes: []string{"vol-1", "vol-2"}}},
+			eligible: map[string][]string{"snapsentry-vm-1": {"vol-1", "vol-2"}},
+			want:     []string{},
+		},
+		{
+			name:     "Missing Member Is Left To EnsureVolumeGroup",
+			existing: []openstack.VolumeGroup{{ID: "g-1", Name: "snapsentry-vm-1", Volumes: []string{"vol-1", "vol-2"}}},
+			eligible: map[string][]string{"snapsentry-vm-1": {"vol-1", "vol-2", "vol-3"}},
+			want:     []string{},
+		},
+		{
+			name:     "Volume Opted Out",
+			existing: []openstack.VolumeGroup{{ID: "g-1", Name: "snapsentry-vm-1", Volumes: []string{"vol-1", "vol-2", "vol-3"}}},
+			eligible: map[string][]string{"snapsentry-vm-1": {"vol-1", "vol-3"}},
+			want:     []string{"g-1 remove [vol-2]"},
+		},
+		{
+			name: "Volume Moved To Another Server",
+			existing: []openstack.VolumeGroup{
+				{ID: "g-1", Name: "snapsentry-vm-1", Volumes: []string{"vol-1", "vol-2", "vol-3"}},
+				{ID: "g-2", Name: "snapsentry-vm-2", Volumes: []string{"vol-4", "vol-5"}},
+			},
+			eligible: map[string][]string{"snapsentry-vm-1": {"vol-1", "vol-2"}, "snapsentry-vm-2": {"vol-3", "vol-4", "vol-5"}},
+			want:     []string{"g-1 remove [vol-3]"},
+		},
+		{
+			name:     "Fewer Than Two Eligible",
+			existing: []openstack.VolumeGroup{{ID: "g-1", Name: "snapsentry-vm-1", Volumes: []string{"vol-1", "vol-2"}}},
+			eligible: map[string][]string{},
+			want:     []string{"g-1 remove [vol-1 vol-2] delete"},
+		},
+		{
+			name:     "Server Deleted",
+			existing: []openstack.VolumeGroup{{ID: "g-1", Name: "snapsentry-vm-1", Volumes: []string{"vol-1", "vol-2", "vol-3"}}},
+			eligible: map[string][]string{"snapsentry-vm-2": {"vol-1", "vol-2"}},
+			want:     []string{"g-1 remove [vol-1 vol-2 vol-3] delete"},
+		},
+		{
+			name:     "Empty Group",
+			existing: []openstack.VolumeGroup{{ID: "g-1", Name: "snapsentry-vm-1"}},
+			want:     []string{"g-1 remove [] delete"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, c := range planVolumeGroupReconcile(tt.existing, tt.eligible) {
+				entry := c.group.ID + " remove [" + strings.Join(c.remove, " ") + "]"
+				if c.delete {
+					entry += " delete"
+				}
+				got = append(got, entry)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("planVolumeGroupReconcile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeCinder serves the volume group API for the given groups, and the given snapshots by ID. It records every
+// changing request as "<method> <path> <body>".
+type fakeCinder struct {
+	mu        sync.Mutex
+	groups    []openstack.VolumeGroup
+	snapshots map[string]snapshots.Snapshot
+	failList  bool
+	requests  []string
+}
+
+func (f *fakeCinder) client(t *testing.T) *openstack.Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/groups/detail":
+			if f.failList {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"groups": f.groups})
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/snapshots/"):
+			snap, ok := f.snapshots[strings.TrimPrefix(r.URL.Path, "/snapshots/")]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"snapshot": map[string]any{
+				"id": snap.ID, "volume_id": snap.VolumeID, "status": snap.Status, "metadata": snap.Metadata,
+			}})
+		case r.Method == http.MethodGet:
+			_ = json.NewEncoder(w).Encode(map[string]any{"group": map[string]string{"status": "available"}})
+		default:
+			var body map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			encoded, _ := json.Marshal(body)
+			f.requests = append(f.requests, r.Method+" "+r.URL.Path+" "+string(encoded))
+			if strings.HasSuffix(r.URL.Path, "/metadata") {
+				_, _ = w.Write(encoded)
+				return
+			}
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return &openstack.Client{
+		RetryConfig:        cloud.RetryConfig{OperationTimeout: 5 * time.Second},
+		BlockStorageClient: &gophercloud.ServiceClient{ProviderClient: &gophercloud.ProviderClient{}, Endpoint: server.URL + "/"},
+	}
+}
+
+func TestReconcileVolumeGroups(t *testing.T) {
+	groups := []volumeGroup{
+		{vmID: "vm-1", vols: []volumes.Volume{groupVolume("vol-1", ""), groupVolume("vol-2", ""), groupVolume("vol-3", "-")}},
+		{vmID: "vm-2", vols: []volumes.Volume{groupVolume("vol-4", ""), groupVolume("vol-5", "")}},
+	}
+	existing := []openstack.VolumeGroup{
+		{ID: "g-1", Name: "snapsentry-vm-1", Volumes: []string{"vol-1", "vol-2", "vol-3"}},
+		{ID: "g-2", Name: "snapsentry-vm-2", Volumes: []string{"vol-4", "vol-5"}},
+		{ID: "g-3", Name: "snapsentry-vm-gone", Volumes: []string{"vol-6", "vol-7"}},
+		{ID: "g-4", Name: "not-managed", Volumes: []string{"vol-8"}},
+	}
+
+	tests := []struct {
+		name     string
+		dryRun   bool
+		failList bool
+		want     []string
+	}{
+		{
+			name: "Removes And Deletes",
+			want: []string{
+				`PUT /groups/g-1 {"group":{"remove_volumes":"vol-3"}}`,
+				`PUT /groups/g-3 {"group":{"remove_volumes":"vol-6,vol-7"}}`,
+				`POST /groups/g-3/action {"delete":{"delete-volumes":false}}`,
+			},
+		},
+		{name: "Dry Run", dryRun: true, want: nil},
+		{name: "Listing Fails", failList: true, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeCinder{groups: existing, failList: tt.failList}
+			reconcileVolumeGroups(context.Background(), fake.client(t), groups, tt.dryRun, testLogger)
+			if !slices.Equal(fake.requests, tt.want) {
+				t.Errorf("requests = %v, want %v", fake.requests, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitDueGroupSnapshots(t *testing.T) {
+	grouped := []volumes.Volume{groupVolume("vol-1", ""), groupVolume("vol-2", "")}
+	due := func(ids ...string) []pendingSnapshot {
+		var pending []pendingSnapshot
+		for _, id := range ids {
+			pending = append(pending, pendingSnapshot{vol: volumes.Volume{ID: id}, policyType: "daily"})
+		}
+		return pending
+	}
+	ids := func(pending []pendingSnapshot) []string {
+		ids := []string{}
+		for _, p := range pending {
+			ids = append(ids, p.vol.ID)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name        string
+		due         []pendingSnapshot
+		wantGroup   []string
+		wantPending []string
+		wantPartial bool
+	}{
+		{name: "Due On Every Grouped Volume", due: due("vol-2", "vol-1"), wantGroup: []string{"vol-2", "vol-1"}, wantPending: []string{}},
+		{name: "Partly Due", due: due("vol-1", "vol-3"), wantGroup: []string{}, wantPending: []string{"vol-3", "vol-1"}, wantPartial: true},
+		{name: "Ungrouped Volumes Alongside", due: due("vol-1", "vol-3", "vol-2"), wantGroup: []string{"vol-1", "vol-2"}, wantPending: []string{"vol-3"}},
+		{name: "Only Ungrouped Due", due: due("vol-3"), wantGroup: []string{}, wantPending: []string{"vol-3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groupPending, pending, partial := splitDueGroupSnapshots(grouped, tt.due)
+			if got := ids(groupPending); !slices.Equal(got, tt.wantGroup) {
+				t.Errorf("group snapshot = %v, want %v", got, tt.wantGroup)
+			}
+			if got := ids(pending); !slices.Equal(got, tt.wantPending) {
+				t.Errorf("per-volume = %v, want %v", got, tt.wantPending)
+			}
+			if partial != tt.wantPartial {
+				t.Errorf("partial = %v, want %v", partial, tt.wantPartial)
+			}
+		})
+	}
+}
+
+// TestCreateGroupPolicySnapshot_Fallback checks that a group snapshot that cannot be taken is left to the per-volume
+// snapshots, without a group snapshot request.
+func TestCreateGroupPolicySnapshot_Fallback(t *testing.T) {
+	pending := []pendingSnapshot{
+		{vol: groupVolume("vol-1", ""), policyType: "daily", logger: testLogger},
+		{vol: groupVolume("vol-2", ""), policyType: "daily", logger: testLogger},
+	}
+
+	tests := []struct {
+		name     string
+		failList bool
+		quota    *policy.SnapshotQuota
+	}{
+		{name: "Group Rejected", failList: true},
+		{name: "Quota Short", quota: policy.NewSnapshotQuota(10, 9, -1, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeCinder{
+				groups:   []openstack.VolumeGroup{{ID: "g-1", Name: "snapsentry-vm-1", Status: "available", Volumes: []string{"vol-1", "vol-2"}}},
+				failList: tt.failList,
+			}
+			report := NewRunReport("req-test", "create-snapshots", false)
+			report.quota = tt.quota
+			volErrs := &volumeErrors{errs: map[string]error{}}
+
+			if createGroupPolicySnapshot(context.Background(), fake.client(t), "vm-1", policy.DefaultGroupType, "daily", pending, volErrs, nil, report, testLogger) {
+				t.Errorf("createGroupPolicySnapshot() = true, want a per-volume fallback")
+			}
+			if len(fake.requests) != 0 || len(report.Snapshots) != 0 || len(volErrs.errs) != 0 {
+				t.Errorf("requests = %v, outcomes = %d, errors = %v, want none", fake.requests, len(report.Snapshots), volErrs.errs)
+			}
+		})
+	}
+}
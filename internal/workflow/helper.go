@@ -1,11 +1,14 @@
 package workflow
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/notifications"
 	"github.com/lmittmann/tint"
 	"golang.org/x/term"
 )
@@ -48,3 +51,56 @@ func generateSnapshotName(policyType string, windowStart time.Time, volumeID str
 	timestamp := windowStart.Format(time.RFC3339)
 	return fmt.Sprintf("managed-%s-%s-%s", policyType, volumeID, timestamp)
 }
+
+// windowStartFromName recovers the window start encoded by generateSnapshotName.
+// It returns the zero time for snapshots named differently.
+func windowStartFromName(snapshotName string, volumeID string) time.Time {
+	_, timestamp, found := strings.Cut(snapshotName, volumeID+"-")
+	if !found {
+		return time.Time{}
+	}
+	windowStart, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return time.Time{}
+	}
+	return windowStart
+}
+
+// generateGroupSnapshotName names the group snapshot of a server; its members are named by generateSnapshotName.
+// Format: managed-<policyType>-group-<serverID>-<windowStart>
+func generateGroupSnapshotName(policyType string, windowStart time.Time, serverID string) string {
+	timestamp := windowStart.Format(time.RFC3339)
+	return fmt.Sprintf("managed-%s-group-%s-%s", policyType, serverID, timestamp)
+}
+
+// generateBackupName names the backup exported from a snapshot, after the snapshot's creation time.
+// Format: managed-backup-<policyType>-<volumeID>-<snapshotCreatedAt>
+func generateBackupName(policyType string, snapshotCreatedAt time.Time, volumeID string) string {
+	timestamp := snapshotCreatedAt.UTC().Format(time.RFC3339)
+	return fmt.Sprintf("managed-backup-%s-%s-%s", policyType, volumeID, timestamp)
+}
+
+// sendNotification delivers an event to the configured notifiers and logs the outcome.
+// A nil notifier means notifications are disabled.
+func sendNotification(ctx context.Context, notifier notifications.Notifier, event notifications.Event, logger *slog.Logger) {
+	if notifier == nil {
+		logger.Debug("Skip notification", "reason", "No notifier is configured by the user")
+		return
+	}
+
+	if !notifications.Accepts(notifier, event.EventType()) {
+		logger.Debug("Skip notification", "reason", "No notifier is subscribed to the event", "event", event.EventType())
+		return
+	}
+
+	// The workflow context may already be expired (that can be the failure being reported),
+	// so only its values are kept. Each notifier bounds its own requests.
+	ctx = context.WithoutCancel(ctx)
+
+	logger.Debug("Attempting to send notification", "notifier", notifier.Name(), "event", event.EventType())
+	if err := notifier.Notify(ctx, event); err != nil {
+		logger.Error("Notification failed to send", "notifier", notifier.Name(), "event", event.EventType(), "error", err)
+		return
+	}
+	logger.Info("Notification sent", "notifier", notifier.Name(), "event", event.EventType())
+}
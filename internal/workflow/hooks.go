@@ -0,0 +1,90 @@
+package workflow
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"slices"
+
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/cloud/openstack"
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/hooks"
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/policy"
+	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/volumes"
+)
+
+// quiesceHooks are the pre/post hooks of one server.
+type quiesceHooks struct {
+	runner hooks.Runner
+	config policy.QuiesceHookConfig
+}
+
+// resolveQuiesceHooks returns the hooks configured for a server, or nil if there are none or the runner is disabled.
+//
+// The server metadata takes precedence. If the server has no hooks (or its metadata cannot be read, e.g. because
+// the application credential is restricted to Cinder), the first volume (by ID) with hooks is used.
+func resolveQuiesceHooks(
+	ctx context.Context,
+	client *openstack.Client,
+	runner hooks.Runner,
+	serverID string,
+	vols []volumes.Volume,
+	logger *slog.Logger,
+) *quiesceHooks {
+	if !runner.Enabled() {
+		return nil
+	}
+
+	serverMeta, err := client.GetServerMetadata(ctx, serverID)
+	if err != nil {
+		logger.Warn("Server metadata is unavailable; only volume metadata is checked for quiesce hooks", "error", err)
+	}
+
+	cfg := policy.QuiesceHookConfig{}
+	if err := cfg.ParseFromMetadata(serverMeta); err != nil {
+		logger.Warn("Quiesce hook configuration of the server is invalid", "error", err)
+	}
+	if cfg.IsConfigured() {
+		logger.Debug("Quiesce hooks configured on the server", "pre", cfg.Pre, "post", cfg.Post)
+		return &quiesceHooks{runner: runner, config: cfg}
+	}
+
+	sorted := slices.SortedFunc(slices.Values(vols), func(a, b volumes.Volume) int { return cmp.Compare(a.ID, b.ID) })
+	for _, vol := range sorted {
+		cfg := policy.QuiesceHookConfig{}
+		if err := cfg.ParseFromMetadata(vol.Metadata); err != nil {
+			logger.Warn("Quiesce hook configuration of the volume is invalid", "volume_id", vol.ID, "error", err)
+			continue
+		}
+		if cfg.IsConfigured() {
+			logger.Debug("Quiesce hooks configured on a volume", "volume_id", vol.ID, "pre", cfg.Pre, "post", cfg.Post)
+			return &quiesceHooks{runner: runner, config: cfg}
+		}
+	}
+
+	return nil
+}
+
+// around calls create between the pre and the post hook.
+//
+// Behavior:
+//   - Pre Hook: If it fails, create is not called and the error is returned as preErr.
+//   - Post Hook: Always runs once the pre hook was attempted, also after a failed pre hook (to undo a partially
+//     applied one, e.g. thaw file systems) or a cancelled ctx. It has its own timeout.
+func (q *quiesceHooks) around(ctx context.Context, target hooks.Target, logger *slog.Logger, create func()) (preErr error, postErr error) {
+	timeout := q.config.Timeout()
+
+	defer func() {
+		target.Phase = hooks.PhasePost
+		logger.Debug("Running post-snapshot hook", "hook", q.config.Post)
+		postErr = q.runner.Run(context.WithoutCancel(ctx), q.config.Post, timeout, target)
+	}()
+
+	target.Phase = hooks.PhasePre
+	logger.Debug("Running pre-snapshot hook", "hook", q.config.Pre, "timeout", timeout)
+	if preErr = q.runner.Run(ctx, q.config.Pre, timeout, target); preErr != nil {
+		return preErr, nil
+	}
+
+	create()
+	return nil, nil
+}
@@ -0,0 +1,252 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/policy"
+	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/volumes"
+)
+
+// PolicyView is a snapshot policy of a volume after normalization, as shown by `policy show`.
+// Windows are computed without snapshot history, so they describe the schedule only.
+type PolicyView struct {
+	PolicyType      string            `json:"policy_type"`
+	Enabled         bool              `json:"enabled"`
+	Schedule        string            `json:"schedule,omitempty"`
+	TimeZone        string            `json:"timezone,omitempty"`
+	Retention       string            `json:"retention,omitempty"`
+	WindowStart     time.Time         `json:"window_start"`
+	WindowEnd       time.Time         `json:"window_end"`
+	NextWindowStart time.Time         `json:"next_window_start"`
+	Error           string            `json:"error,omitempty"`
+	Metadata        map[string]string `json:"metadata"`
+}
+
+// VolumePolicies lists the snapshot policies and the other SnapSentry settings of one volume.
+type VolumePolicies struct {
+	VolumeID   string       `json:"volume_id"`
+	VolumeName string       `json:"volume_name"`
+	Status     string       `json:"status"`
+	ServerID   string       `json:"server_id,omitempty"`
+	Managed    bool         `json:"managed"`
+	Policies   []PolicyView `json:"policies"`
+	Settings   []string     `json:"settings,omitempty"`
+}
+
+// ShowVolumePolicies prints every snapshot policy configured on a volume, normalized, with its current and next window.
+func ShowVolumePolicies(cloudName, logLevel, volID, outputFormat string) error {
+	logger := SetupLogger(logLevel, cloudName).With("workflow", "policy-show", "volume_id", volID)
+
+	client, err := initClient(cloudName, logLevel)
+	if err != nil {
+		return err
+	}
+
+	vol, err := client.GetVolume(context.Background(), volID)
+	if err != nil {
+		logger.Error("Failed to fetch volume", "error", err)
+		return err
+	}
+
+	view := describeVolumePolicies(vol, time.Now())
+	return renderListing(os.Stdout, outputFormat, view, func(w io.Writer) error {
+		info := newStyledTable("VOLUME ID", "VOLUME NAME", "STATUS", "SERVER ID", "MANAGED", "SETTINGS")
+		info.Row(view.VolumeID, view.VolumeName, view.Status, view.ServerID, strconv.FormatBool(view.Managed), strings.Join(view.Settings, ", "))
+		if _, err := fmt.Fprintln(w, info); err != nil {
+			return err
+		}
+
+		t := newStyledTable("POLICY", "ENABLED", "SCHEDULE", "TIMEZONE", "RETENTION", "CURRENT WINDOW", "NEXT WINDOW", "ERROR")
+		for _, p := range view.Policies {
+			var current string
+			if !p.WindowStart.IsZero() {
+				current = formatReportTime(p.WindowStart) + " - " + formatReportTime(p.WindowEnd)
+			}
+			t.Row(p.PolicyType, strconv.FormatBool(p.Enabled), p.Schedule, p.TimeZone, p.Retention, current, formatReportTime(p.NextWindowStart), p.Error)
+		}
+		_, err := fmt.Fprintln(w, t)
+		return err
+	})
+}
+
+// ListVolumePolicies prints every subscribed volume of the project with a compact summary of its policies.
+func ListVolumePolicies(cloudName, logLevel, outputFormat string) error {
+	logger := SetupLogger(logLevel, cloudName).With("workflow", "policy-list")
+
+	client, err := initClient(cloudName, logLevel)
+	if err != nil {
+		return err
+	}
+
+	vols, err := client.ListSubscribedVolumes(context.Background())
+	if err != nil {
+		logger.Error("Volume discovery failed", "error", err)
+		return err
+	}
+	logger.Debug("Found subscribed volumes", "count", len(vols))
+
+	now := time.Now()
+	views := make([]VolumePolicies, 0, len(vols))
+	for _, vol := range vols {
+		views = append(views, describeVolumePolicies(vol, now))
+	}
+
+	return renderListing(os.Stdout, outputFormat, views, func(w io.Writer) error {
+		t := newStyledTable("VOLUME ID", "VOLUME NAME", "STATUS", "SERVER ID", "POLICIES", "SETTINGS")
+		for _, v := range views {
+			var summary []string
+			for _, p := range v.Policies {
+				switch {
+				case p.Error != "":
+					summary = append(summary, p.PolicyType+" (invalid)")
+				case p.Enabled:
+					summary = append(summary, fmt.Sprintf("%s %s (%s)", p.PolicyType, p.Schedule, p.Retention))
+				}
+			}
+			t.Row(v.VolumeID, v.VolumeName, v.Status, v.ServerID, strings.Join(summary, "\n"), strings.Join(v.Settings, ", "))
+		}
+		_, err := fmt.Fprintln(w, t)
+		return err
+	})
+}
+
+// describeVolumePolicies collects the snapshot policies configured on a volume (enabled or not) and its other settings.
+func describeVolumePolicies(vol volumes.Volume, now time.Time) VolumePolicies {
+	view := VolumePolicies{
+		VolumeID:   vol.ID,
+		VolumeName: vol.Name,
+		Status:     vol.Status,
+		Managed:    vol.Metadata[policy.ManagedTag] == "true",
+		Policies:   []PolicyView{},
+	}
+	if len(vol.Attachments) > 0 {
+		view.ServerID = vol.Attachments[0].ServerID
+	}
+
+	// Same order as the evaluation in processVolume.
+	policies := []policy.SnapshotPolicy{
+		&policy.SnapshotPolicyExpress{},
+		&policy.SnapshotPolicyCron{},
+		&policy.SnapshotPolicyDaily{},
+		&policy.SnapshotPolicyWeekly{},
+		&policy.SnapshotPolicyMonthly{},
+	}
+
+	for _, p := range policies {
+		policyType := p.GetPolicyType()
+		configured := slices.ContainsFunc(policy.SubscriptionMetadataKeys(policyType), func(key string) bool {
+			_, ok := vol.Metadata[key]
+			return ok
+		})
+		if !configured {
+			continue
+		}
+
+		pv := PolicyView{PolicyType: policyType}
+		if err := p.ParseFromMetadata(vol.Metadata); err != nil {
+			pv.Error = err.Error()
+			view.Policies = append(view.Policies, pv)
+			continue
+		}
+		pv.Enabled = p.IsEnabled()
+
+		if err := p.Normalize(); err != nil {
+			pv.Error = err.Error()
+			view.Policies = append(view.Policies, pv)
+			continue
+		}
+		pv.Schedule, pv.TimeZone, pv.Retention = describePolicy(p)
+		pv.Metadata = p.ToOpenstackMetadata()
+		delete(pv.Metadata, policy.ManagedTag)
+
+		if pv.Enabled {
+			result, err := p.Evaluate(now, policy.LastSnapshotInfo{})
+			if err != nil {
+				pv.Error = err.Error()
+			} else {
+				pv.WindowStart = result.Window.StartTime
+				pv.WindowEnd = result.Window.EndTime
+				// Windows are contiguous: the next one opens when the current one closes.
+				pv.NextWindowStart = result.Window.EndTime
+			}
+		}
+		view.Policies = append(view.Policies, pv)
+	}
+
+	view.Settings = describeVolumeSettings(vol.Metadata)
+	return view
+}
+
+// describePolicy summarizes the schedule, timezone and retention of a normalized policy.
+func describePolicy(p policy.SnapshotPolicy) (schedule, timeZone, retention string) {
+	var retentionType string
+	var retentionCount int
+
+	switch s := p.(type) {
+	case *policy.SnapshotPolicyExpress:
+		if s.IntervalMinutes > 0 {
+			schedule = fmt.Sprintf("every %dm from %s", s.IntervalMinutes, s.StartTime)
+		} else {
+			schedule = fmt.Sprintf("every %dh from %s", s.IntervalHours, s.StartTime)
+		}
+		timeZone, retentionType, retentionCount = s.TimeZone, s.RetentionType, s.RetentionCount
+	case *policy.SnapshotPolicyCron:
+		schedule = "'" + s.Expression + "'"
+		timeZone, retentionType, retentionCount = s.TimeZone, s.RetentionType, s.RetentionCount
+	case *policy.SnapshotPolicyDaily:
+		schedule = "at " + s.StartTime
+		timeZone, retentionType, retentionCount = s.TimeZone, s.RetentionType, s.RetentionCount
+	case *policy.SnapshotPolicyWeekly:
+		schedule = fmt.Sprintf("%s at %s", s.DayOfWeek, s.StartTime)
+		timeZone, retentionType, retentionCount = s.TimeZone, s.RetentionType, s.RetentionCount
+	case *policy.SnapshotPolicyMonthly:
+		schedule = fmt.Sprintf("day %d at %s", s.DayOfMonth, s.StartTime)
+		timeZone, retentionType, retentionCount = s.TimeZone, s.RetentionType, s.RetentionCount
+	}
+
+	if retentionType == policy.RetentionTypeCount {
+		retention = fmt.Sprintf("last %d", retentionCount)
+	} else {
+		retention = fmt.Sprintf("%dd", p.GetPolicyRetention())
+	}
+	return schedule, timeZone, retention
+}
+
+// describeVolumeSettings summarizes the volume level settings that are not snapshot policies.
+func describeVolumeSettings(metadata map[string]string) []string {
+	var settings []string
+
+	gfs := policy.GFSConfig{}
+	if err := gfs.ParseFromMetadata(metadata); err == nil && gfs.Enabled {
+		settings = append(settings, "gfs")
+	}
+
+	group := policy.GroupSnapshotConfig{}
+	if err := group.ParseFromMetadata(metadata); err == nil && group.Enabled {
+		settings = append(settings, "group-snapshot ("+group.EffectiveGroupType()+")")
+	}
+
+	hooks := policy.QuiesceHookConfig{}
+	if err := hooks.ParseFromMetadata(metadata); err == nil && hooks.IsConfigured() {
+		settings = append(settings, "hooks")
+	}
+
+	pause := policy.PauseConfig{}
+	if err := pause.ParseFromMetadata(metadata); err == nil && pause.IsActive(time.Now()) {
+		settings = append(settings, pause.Describe())
+	}
+
+	backup := policy.BackupConfig{}
+	if err := backup.ParseFromMetadata(metadata); err == nil && backup.Enabled {
+		settings = append(settings, fmt.Sprintf("backup %s (%dd)", backup.PolicyType, backup.RetentionDays))
+	}
+
+	return settings
+}
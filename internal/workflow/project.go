@@ -9,8 +9,6 @@ import (
 	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/cloud"
 	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/cloud/openstack"
 	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/notifications"
-	"github.com/charmbracelet/lipgloss"
-	"github.com/charmbracelet/lipgloss/table"
 	"github.com/google/uuid"
 )
 
@@ -60,31 +58,7 @@ func RunAdminProjectDisoceryWorkflow(cloudName string, timeoutSeconds int, notif
 		return fmt.Errorf("listing project failed: %w", err)
 	}
 
-	var (
-		purple    = lipgloss.Color("99")
-		gray      = lipgloss.Color("245")
-		lightGray = lipgloss.Color("241")
-
-		headerStyle  = lipgloss.NewStyle().Foreground(purple).Bold(true).Align(lipgloss.Center)
-		cellStyle    = lipgloss.NewStyle().Padding(0, 1)
-		oddRowStyle  = cellStyle.Foreground(gray)
-		evenRowStyle = cellStyle.Foreground(lightGray)
-	)
-
-	t := table.New().
-		Border(lipgloss.NormalBorder()).
-		BorderStyle(lipgloss.NewStyle().Foreground(purple)).
-		StyleFunc(func(row, col int) lipgloss.Style {
-			switch {
-			case row == table.HeaderRow:
-				return headerStyle
-			case row%2 == 0:
-				return evenRowStyle
-			default:
-				return oddRowStyle
-			}
-		}).
-		Headers("PROJECT ID", "PROJECT NAME", "DOMAIN ID", "TAGS")
+	t := newStyledTable("PROJECT ID", "PROJECT NAME", "DOMAIN ID", "TAGS")
 
 	logger.Info("Fetched subscribed projects", "count", len(subedProjects))
 	for _, i := range subedProjects {
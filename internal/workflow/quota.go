@@ -0,0 +1,205 @@
+package workflow
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/cloud/openstack"
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/metrics"
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/notifications"
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/policy"
+	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/quotasets"
+	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/volumes"
+)
+
+// checkSnapshotQuota reads the block storage quota of the project once per run and records the quota left for
+// new snapshots on the report, where createPolicySnapshot and createGroupPolicySnapshot reserve from it.
+//
+// The space needed by the run is estimated as one snapshot of every subscribed volume (its size in gigabytes).
+// If that does not fit, the run goes on and volumes are served by priority (policy.PriorityTag, see
+// orderQuotaReservations) until the quota is used up. If the quota cannot be read, the quota is treated as
+// unlimited until Cinder rejects a snapshot for exceeding it.
+//
+// Returns the quota usage, for the QuotaExhausted notification.
+func checkSnapshotQuota(ctx context.Context, client *openstack.Client, vols []volumes.Volume, report *RunReport, logger *slog.Logger) quotasets.QuotaUsageSet {
+	usage, reqID, err := client.GetBlockStorageQuotaUsage(ctx)
+	if err != nil {
+		logger.Warn("Snapshot quota check failed; creating snapshots until the quota is exceeded", "error", err, "request_id", reqID)
+		report.quota = policy.NewSnapshotQuota(-1, 0, -1, 0)
+		return quotasets.QuotaUsageSet{Snapshots: quotasets.QuotaUsage{Limit: -1}, Gigabytes: quotasets.QuotaUsage{Limit: -1}}
+	}
+
+	report.quota = policy.NewSnapshotQuota(
+		usage.Snapshots.Limit, usage.Snapshots.InUse+usage.Snapshots.Reserved,
+		usage.Gigabytes.Limit, usage.Gigabytes.InUse+usage.Gigabytes.Reserved,
+	)
+
+	estimatedSnapshots, estimatedGigabytes := estimateSnapshotQuota(vols)
+	quotaLogger := logger.With(
+		"snapshot_limit", usage.Snapshots.Limit,
+		"snapshots_in_use", usage.Snapshots.InUse,
+		"gigabytes_limit", usage.Gigabytes.Limit,
+		"gigabytes_in_use", usage.Gigabytes.InUse,
+		"estimated_snapshots", estimatedSnapshots,
+		"estimated_gigabytes", estimatedGigabytes,
+	)
+	if report.quota.Fits(estimatedSnapshots, estimatedGigabytes) {
+		quotaLogger.Debug("Snapshot quota covers the run")
+	} else {
+		quotaLogger.Warn("Snapshot quota may not cover every due snapshot; volumes are snapshotted by priority")
+	}
+	return usage
+}
+
+// estimateSnapshotQuota estimates the quota a run needs: one snapshot of every volume, of the volume's size.
+func estimateSnapshotQuota(vols []volumes.Volume) (snapshots int, gigabytes int) {
+	for _, vol := range vols {
+		gigabytes += vol.Size
+	}
+	return len(vols), gigabytes
+}
+
+// orderQuotaReservations sorts the volume groups by priority (sortGroupsByPriority) and, if the snapshot quota
+// may not cover the run (estimateSnapshotQuota), makes the groups reserve the quota in that order
+// (see quotaOrder). A quota that covers the run needs no ordering, so the workers do not wait for each other.
+func orderQuotaReservations(groups []volumeGroup, report *RunReport) {
+	sortGroupsByPriority(groups)
+
+	var vols []volumes.Volume
+	for _, g := range groups {
+		vols = append(vols, g.vols...)
+	}
+	if !report.quota.Fits(estimateSnapshotQuota(vols)) {
+		report.quotaOrder = newQuotaOrder(groups)
+	}
+}
+
+// sortGroupsByPriority orders the volume groups by their highest volume priority (policy.PriorityTag), highest
+// first, so that they reserve the snapshot quota first. Groups of equal priority keep their order.
+func sortGroupsByPriority(groups []volumeGroup) {
+	priority := func(g volumeGroup) int {
+		p := 0
+		for i, vol := range g.vols {
+			if vp := policy.VolumePriority(vol.Metadata); i == 0 || vp > p {
+				p = vp
+			}
+		}
+		return p
+	}
+	slices.SortStableFunc(groups, func(a, b volumeGroup) int { return cmp.Compare(priority(b), priority(a)) })
+}
+
+// quotaOrder makes the volume groups of a run reserve the snapshot quota in their order, even though they are
+// processed by several workers: a volume reserves only once every group before its own finished processing.
+// Groups are handed to the workers in order, so the first unfinished group never waits and the run cannot stall.
+//
+// A nil quotaOrder does not order reservations.
+type quotaOrder struct {
+	mu       sync.Mutex
+	finished *sync.Cond
+	rank     map[string]int // volume ID → index of its group
+	done     []bool
+	next     int // first group that has not finished
+}
+
+// newQuotaOrder returns the reservation order of the given groups.
+func newQuotaOrder(groups []volumeGroup) *quotaOrder {
+	o := &quotaOrder{rank: map[string]int{}, done: make([]bool, len(groups))}
+	o.finished = sync.NewCond(&o.mu)
+	for i, g := range groups {
+		for _, vol := range g.vols {
+			o.rank[vol.ID] = i
+		}
+	}
+	return o
+}
+
+// await blocks until every group before the group of the volume finished. Unknown volumes do not wait.
+func (o *quotaOrder) await(volumeID string) {
+	if o == nil {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	rank, ok := o.rank[volumeID]
+	if !ok {
+		return
+	}
+	for o.next < rank {
+		o.finished.Wait()
+	}
+}
+
+// finish marks the group at index rank as finished, whether it reserved anything or not.
+func (o *quotaOrder) finish(rank int) {
+	if o == nil {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.done[rank] = true
+	for o.next < len(o.done) && o.done[o.next] {
+		o.next++
+	}
+	o.finished.Broadcast()
+}
+
+// recordQuotaExceeded records a due snapshot that was not created because the project quota is exhausted.
+// It is reported once per run by notifyQuotaExhausted instead of a failure notification per volume.
+func recordQuotaExceeded(vol volumes.Volume, policyType string, outcome SnapshotOutcome, reason string, report *RunReport, policyLogger *slog.Logger) {
+	outcome.Outcome = OutcomeQuotaExceeded
+	outcome.Reason = reason
+	report.addSnapshot(outcome)
+	metrics.SnapshotsQuotaExceeded.WithLabelValues(policyType).Inc()
+
+	policyLogger.Warn("Snapshot not created; project quota exhausted",
+		"volume_size_gb", vol.Size,
+		"priority", policy.VolumePriority(vol.Metadata),
+		"reason", reason,
+	)
+}
+
+// notifyQuotaExhausted sends a single QuotaExhausted notification if the run recorded any quota_exceeded outcome.
+func notifyQuotaExhausted(ctx context.Context, client *openstack.Client, usage quotasets.QuotaUsageSet, notifyProvider notifications.Notifier, report *RunReport, logger *slog.Logger) {
+	report.mu.Lock()
+	var volumeIDs []string
+	skipped := 0
+	for _, s := range report.Snapshots {
+		if s.Outcome != OutcomeQuotaExceeded {
+			continue
+		}
+		skipped++
+		if !slices.Contains(volumeIDs, s.VolumeID) {
+			volumeIDs = append(volumeIDs, s.VolumeID)
+		}
+	}
+	report.mu.Unlock()
+
+	if skipped == 0 {
+		return
+	}
+	slices.Sort(volumeIDs)
+
+	logger.Error("Snapshot quota exhausted; due snapshots were not created",
+		"snapshots_skipped", skipped,
+		"volume_count", len(volumeIDs))
+	sendNotification(ctx, notifyProvider, notifications.QuotaExhausted{
+		Service:          "snapsentry",
+		ProjectID:        client.ProjectID,
+		RunID:            report.RunID,
+		SnapshotLimit:    usage.Snapshots.Limit,
+		SnapshotsInUse:   usage.Snapshots.InUse,
+		GigabytesLimit:   usage.Gigabytes.Limit,
+		GigabytesInUse:   usage.Gigabytes.InUse,
+		SnapshotsSkipped: skipped,
+		VolumeIDs:        volumeIDs,
+		Message: fmt.Sprintf("%d due snapshot(s) of %d volume(s) were not created. Raise the quota, or let expire-snapshots free space; volumes with a higher %s are served first.",
+			skipped, len(volumeIDs), policy.PriorityTag),
+	}, logger)
+}
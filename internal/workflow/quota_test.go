@@ -0,0 +1,174 @@
+package workflow
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/policy"
+	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/volumes"
+)
+
+// priorityVolume builds a volume of the given size with the given priority (policy.PriorityTag); 0 leaves it unset.
+func priorityVolume(id string, size int, priority int) volumes.Volume {
+	vol := volumes.Volume{ID: id, Size: size, Metadata: map[string]string{}}
+	if priority != 0 {
+		vol.Metadata[policy.PriorityTag] = strconv.Itoa(priority)
+	}
+	return vol
+}
+
+func groupIDs(groups []volumeGroup) []string {
+	ids := []string{}
+	for _, g := range groups {
+		ids = append(ids, g.vols[0].ID)
+	}
+	return ids
+}
+
+func TestSortGroupsByPriority(t *testing.T) {
+	tests := []struct {
+		name   string
+		groups []volumeGroup
+		want   []string
+	}{
+		{
+			name: "Highest First",
+			groups: []volumeGroup{
+				{vols: []volumes.Volume{priorityVolume("low", 10, -5)}},
+				{vols: []volumes.Volume{priorityVolume("default", 10, 0)}},
+				{vols: []volumes.Volume{priorityVolume("high", 10, 100)}},
+			},
+			want: []string{"high", "default", "low"},
+		},
+		{
+			name: "Equal Priority Keeps Order",
+			groups: []volumeGroup{
+				{vols: []volumes.Volume{priorityVolume("a", 10, 0)}},
+				{vols: []volumes.Volume{priorityVolume("b", 10, 0)}},
+				{vols: []volumes.Volume{priorityVolume("c", 10, 0)}},
+			},
+			want: []string{"a", "b", "c"},
+		},
+		{
+			name: "VM Uses Its Highest Volume",
+			groups: []volumeGroup{
+				{vols: []volumes.Volume{priorityVolume("single", 10, 50)}},
+				{vmID: "vm-1", vols: []volumes.Volume{priorityVolume("vm-root", 10, 0), priorityVolume("vm-data", 10, 80)}},
+			},
+			want: []string{"vm-root", "single"},
+		},
+		{
+			name: "Negative Only VM",
+			groups: []volumeGroup{
+				{vmID: "vm-1", vols: []volumes.Volume{priorityVolume("vm-root", 10, -10), priorityVolume("vm-data", 10, -3)}},
+				{vols: []volumes.Volume{priorityVolume("single", 10, -5)}},
+			},
+			want: []string{"vm-root", "single"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sortGroupsByPriority(tt.groups)
+			if got := groupIDs(tt.groups); !slices.Equal(got, tt.want) {
+				t.Errorf("sortGroupsByPriority() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEstimateSnapshotQuota(t *testing.T) {
+	vols := []volumes.Volume{priorityVolume("a", 100, 0), priorityVolume("b", 50, 0), priorityVolume("c", 10, 0)}
+
+	tests := []struct {
+		name  string
+		quota *policy.SnapshotQuota
+		want  bool
+	}{
+		{name: "Unlimited", quota: policy.NewSnapshotQuota(-1, 0, -1, 0), want: true},
+		{name: "Exact Fit", quota: policy.NewSnapshotQuota(10, 7, 1000, 840), want: true},
+		{name: "Snapshot Limit", quota: policy.NewSnapshotQuota(10, 8, -1, 0), want: false},
+		{name: "Gigabytes Limit", quota: policy.NewSnapshotQuota(-1, 0, 1000, 841), want: false},
+	}
+
+	snapshots, gigabytes := estimateSnapshotQuota(vols)
+	if snapshots != 3 || gigabytes != 160 {
+		t.Fatalf("estimateSnapshotQuota() = %d, %d, want 3, 160", snapshots, gigabytes)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.quota.Fits(snapshots, gigabytes); got != tt.want {
+				t.Errorf("Fits(%d, %d) = %v, want %v", snapshots, gigabytes, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderQuotaReservations(t *testing.T) {
+	tests := []struct {
+		name      string
+		quota     *policy.SnapshotQuota
+		wantOrder bool
+	}{
+		{name: "Quota Covers Run", quota: policy.NewSnapshotQuota(10, 0, -1, 0), wantOrder: false},
+		{name: "Quota Short", quota: policy.NewSnapshotQuota(2, 0, -1, 0), wantOrder: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups := []volumeGroup{
+				{vols: []volumes.Volume{priorityVolume("low", 10, 0)}},
+				{vols: []volumes.Volume{priorityVolume("high", 10, 10)}},
+				{vols: []volumes.Volume{priorityVolume("mid", 10, 5)}},
+			}
+			report := &RunReport{quota: tt.quota}
+			orderQuotaReservations(groups, report)
+
+			if got := groupIDs(groups); !slices.Equal(got, []string{"high", "mid", "low"}) {
+				t.Errorf("groups = %v, want priority order", got)
+			}
+			if got := report.quotaOrder != nil; got != tt.wantOrder {
+				t.Errorf("reservation order installed = %v, want %v", got, tt.wantOrder)
+			}
+		})
+	}
+}
+
+// TestQuotaOrder_Concurrent reserves from a short quota on several workers, with the high priority groups being the
+// slowest to get to their reservation, and checks that the quota still goes to the highest priorities.
+func TestQuotaOrder_Concurrent(t *testing.T) {
+	const concurrency = 4
+
+	var groups []volumeGroup
+	for i := range 8 {
+		groups = append(groups, volumeGroup{vols: []volumes.Volume{priorityVolume("vol-"+strconv.Itoa(i), 10, i)}})
+	}
+	report := &RunReport{quota: policy.NewSnapshotQuota(3, 0, -1, 0)}
+	orderQuotaReservations(groups, report)
+	if report.quotaOrder == nil {
+		t.Fatalf("reservation order not installed for a short quota")
+	}
+
+	var mu sync.Mutex
+	var reserved []string
+
+	runWorkers(context.Background(), len(groups), concurrency, func(i int) {
+		vol := groups[i].vols[0]
+		time.Sleep(time.Duration(len(groups)-i) * time.Millisecond)
+		if report.reserveQuota(vol.ID, 1, vol.Size) {
+			mu.Lock()
+			reserved = append(reserved, vol.ID)
+			mu.Unlock()
+		}
+		report.quotaOrder.finish(i)
+	})
+
+	if want := []string{"vol-7", "vol-6", "vol-5"}; !slices.Equal(reserved, want) {
+		t.Errorf("reserved = %v, want %v", reserved, want)
+	}
+}
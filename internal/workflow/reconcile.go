@@ -0,0 +1,443 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/cloud/openstack"
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/metrics"
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/notifications"
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/policy"
+	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/snapshots"
+	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/volumes"
+)
+
+// defaultCreationTimeout is how long a created snapshot may take to become available when
+// RunOptions.CreationTimeout is not set.
+const defaultCreationTimeout = 10 * time.Minute
+
+// creationTimeouts resolves the creation timeout of a volume: an override for its volume type, then for its
+// backend, then the fallback.
+type creationTimeouts struct {
+	fallback  time.Duration
+	overrides map[string]time.Duration
+}
+
+// creationTimeouts parses RunOptions.CreationTimeout and RunOptions.CreationTimeouts.
+// Overrides are given as '<volume type|backend>=<duration>' and must be positive.
+func (o RunOptions) creationTimeouts() (creationTimeouts, error) {
+	if o.CreationTimeout < 0 {
+		return creationTimeouts{}, fmt.Errorf("invalid creation timeout %s; must be 0 (default) or greater", o.CreationTimeout)
+	}
+
+	timeouts := creationTimeouts{fallback: o.CreationTimeout, overrides: map[string]time.Duration{}}
+	if timeouts.fallback == 0 {
+		timeouts.fallback = defaultCreationTimeout
+	}
+	for _, entry := range o.CreationTimeouts {
+		name, value, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return creationTimeouts{}, fmt.Errorf("invalid creation timeout '%s'; must be '<volume type|backend>=<duration>'", entry)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || d <= 0 {
+			return creationTimeouts{}, fmt.Errorf("invalid creation timeout '%s'; duration must be greater than 0", entry)
+		}
+		timeouts.overrides[name] = d
+	}
+	return timeouts, nil
+}
+
+// forVolume returns the creation timeout of a volume.
+func (t creationTimeouts) forVolume(vol volumes.Volume) time.Duration {
+	if d, ok := t.overrides[vol.VolumeType]; ok && vol.VolumeType != "" {
+		return d
+	}
+	if d, ok := t.overrides[volumeBackend(vol.Host)]; ok {
+		return d
+	}
+	return t.fallback
+}
+
+// volumeBackend returns the backend name of a Cinder volume host ('host@backend#pool').
+// Empty when the host is unknown, e.g. for users without the admin host attribute.
+func volumeBackend(host string) string {
+	_, backend, ok := strings.Cut(host, "@")
+	if !ok {
+		return ""
+	}
+	backend, _, _ = strings.Cut(backend, "#")
+	return backend
+}
+
+// inflightSnapshot is a snapshot whose create request was accepted by createPolicySnapshot, or a member of a
+// group snapshot accepted by createGroupPolicySnapshot, and whose final status is resolved by
+// reconcileCreatedSnapshots.
+type inflightSnapshot struct {
+	vol             volumes.Volume
+	policyType      string
+	result          policy.PolicyEvalResult
+	snapshotID      string
+	groupSnapshotID string
+	startedAt       time.Time
+	outcome         SnapshotOutcome
+	logger          *slog.Logger
+}
+
+// reconcileCreatedSnapshots waits for the snapshots created in this run to leave the "creating" state.
+//
+// Each snapshot is awaited in parallel until its creation timeout (see creationTimeouts), counted from
+// its create request:
+//   - available: recorded as created and notified (SnapshotCreated).
+//   - any other final status: handled as a failed creation by failPolicySnapshot (orphan cleanup).
+//   - still creating: recorded as pending and marked with policy.PendingTag, so that the next snapshot run
+//     confirms or cleans it up (see reconcilePreviousSnapshots). It already satisfies its window, so the
+//     next run does not create a second one.
+//
+// The members of a group snapshot are resolved together by reconcileGroupSnapshot.
+func reconcileCreatedSnapshots(
+	ctx context.Context,
+	client *openstack.Client,
+	timeouts creationTimeouts,
+	notifyProvider notifications.Notifier,
+	report *RunReport,
+	logger *slog.Logger,
+) {
+	inflight := report.takeInflight()
+	if len(inflight) == 0 {
+		return
+	}
+	logger.Info("Waiting for created snapshots to become available", "snapshot_count", len(inflight))
+
+	var single []inflightSnapshot
+	var groupIDs []string
+	groups := map[string][]inflightSnapshot{}
+	for _, s := range inflight {
+		if s.groupSnapshotID == "" {
+			single = append(single, s)
+			continue
+		}
+		if _, ok := groups[s.groupSnapshotID]; !ok {
+			groupIDs = append(groupIDs, s.groupSnapshotID)
+		}
+		groups[s.groupSnapshotID] = append(groups[s.groupSnapshotID], s)
+	}
+
+	var reconcileWaitGroup sync.WaitGroup
+	for _, s := range single {
+		reconcileWaitGroup.Add(1)
+		go func() {
+			defer reconcileWaitGroup.Done()
+			deadline := s.startedAt.Add(timeouts.forVolume(s.vol))
+			waitCtx, cancel := context.WithDeadline(ctx, deadline)
+			defer cancel()
+
+			snap, err := client.AwaitSnapshot(waitCtx, s.snapshotID)
+			switch resolveCreatedSnapshot(snap.Status, err) {
+			case resolvePending:
+				markPendingSnapshot(ctx, client, s, err, timeouts.forVolume(s.vol), report)
+			case resolveCreated:
+				confirmCreatedSnapshot(ctx, s.vol, s.policyType, s.result, s.outcome, notifyProvider, report, s.logger)
+			default:
+				_ = failPolicySnapshot(ctx, client, s.vol, s.policyType, s.result.Window, s.snapshotID,
+					fmt.Errorf("snapshot %s ended in status %s", s.snapshotID, snap.Status), s.outcome, notifyProvider, report, s.logger)
+			}
+		}()
+	}
+	for _, groupSnapshotID := range groupIDs {
+		reconcileWaitGroup.Add(1)
+		go func() {
+			defer reconcileWaitGroup.Done()
+			reconcileGroupSnapshot(ctx, client, groupSnapshotID, groups[groupSnapshotID], timeouts, notifyProvider, report, logger)
+		}()
+	}
+	reconcileWaitGroup.Wait()
+}
+
+// snapshotResolution is how reconcileCreatedSnapshots resolves a snapshot (or group snapshot) of this run.
+type snapshotResolution int
+
+const (
+	resolvePending snapshotResolution = iota // Still creating after its creation timeout; left to the next run.
+	resolveCreated                           // Available.
+	resolveFailed                            // Any other final status; cleaned up as a failed creation.
+)
+
+// resolveCreatedSnapshot decides how a snapshot is resolved from the last seen status and the error of awaiting it.
+// Awaiting only fails once the creation timeout passed (or the run was cancelled), so any error leaves it pending.
+func resolveCreatedSnapshot(status string, waitErr error) snapshotResolution {
+	switch {
+	case waitErr != nil:
+		return resolvePending
+	case status == policy.SnapshotStatusAvailable:
+		return resolveCreated
+	default:
+		return resolveFailed
+	}
+}
+
+// reconcileGroupSnapshot waits for a group snapshot created in this run, until the longest creation timeout of its
+// members, and resolves its members like reconcileCreatedSnapshots. A group snapshot that ends in any status other
+// than "available" is deleted with all of its members (cleanupGroupSnapshot), since members cannot be deleted
+// on their own.
+func reconcileGroupSnapshot(
+	ctx context.Context,
+	client *openstack.Client,
+	groupSnapshotID string,
+	members []inflightSnapshot,
+	timeouts creationTimeouts,
+	notifyProvider notifications.Notifier,
+	report *RunReport,
+	logger *slog.Logger,
+) {
+	var timeout time.Duration
+	for _, s := range members {
+		timeout = max(timeout, timeouts.forVolume(s.vol))
+	}
+	waitCtx, cancel := context.WithDeadline(ctx, members[0].startedAt.Add(timeout))
+	defer cancel()
+
+	groupLogger := logger.With("group_snapshot_id", groupSnapshotID)
+	groupSnap, err := client.AwaitGroupSnapshot(waitCtx, groupSnapshotID)
+	switch resolveCreatedSnapshot(groupSnap.Status, err) {
+	case resolvePending:
+		for _, s := range members {
+			markPendingSnapshot(ctx, client, s, err, timeout, report)
+		}
+	case resolveCreated:
+		for _, s := range members {
+			// Refreshes the member in the snapshot index, e.g. for the backup export of this run.
+			if _, err := client.AwaitSnapshot(ctx, s.snapshotID); err != nil {
+				s.logger.Warn("Failed to refresh the member snapshot of a group snapshot", "snapshot_id", s.snapshotID, "error", err)
+			}
+			confirmCreatedSnapshot(ctx, s.vol, s.policyType, s.result, s.outcome, notifyProvider, report, s.logger)
+		}
+	default:
+		cause := fmt.Errorf("group snapshot %s ended in status %s", groupSnapshotID, groupSnap.Status)
+		groupLogger.Error("Group snapshot creation failed", "error", cause)
+		delReqID, cleanupErr := cleanupGroupSnapshot(ctx, client, groupSnapshotID, groupLogger)
+		_ = failGroupPolicySnapshot(ctx, groupSnapshotID, members, cause, delReqID, cleanupErr, notifyProvider, report)
+	}
+}
+
+// awaitQuiescedSnapshots waits, at most timeout, for the snapshots created in this run for the given volumes to
+// leave the "creating" state, so that a post-snapshot hook does not release the guest before the backend took them.
+// Their outcome is still recorded by reconcileCreatedSnapshots.
+func awaitQuiescedSnapshots(ctx context.Context, client *openstack.Client, report *RunReport, volumeIDs []string, timeout time.Duration, logger *slog.Logger) {
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var awaitWaitGroup sync.WaitGroup
+	for _, s := range report.inflightFor(volumeIDs) {
+		awaitWaitGroup.Add(1)
+		go func() {
+			defer awaitWaitGroup.Done()
+			if _, err := client.AwaitSnapshot(waitCtx, s.snapshotID); err != nil {
+				logger.Warn("Snapshot not taken before the post-snapshot hook; it may not be application consistent",
+					"volume_id", s.vol.ID,
+					"snapshot_id", s.snapshotID,
+					"error", err,
+				)
+			}
+		}()
+	}
+	awaitWaitGroup.Wait()
+}
+
+// confirmCreatedSnapshot records a snapshot that reached "available" as created.
+func confirmCreatedSnapshot(
+	ctx context.Context,
+	vol volumes.Volume,
+	policyType string,
+	result policy.PolicyEvalResult,
+	outcome SnapshotOutcome,
+	notifyProvider notifications.Notifier,
+	report *RunReport,
+	policyLogger *slog.Logger,
+) {
+	outcome.Outcome = OutcomeCreated
+	report.addSnapshot(outcome)
+	metrics.SnapshotsCreated.WithLabelValues(policyType).Inc()
+
+	policyLogger.Info("Snapshot resource successfully created",
+		"snapshot_id", outcome.SnapshotID,
+		"request_id", outcome.RequestID,
+	)
+	sendNotification(ctx, notifyProvider, notifications.SnapshotCreated{
+		Service:      "snapsentry",
+		VolumeID:     vol.ID,
+		VolumeName:   vol.Name,
+		PolicyType:   policyType,
+		SnapshotID:   outcome.SnapshotID,
+		SnapshotName: outcome.SnapshotName,
+		ExpiryDate:   result.Metadata.ExpiryDate,
+		Window:       result.Window,
+	}, policyLogger)
+}
+
+// markPendingSnapshot records a snapshot that did not become available within its creation timeout and
+// marks it with policy.PendingTag for the next run.
+func markPendingSnapshot(ctx context.Context, client *openstack.Client, s inflightSnapshot, waitErr error, timeout time.Duration, report *RunReport) {
+	outcome := s.outcome
+	outcome.Outcome = OutcomePending
+	outcome.Reason = fmt.Sprintf("not available after %s; the next run confirms or cleans it up", timeout)
+	report.addSnapshot(outcome)
+
+	s.logger.Warn("Snapshot not available within the creation timeout; deferring to the next run",
+		"snapshot_id", s.snapshotID,
+		"timeout", timeout,
+		"error", waitErr,
+	)
+	if reqID, err := client.UpdateManagedSnapshotMetadata(ctx, s.snapshotID, map[string]string{policy.PendingTag: "true"}); err != nil {
+		s.logger.Warn("Failed to mark snapshot as pending",
+			"snapshot_id", s.snapshotID,
+			"error", err,
+			"request_id", reqID,
+		)
+	}
+}
+
+// reconcilePreviousSnapshots resolves the snapshots a previous run left pending, before any policy is evaluated.
+//
+// For the snapshots of the given volumes selected by planPreviousSnapshots, including group snapshot members:
+//   - confirmed: the pending mark is cleared and the snapshot is recorded as created.
+//   - failed: handled as a failed creation by failPolicySnapshot, so the policy creates a new snapshot in the same
+//     run. Members of a group snapshot are cleaned up by deleting their group snapshot once (see
+//     failGroupPolicySnapshot).
+//
+// Requires the snapshot index; without it, or during a dry-run, nothing is reconciled.
+func reconcilePreviousSnapshots(
+	ctx context.Context,
+	client *openstack.Client,
+	vols []volumes.Volume,
+	timeouts creationTimeouts,
+	now time.Time,
+	notifyProvider notifications.Notifier,
+	report *RunReport,
+	logger *slog.Logger,
+) {
+	if client.SnapshotIndex == nil || report.DryRun {
+		return
+	}
+
+	plan := planPreviousSnapshots(client.SnapshotIndex, vols, timeouts, now)
+
+	for _, p := range plan.confirm {
+		policyLogger := previousSnapshotLogger(logger, p)
+		if reqID, err := client.UpdateManagedSnapshotMetadata(ctx, p.snap.ID, map[string]string{policy.PendingTag: "false"}); err != nil {
+			policyLogger.Warn("Failed to clear the pending mark of a snapshot", "error", err, "request_id", reqID)
+		}
+		outcome := reconciledOutcome(p.vol, p.snap, p.metadata)
+		outcome.Reason = "pending snapshot of a previous run became available"
+		confirmCreatedSnapshot(ctx, p.vol, p.metadata.PolicyType, policy.PolicyEvalResult{Metadata: p.metadata},
+			outcome, notifyProvider, report, policyLogger)
+	}
+
+	for _, p := range plan.fail {
+		outcome := reconciledOutcome(p.vol, p.snap, p.metadata)
+		outcome.Reason = "snapshot of a previous run did not become available"
+		_ = failPolicySnapshot(ctx, client, p.vol, p.metadata.PolicyType, policy.SnapshotPolicyWindow{}, p.snap.ID,
+			fmt.Errorf("snapshot %s is %s since %s", p.snap.ID, p.snap.Status, p.snap.CreatedAt.Format(time.RFC3339)),
+			outcome, notifyProvider, report, previousSnapshotLogger(logger, p))
+	}
+
+	for _, groupSnapshotID := range plan.groupIDs {
+		members := make([]inflightSnapshot, 0, len(plan.failedGroups[groupSnapshotID]))
+		for _, p := range plan.failedGroups[groupSnapshotID] {
+			outcome := reconciledOutcome(p.vol, p.snap, p.metadata)
+			outcome.Reason = "snapshot of a previous run did not become available"
+			members = append(members, inflightSnapshot{
+				vol:             p.vol,
+				policyType:      p.metadata.PolicyType,
+				result:          policy.PolicyEvalResult{Metadata: p.metadata},
+				snapshotID:      p.snap.ID,
+				groupSnapshotID: groupSnapshotID,
+				outcome:         outcome,
+				logger:          previousSnapshotLogger(logger, p),
+			})
+		}
+
+		groupLogger := logger.With("group_snapshot_id", groupSnapshotID)
+		cause := fmt.Errorf("group snapshot %s of a previous run did not become available", groupSnapshotID)
+		groupLogger.Error("Group snapshot creation failed", "error", cause)
+		delReqID, cleanupErr := cleanupGroupSnapshot(ctx, client, groupSnapshotID, groupLogger)
+		_ = failGroupPolicySnapshot(ctx, groupSnapshotID, members, cause, delReqID, cleanupErr, notifyProvider, report)
+	}
+}
+
+// previousSnapshot is a snapshot of a previous run selected by planPreviousSnapshots.
+type previousSnapshot struct {
+	vol      volumes.Volume
+	snap     snapshots.Snapshot
+	metadata policy.SnapshotMetadata
+}
+
+// previousSnapshotPlan is what reconcilePreviousSnapshots does with the snapshots of previous runs.
+type previousSnapshotPlan struct {
+	// confirm are available snapshots still marked pending.
+	confirm []previousSnapshot
+	// fail are snapshots that did not become available, to be cleaned up and created again.
+	fail []previousSnapshot
+	// failedGroups are the failed members of group snapshots, keyed by group snapshot ID, in the order of groupIDs.
+	// A group snapshot is cleaned up once, however many of its members failed.
+	failedGroups map[string][]previousSnapshot
+	groupIDs     []string
+}
+
+// planPreviousSnapshots selects the snapshots of the given volumes that reconcilePreviousSnapshots resolves:
+//   - available and marked pending: confirmed.
+//   - marked pending and in "error", or still "creating" after the creation timeout of its volume: failed.
+//
+// Snapshots without valid metadata and all others are left alone.
+func planPreviousSnapshots(index *openstack.SnapshotIndex, vols []volumes.Volume, timeouts creationTimeouts, now time.Time) previousSnapshotPlan {
+	plan := previousSnapshotPlan{failedGroups: map[string][]previousSnapshot{}}
+
+	for _, vol := range vols {
+		for _, snap := range index.VolumeSnapshots(vol.ID, "") {
+			metadata := policy.SnapshotMetadata{}
+			if err := metadata.ParseFromMetadata(snap.Metadata); err != nil {
+				continue
+			}
+			p := previousSnapshot{vol: vol, snap: snap, metadata: metadata}
+
+			switch {
+			case snap.Status == policy.SnapshotStatusAvailable && metadata.Pending:
+				plan.confirm = append(plan.confirm, p)
+
+			case snap.Status == policy.SnapshotStatusError && metadata.Pending,
+				snap.Status == policy.SnapshotStatusCreating && now.Sub(snap.CreatedAt) > timeouts.forVolume(vol):
+				if metadata.GroupSnapshotID == "" {
+					plan.fail = append(plan.fail, p)
+					continue
+				}
+				if _, ok := plan.failedGroups[metadata.GroupSnapshotID]; !ok {
+					plan.groupIDs = append(plan.groupIDs, metadata.GroupSnapshotID)
+				}
+				plan.failedGroups[metadata.GroupSnapshotID] = append(plan.failedGroups[metadata.GroupSnapshotID], p)
+			}
+		}
+	}
+	return plan
+}
+
+// previousSnapshotLogger returns the logger of a snapshot of a previous run.
+func previousSnapshotLogger(logger *slog.Logger, p previousSnapshot) *slog.Logger {
+	return logger.With("volume_id", p.vol.ID, "policy_type", p.metadata.PolicyType, "snapshot_id", p.snap.ID)
+}
+
+// reconciledOutcome builds the report entry for a snapshot of a previous run.
+func reconciledOutcome(vol volumes.Volume, snap snapshots.Snapshot, metadata policy.SnapshotMetadata) SnapshotOutcome {
+	return SnapshotOutcome{
+		VolumeID:        vol.ID,
+		VolumeName:      vol.Name,
+		PolicyType:      metadata.PolicyType,
+		SnapshotID:      snap.ID,
+		SnapshotName:    snap.Name,
+		GroupSnapshotID: metadata.GroupSnapshotID,
+	}
+}
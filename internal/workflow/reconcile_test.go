@@ -0,0 +1,279 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/cloud/openstack"
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/policy"
+	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/snapshots"
+	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/volumes"
+)
+
+func TestVolumeBackend(t *testing.T) {
+	tests := []struct {
+		name string
+		host string
+		want string
+	}{
+		{name: "Host Backend Pool", host: "cinder-1@ceph-hdd#pool-a", want: "ceph-hdd"},
+		{name: "Host Backend", host: "cinder-1@ceph-hdd", want: "ceph-hdd"},
+		{name: "Host Only", host: "cinder-1", want: ""},
+		{name: "Unknown Host", host: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := volumeBackend(tt.host); got != tt.want {
+				t.Errorf("volumeBackend(%q) = %q, want %q", tt.host, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreationTimeouts(t *testing.T) {
+	ssd := volumes.Volume{VolumeType: "ssd", Host: "cinder-1@ceph-ssd#pool"}
+	hdd := volumes.Volume{VolumeType: "hdd", Host: "cinder-1@ceph-hdd#pool"}
+	untyped := volumes.Volume{Host: "cinder-1@ceph-hdd#pool"}
+
+	tests := []struct {
+		name    string
+		opts    RunOptions
+		vol     volumes.Volume
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "Default", opts: RunOptions{}, vol: ssd, want: defaultCreationTimeout},
+		{name: "Fallback", opts: RunOptions{CreationTimeout: 15 * time.Minute}, vol: ssd, want: 15 * time.Minute},
+		{
+			name: "Volume Type Override",
+			opts: RunOptions{CreationTimeouts: []string{"hdd=1h"}},
+			vol:  hdd,
+			want: time.Hour,
+		},
+		{
+			name: "Backend Override",
+			opts: RunOptions{CreationTimeouts: []string{" ceph-hdd = 45m "}},
+			vol:  hdd,
+			want: 45 * time.Minute,
+		},
+		{
+			name: "Volume Type Before Backend",
+			opts: RunOptions{CreationTimeouts: []string{"ceph-hdd=45m", "hdd=1h"}},
+			vol:  hdd,
+			want: time.Hour,
+		},
+		{
+			name: "Backend Without Volume Type",
+			opts: RunOptions{CreationTimeouts: []string{"ceph-hdd=45m"}},
+			vol:  untyped,
+			want: 45 * time.Minute,
+		},
+		{
+			name: "No Matching Override",
+			opts: RunOptions{CreationTimeout: 20 * time.Minute, CreationTimeouts: []string{"hdd=1h"}},
+			vol:  ssd,
+			want: 20 * time.Minute,
+		},
+		{name: "Negative Fallback", opts: RunOptions{CreationTimeout: -time.Minute}, wantErr: true},
+		{name: "Missing Separator", opts: RunOptions{CreationTimeouts: []string{"hdd"}}, wantErr: true},
+		{name: "Missing Name", opts: RunOptions{CreationTimeouts: []string{"=1h"}}, wantErr: true},
+		{name: "Invalid Duration", opts: RunOptions{CreationTimeouts: []string{"hdd=soon"}}, wantErr: true},
+		{name: "Zero Duration", opts: RunOptions{CreationTimeouts: []string{"hdd=0s"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timeouts, err := tt.opts.creationTimeouts()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("creationTimeouts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := timeouts.forVolume(tt.vol); got != tt.want {
+				t.Errorf("forVolume() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveCreatedSnapshot(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  string
+		waitErr error
+		want    snapshotResolution
+	}{
+		{name: "Available", status: policy.SnapshotStatusAvailable, want: resolveCreated},
+		{name: "Error", status: policy.SnapshotStatusError, want: resolveFailed},
+		{name: "Deleted Meanwhile", status: "deleting", want: resolveFailed},
+		{name: "Creating Past Timeout", status: policy.SnapshotStatusCreating, waitErr: context.DeadlineExceeded, want: resolvePending},
+		{name: "Status Unknown", waitErr: errors.New("connection reset by peer"), want: resolvePending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveCreatedSnapshot(tt.status, tt.waitErr); got != tt.want {
+				t.Errorf("resolveCreatedSnapshot() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// previousRunSnapshot builds a managed snapshot of a previous run with the given status.
+func previousRunSnapshot(id, volumeID, status string, createdAt time.Time, pending bool, groupSnapshotID string) snapshots.Snapshot {
+	meta := policy.SnapshotMetadata{Managed: true, PolicyType: "daily", Pending: pending, GroupSnapshotID: groupSnapshotID}
+	return snapshots.Snapshot{ID: id, VolumeID: volumeID, Status: status, CreatedAt: createdAt, Metadata: meta.ToOpenstackMetadata()}
+}
+
+func TestPlanPreviousSnapshots(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	recent := now.Add(-5 * time.Minute)
+	old := now.Add(-time.Hour)
+	timeouts := creationTimeouts{fallback: 10 * time.Minute, overrides: map[string]time.Duration{"slow": 2 * time.Hour}}
+
+	tests := []struct {
+		name        string
+		vols        []volumes.Volume
+		snaps       []snapshots.Snapshot
+		wantConfirm []string
+		wantFail    []string
+		wantGroups  map[string][]string
+	}{
+		{
+			name:        "Available And Pending",
+			snaps:       []snapshots.Snapshot{previousRunSnapshot("s-1", "vol-1", "available", old, true, "")},
+			wantConfirm: []string{"s-1"},
+		},
+		{
+			name:  "Available Without Pending Mark",
+			snaps: []snapshots.Snapshot{previousRunSnapshot("s-1", "vol-1", "available", old, false, "")},
+		},
+		{
+			name:     "Error And Pending",
+			snaps:    []snapshots.Snapshot{previousRunSnapshot("s-1", "vol-1", "error", recent, true, "")},
+			wantFail: []string{"s-1"},
+		},
+		{
+			// Failed snapshots that were never pending are left to the stuck snapshot cleanup of the expiry run.
+			name:  "Error Without Pending Mark",
+			snaps: []snapshots.Snapshot{previousRunSnapshot("s-1", "vol-1", "error", old, false, "")},
+		},
+		{
+			name:     "Creating Past Timeout",
+			snaps:    []snapshots.Snapshot{previousRunSnapshot("s-1", "vol-1", "creating", old, false, "")},
+			wantFail: []string{"s-1"},
+		},
+		{
+			name:  "Creating Within Timeout",
+			snaps: []snapshots.Snapshot{previousRunSnapshot("s-1", "vol-1", "creating", recent, true, "")},
+		},
+		{
+			name:  "Creating Within Volume Type Timeout",
+			vols:  []volumes.Volume{{ID: "vol-1", VolumeType: "slow"}},
+			snaps: []snapshots.Snapshot{previousRunSnapshot("s-1", "vol-1", "creating", old, true, "")},
+		},
+		{
+			name: "Group Members Collected Once Per Group",
+			vols: []volumes.Volume{{ID: "vol-1"}, {ID: "vol-2"}},
+			snaps: []snapshots.Snapshot{
+				previousRunSnapshot("m-1", "vol-1", "error", old, true, "gs-1"),
+				previousRunSnapshot("m-2", "vol-2", "creating", old, true, "gs-1"),
+				previousRunSnapshot("m-3", "vol-2", "creating", old.Add(-time.Hour), true, "gs-2"),
+			},
+			wantGroups: map[string][]string{"gs-1": {"m-1", "m-2"}, "gs-2": {"m-3"}},
+		},
+		{
+			name:  "Volume Not Reconciled",
+			vols:  []volumes.Volume{{ID: "vol-2"}},
+			snaps: []snapshots.Snapshot{previousRunSnapshot("s-1", "vol-1", "error", old, true, "")},
+		},
+	}
+
+	ids := func(snaps []previousSnapshot) []string {
+		var ids []string
+		for _, p := range snaps {
+			ids = append(ids, p.snap.ID)
+		}
+		return ids
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vols := tt.vols
+			if vols == nil {
+				vols = []volumes.Volume{{ID: "vol-1"}}
+			}
+
+			plan := planPreviousSnapshots(openstack.NewSnapshotIndex(tt.snaps), vols, timeouts, now)
+			if got := ids(plan.confirm); !slices.Equal(got, tt.wantConfirm) {
+				t.Errorf("confirm = %v, want %v", got, tt.wantConfirm)
+			}
+			if got := ids(plan.fail); !slices.Equal(got, tt.wantFail) {
+				t.Errorf("fail = %v, want %v", got, tt.wantFail)
+			}
+			if len(plan.groupIDs) != len(tt.wantGroups) {
+				t.Errorf("groupIDs = %v, want %d groups", plan.groupIDs, len(tt.wantGroups))
+			}
+			for _, groupSnapshotID := range plan.groupIDs {
+				if got := ids(plan.failedGroups[groupSnapshotID]); !slices.Equal(got, tt.wantGroups[groupSnapshotID]) {
+					t.Errorf("members of %s = %v, want %v", groupSnapshotID, got, tt.wantGroups[groupSnapshotID])
+				}
+			}
+		})
+	}
+}
+
+func TestReconcileCreatedSnapshots(t *testing.T) {
+	now := time.Now()
+	inflight := func(id string, startedAt time.Time) inflightSnapshot {
+		return inflightSnapshot{
+			vol:        volumes.Volume{ID: "vol-" + id},
+			policyType: "daily",
+			snapshotID: id,
+			startedAt:  startedAt,
+			outcome:    SnapshotOutcome{VolumeID: "vol-" + id, PolicyType: "daily", SnapshotID: id},
+			logger:     testLogger,
+		}
+	}
+
+	fake := &fakeCinder{snapshots: map[string]snapshots.Snapshot{
+		"s-available": {ID: "s-available", VolumeID: "vol-s-available", Status: policy.SnapshotStatusAvailable},
+		"s-error":     {ID: "s-error", VolumeID: "vol-s-error", Status: policy.SnapshotStatusError},
+		"s-creating":  {ID: "s-creating", VolumeID: "vol-s-creating", Status: policy.SnapshotStatusCreating, Metadata: map[string]string{policy.ManagedTag: "true"}},
+	}}
+	report := NewRunReport("req-test", "create-snapshots", false)
+	report.addInflight(inflight("s-available", now))
+	report.addInflight(inflight("s-error", now))
+	// Started long before its creation timeout, so it is not awaited and marked pending right away.
+	report.addInflight(inflight("s-creating", now.Add(-time.Hour)))
+
+	timeouts := creationTimeouts{fallback: 10 * time.Minute}
+	reconcileCreatedSnapshots(context.Background(), fake.client(t), timeouts, nil, report, testLogger)
+
+	got := map[string]string{}
+	for _, s := range report.Snapshots {
+		got[s.SnapshotID] = s.Outcome
+	}
+	want := map[string]string{"s-available": OutcomeCreated, "s-error": OutcomeFailed, "s-creating": OutcomePending}
+	if !maps.Equal(got, want) {
+		t.Errorf("outcomes = %v, want %v", got, want)
+	}
+
+	slices.Sort(fake.requests)
+	wantRequests := []string{
+		"DELETE /snapshots/s-error null",
+		`PUT /snapshots/s-creating/metadata {"metadata":{"x-snapsentry-managed":"true","x-snapsentry-snapshot-pending":"true"}}`,
+	}
+	if !slices.Equal(fake.requests, wantRequests) {
+		t.Errorf("requests = %v, want %v", fake.requests, wantRequests)
+	}
+	if len(report.takeInflight()) != 0 {
+		t.Errorf("snapshots left in flight after reconciliation")
+	}
+}
@@ -0,0 +1,534 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/hooks"
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/metrics"
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/notifications"
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/policy"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"sigs.k8s.io/yaml"
+)
+
+// Supported output formats for rendered plans.
+const (
+	OutputFormatTable = "table"
+	OutputFormatJSON  = "json"
+	OutputFormatYAML  = "yaml"
+)
+
+// Outcomes recorded for a volume policy or an expiry candidate.
+// The "would_*" outcomes are only produced by dry-runs.
+const (
+	OutcomeSkipped       = "skipped"
+	OutcomePaused        = "paused"
+	OutcomeDeferred      = "deferred"
+	OutcomeCreated       = "created"
+	OutcomePending       = "pending"
+	OutcomeQuotaExceeded = "quota_exceeded"
+	OutcomePromoted      = "promoted"
+	OutcomeFailed        = "failed"
+	OutcomeDeleted       = "deleted"
+	OutcomeWouldCreate   = "would_create"
+	OutcomeWouldPromote  = "would_promote"
+	OutcomeWouldDelete   = "would_delete"
+)
+
+// Outcomes of the cleanup of a snapshot left behind by a failed creation.
+const (
+	OrphanCleaned       = "cleaned"
+	OrphanCleanupFailed = "cleanup_failed"
+)
+
+// RunOptions controls how the snapshot and expiry workflows execute.
+//
+// Fields:
+//   - DryRun: Runs discovery and evaluation only. No snapshot is created, relabelled or deleted;
+//     the run report is rendered as a plan of the intended actions instead.
+//   - OutputFormat: Format of the rendered plan (table, json, yaml). Defaults to table.
+//   - ReportPath: Writes the run report as JSON to this file ("-" for stdout). Empty disables it.
+//   - Concurrency: Number of VM groups (and unattached/multi-attached volumes) processed in parallel
+//     by the snapshot workflow. 0 or 1 processes them one after another.
+//   - RateLimit: Client-side limit of OpenStack API requests per second, shared by all workers. 0 disables it.
+//   - HookDir: Directory of the executables that quiesce hooks may run. Empty disables command hooks.
+//   - AllowHTTPHooks: Allows quiesce hooks to call http(s) URLs.
+//   - DefaultsFile: Default policies (policy.Defaults) inherited by volumes without a policy of their own.
+//     Used by the snapshot workflow, and by the expiry workflow for inherited count retention. Empty disables defaults.
+//   - Blackouts: Project-wide blackout windows (see policy.BlackoutWindow) in which the snapshot workflow
+//     creates no snapshots or backups. Recurring windows are read in BlackoutTimeZone (default UTC).
+//   - CreationTimeout: How long the snapshot workflow waits for a created snapshot to become available before
+//     leaving it to the next run (see reconcileCreatedSnapshots). 0 uses defaultCreationTimeout.
+//   - CreationTimeouts: Overrides of CreationTimeout per volume type or backend, as '<name>=<duration>'.
+//   - StuckSnapshotAge: Managed snapshots in "error", "error_deleting" or "creating" for longer than this are
+//     deleted by the expiry workflow (see policy.IsStuckSnapshot). 0 disables the cleanup.
+type RunOptions struct {
+	DryRun           bool
+	OutputFormat     string
+	ReportPath       string
+	Concurrency      int
+	RateLimit        float64
+	HookDir          string
+	AllowHTTPHooks   bool
+	DefaultsFile     string
+	Blackouts        []string
+	BlackoutTimeZone string
+	CreationTimeout  time.Duration
+	CreationTimeouts []string
+	StuckSnapshotAge time.Duration
+}
+
+// hookRunner returns the runner for the quiesce hooks allowed by the options.
+func (o RunOptions) hookRunner() hooks.Runner {
+	return hooks.Runner{Dir: o.HookDir, AllowHTTP: o.AllowHTTPHooks}
+}
+
+// Validate checks the options before any API call is made.
+func (o RunOptions) Validate() error {
+	if o.Concurrency < 0 {
+		return fmt.Errorf("invalid concurrency %d; must be 0 or greater", o.Concurrency)
+	}
+	if o.RateLimit < 0 {
+		return fmt.Errorf("invalid rate limit %g; must be 0 (disabled) or greater", o.RateLimit)
+	}
+	if _, err := o.creationTimeouts(); err != nil {
+		return err
+	}
+	if o.StuckSnapshotAge < 0 {
+		return fmt.Errorf("invalid stuck snapshot age %s; must be 0 (disabled) or greater", o.StuckSnapshotAge)
+	}
+	if _, err := policy.ParseBlackoutWindows(o.Blackouts, o.BlackoutTimeZone); err != nil {
+		return err
+	}
+	if o.HookDir != "" {
+		if info, err := os.Stat(o.HookDir); err != nil || !info.IsDir() {
+			return fmt.Errorf("invalid hook directory '%s'; must be an existing directory", o.HookDir)
+		}
+	}
+
+	switch o.OutputFormat {
+	case "", OutputFormatTable, OutputFormatJSON, OutputFormatYAML:
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format '%s'; must be table, json or yaml", o.OutputFormat)
+	}
+}
+
+// RunReport is the machine-readable record of a single workflow execution, keyed by its snapsentry_id.
+// During a dry-run the same report doubles as the plan.
+//
+// It is safe for concurrent use, since volumes of a VM group are processed in parallel.
+type RunReport struct {
+	RunID      string            `json:"snapsentry_id"`
+	Workflow   string            `json:"workflow"`
+	DryRun     bool              `json:"dry_run"`
+	Blackout   string            `json:"blackout,omitempty"`
+	StartedAt  time.Time         `json:"started_at"`
+	FinishedAt time.Time         `json:"finished_at"`
+	Error      string            `json:"error,omitempty"`
+	Summary    ReportSummary     `json:"summary"`
+	Snapshots  []SnapshotOutcome `json:"snapshots"`
+	Deletions  []DeletionOutcome `json:"deletions"`
+	Backups    []BackupOutcome   `json:"backups"`
+
+	// inflight holds the snapshots created by this run that still have to be confirmed.
+	inflight []inflightSnapshot
+	// quota is the snapshot quota left for this run (see checkSnapshotQuota). Nil is unlimited.
+	quota *policy.SnapshotQuota
+	// quotaOrder orders the reservations from quota by the priority of the volume groups (see orderQuotaReservations).
+	quotaOrder *quotaOrder
+	mu         sync.Mutex
+}
+
+// ReportSummary aggregates a run report.
+type ReportSummary struct {
+	VolumesProcessed int            `json:"volumes_processed"`
+	VolumesSucceeded int            `json:"volumes_succeeded"`
+	VolumesFailed    int            `json:"volumes_failed"`
+	SnapshotsFound   int            `json:"snapshots_found"`
+	Outcomes         map[string]int `json:"outcomes"`
+}
+
+// SnapshotOutcome is the result of one policy on one volume.
+type SnapshotOutcome struct {
+	VolumeID         string    `json:"volume_id"`
+	VolumeName       string    `json:"volume_name"`
+	PolicyType       string    `json:"policy_type"`
+	Outcome          string    `json:"outcome"`
+	ShouldSnapshot   bool      `json:"should_snapshot"`
+	WindowStart      time.Time `json:"window_start"`
+	WindowEnd        time.Time `json:"window_end"`
+	Reason           string    `json:"reason,omitempty"`
+	SnapshotName     string    `json:"snapshot_name,omitempty"`
+	SnapshotID       string    `json:"snapshot_id,omitempty"`
+	RequestID        string    `json:"request_id,omitempty"`
+	GFSCovers        string    `json:"gfs_covers,omitempty"`
+	GroupSnapshotID  string    `json:"group_snapshot_id,omitempty"`
+	Error            string    `json:"error,omitempty"`
+	OrphanSnapshotID string    `json:"orphan_snapshot_id,omitempty"`
+	OrphanCleanup    string    `json:"orphan_cleanup,omitempty"`
+	CleanupRequestID string    `json:"cleanup_request_id,omitempty"`
+}
+
+// DeletionOutcome is the result for a managed snapshot selected by the expiry workflow.
+type DeletionOutcome struct {
+	SnapshotID      string    `json:"snapshot_id"`
+	SnapshotName    string    `json:"snapshot_name"`
+	VolumeID        string    `json:"volume_id"`
+	PolicyType      string    `json:"policy_type"`
+	RetentionType   string    `json:"retention_type"`
+	RetentionCount  int       `json:"retention_count,omitempty"`
+	ExpiryDate      time.Time `json:"expiry_date"`
+	GroupSnapshotID string    `json:"group_snapshot_id,omitempty"`
+	Reason          string    `json:"reason"`
+	Outcome         string    `json:"outcome"`
+	RequestID       string    `json:"request_id,omitempty"`
+	Error           string    `json:"error,omitempty"`
+}
+
+// BackupOutcome is the result of a backup export (create-snapshots) or of a managed backup selected by the
+// expiry workflow (expire-snapshots).
+type BackupOutcome struct {
+	VolumeID    string    `json:"volume_id"`
+	VolumeName  string    `json:"volume_name,omitempty"`
+	PolicyType  string    `json:"policy_type"`
+	SnapshotID  string    `json:"snapshot_id,omitempty"`
+	BackupID    string    `json:"backup_id,omitempty"`
+	BackupName  string    `json:"backup_name,omitempty"`
+	Incremental bool      `json:"incremental,omitempty"`
+	ExpiryDate  time.Time `json:"expiry_date"`
+	Reason      string    `json:"reason,omitempty"`
+	Outcome     string    `json:"outcome"`
+	RequestID   string    `json:"request_id,omitempty"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// NewRunReport creates an empty report for the given workflow run.
+func NewRunReport(runID, workflowName string, dryRun bool) *RunReport {
+	return &RunReport{
+		RunID:     runID,
+		Workflow:  workflowName,
+		DryRun:    dryRun,
+		StartedAt: time.Now().UTC(),
+		Snapshots: []SnapshotOutcome{},
+		Deletions: []DeletionOutcome{},
+		Backups:   []BackupOutcome{},
+	}
+}
+
+// addSnapshot records a policy outcome.
+func (r *RunReport) addSnapshot(entry SnapshotOutcome) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Snapshots = append(r.Snapshots, entry)
+}
+
+// addInflight records a created snapshot for the reconciliation at the end of the run.
+func (r *RunReport) addInflight(entry inflightSnapshot) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.inflight = append(r.inflight, entry)
+}
+
+// inflightFor returns the snapshots recorded with addInflight for the given volumes.
+func (r *RunReport) inflightFor(volumeIDs []string) []inflightSnapshot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var inflight []inflightSnapshot
+	for _, s := range r.inflight {
+		if slices.Contains(volumeIDs, s.vol.ID) {
+			inflight = append(inflight, s)
+		}
+	}
+	return inflight
+}
+
+// takeInflight returns and forgets the snapshots recorded with addInflight.
+func (r *RunReport) takeInflight() []inflightSnapshot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	inflight := r.inflight
+	r.inflight = nil
+	return inflight
+}
+
+// reserveQuota reserves count snapshots of the given total size for a volume from the snapshot quota of the run,
+// once the volume groups before the volume's own made their reservations (see quotaOrder).
+func (r *RunReport) reserveQuota(volumeID string, count, gigabytes int) bool {
+	r.quotaOrder.await(volumeID)
+	return r.quota.Reserve(count, gigabytes)
+}
+
+// addDeletion records the outcome for an expiry candidate.
+func (r *RunReport) addDeletion(entry DeletionOutcome) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Deletions = append(r.Deletions, entry)
+}
+
+// addBackup records the outcome of a backup export or expiry.
+func (r *RunReport) addBackup(entry BackupOutcome) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Backups = append(r.Backups, entry)
+}
+
+// finish stamps the end time, records a workflow level error and computes the outcome summary.
+func (r *RunReport) finish(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.FinishedAt = time.Now().UTC()
+	if err != nil {
+		r.Error = err.Error()
+	}
+
+	r.Summary.Outcomes = make(map[string]int)
+	for _, s := range r.Snapshots {
+		r.Summary.Outcomes[s.Outcome]++
+	}
+	for _, d := range r.Deletions {
+		r.Summary.Outcomes[d.Outcome]++
+	}
+	for _, b := range r.Backups {
+		r.Summary.Outcomes["backup_"+b.Outcome]++
+	}
+}
+
+// completeRun finalizes the report of a workflow run: it records the run metrics, sends the run summary
+// notification, renders the plan of a dry-run to stdout and writes the report to opts.ReportPath.
+// The workflow error is passed through unchanged.
+func completeRun(report *RunReport, opts RunOptions, notifyProvider notifications.Notifier, logger *slog.Logger, runErr error) (*RunReport, error) {
+	report.finish(runErr)
+
+	// Dry-runs must not move the "last success" timestamp an alert relies on.
+	if !report.DryRun {
+		metrics.ObserveWorkflowRun(report.Workflow, report.StartedAt, report.FinishedAt, runErr == nil)
+		sendNotification(context.Background(), notifyProvider, report.summaryEvent(), logger)
+	}
+
+	var outputErr error
+	if report.DryRun {
+		outputErr = report.Render(os.Stdout, opts.OutputFormat)
+	}
+	if opts.ReportPath != "" {
+		if err := report.WriteFile(opts.ReportPath); err != nil {
+			outputErr = err
+		}
+	}
+
+	if runErr != nil {
+		return report, runErr
+	}
+	return report, outputErr
+}
+
+// summaryEvent converts the finished report into a run summary notification.
+func (r *RunReport) summaryEvent() notifications.RunSummary {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return notifications.RunSummary{
+		Service:          "snapsentry",
+		RunID:            r.RunID,
+		Workflow:         r.Workflow,
+		StartedAt:        r.StartedAt,
+		FinishedAt:       r.FinishedAt,
+		Error:            r.Error,
+		VolumesProcessed: r.Summary.VolumesProcessed,
+		VolumesSucceeded: r.Summary.VolumesSucceeded,
+		VolumesFailed:    r.Summary.VolumesFailed,
+		SnapshotsFound:   r.Summary.SnapshotsFound,
+		Outcomes:         maps.Clone(r.Summary.Outcomes),
+	}
+}
+
+// WriteFile writes the report as JSON to path. A path of "-" writes to stdout.
+// Files are replaced atomically so that readers never observe a partial report.
+func (r *RunReport) WriteFile(path string) error {
+	if path == "-" {
+		return r.Render(os.Stdout, OutputFormatJSON)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".snapsentry-report-*")
+	if err != nil {
+		return fmt.Errorf("failed to write run report: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := r.Render(tmp, OutputFormatJSON); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write run report: %w", err)
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write run report: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write run report: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write run report: %w", err)
+	}
+	return nil
+}
+
+// Render writes the report to w in the requested format.
+func (r *RunReport) Render(w io.Writer, format string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch format {
+	case OutputFormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+
+	case OutputFormatYAML:
+		out, err := yaml.Marshal(r)
+		if err != nil {
+			return fmt.Errorf("failed to render report as yaml: %w", err)
+		}
+		_, err = w.Write(out)
+		return err
+
+	case "", OutputFormatTable:
+		if r.Workflow == "create-snapshots" {
+			t := newStyledTable("VOLUME ID", "VOLUME NAME", "POLICY", "OUTCOME", "WINDOW START", "WINDOW END", "SNAPSHOT", "REASON")
+			for _, s := range r.Snapshots {
+				snapshot := s.SnapshotName
+				if s.SnapshotID != "" {
+					snapshot = s.SnapshotID
+				}
+				reason := s.Reason
+				if s.Error != "" {
+					reason = s.Error
+				}
+				t.Row(s.VolumeID, s.VolumeName, s.PolicyType, s.Outcome,
+					formatReportTime(s.WindowStart), formatReportTime(s.WindowEnd), snapshot, reason)
+			}
+			if _, err := fmt.Fprintln(w, t); err != nil {
+				return err
+			}
+			return r.renderBackupTable(w)
+		}
+
+		t := newStyledTable("SNAPSHOT ID", "SNAPSHOT NAME", "VOLUME ID", "POLICY", "RETENTION", "EXPIRY DATE", "OUTCOME", "REASON")
+		for _, d := range r.Deletions {
+			retention := d.RetentionType
+			if d.RetentionCount > 0 {
+				retention += " (" + strconv.Itoa(d.RetentionCount) + ")"
+			}
+			reason := d.Reason
+			if d.Error != "" {
+				reason = d.Error
+			}
+			t.Row(d.SnapshotID, d.SnapshotName, d.VolumeID, d.PolicyType, retention, formatReportTime(d.ExpiryDate), d.Outcome, reason)
+		}
+		if _, err := fmt.Fprintln(w, t); err != nil {
+			return err
+		}
+		return r.renderBackupTable(w)
+
+	default:
+		return fmt.Errorf("unsupported output format '%s'; must be table, json or yaml", format)
+	}
+}
+
+// renderBackupTable writes the backup outcomes as a second table, if there are any.
+func (r *RunReport) renderBackupTable(w io.Writer) error {
+	if len(r.Backups) == 0 {
+		return nil
+	}
+
+	t := newStyledTable("VOLUME ID", "POLICY", "SNAPSHOT ID", "BACKUP", "EXPIRY DATE", "OUTCOME", "REASON")
+	for _, b := range r.Backups {
+		backup := b.BackupName
+		if b.BackupID != "" {
+			backup = b.BackupID
+		}
+		if b.Incremental {
+			backup += " (incremental)"
+		}
+		reason := b.Reason
+		if b.Error != "" {
+			reason = b.Error
+		}
+		t.Row(b.VolumeID, b.PolicyType, b.SnapshotID, backup, formatReportTime(b.ExpiryDate), b.Outcome, reason)
+	}
+	_, err := fmt.Fprintln(w, t)
+	return err
+}
+
+// renderListing writes the result of a read-only command: v as json or yaml, or the table drawn by renderTable.
+func renderListing(w io.Writer, format string, v any, renderTable func(w io.Writer) error) error {
+	switch format {
+	case OutputFormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+
+	case OutputFormatYAML:
+		out, err := yaml.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("failed to render output as yaml: %w", err)
+		}
+		_, err = w.Write(out)
+		return err
+
+	case "", OutputFormatTable:
+		return renderTable(w)
+
+	default:
+		return fmt.Errorf("unsupported output format '%s'; must be table, json or yaml", format)
+	}
+}
+
+// formatReportTime renders a timestamp for table output, leaving zero times blank.
+func formatReportTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// newStyledTable returns a lipgloss table with the SnapSentry look and the given headers.
+func newStyledTable(headers ...string) *table.Table {
+	var (
+		purple    = lipgloss.Color("99")
+		gray      = lipgloss.Color("245")
+		lightGray = lipgloss.Color("241")
+
+		headerStyle  = lipgloss.NewStyle().Foreground(purple).Bold(true).Align(lipgloss.Center)
+		cellStyle    = lipgloss.NewStyle().Padding(0, 1)
+		oddRowStyle  = cellStyle.Foreground(gray)
+		evenRowStyle = cellStyle.Foreground(lightGray)
+	)
+
+	return table.New().
+		Border(lipgloss.NormalBorder()).
+		BorderStyle(lipgloss.NewStyle().Foreground(purple)).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			switch {
+			case row == table.HeaderRow:
+				return headerStyle
+			case row%2 == 0:
+				return evenRowStyle
+			default:
+				return oddRowStyle
+			}
+		}).
+		Headers(headers...)
+}
@@ -0,0 +1,116 @@
+package workflow
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+func TestRunOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    RunOptions
+		wantErr bool
+	}{
+		{name: "Defaults", opts: RunOptions{}},
+		{
+			name: "All Set",
+			opts: RunOptions{
+				OutputFormat:     OutputFormatYAML,
+				Concurrency:      4,
+				RateLimit:        2.5,
+				HookDir:          ".",
+				Blackouts:        []string{"daily 01:00-03:00"},
+				BlackoutTimeZone: "Europe/Berlin",
+				CreationTimeout:  time.Hour,
+				CreationTimeouts: []string{"hdd=2h"},
+				StuckSnapshotAge: 24 * time.Hour,
+			},
+		},
+		{name: "Negative Concurrency", opts: RunOptions{Concurrency: -1}, wantErr: true},
+		{name: "Negative Rate Limit", opts: RunOptions{RateLimit: -1}, wantErr: true},
+		{name: "Invalid Creation Timeout", opts: RunOptions{CreationTimeouts: []string{"hdd"}}, wantErr: true},
+		{name: "Negative Stuck Snapshot Age", opts: RunOptions{StuckSnapshotAge: -time.Hour}, wantErr: true},
+		{name: "Invalid Blackout", opts: RunOptions{Blackouts: []string{"someday 01:00-03:00"}}, wantErr: true},
+		{name: "Missing Hook Directory", opts: RunOptions{HookDir: "./does-not-exist"}, wantErr: true},
+		{name: "Hook Directory Is A File", opts: RunOptions{HookDir: "report_test.go"}, wantErr: true},
+		{name: "Unsupported Output Format", opts: RunOptions{OutputFormat: "xml"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunReportRender(t *testing.T) {
+	expiry := time.Date(2026, 10, 17, 2, 0, 0, 0, time.UTC)
+
+	newReport := func(workflowName string) *RunReport {
+		report := NewRunReport("req-test", workflowName, true)
+		report.addSnapshot(SnapshotOutcome{VolumeID: "vol-1", VolumeName: "db-data", PolicyType: "daily", Outcome: OutcomeWouldCreate, SnapshotName: "snap-daily"})
+		report.addDeletion(DeletionOutcome{SnapshotID: "snap-1", VolumeID: "vol-1", PolicyType: "weekly", RetentionType: "count", RetentionCount: 4, ExpiryDate: expiry, Reason: "exceeds retention count of 4", Outcome: OutcomeWouldDelete})
+		report.finish(nil)
+		return report
+	}
+
+	tests := []struct {
+		name     string
+		workflow string
+		format   string
+		want     []string
+		wantErr  bool
+	}{
+		{name: "Create Table", workflow: "create-snapshots", format: OutputFormatTable, want: []string{"VOLUME ID", "db-data", "would_create", "snap-daily"}},
+		{name: "Expire Table", workflow: "expire-snapshots", format: "", want: []string{"SNAPSHOT ID", "snap-1", "count (4)", "2026-10-17T02:00:00Z", "exceeds retention count of 4"}},
+		{name: "JSON", workflow: "expire-snapshots", format: OutputFormatJSON},
+		{name: "YAML", workflow: "expire-snapshots", format: OutputFormatYAML},
+		{name: "Unsupported Format", workflow: "expire-snapshots", format: "xml", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := newReport(tt.workflow).Render(&buf, tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Render() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("Render() output is missing %q:\n%s", want, buf.String())
+				}
+			}
+
+			// Machine-readable formats must decode back into the report.
+			var decoded RunReport
+			switch tt.format {
+			case OutputFormatJSON:
+				err = json.Unmarshal(buf.Bytes(), &decoded)
+			case OutputFormatYAML:
+				err = yaml.Unmarshal(buf.Bytes(), &decoded)
+			default:
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to decode rendered report: %v", err)
+			}
+			if decoded.RunID != "req-test" || !decoded.DryRun || len(decoded.Deletions) != 1 || decoded.Deletions[0].RetentionCount != 4 {
+				t.Errorf("decoded report = %s (dry run %v, %d deletions), want the rendered run", decoded.RunID, decoded.DryRun, len(decoded.Deletions))
+			}
+			if decoded.Summary.Outcomes[OutcomeWouldDelete] != 1 || decoded.Summary.Outcomes[OutcomeWouldCreate] != 1 {
+				t.Errorf("decoded summary outcomes = %v, want one would_create and one would_delete", decoded.Summary.Outcomes)
+			}
+		})
+	}
+}
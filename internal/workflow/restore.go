@@ -0,0 +1,318 @@
+package workflow
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/cloud"
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/cloud/openstack"
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/policy"
+	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/snapshots"
+)
+
+// restoreTimeout bounds a restore when no global timeout is set. Creating a volume from a large snapshot,
+// or reverting one, takes far longer than the API calls of the other commands.
+const restoreTimeout = 30 * time.Minute
+
+// RestoreOptions selects the snapshot to restore and describes the restored volume.
+//
+// Fields:
+//   - VolumeID: Volume whose managed snapshots are searched. Required for a revert.
+//   - SnapshotID: Restores exactly this snapshot. Otherwise the newest managed snapshot of VolumeID is used,
+//     restricted to snapshots covering PolicyType (if set) and created before Before (if set).
+//   - Name, Size, VolumeType, AvailabilityZone: Properties of the new volume. Empty/0 use the defaults
+//     ("restored-<snapshot name>", the snapshot size, the Cinder default type and zone).
+//   - Reason: Free text for the audit record, e.g. an incident or ticket number.
+type RestoreOptions struct {
+	VolumeID         string
+	SnapshotID       string
+	PolicyType       string
+	Before           time.Time
+	Name             string
+	Size             int
+	VolumeType       string
+	AvailabilityZone string
+	Reason           string
+}
+
+// RestorePoint is a managed snapshot as listed by `restore list`.
+type RestorePoint struct {
+	SnapshotID      string    `json:"snapshot_id"`
+	SnapshotName    string    `json:"snapshot_name"`
+	Status          string    `json:"status"`
+	SizeGB          int       `json:"size_gb"`
+	CreatedAt       time.Time `json:"created_at"`
+	PolicyType      string    `json:"policy_type"`
+	GFSCovers       string    `json:"gfs_covers,omitempty"`
+	WindowStart     time.Time `json:"window_start"`
+	RetentionType   string    `json:"retention_type"`
+	RetentionCount  int       `json:"retention_count,omitempty"`
+	ExpiryDate      time.Time `json:"expiry_date"`
+	GroupSnapshotID string    `json:"group_snapshot_id,omitempty"`
+}
+
+// initRestoreClient connects with a timeout suited for restores: the global timeout, or restoreTimeout.
+func initRestoreClient(cloudName string, timeoutSeconds int) (*openstack.Client, error) {
+	operationTimeout := restoreTimeout
+	if timeoutSeconds > 0 {
+		operationTimeout = time.Duration(timeoutSeconds) * time.Second
+	}
+
+	ostk := openstack.Client{
+		ProfileName: cloudName,
+		RetryConfig: cloud.RetryConfig{
+			MaxRetries:       3,
+			BaseDelay:        2 * time.Second,
+			MaxDelay:         10 * time.Second,
+			OperationTimeout: operationTimeout,
+		},
+	}
+	if err := ostk.NewClient(); err != nil {
+		return nil, fmt.Errorf("failed to connect to cloud: %w", err)
+	}
+	return &ostk, nil
+}
+
+// ListRestorePoints prints the managed snapshots of a volume, newest first, optionally only those
+// covering policyType. outputFormat is table, json or yaml.
+func ListRestorePoints(cloudName, logLevel, volumeID, policyType, outputFormat string) error {
+	logger := SetupLogger(logLevel, cloudName).With("workflow", "restore-list", "volume_id", volumeID)
+
+	client, err := initClient(cloudName, logLevel)
+	if err != nil {
+		return err
+	}
+
+	snaps, err := client.ListManagedVolumeSnapshots(context.Background(), volumeID, "", false)
+	if err != nil {
+		logger.Error("Failed to list managed snapshots", "error", err)
+		return err
+	}
+	sortNewestFirst(snaps)
+
+	points := []RestorePoint{}
+	for _, snap := range snaps {
+		meta := policy.SnapshotMetadata{}
+		_ = meta.ParseFromMetadata(snap.Metadata)
+		if policyType != "" && !meta.CoversPolicy(policyType) {
+			continue
+		}
+		points = append(points, RestorePoint{
+			SnapshotID:      snap.ID,
+			SnapshotName:    snap.Name,
+			Status:          snap.Status,
+			SizeGB:          snap.Size,
+			CreatedAt:       snap.CreatedAt,
+			PolicyType:      meta.PolicyType,
+			GFSCovers:       meta.GFSCovers,
+			WindowStart:     windowStartFromName(snap.Name, volumeID),
+			RetentionType:   meta.RetentionType,
+			RetentionCount:  meta.RetentionCount,
+			ExpiryDate:      meta.ExpiryDate,
+			GroupSnapshotID: meta.GroupSnapshotID,
+		})
+	}
+	logger.Debug("Found restore points", "count", len(points))
+
+	return renderListing(os.Stdout, outputFormat, points, func(w io.Writer) error {
+		t := newStyledTable("SNAPSHOT ID", "SNAPSHOT NAME", "POLICY", "WINDOW START", "CREATED AT", "RETENTION", "EXPIRY DATE", "SIZE (GB)")
+		for _, p := range points {
+			policyLabel := p.PolicyType
+			if p.GFSCovers != "" {
+				policyLabel += " (" + p.GFSCovers + ")"
+			}
+			retention := p.RetentionType
+			if p.RetentionCount > 0 {
+				retention += " (" + strconv.Itoa(p.RetentionCount) + ")"
+			}
+			t.Row(p.SnapshotID, p.SnapshotName, policyLabel, formatReportTime(p.WindowStart), formatReportTime(p.CreatedAt),
+				retention, formatReportTime(p.ExpiryDate), strconv.Itoa(p.SizeGB))
+		}
+		_, err := fmt.Fprintln(w, t)
+		return err
+	})
+}
+
+// RestoreToNewVolume creates a new volume from the selected snapshot and records the restore in its metadata.
+// The source volume is not touched.
+func RestoreToNewVolume(cloudName, logLevel string, timeoutSeconds int, opts RestoreOptions) error {
+	logger := SetupLogger(logLevel, cloudName).With("workflow", "restore-to-new-volume")
+	ctx := context.Background()
+
+	client, err := initRestoreClient(cloudName, timeoutSeconds)
+	if err != nil {
+		return err
+	}
+
+	snap, meta, err := selectRestoreSnapshot(ctx, client, opts)
+	if err != nil {
+		logger.Error("No snapshot to restore", "error", err)
+		return err
+	}
+	logger = logger.With("snapshot_id", snap.ID, "source_volume_id", snap.VolumeID)
+
+	name := opts.Name
+	if name == "" {
+		name = "restored-" + cmp.Or(snap.Name, snap.ID)
+	}
+
+	audit := policy.RestoreMetadata{
+		Mode:              policy.RestoreModeNewVolume,
+		SourceSnapshotID:  snap.ID,
+		SourceVolumeID:    snap.VolumeID,
+		PolicyType:        meta.PolicyType,
+		SnapshotCreatedAt: snap.CreatedAt,
+		RestoredAt:        time.Now(),
+		RequestedBy:       cloudName,
+		Reason:            opts.Reason,
+	}
+
+	logger.Info("Creating volume from snapshot", "volume_name", name, "snapshot_created_at", snap.CreatedAt, "policy_type", meta.PolicyType)
+	vol, reqID, err := client.CreateVolumeFromSnapshot(ctx, snap.ID, name, opts.Size, opts.VolumeType, opts.AvailabilityZone, audit.ToOpenstackMetadata())
+	if err != nil {
+		logger.Error("Restore failed", "error", err, "request_id", reqID, "volume_id", vol.ID)
+		return err
+	}
+	logger.Info("Volume restored successfully", "volume_id", vol.ID, "request_id", reqID)
+
+	t := newStyledTable("NEW VOLUME ID", "NEW VOLUME NAME", "SOURCE VOLUME ID", "SNAPSHOT ID", "POLICY", "SNAPSHOT CREATED AT", "REQUEST ID")
+	t.Row(vol.ID, vol.Name, snap.VolumeID, snap.ID, meta.PolicyType, formatReportTime(snap.CreatedAt), reqID)
+	fmt.Println(t)
+	return nil
+}
+
+// RevertVolume reverts opts.VolumeID in place to the selected snapshot and records the restore in its metadata.
+//
+// Everything written after the snapshot is lost, so nothing happens without confirmed; the plan is printed instead.
+// Cinder only reverts detached volumes to their latest snapshot, on backends that support it.
+func RevertVolume(cloudName, logLevel string, timeoutSeconds int, opts RestoreOptions, confirmed bool) error {
+	logger := SetupLogger(logLevel, cloudName).With("workflow", "restore-revert", "volume_id", opts.VolumeID)
+	ctx := context.Background()
+
+	if opts.VolumeID == "" {
+		return fmt.Errorf("a volume ID is required to revert")
+	}
+
+	client, err := initRestoreClient(cloudName, timeoutSeconds)
+	if err != nil {
+		return err
+	}
+
+	snap, meta, err := selectRestoreSnapshot(ctx, client, opts)
+	if err != nil {
+		logger.Error("No snapshot to revert to", "error", err)
+		return err
+	}
+	logger = logger.With("snapshot_id", snap.ID)
+
+	vol, err := client.GetVolume(ctx, opts.VolumeID)
+	if err != nil {
+		return err
+	}
+	if vol.Status != "available" {
+		return fmt.Errorf("volume %s is '%s'; detach it first, Cinder only reverts 'available' volumes", vol.ID, vol.Status)
+	}
+
+	t := newStyledTable("VOLUME ID", "VOLUME NAME", "SNAPSHOT ID", "POLICY", "SNAPSHOT CREATED AT")
+	t.Row(vol.ID, vol.Name, snap.ID, meta.PolicyType, formatReportTime(snap.CreatedAt))
+	fmt.Println(t)
+
+	if !confirmed {
+		return fmt.Errorf("reverting discards everything written to volume %s after %s; re-run with --yes to proceed",
+			vol.ID, snap.CreatedAt.Format(time.RFC3339))
+	}
+
+	logger.Info("Reverting volume to snapshot", "snapshot_created_at", snap.CreatedAt, "policy_type", meta.PolicyType)
+	reqID, err := client.RevertVolumeToSnapshot(ctx, vol.ID, snap.ID)
+	if err != nil {
+		logger.Error("Revert failed", "error", err, "request_id", reqID)
+		return err
+	}
+
+	audit := policy.RestoreMetadata{
+		Mode:              policy.RestoreModeRevert,
+		SourceSnapshotID:  snap.ID,
+		SourceVolumeID:    vol.ID,
+		PolicyType:        meta.PolicyType,
+		SnapshotCreatedAt: snap.CreatedAt,
+		RestoredAt:        time.Now(),
+		RequestedBy:       cloudName,
+		Reason:            opts.Reason,
+	}
+	// CreateVolumeSubscription merges the tags into the existing metadata, keeping the volume's policies.
+	if _, auditReqID, err := client.CreateVolumeSubscription(ctx, vol.ID, audit.ToOpenstackMetadata()); err != nil {
+		logger.Error("Volume reverted, but recording the restore in its metadata failed", "error", err, "request_id", auditReqID)
+		return fmt.Errorf("volume reverted, but recording the restore failed: %w", err)
+	}
+
+	logger.Info("Volume reverted successfully", "request_id", reqID)
+	return nil
+}
+
+// selectRestoreSnapshot resolves the snapshot described by opts, together with its parsed metadata.
+func selectRestoreSnapshot(ctx context.Context, client *openstack.Client, opts RestoreOptions) (snapshots.Snapshot, policy.SnapshotMetadata, error) {
+	meta := policy.SnapshotMetadata{}
+
+	// A. Explicit Snapshot
+	if opts.SnapshotID != "" {
+		snap, err := client.GetSnapshot(ctx, opts.SnapshotID)
+		if err != nil {
+			return snap, meta, err
+		}
+		if opts.VolumeID != "" && snap.VolumeID != opts.VolumeID {
+			return snap, meta, fmt.Errorf("snapshot %s belongs to volume %s, not %s", snap.ID, snap.VolumeID, opts.VolumeID)
+		}
+		if snap.Status != "available" {
+			return snap, meta, fmt.Errorf("snapshot %s is '%s', not 'available'", snap.ID, snap.Status)
+		}
+		_ = meta.ParseFromMetadata(snap.Metadata)
+		return snap, meta, nil
+	}
+
+	// B. Newest Matching Managed Snapshot
+	if opts.VolumeID == "" {
+		return snapshots.Snapshot{}, meta, fmt.Errorf("either a snapshot ID or a volume ID is required")
+	}
+
+	snaps, err := client.ListManagedVolumeSnapshots(ctx, opts.VolumeID, "", false)
+	if err != nil {
+		return snapshots.Snapshot{}, meta, err
+	}
+	sortNewestFirst(snaps)
+
+	for _, snap := range snaps {
+		// Snapshots still in flight are listed as restore points, but cannot be restored yet.
+		if snap.Status != policy.SnapshotStatusAvailable {
+			continue
+		}
+		m := policy.SnapshotMetadata{}
+		_ = m.ParseFromMetadata(snap.Metadata)
+		if opts.PolicyType != "" && !m.CoversPolicy(opts.PolicyType) {
+			continue
+		}
+		if !opts.Before.IsZero() && !snap.CreatedAt.Before(opts.Before) {
+			continue
+		}
+		return snap, m, nil
+	}
+
+	description := "managed snapshot"
+	if opts.PolicyType != "" {
+		description = fmt.Sprintf("managed %s snapshot", opts.PolicyType)
+	}
+	if !opts.Before.IsZero() {
+		description += " created before " + opts.Before.Format(time.RFC3339)
+	}
+	return snapshots.Snapshot{}, meta, fmt.Errorf("volume %s has no %s", opts.VolumeID, description)
+}
+
+// sortNewestFirst orders snapshots by creation time, newest first, instead of relying on the API sort order.
+func sortNewestFirst(snaps []snapshots.Snapshot) {
+	slices.SortStableFunc(snaps, func(a, b snapshots.Snapshot) int { return b.CreatedAt.Compare(a.CreatedAt) })
+}
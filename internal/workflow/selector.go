@@ -0,0 +1,244 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/cloud/openstack"
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/policy"
+	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/volumes"
+)
+
+// uuidPattern recognizes server IDs, so that selecting by ID needs no Compute API call.
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// VolumeSelector matches the volumes of a project for bulk subscription. Every criterion that is set must match.
+//
+// Fields:
+//   - NameRegex: Regular expression matched against the volume name.
+//   - Metadata: Key/value pairs the volume metadata must contain (filtered by Cinder).
+//   - Server: Server the volume is attached to, by ID or exact name.
+//   - VolumeType, AvailabilityZone: Exact volume type and availability zone.
+type VolumeSelector struct {
+	NameRegex        string
+	Metadata         map[string]string
+	Server           string
+	VolumeType       string
+	AvailabilityZone string
+}
+
+// IsEmpty reports whether no criterion is set.
+func (s VolumeSelector) IsEmpty() bool {
+	return s.NameRegex == "" && len(s.Metadata) == 0 && s.Server == "" && s.VolumeType == "" && s.AvailabilityZone == ""
+}
+
+// String describes the selector for logs and messages.
+func (s VolumeSelector) String() string {
+	var parts []string
+	if s.NameRegex != "" {
+		parts = append(parts, "name=~"+s.NameRegex)
+	}
+	for _, key := range slices.Sorted(maps.Keys(s.Metadata)) {
+		parts = append(parts, key+"="+s.Metadata[key])
+	}
+	if s.Server != "" {
+		parts = append(parts, "server="+s.Server)
+	}
+	if s.VolumeType != "" {
+		parts = append(parts, "volume_type="+s.VolumeType)
+	}
+	if s.AvailabilityZone != "" {
+		parts = append(parts, "availability_zone="+s.AvailabilityZone)
+	}
+	return strings.Join(parts, ",")
+}
+
+// SubscriptionTarget selects the volumes a subscribe command applies to: one volume by ID,
+// or every volume matched by Selector.
+//
+// Fields:
+//   - Confirmed: Applies a selector subscription. Without it the matched volumes are only previewed.
+//   - Concurrency: Number of volumes updated in parallel for a selector.
+//   - Output: Where the preview and the per-volume results of a selector are printed (os.Stdout if nil).
+type SubscriptionTarget struct {
+	VolumeID    string
+	Selector    VolumeSelector
+	Confirmed   bool
+	Concurrency int
+	Output      io.Writer
+}
+
+// String returns the volume ID, or the description of the selector.
+func (t SubscriptionTarget) String() string {
+	if t.VolumeID != "" {
+		return t.VolumeID
+	}
+	return t.Selector.String()
+}
+
+// Validate checks that the target names exactly one volume or a selector.
+func (t SubscriptionTarget) Validate() error {
+	switch {
+	case t.VolumeID == "" && t.Selector.IsEmpty():
+		return fmt.Errorf("either a volume ID or a volume selector is required")
+	case t.VolumeID != "" && !t.Selector.IsEmpty():
+		return fmt.Errorf("a volume ID and a volume selector cannot be combined")
+	}
+	if t.Selector.NameRegex != "" {
+		if _, err := regexp.Compile(t.Selector.NameRegex); err != nil {
+			return fmt.Errorf("invalid name pattern '%s': %w", t.Selector.NameRegex, err)
+		}
+	}
+	return nil
+}
+
+// selectVolumes lists the volumes of the project matched by the selector.
+// The metadata criterion is filtered by Cinder; the others are matched client-side by matchVolumes.
+func selectVolumes(ctx context.Context, client *openstack.Client, s VolumeSelector) ([]volumes.Volume, error) {
+	var serverIDs []string
+	if s.Server != "" {
+		serverIDs = []string{s.Server}
+		if !uuidPattern.MatchString(s.Server) {
+			var err error
+			if serverIDs, err = client.FindServerIDs(ctx, s.Server); err != nil {
+				return nil, err
+			}
+			if len(serverIDs) == 0 {
+				return nil, fmt.Errorf("no server named '%s' found", s.Server)
+			}
+		}
+	}
+
+	all, err := client.ListVolumes(ctx, s.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	return matchVolumes(all, s, serverIDs)
+}
+
+// matchVolumes returns the volumes matching the name pattern, volume type and availability zone of the selector,
+// and, if it selects a server, attached to one of serverIDs (the server resolved to its IDs).
+// The metadata criterion is not checked; Cinder filtered it when listing.
+func matchVolumes(all []volumes.Volume, s VolumeSelector, serverIDs []string) ([]volumes.Volume, error) {
+	var nameRegex *regexp.Regexp
+	if s.NameRegex != "" {
+		var err error
+		if nameRegex, err = regexp.Compile(s.NameRegex); err != nil {
+			return nil, fmt.Errorf("invalid name pattern '%s': %w", s.NameRegex, err)
+		}
+	}
+
+	var selected []volumes.Volume
+	for _, vol := range all {
+		switch {
+		case nameRegex != nil && !nameRegex.MatchString(vol.Name):
+		case s.VolumeType != "" && vol.VolumeType != s.VolumeType:
+		case s.AvailabilityZone != "" && vol.AvailabilityZone != s.AvailabilityZone:
+		case s.Server != "" && !slices.ContainsFunc(vol.Attachments, func(a volumes.Attachment) bool {
+			return slices.Contains(serverIDs, a.ServerID)
+		}):
+		default:
+			selected = append(selected, vol)
+		}
+	}
+	return selected, nil
+}
+
+// applyBulkSubscription writes the policy tags to every volume matched by the target's selector.
+//
+// Workflow:
+//  1. Selection: Lists the matching volumes and prints them as a preview to w.
+//  2. Confirmation: Without target.Confirmed, stops after the preview with a hint to re-run with --yes.
+//  3. Apply: Updates the volumes on target.Concurrency workers, each with the same merge as a single
+//     subscription, and prints the result per volume. Failed volumes do not stop the others.
+func applyBulkSubscription(ctx context.Context, client *openstack.Client, w io.Writer, target SubscriptionTarget, metadata map[string]string, logger *slog.Logger) error {
+	// 1. Selection
+	selected, err := selectVolumes(ctx, client, target.Selector)
+	if err != nil {
+		logger.Error("Volume selection failed", "error", err)
+		return err
+	}
+	if len(selected) == 0 {
+		return fmt.Errorf("no volume matches the selector %s", target.Selector)
+	}
+
+	preview := newStyledTable("VOLUME ID", "VOLUME NAME", "STATUS", "VOLUME TYPE", "AVAILABILITY ZONE", "SERVER ID", "MANAGED")
+	for _, vol := range selected {
+		var serverID string
+		if len(vol.Attachments) > 0 {
+			serverID = vol.Attachments[0].ServerID
+		}
+		preview.Row(vol.ID, vol.Name, vol.Status, vol.VolumeType, vol.AvailabilityZone, serverID, vol.Metadata[policy.ManagedTag])
+	}
+	if _, err := fmt.Fprintln(w, preview); err != nil {
+		return err
+	}
+
+	// 2. Confirmation
+	if !target.Confirmed {
+		_, err := fmt.Fprintf(w, "%d volumes match the selector %s; re-run with --yes to apply the subscription\n", len(selected), target.Selector)
+		return err
+	}
+
+	// 3. Apply
+	logger.Info("Applying subscription policy to volumes", "volume_count", len(selected), "concurrency", target.Concurrency)
+
+	type applyResult struct {
+		requestID string
+		err       error
+	}
+	results := make([]applyResult, len(selected))
+	queue := make(chan int)
+	var workers sync.WaitGroup
+
+	for range min(max(1, target.Concurrency), len(selected)) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for i := range queue {
+				vol := selected[i]
+				_, reqID, err := client.CreateVolumeSubscription(ctx, vol.ID, metadata)
+				results[i] = applyResult{requestID: reqID, err: err}
+				if err != nil {
+					logger.Error("Failed to update volume metadata", "volume_id", vol.ID, "error", err, "request_id", reqID)
+					continue
+				}
+				logger.Debug("Subscription applied", "volume_id", vol.ID, "request_id", reqID)
+			}
+		}()
+	}
+	for i := range selected {
+		queue <- i
+	}
+	close(queue)
+	workers.Wait()
+
+	failed := 0
+	t := newStyledTable("VOLUME ID", "VOLUME NAME", "OUTCOME", "REQUEST ID", "ERROR")
+	for i, vol := range selected {
+		outcome, errMsg := "applied", ""
+		if results[i].err != nil {
+			failed++
+			outcome, errMsg = OutcomeFailed, results[i].err.Error()
+		}
+		t.Row(vol.ID, vol.Name, outcome, results[i].requestID, errMsg)
+	}
+	if _, err := fmt.Fprintln(w, t); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		logger.Error("Subscription failed on some volumes", "failed", failed, "applied", len(selected)-failed)
+		return fmt.Errorf("subscription failed on %d of %d volumes", failed, len(selected))
+	}
+
+	logger.Info("Subscription applied successfully", "volume_count", len(selected))
+	return nil
+}
@@ -0,0 +1,95 @@
+package workflow
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/volumes"
+)
+
+func TestMatchVolumes(t *testing.T) {
+	attached := func(serverIDs ...string) []volumes.Attachment {
+		var attachments []volumes.Attachment
+		for _, id := range serverIDs {
+			attachments = append(attachments, volumes.Attachment{ServerID: id})
+		}
+		return attachments
+	}
+	all := []volumes.Volume{
+		{ID: "vol-1", Name: "db-data", VolumeType: "ssd", AvailabilityZone: "az-1", Attachments: attached("srv-db")},
+		{ID: "vol-2", Name: "db-logs", VolumeType: "hdd", AvailabilityZone: "az-1", Attachments: attached("srv-db")},
+		{ID: "vol-3", Name: "web-root", VolumeType: "ssd", AvailabilityZone: "az-2", Attachments: attached("srv-web-1", "srv-web-2")},
+		{ID: "vol-4", Name: "spare-db", VolumeType: "ssd", AvailabilityZone: "az-2"},
+	}
+
+	tests := []struct {
+		name      string
+		selector  VolumeSelector
+		serverIDs []string
+		want      []string
+		wantErr   bool
+	}{
+		{
+			name:     "Name Regex",
+			selector: VolumeSelector{NameRegex: "^db-"},
+			want:     []string{"vol-1", "vol-2"},
+		},
+		{
+			name:     "Volume Type",
+			selector: VolumeSelector{VolumeType: "ssd"},
+			want:     []string{"vol-1", "vol-3", "vol-4"},
+		},
+		{
+			name:     "Availability Zone",
+			selector: VolumeSelector{AvailabilityZone: "az-2"},
+			want:     []string{"vol-3", "vol-4"},
+		},
+		{
+			name:      "Server Attachment",
+			selector:  VolumeSelector{Server: "web"},
+			serverIDs: []string{"srv-web-2"},
+			want:      []string{"vol-3"},
+		},
+		{
+			name:      "All Criteria Must Match",
+			selector:  VolumeSelector{NameRegex: "db", VolumeType: "ssd", Server: "srv-db"},
+			serverIDs: []string{"srv-db"},
+			want:      []string{"vol-1"},
+		},
+		{
+			name:     "Metadata Is Left To Cinder",
+			selector: VolumeSelector{Metadata: map[string]string{"env": "prod"}},
+			want:     []string{"vol-1", "vol-2", "vol-3", "vol-4"},
+		},
+		{
+			name:     "No Match",
+			selector: VolumeSelector{VolumeType: "nvme"},
+			want:     []string{},
+		},
+		{
+			name:     "Invalid Name Regex",
+			selector: VolumeSelector{NameRegex: "db-("},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, err := matchVolumes(all, tt.selector, tt.serverIDs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("matchVolumes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			got := []string{}
+			for _, vol := range selected {
+				got = append(got, vol.ID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("matchVolumes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
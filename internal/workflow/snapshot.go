@@ -11,6 +11,8 @@ import (
 
 	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/cloud"
 	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/cloud/openstack"
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/hooks"
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/metrics"
 	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/notifications"
 	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/policy"
 	"github.com/google/uuid"
@@ -21,24 +23,72 @@ import (
 //
 // Responsibilities:
 //   1. Connection: Initializes the OpenStack client with retry logic and authenticates.
-//   2. Discovery: Fetches only the volumes tagged for management (reducing API load).
-//   3. Iteration: Processes volumes sequentially to avoid rate-limiting issues.
-//      TODO: (aravindh-murugesan) Future enhancement could include controlled parallelism.
-//   4. Safety: Respects a global timeout context to prevent hung processes.
+//   2. Discovery: Fetches only the volumes tagged for management (reducing API load). With opts.DefaultsFile,
+//      volumes without a policy of their own inherit the project defaults (policy.Defaults) for the run.
+//   3. Iteration: Processes VM groups, multi-attached and unattached volumes with a bounded worker pool
+//      (opts.Concurrency). The volumes of a VM group still start together. With opts.RateLimit, all
+//      API calls of the run share one client-side rate limiter. Volumes of a VM that opted into group
+//      snapshots (policy.GroupSnapshotConfig) are snapshotted with one Cinder group snapshot instead.
+//      VMs with quiesce hooks (policy.QuiesceHookConfig) get their pre hook before the first and their post
+//      hook after the last snapshot; hooks only run when a snapshot is due and never during a dry-run.
+//      Snapshots are created asynchronously: the create requests are awaited together after all groups were
+//      processed, up to a per volume type/backend creation timeout (opts.CreationTimeout, opts.CreationTimeouts).
+//      Snapshots that are not available by then are left pending for the next run, which confirms or cleans them up.
+//      Snapshots reserve from the project quota read at the start of the run; when it is exhausted, volumes with a
+//      higher priority (policy.PriorityTag) are served first, also across workers, and the rest are reported once
+//      (QuotaExhausted).
+//   4. Backup Export: Volumes with a backup policy (policy.BackupConfig) get the newest snapshot of the
+//      configured policy type exported to a Cinder backup.
+//   5. Safety: Respects a global timeout context to prevent hung processes.
+//   6. Blackouts: While one of opts.Blackouts is active, due snapshots and backups are deferred (recorded, not created).
+//      Paused volumes (policy.PauseConfig) are skipped. Both are recorded in the report with their reason.
+//   7. Dry-Run: With opts.DryRun, policies are evaluated as usual but nothing is created or relabelled;
+//      the run report is rendered to stdout as a plan in opts.OutputFormat.
+//   8. Reporting: Every policy outcome is collected in a RunReport keyed by the snapsentry_id. It is returned
+//      and, with opts.ReportPath, written as JSON.
 //
 // Parameters:
 //   - cloudName: The profile name from `clouds.yaml`.
 //   - timeoutSeconds: Hard limit for the job duration.
 
-func RunProjectSnapshotWorkflow(cloudName string, timeoutSeconds int, notifyProvider notifications.Webhook, logLevel string) error {
+func RunProjectSnapshotWorkflow(cloudName string, timeoutSeconds int, notifyProvider notifications.Notifier, logLevel string, opts RunOptions) (*RunReport, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
 	// 1. Initialize Structured Logger
 	// We use slog with tint for colorized, human-readable logs in development/CLI usage.
 	logger := SetupLogger(logLevel, cloudName)
 
 	snapsentryRunID := fmt.Sprintf("req-%s", uuid.New().String())
 	logger = logger.With("snapsentry_id", snapsentryRunID)
+
+	report := NewRunReport(snapsentryRunID, "create-snapshots", opts.DryRun)
+	if opts.DryRun {
+		logger = logger.With("dry_run", true)
+		// A plan has no side effects, notifications included.
+		notifyProvider = nil
+	}
 	logger.Info("Initializing snapshot lifecycle workflow")
 
+	// Policies are still evaluated during a blackout, so the deferred snapshots show up in the report.
+	blackouts, _ := policy.ParseBlackoutWindows(opts.Blackouts, opts.BlackoutTimeZone) // validated by opts.Validate
+	if w, until, ok := policy.ActiveBlackout(blackouts, time.Now()); ok {
+		report.Blackout = fmt.Sprintf("'%s' until %s", w.Spec, until.UTC().Format(time.RFC3339))
+		logger.Warn("Blackout window active; snapshot creation is deferred", "blackout", w.Spec, "until", until)
+	}
+
+	// The defaults file is read on every run, so a change is picked up without a restart.
+	var defaults *policy.Defaults
+	if opts.DefaultsFile != "" {
+		d, err := loadDefaultsFile(opts.DefaultsFile)
+		if err != nil {
+			logger.Error("Invalid defaults file", "file", opts.DefaultsFile, "error", err)
+			return completeRun(report, opts, notifyProvider, logger, err)
+		}
+		defaults = &d
+	}
+
 	// 2. Setup Context (Optional Timeout)
 	// This ensures the job doesn't hang indefinitely if the API becomes unresponsive.
 	ctx := context.Background()
@@ -60,55 +110,191 @@ func RunProjectSnapshotWorkflow(cloudName string, timeoutSeconds int, notifyProv
 			MaxDelay:         10 * time.Second,
 			OperationTimeout: 30 * time.Second,
 		},
+		// Shared by all workers, so the limit applies to the whole run.
+		RateLimiter: openstack.NewRateLimiter(opts.RateLimit),
 	}
 
 	logger.Debug("Attempting to connect to OpenStack", "profile", cloudName)
 	if err := ostk.NewClient(); err != nil {
 		logger.Error("OpenStack client initialization failed", "error", err)
-		return fmt.Errorf("client initialization failed: %w", err)
+		return completeRun(report, opts, notifyProvider, logger, fmt.Errorf("client initialization failed: %w", err))
 	}
 	logger.Debug("OpenStack connection established successfully")
 
 	// 4. Fetch Subscribed Volumes
-	// Only volumes with the specific management tag are retrieved to reduce processing overhead.
-	logger.Debug("Querying for subscribed volumes", "tag", policy.ManagedTag)
-	managedVolumes, err := ostk.ListSubscribedVolumes(ctx)
+	// Only volumes with the specific management tag are retrieved to reduce processing overhead,
+	// unless project defaults apply to volumes without a policy of their own.
+	managedVolumes, err := discoverVolumes(ctx, &ostk, defaults, logger)
 	if err != nil {
 		logger.Error("Volume discovery failed", "error", err)
-		return fmt.Errorf("listing volumes failed: %w", err)
+		return completeRun(report, opts, notifyProvider, logger, fmt.Errorf("listing volumes failed: %w", err))
 	}
 
 	logger.Info("Subscribed volume discovery completed", "volume_count", len(managedVolumes))
+	metrics.SubscribedVolumes.Set(float64(len(managedVolumes)))
+
+	// 4b. Index Snapshot History
+	// The managed snapshots of the project are listed once and shared by all workers, instead of listing
+	// the history of every volume for every policy. Without the index, the history is listed per volume.
+	if len(managedVolumes) > 0 {
+		index, err := ostk.BuildSnapshotIndex(ctx)
+		if err != nil {
+			logger.Warn("Snapshot history indexing failed; listing history per volume", "error", err)
+		} else {
+			ostk.SnapshotIndex = index
+			logger.Info("Snapshot history indexed", "snapshot_count", index.Len())
+		}
+	}
+
+	// 4c. Reconcile Pending Snapshots
+	// Snapshots a previous run stopped waiting for are confirmed or cleaned up before the policies are evaluated.
+	timeouts, _ := opts.creationTimeouts() // validated by opts.Validate
+	reconcilePreviousSnapshots(ctx, &ostk, managedVolumes, timeouts, time.Now(), notifyProvider, report, logger)
 
-	// 5. Process Volumes Sequentially
-	// We process volumes one by one rather than in parallel to avoid hitting OpenStack API rate limits.
+	// 4d. Check Snapshot Quota
+	// The quota is read once; snapshots reserve from it, so an exhausted quota is not hit on every volume.
+	quotaUsage := checkSnapshotQuota(ctx, &ostk, managedVolumes, report, logger)
+
+	// 5. Process Volume Groups
+	// Groups are distributed over a bounded pool of workers; the API load is further capped by the rate limiter.
 	var successCount int32
 	var errorCount int32
 
 	groupedVolumes := ostk.GroupVolumeByVMAttachment(managedVolumes)
 
-	logger.Debug("Starting to process single-attached volumes", "vm_count", len(groupedVolumes.Attached))
+	groups := make([]volumeGroup, 0, len(groupedVolumes.Attached)+len(groupedVolumes.MultiAttached)+len(groupedVolumes.Unattached))
 	for vm, vols := range groupedVolumes.Attached {
-		logger.Debug("Starting to process volumes attached to a VM", "vm_id", vm, "volume_count", len(vols))
-		processVolumeGroup(ctx, &ostk, vols, &successCount, &errorCount, notifyProvider, logger)
+		groups = append(groups, volumeGroup{vmID: vm, vols: vols})
 	}
-
-	logger.Debug("Starting to process multi-attached volumes", "count", len(groupedVolumes.MultiAttached))
 	for _, vol := range groupedVolumes.MultiAttached {
-		processVolumeGroup(ctx, &ostk, []volumes.Volume{vol}, &successCount, &errorCount, notifyProvider, logger)
+		groups = append(groups, volumeGroup{vols: []volumes.Volume{vol}})
 	}
-
-	logger.Debug("Starting to process unattached volumes", "count", len(groupedVolumes.Unattached))
 	for _, vol := range groupedVolumes.Unattached {
-		processVolumeGroup(ctx, &ostk, []volumes.Volume{vol}, &successCount, &errorCount, notifyProvider, logger)
+		groups = append(groups, volumeGroup{vols: []volumes.Volume{vol}})
 	}
+	// Volumes with a higher priority reserve the snapshot quota first.
+	orderQuotaReservations(groups, report)
+	// Group membership follows the volumes on every run, not only when a group snapshot is due.
+	reconcileVolumeGroups(ctx, &ostk, groups, opts.DryRun, logger)
+
+	logger.Debug("Starting to process volume groups",
+		"vm_count", len(groupedVolumes.Attached),
+		"multi_attached_count", len(groupedVolumes.MultiAttached),
+		"unattached_count", len(groupedVolumes.Unattached),
+		"concurrency", max(1, opts.Concurrency),
+		"rate_limit", opts.RateLimit)
+	processVolumeGroups(ctx, &ostk, groups, opts.Concurrency, opts.hookRunner(), &successCount, &errorCount, notifyProvider, report, logger)
+
+	// 5b. Reconcile Created Snapshots
+	// Snapshot creation is asynchronous: the snapshots requested above are awaited here, up to their creation timeout.
+	reconcileCreatedSnapshots(ctx, &ostk, timeouts, notifyProvider, report, logger)
+	notifyQuotaExhausted(ctx, &ostk, quotaUsage, notifyProvider, report, logger)
+
+	// 6. Export Backups
+	// Runs after creation so that a snapshot taken in this run is exported in the same run.
+	processBackupExports(ctx, &ostk, managedVolumes, notifyProvider, report, logger)
 
 	logger.Info("Snapshot workflow execution summary for evaluation. This only refers to snapsentry processing and excludes openstack api errors",
 		"volumes_processed", len(managedVolumes),
 		"success_count", successCount,
 		"error_count", errorCount)
 
-	return nil
+	report.Summary.VolumesProcessed = len(managedVolumes)
+	report.Summary.VolumesSucceeded = int(successCount)
+	report.Summary.VolumesFailed = int(errorCount)
+
+	return completeRun(report, opts, notifyProvider, logger, nil)
+}
+
+// volumeGroup is a unit of work of the snapshot workflow: the volumes of one VM, or a single
+// multi-attached or unattached volume (vmID empty).
+type volumeGroup struct {
+	vmID string
+	vols []volumes.Volume
+}
+
+// processVolumeGroups runs processVolumeGroup for every group on a pool of 'concurrency' workers (see runWorkers).
+// Quiesce hooks of VM groups are run with hookRunner.
+// Groups are handed out in order and marked finished for the quota reservation order of the run (see quotaOrder).
+//
+// Once the context is cancelled, no further group is handed out; groups already started are awaited.
+func processVolumeGroups(
+	ctx context.Context,
+	client *openstack.Client,
+	groups []volumeGroup,
+	concurrency int,
+	hookRunner hooks.Runner,
+	successCounter *int32,
+	errorCounter *int32,
+	notifyProvider notifications.Notifier,
+	report *RunReport,
+	logger *slog.Logger,
+) {
+	process := func(group volumeGroup) {
+		if group.vmID == "" {
+			processVolumeGroup(ctx, client, group.vols, successCounter, errorCounter, notifyProvider, report, logger)
+			return
+		}
+
+		groupLogger := logger.With("vm_id", group.vmID)
+		groupLogger.Debug("Starting to process volumes attached to a VM", "volume_count", len(group.vols))
+
+		// Volumes that opted into group snapshots are snapshotted together; the others as before.
+		// With quiesce hooks, every volume of the VM is snapshotted between the same pre and post hook.
+		grouped, rest, groupType := splitGroupSnapshotVolumes(group.vols)
+		if quiesce := resolveQuiesceHooks(ctx, client, hookRunner, group.vmID, group.vols, groupLogger); quiesce != nil {
+			processDeferredVolumeGroup(ctx, client, group.vmID, group.vols, grouped, groupType, quiesce, successCounter, errorCounter, notifyProvider, report, groupLogger)
+			return
+		}
+		if len(grouped) > 0 {
+			processDeferredVolumeGroup(ctx, client, group.vmID, grouped, grouped, groupType, nil, successCounter, errorCounter, notifyProvider, report, groupLogger)
+		}
+		if len(rest) > 0 {
+			processVolumeGroup(ctx, client, rest, successCounter, errorCounter, notifyProvider, report, groupLogger)
+		}
+	}
+
+	dispatched := runWorkers(ctx, len(groups), concurrency, func(i int) {
+		process(groups[i])
+		report.quotaOrder.finish(i)
+	})
+	if dispatched < len(groups) {
+		logger.Error("Workflow execution halted due to timeout or cancellation", "remaining_groups", len(groups)-dispatched)
+	}
+}
+
+// runWorkers calls work for the indices 0 to n-1 on a pool of 'concurrency' workers, handing them out in order.
+// A concurrency below 1 is treated as 1 (sequential).
+//
+// Once the context is cancelled, no further index is handed out; work already started is awaited.
+// It returns the number of indices handed out.
+func runWorkers(ctx context.Context, n int, concurrency int, work func(i int)) int {
+	queue := make(chan int)
+	var workers sync.WaitGroup
+
+	for range min(max(1, concurrency), max(1, n)) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for i := range queue {
+				work(i)
+			}
+		}()
+	}
+
+	dispatched := 0
+dispatch:
+	for ; dispatched < n && ctx.Err() == nil; dispatched++ {
+		select {
+		case queue <- dispatched:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(queue)
+
+	workers.Wait()
+	return dispatched
 }
 
 // processVolumeGroup executes snapshot logic for a list of volumes concurrently.
@@ -124,6 +310,7 @@ func RunProjectSnapshotWorkflow(cloudName string, timeoutSeconds int, notifyProv
 //   - client: Authenticated OpenStack client.
 //   - vols: Slice of volumes to process (usually belonging to the same VM).
 //   - success/errorCounter: Pointers to thread-safe counters.
+//   - report: Collects the outcome of every policy (the plan during a dry-run).
 //   - logger: Base logger (fields like 'vm_id' should already be attached).
 func processVolumeGroup(
 	ctx context.Context,
@@ -131,7 +318,8 @@ func processVolumeGroup(
 	vols []volumes.Volume,
 	successCounter *int32,
 	errorCounter *int32,
-	notifyProvider notifications.Webhook,
+	notifyProvider notifications.Notifier,
+	report *RunReport,
 	logger *slog.Logger,
 ) {
 
@@ -161,7 +349,7 @@ func processVolumeGroup(
 			volLogger.Debug("Starting processing for volume")
 
 			// Execute the core logic (policy checks, snapshot creation, etc.)
-			if err := processVolume(ctx, client, vol, notifyProvider, volLogger); err != nil {
+			if err := processVolume(ctx, client, vol, createPolicySnapshot, notifyProvider, report, volLogger); err != nil {
 				volLogger.Error("Volume processing encountered an error", "error", err)
 				// Atomic increment is required because multiple goroutines write to this address simultaneously.
 				atomic.AddInt32(errorCounter, 1)
@@ -180,18 +368,39 @@ func processVolumeGroup(
 // processVolume applies the business logic to a single volume.
 //
 // Workflow:
-//  1. Policy Loading: Instantiates Daily, Weekly, and Monthly policies and hydrates them from the volume's metadata.
+//  1. Policy Loading: Instantiates Express, Cron, Daily, Weekly, and Monthly policies and hydrates them from the volume's metadata.
 //  2. History Check: Queries OpenStack for the most recent snapshot of the specific policy type.
 //  3. Evaluation: Uses the policy's `Evaluate()` method to determine if a snapshot is needed now.
-//  4. Execution: Triggers the snapshot creation if the window is open and unsatisfied.
+//  4. Execution: Triggers the snapshot creation if the window is open and unsatisfied. During a blackout
+//     window (report.Blackout) the creation is deferred instead.
 //  5. Auditing: Writes detailed logs (Skipped/Created/Failed) to the database.
 //  6. Cleanup: Detects and deletes "zombie" snapshots if creation reports failure but leaves an ID behind.
-func processVolume(ctx context.Context, client *openstack.Client, vol volumes.Volume, notifyProvider notifications.Webhook, logger *slog.Logger) error {
+//
+// Policies of a paused volume (policy.PauseConfig) are recorded as paused without being evaluated.
+//
+// Volumes with GFS enabled hand their Daily/Weekly/Monthly policies over to processVolumeGFS
+// after validation; only Express and Cron are evaluated independently in that case.
+//
+// Every evaluated policy is recorded in the report. During a dry-run, step 4 is skipped.
+// Step 4 is delegated to 'create' (createPolicySnapshot, or the deferred creation of group snapshot mode).
+func processVolume(ctx context.Context, client *openstack.Client, vol volumes.Volume, create snapshotCreator, notifyProvider notifications.Notifier, report *RunReport, logger *slog.Logger) error {
 
 	var execErrors error
+
+	gfs := policy.GFSConfig{}
+	_ = gfs.ParseFromMetadata(vol.Metadata)
+	var gfsTiers []policy.SnapshotPolicy
+
+	pause := policy.PauseConfig{}
+	if err := pause.ParseFromMetadata(vol.Metadata); err != nil {
+		logger.Warn("Pause configuration is invalid; the volume is not paused", "err", err)
+	}
+	paused := pause.IsActive(time.Now())
+
 	// Define the order of policy evaluation.
 	policies := []policy.SnapshotPolicy{
 		&policy.SnapshotPolicyExpress{},
+		&policy.SnapshotPolicyCron{},
 		&policy.SnapshotPolicyDaily{},
 		&policy.SnapshotPolicyWeekly{},
 		&policy.SnapshotPolicyMonthly{},
@@ -211,10 +420,17 @@ func processVolume(ctx context.Context, client *openstack.Client, vol volumes.Vo
 		}
 
 		if err := p.Normalize(); err != nil {
-			// If a policy is unconfigured or disabled, Normalize returns an error.
-			// This is normal behavior for optional policies, so we just log debug and skip.
-			policyLogger.Debug("Policy configuration skipped or invalid", "err", err)
+			// The policy is enabled but its metadata is invalid, so it can never run until fixed.
+			policyLogger.Warn("Policy configuration is invalid", "err", err)
 			execErrors = errors.Join(execErrors, fmt.Errorf("%s policy configuration is invalid or skipped. %w", policyType, err))
+			report.addSnapshot(failedOutcome(vol, policyType, err))
+			sendNotification(ctx, notifyProvider, notifications.PolicyMisconfigured{
+				Service:    "snapsentry",
+				VolumeID:   vol.ID,
+				VolumeName: vol.Name,
+				PolicyType: policyType,
+				Message:    err.Error(),
+			}, policyLogger)
 			continue
 		}
 
@@ -222,6 +438,24 @@ func processVolume(ctx context.Context, client *openstack.Client, vol volumes.Vo
 			"retention_days", p.GetPolicyRetention(),
 			"type", p.GetPolicyType())
 
+		if paused {
+			policyLogger.Info("Snapshot creation skipped; volume is paused", "paused_until", pause.Until, "pause_reason", pause.Reason)
+			report.addSnapshot(SnapshotOutcome{
+				VolumeID:   vol.ID,
+				VolumeName: vol.Name,
+				PolicyType: policyType,
+				Outcome:    OutcomePaused,
+				Reason:     pause.Describe(),
+			})
+			continue
+		}
+
+		if gfs.Enabled && policy.GFSRank(policyType) > 0 {
+			policyLogger.Debug("Policy is part of the GFS hierarchy; deferring evaluation")
+			gfsTiers = append(gfsTiers, p)
+			continue
+		}
+
 		// B. Fetch Last Snapshot
 		// We need the most recent snapshot of THIS policy type to determine if a new one is needed.
 		policyLogger.Debug("Fetching snapshot history for policy")
@@ -229,6 +463,7 @@ func processVolume(ctx context.Context, client *openstack.Client, vol volumes.Vo
 		if err != nil {
 			policyLogger.Error("Snapshot history retrieval failed", "error", err)
 			execErrors = errors.Join(execErrors, fmt.Errorf("%s policy snapshot history retrieval failed. %w", policyType, err))
+			report.addSnapshot(failedOutcome(vol, policyType, err))
 			continue
 		}
 
@@ -254,6 +489,7 @@ func processVolume(ctx context.Context, client *openstack.Client, vol volumes.Vo
 		if err != nil {
 			policyLogger.Error("Policy evaluation failed", "error", err)
 			execErrors = errors.Join(execErrors, fmt.Errorf("%s policy evaluation failed. %w", policyType, err))
+			report.addSnapshot(failedOutcome(vol, policyType, err))
 			continue
 		}
 
@@ -263,87 +499,253 @@ func processVolume(ctx context.Context, client *openstack.Client, vol volumes.Vo
 				"window_start", result.Window.StartTime,
 				"window_end", result.Window.EndTime,
 			)
+			report.addSnapshot(snapshotOutcome(vol, policyType, OutcomeSkipped, result))
 			continue
 		}
 
 		// D. Execute
+		if report.Blackout != "" {
+			report.addSnapshot(deferredOutcome(vol, policyType, result, report.Blackout))
+			policyLogger.Info("Snapshot creation deferred by blackout window",
+				"blackout", report.Blackout,
+				"window_start", result.Window.StartTime,
+				"window_end", result.Window.EndTime)
+			continue
+		}
+
+		if report.DryRun {
+			report.addSnapshot(snapshotOutcome(vol, policyType, OutcomeWouldCreate, result))
+			policyLogger.Info("Dry-run: snapshot would be created",
+				"window_start", result.Window.StartTime,
+				"window_end", result.Window.EndTime,
+				"snapshot_name", generateSnapshotName(policyType, result.Window.StartTime, vol.ID))
+			continue
+		}
+
 		policyLogger.Info("Snapshot window active; initiating creation",
 			"window_start", result.Window.StartTime,
 			"window_end", result.Window.EndTime,
 			"reason", result.Reason)
 
-		snapName := generateSnapshotName(policyType, result.Window.StartTime, vol.ID)
-		snapMeta := result.Metadata.ToOpenstackMetadata()
+		if err := create(ctx, client, vol, policyType, result, notifyProvider, report, policyLogger); err != nil {
+			execErrors = errors.Join(execErrors, err)
+		}
+	}
+
+	// E. GFS Tiers
+	// Daily/Weekly/Monthly are evaluated together so that one snapshot can serve several tiers.
+	if len(gfsTiers) > 0 {
+		if err := processVolumeGFS(ctx, client, vol, gfsTiers, create, notifyProvider, report, logger); err != nil {
+			execErrors = errors.Join(execErrors, err)
+		}
+	}
+
+	return execErrors
+}
+
+// snapshotOutcome builds the report entry for an evaluated policy.
+func snapshotOutcome(vol volumes.Volume, policyType, outcome string, result policy.PolicyEvalResult) SnapshotOutcome {
+	entry := SnapshotOutcome{
+		VolumeID:       vol.ID,
+		VolumeName:     vol.Name,
+		PolicyType:     policyType,
+		Outcome:        outcome,
+		ShouldSnapshot: result.ShouldSnapshot,
+		WindowStart:    result.Window.StartTime,
+		WindowEnd:      result.Window.EndTime,
+		Reason:         result.Reason,
+		GFSCovers:      result.Metadata.GFSCovers,
+	}
+	if result.ShouldSnapshot {
+		entry.SnapshotName = generateSnapshotName(policyType, result.Window.StartTime, vol.ID)
+	}
+	return entry
+}
+
+// deferredOutcome builds the report entry for a due snapshot that the active blackout window defers.
+func deferredOutcome(vol volumes.Volume, policyType string, result policy.PolicyEvalResult, blackout string) SnapshotOutcome {
+	entry := snapshotOutcome(vol, policyType, OutcomeDeferred, result)
+	entry.Reason = "deferred by blackout window " + blackout
+	return entry
+}
+
+// failedOutcome builds the report entry for a policy that could not be evaluated.
+func failedOutcome(vol volumes.Volume, policyType string, err error) SnapshotOutcome {
+	return SnapshotOutcome{
+		VolumeID:   vol.ID,
+		VolumeName: vol.Name,
+		PolicyType: policyType,
+		Outcome:    OutcomeFailed,
+		Error:      err.Error(),
+	}
+}
+
+// snapshotCreator creates the snapshot for an evaluated policy window. createPolicySnapshot is the default.
+type snapshotCreator func(
+	ctx context.Context,
+	client *openstack.Client,
+	vol volumes.Volume,
+	policyType string,
+	result policy.PolicyEvalResult,
+	notifyProvider notifications.Notifier,
+	report *RunReport,
+	policyLogger *slog.Logger,
+) error
+
+// createPolicySnapshot starts the snapshot for an evaluated policy window.
+//
+// The snapshot is stamped with the window key of the volume, policy type and window (policy.SnapshotWindowKey),
+// which CreateManagedSnapshot checks before every create attempt.
+//
+// Each snapshot reserves its size from the project quota of the run (see checkSnapshotQuota). Snapshots that do
+// not fit, or that Cinder rejects for exceeding the quota, are recorded as quota_exceeded without a failure
+// notification; the run reports them once through notifyQuotaExhausted.
+//
+// The create request returns as soon as Cinder accepted it. The snapshot is recorded as in flight and confirmed,
+// or cleaned up, by reconcileCreatedSnapshots at the end of the run; only then is its outcome recorded.
+// A failed create request is handled by failPolicySnapshot.
+//
+// Returns an error describing the creation (and cleanup) failure, nil once the request was accepted.
+func createPolicySnapshot(
+	ctx context.Context,
+	client *openstack.Client,
+	vol volumes.Volume,
+	policyType string,
+	result policy.PolicyEvalResult,
+	notifyProvider notifications.Notifier,
+	report *RunReport,
+	policyLogger *slog.Logger,
+) error {
+	snapName := generateSnapshotName(policyType, result.Window.StartTime, vol.ID)
+	result.Metadata.WindowKey = policy.SnapshotWindowKey(vol.ID, policyType, result.Window.StartTime)
+	snapMeta := result.Metadata.ToOpenstackMetadata()
+	outcome := snapshotOutcome(vol, policyType, OutcomeCreated, result)
+
+	if !report.reserveQuota(vol.ID, 1, vol.Size) {
+		recordQuotaExceeded(vol, policyType, outcome, fmt.Sprintf("project snapshot quota does not cover a %d GB snapshot", vol.Size), report, policyLogger)
+		return nil
+	}
+
+	policyLogger.Debug("Sending create request to OpenStack", "snapshot_name", snapName, "window_key", result.Metadata.WindowKey)
+	startedAt := time.Now()
+	createdSnap, reqID, err := client.CreateManagedSnapshot(ctx, vol.ID, snapName, snapMeta)
+	outcome.RequestID = reqID
+	if err != nil {
+		report.quota.Release(1, vol.Size)
+		if openstack.IsQuotaExceeded(err) && createdSnap.ID == "" {
+			// Every further snapshot of the run would be rejected as well.
+			report.quota.Exhaust()
+			recordQuotaExceeded(vol, policyType, outcome, fmt.Sprintf("rejected by Cinder: %s", err), report, policyLogger)
+			return nil
+		}
+		return failPolicySnapshot(ctx, client, vol, policyType, result.Window, createdSnap.ID, err, outcome, notifyProvider, report, policyLogger)
+	}
+
+	outcome.SnapshotID = createdSnap.ID
+	policyLogger.Info("Snapshot creation accepted",
+		"snapshot_id", createdSnap.ID,
+		"status", createdSnap.Status,
+		"request_id", reqID,
+	)
+	report.addInflight(inflightSnapshot{
+		vol:        vol,
+		policyType: policyType,
+		result:     result,
+		snapshotID: createdSnap.ID,
+		startedAt:  startedAt,
+		outcome:    outcome,
+		logger:     policyLogger,
+	})
+	return nil
+}
 
-		policyLogger.Debug("Sending create request to OpenStack", "snapshot_name", snapName)
-		createdSnap, reqID, err := client.CreateManagedSnapshot(ctx, vol.ID, snapName, snapMeta)
-		if err == nil {
-			// Success path
-			policyLogger.Info("Snapshot resource successfully created",
-				"snapshot_id", createdSnap.ID,
-				"request_id", reqID,
+// failPolicySnapshot records a snapshot creation that failed, either when it was requested or while it was
+// awaited, with the given cause.
+//
+// Failure Handling:
+//   - Orphaned Resource Cleanup: If the failure leaves a snapshot ID behind, the partial snapshot is deleted
+//     to save quota.
+//   - Notification: The configured webhook is notified about the failure (including the cleanup outcome).
+//
+// The outcome (failed, with the cleanup result) is recorded in the report.
+// Returns an error describing the creation (and cleanup) failure.
+func failPolicySnapshot(
+	ctx context.Context,
+	client *openstack.Client,
+	vol volumes.Volume,
+	policyType string,
+	window policy.SnapshotPolicyWindow,
+	snapshotID string,
+	cause error,
+	outcome SnapshotOutcome,
+	notifyProvider notifications.Notifier,
+	report *RunReport,
+	policyLogger *slog.Logger,
+) error {
+	var execErrors error
+	defer func() { report.addSnapshot(outcome) }()
+
+	outcome.Outcome = OutcomeFailed
+	outcome.Error = cause.Error()
+	metrics.SnapshotsFailed.WithLabelValues(policyType).Inc()
+	execErrors = errors.Join(execErrors, fmt.Errorf("%s policy snapshot resource creation failed. %w", policyType, cause))
+	policyLogger.Error("Snapshot resource creation failed",
+		"error", cause,
+		"request_id", outcome.RequestID,
+		"snapshot_id", snapshotID,
+	)
+
+	snapFailNotify := notifications.SnapshotCreationFailure{
+		Service:    "snapsentry",
+		VolumeID:   vol.ID,
+		Window:     window,
+		SnapshotID: snapshotID,
+		PolicyType: policyType,
+		Message:    fmt.Sprintf("Snapsentry Snapshot has failed due to %s. ", cause),
+	}
+
+	// SAFETY CHECK: Orphaned Resource Cleanup
+	if snapshotID != "" {
+		policyLogger.Debug("Orphaned resource detected; initiating cleanup", "snapshot_id", snapshotID)
+
+		// Attempt to delete the partial/failed snapshot to save quota.
+		delReqID, cleanupErr := client.DeleteSnapshot(ctx, snapshotID)
+		outcome.OrphanSnapshotID = snapshotID
+		outcome.CleanupRequestID = delReqID
+		outcome.OrphanCleanup = OrphanCleaned
+
+		if cleanupErr != nil {
+			// CRITICAL: We failed to create it AND failed to delete the zombie resource.
+			outcome.OrphanCleanup = OrphanCleanupFailed
+			metrics.OrphanCleanups.WithLabelValues("failed").Inc()
+
+			execErrors = errors.Join(execErrors, fmt.Errorf("%s policy orphaned snapshot cleanup failed; manual intervention required. %w", policyType, cleanupErr))
+			snapFailNotify.Message += fmt.Sprintf("Orphaned snapshot cleanup failed; manual intervention required (Request ID: %s)", delReqID)
+			policyLogger.Error("Orphaned snapshot cleanup failed; manual intervention required",
+				"error", cleanupErr,
+				"snapshot_id", snapshotID,
+				"cleanup_request_id", delReqID,
 			)
-			continue
 		} else {
-			// Failure path
-			execErrors = errors.Join(execErrors, fmt.Errorf("%s policy snapshot resource creation failed. %w", policyType, err))
-			policyLogger.Error("Snapshot resource creation failed",
-				"error", err,
-				"request_id", reqID,
-				"snapshot_id", createdSnap.ID,
+			// INFO: We failed to create it, but at least we cleaned up the mess.
+			metrics.OrphanCleanups.WithLabelValues("cleaned").Inc()
+			snapFailNotify.Message += fmt.Sprintf("Orphaned snapshot successfully clean up (Request ID: %s).", delReqID)
+			policyLogger.Info("Orphaned snapshot successfully cleaned up",
+				"snapshot_id", snapshotID,
+				"cleanup_request_id", delReqID,
 			)
-
-			snapFailNotify := notifications.SnapshotCreationFailure{
+			sendNotification(ctx, notifyProvider, notifications.OrphanCleanedUp{
 				Service:    "snapsentry",
 				VolumeID:   vol.ID,
-				Window:     result.Window,
-				SnapshotID: createdSnap.ID,
-				Message:    fmt.Sprintf("Snapsentry Snapshot has failed due to %s. ", err),
-			}
-
-			// SAFETY CHECK: Orphaned Resource Cleanup
-			if createdSnap.ID != "" {
-				policyLogger.Debug("Orphaned resource detected; initiating cleanup",
-					"snapshot_id", createdSnap.ID,
-					"status", createdSnap.Status,
-				)
-
-				// Attempt to delete the partial/failed snapshot to save quota.
-				delReqID, cleanupErr := client.DeleteSnapshot(ctx, createdSnap.ID)
-
-				if cleanupErr != nil {
-					// CRITICAL: We failed to create it AND failed to delete the zombie resource.
-
-					execErrors = errors.Join(execErrors, fmt.Errorf("%s policy orphaned snapshot cleanup failed; manual intervention required. %w", policyType, cleanupErr))
-					snapFailNotify.Message += fmt.Sprintf("Orphaned snapshot cleanup failed; manual intervention required (Request ID: %s)", delReqID)
-					policyLogger.Error("Orphaned snapshot cleanup failed; manual intervention required",
-						"error", cleanupErr,
-						"snapshot_id", createdSnap.ID,
-						"cleanup_request_id", delReqID,
-					)
-				} else {
-					// INFO: We failed to create it, but at least we cleaned up the mess.
-					snapFailNotify.Message += fmt.Sprintf("Orphaned snapshot successfully clean up (Request ID: %s).", delReqID)
-					policyLogger.Info("Orphaned snapshot successfully cleaned up",
-						"snapshot_id", createdSnap.ID,
-						"cleanup_request_id", delReqID,
-					)
-				}
-			}
-
-			if notifyProvider.URL != "" {
-				policyLogger.Debug("Attempting to notify via configured webhook", "provider", notifyProvider.URL)
-				err := notifyProvider.Notify(snapFailNotify)
-				if err != nil {
-					policyLogger.Error("Notification failed to send", "webhook", notifyProvider.URL, "err", err)
-				} else {
-					policyLogger.Info("Notification sent for the snapshot failure", "webhook", notifyProvider.URL)
-				}
-			} else {
-				policyLogger.Debug("Skip notification", "reason", "No webhook provider is configured by the user")
-			}
+				PolicyType: policyType,
+				SnapshotID: snapshotID,
+				RequestID:  delReqID,
+				Message:    fmt.Sprintf("Snapshot creation failed due to %s", cause),
+			}, policyLogger)
 		}
 	}
 
+	sendNotification(ctx, notifyProvider, snapFailNotify, policyLogger)
+
 	return execErrors
 }
@@ -0,0 +1,149 @@
+package workflow
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/cloud/openstack"
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/notifications"
+	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/policy"
+	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/snapshots"
+	"github.com/gophercloud/gophercloud/v2/openstack/blockstorage/v3/volumes"
+)
+
+// dailyVolume builds a volume subscribed to a daily policy starting at midnight UTC, with extra metadata merged in.
+func dailyVolume(id string, extra map[string]string) volumes.Volume {
+	metadata := map[string]string{
+		policy.ManagedTag:                   "true",
+		"x-snapsentry-daily-enabled":        "true",
+		"x-snapsentry-daily-retention-days": "7",
+		"x-snapsentry-daily-timezone":       "UTC",
+		"x-snapsentry-daily-start-time":     "00:00",
+	}
+	maps.Copy(metadata, extra)
+	return volumes.Volume{ID: id, Name: id, Metadata: metadata}
+}
+
+func TestProcessVolumeDryRun(t *testing.T) {
+	now := time.Now().UTC()
+	taken := policy.SnapshotMetadata{Managed: true, PolicyType: "daily", ExpiryDate: now.AddDate(0, 0, 7)}
+
+	tests := []struct {
+		name     string
+		vol      volumes.Volume
+		existing []snapshots.Snapshot
+		blackout string
+		want     string
+	}{
+		{name: "Due", vol: dailyVolume("vol-1", nil), want: OutcomeWouldCreate},
+		{
+			name:     "Window Already Satisfied",
+			vol:      dailyVolume("vol-1", nil),
+			existing: []snapshots.Snapshot{{ID: "snap-1", VolumeID: "vol-1", Status: "available", CreatedAt: now, Metadata: taken.ToOpenstackMetadata()}},
+			want:     OutcomeSkipped,
+		},
+		{name: "Blackout", vol: dailyVolume("vol-1", nil), blackout: "'daily 00:00-23:59'", want: OutcomeDeferred},
+		{name: "Paused", vol: dailyVolume("vol-1", map[string]string{"x-snapsentry-paused": "true"}), want: OutcomePaused},
+		{name: "Invalid Policy", vol: dailyVolume("vol-1", map[string]string{"x-snapsentry-daily-timezone": "Mars/Base"}), want: OutcomeFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &openstack.Client{SnapshotIndex: openstack.NewSnapshotIndex(tt.existing)}
+			report := NewRunReport("req-test", "create-snapshots", true)
+			report.Blackout = tt.blackout
+
+			create := func(context.Context, *openstack.Client, volumes.Volume, string, policy.PolicyEvalResult, notifications.Notifier, *RunReport, *slog.Logger) error {
+				t.Errorf("snapshot created during a dry-run")
+				return nil
+			}
+			_ = processVolume(context.Background(), client, tt.vol, create, nil, report, testLogger)
+
+			got := []string{}
+			for _, s := range report.Snapshots {
+				got = append(got, s.Outcome)
+			}
+			if want := []string{tt.want}; !slices.Equal(got, want) {
+				t.Errorf("outcomes = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestRunWorkers(t *testing.T) {
+	tests := []struct {
+		name        string
+		n           int
+		concurrency int
+		wantWorkers int
+	}{
+		{name: "Sequential", n: 5, concurrency: 1, wantWorkers: 1},
+		{name: "Concurrency Below One", n: 5, concurrency: 0, wantWorkers: 1},
+		{name: "Bounded Pool", n: 12, concurrency: 4, wantWorkers: 4},
+		{name: "More Workers Than Work", n: 2, concurrency: 8, wantWorkers: 2},
+		{name: "No Work", n: 0, concurrency: 4, wantWorkers: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var running, maxRunning int
+			calls := make([]int, tt.n)
+
+			dispatched := runWorkers(context.Background(), tt.n, tt.concurrency, func(i int) {
+				mu.Lock()
+				calls[i]++
+				running++
+				maxRunning = max(maxRunning, running)
+				mu.Unlock()
+
+				time.Sleep(5 * time.Millisecond)
+
+				mu.Lock()
+				running--
+				mu.Unlock()
+			})
+
+			if dispatched != tt.n {
+				t.Errorf("runWorkers() = %d, want %d", dispatched, tt.n)
+			}
+			for i, c := range calls {
+				if c != 1 {
+					t.Errorf("index %d processed %d times, want once", i, c)
+				}
+			}
+			if maxRunning != tt.wantWorkers {
+				t.Errorf("max concurrent work = %d, want %d", maxRunning, tt.wantWorkers)
+			}
+		})
+	}
+}
+
+func TestRunWorkers_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var mu sync.Mutex
+	var processed []int
+	dispatched := runWorkers(ctx, 10, 2, func(i int) {
+		mu.Lock()
+		processed = append(processed, i)
+		mu.Unlock()
+		if i == 2 {
+			cancel()
+		}
+		time.Sleep(5 * time.Millisecond)
+	})
+
+	// The index handed out while the cancellation raced the dispatch may still run.
+	if dispatched < 3 || dispatched > 5 {
+		t.Errorf("runWorkers() = %d, want 3 to 5 after cancelling at index 2", dispatched)
+	}
+	if len(processed) != dispatched {
+		t.Errorf("processed %d indices, want the %d handed out", len(processed), dispatched)
+	}
+}
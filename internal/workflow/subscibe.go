@@ -3,6 +3,10 @@ package workflow
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
 	"time"
 
 	"github.com/aravindh-murugesan/openstack-snapsentry-go/internal/cloud"
@@ -27,16 +31,27 @@ func initClient(cloudName string, logLevel string) (*openstack.Client, error) {
 	return &ostk, nil
 }
 
-// SubscribeVolumeDaily configures the Daily policy on a volume.
-func SubscribeVolumeExpress(cloudName, logLevel, volID string, enabled bool, retention int, tz string, interval int) error {
-	logger := SetupLogger(logLevel, cloudName).With("workflow", "subscribe-daily", "volume_id", volID)
+// retentionTypeFor picks count based retention when a retention count was requested.
+func retentionTypeFor(retentionCount int) string {
+	if retentionCount > 0 {
+		return policy.RetentionTypeCount
+	}
+	return policy.RetentionTypeTime
+}
+
+// SubscribeVolumeExpress configures the Express policy on a volume.
+func SubscribeVolumeExpress(cloudName, logLevel string, target SubscriptionTarget, enabled bool, retention int, retentionCount int, tz string, interval int, intervalMinutes int, start string) error {
+	logger := SetupLogger(logLevel, cloudName).With("workflow", "subscribe-express", "target", target.String())
 
 	p := policy.SnapshotPolicyExpress{
-		Enabled:       enabled,
-		RetentionDays: retention,
-		RetentionType: "time",
-		IntervalHours: interval,
-		TimeZone:      tz,
+		Enabled:         enabled,
+		RetentionDays:   retention,
+		RetentionType:   retentionTypeFor(retentionCount),
+		RetentionCount:  retentionCount,
+		IntervalHours:   interval,
+		IntervalMinutes: intervalMinutes,
+		StartTime:       start,
+		TimeZone:        tz,
 	}
 
 	if err := p.Normalize(); err != nil {
@@ -44,19 +59,20 @@ func SubscribeVolumeExpress(cloudName, logLevel, volID string, enabled bool, ret
 		return err
 	}
 
-	return applySubscription(cloudName, logLevel, volID, p.ToOpenstackMetadata(), logger)
+	return applySubscription(cloudName, logLevel, target, p.ToOpenstackMetadata(), logger)
 }
 
 // SubscribeVolumeDaily configures the Daily policy on a volume.
-func SubscribeVolumeDaily(cloudName, logLevel, volID string, enabled bool, retention int, start, tz string) error {
-	logger := SetupLogger(logLevel, cloudName).With("workflow", "subscribe-daily", "volume_id", volID)
+func SubscribeVolumeDaily(cloudName, logLevel string, target SubscriptionTarget, enabled bool, retention int, retentionCount int, start, tz string) error {
+	logger := SetupLogger(logLevel, cloudName).With("workflow", "subscribe-daily", "target", target.String())
 
 	p := policy.SnapshotPolicyDaily{
-		Enabled:       enabled,
-		RetentionDays: retention,
-		RetentionType: "time",
-		StartTime:     start,
-		TimeZone:      tz,
+		Enabled:        enabled,
+		RetentionDays:  retention,
+		RetentionType:  retentionTypeFor(retentionCount),
+		RetentionCount: retentionCount,
+		StartTime:      start,
+		TimeZone:       tz,
 	}
 
 	if err := p.Normalize(); err != nil {
@@ -64,20 +80,21 @@ func SubscribeVolumeDaily(cloudName, logLevel, volID string, enabled bool, reten
 		return err
 	}
 
-	return applySubscription(cloudName, logLevel, volID, p.ToOpenstackMetadata(), logger)
+	return applySubscription(cloudName, logLevel, target, p.ToOpenstackMetadata(), logger)
 }
 
 // SubscribeVolumeWeekly configures the Weekly policy on a volume.
-func SubscribeVolumeWeekly(cloudName, logLevel, volID string, enabled bool, retention int, start, tz, weekday string) error {
-	logger := SetupLogger(logLevel, cloudName).With("workflow", "subscribe-weekly", "volume_id", volID)
+func SubscribeVolumeWeekly(cloudName, logLevel string, target SubscriptionTarget, enabled bool, retention int, retentionCount int, start, tz, weekday string) error {
+	logger := SetupLogger(logLevel, cloudName).With("workflow", "subscribe-weekly", "target", target.String())
 
 	p := policy.SnapshotPolicyWeekly{
-		Enabled:       enabled,
-		RetentionDays: retention,
-		RetentionType: "count",
-		StartTime:     start,
-		TimeZone:      tz,
-		DayOfWeek:     weekday,
+		Enabled:        enabled,
+		RetentionDays:  retention,
+		RetentionType:  retentionTypeFor(retentionCount),
+		RetentionCount: retentionCount,
+		StartTime:      start,
+		TimeZone:       tz,
+		DayOfWeek:      weekday,
 	}
 
 	if err := p.Normalize(); err != nil {
@@ -85,20 +102,42 @@ func SubscribeVolumeWeekly(cloudName, logLevel, volID string, enabled bool, rete
 		return err
 	}
 
-	return applySubscription(cloudName, logLevel, volID, p.ToOpenstackMetadata(), logger)
+	return applySubscription(cloudName, logLevel, target, p.ToOpenstackMetadata(), logger)
 }
 
 // SubscribeVolumeMonthly configures the Monthly policy on a volume.
-func SubscribeVolumeMonthly(cloudName, logLevel, volID string, enabled bool, retention int, start, tz string, day int) error {
-	logger := SetupLogger(logLevel, cloudName).With("workflow", "subscribe-monthly", "volume_id", volID)
+func SubscribeVolumeMonthly(cloudName, logLevel string, target SubscriptionTarget, enabled bool, retention int, retentionCount int, start, tz string, day int) error {
+	logger := SetupLogger(logLevel, cloudName).With("workflow", "subscribe-monthly", "target", target.String())
 
 	p := policy.SnapshotPolicyMonthly{
-		Enabled:       enabled,
-		RetentionDays: retention,
-		RetentionType: "count",
-		StartTime:     start,
-		TimeZone:      tz,
-		DayOfMonth:    day,
+		Enabled:        enabled,
+		RetentionDays:  retention,
+		RetentionType:  retentionTypeFor(retentionCount),
+		RetentionCount: retentionCount,
+		StartTime:      start,
+		TimeZone:       tz,
+		DayOfMonth:     day,
+	}
+
+	if err := p.Normalize(); err != nil {
+		logger.Error("Invalid policy configuration", "error", err)
+		return err
+	}
+
+	return applySubscription(cloudName, logLevel, target, p.ToOpenstackMetadata(), logger)
+}
+
+// SubscribeVolumeCron configures the Cron policy on a volume.
+func SubscribeVolumeCron(cloudName, logLevel string, target SubscriptionTarget, enabled bool, retention int, retentionCount int, expression, tz string) error {
+	logger := SetupLogger(logLevel, cloudName).With("workflow", "subscribe-cron", "target", target.String())
+
+	p := policy.SnapshotPolicyCron{
+		Enabled:        enabled,
+		RetentionDays:  retention,
+		RetentionType:  retentionTypeFor(retentionCount),
+		RetentionCount: retentionCount,
+		Expression:     expression,
+		TimeZone:       tz,
 	}
 
 	if err := p.Normalize(); err != nil {
@@ -106,23 +145,171 @@ func SubscribeVolumeMonthly(cloudName, logLevel, volID string, enabled bool, ret
 		return err
 	}
 
-	return applySubscription(cloudName, logLevel, volID, p.ToOpenstackMetadata(), logger)
+	return applySubscription(cloudName, logLevel, target, p.ToOpenstackMetadata(), logger)
 }
 
-// applySubscription handles the actual API call to update the volume metadata.
-func applySubscription(cloudName, logLevel, volID string, metadata map[string]string, logger interface {
-	Info(string, ...interface{})
-	Error(string, ...interface{})
-}) error {
+// SubscribeVolumeGFS enables or disables GFS promotion across the Daily/Weekly/Monthly policies of a volume.
+func SubscribeVolumeGFS(cloudName, logLevel string, target SubscriptionTarget, enabled bool) error {
+	logger := SetupLogger(logLevel, cloudName).With("workflow", "subscribe-gfs", "target", target.String())
+
+	g := policy.GFSConfig{
+		Enabled: enabled,
+	}
+
+	return applySubscription(cloudName, logLevel, target, g.ToOpenstackMetadata(), logger)
+}
+
+// SubscribeVolumeGroupSnapshot opts a volume in or out of group snapshots with the other volumes of its server.
+func SubscribeVolumeGroupSnapshot(cloudName, logLevel string, target SubscriptionTarget, enabled bool, groupType string) error {
+	logger := SetupLogger(logLevel, cloudName).With("workflow", "subscribe-group-snapshot", "target", target.String())
+
+	g := policy.GroupSnapshotConfig{
+		Enabled:   enabled,
+		GroupType: groupType,
+	}
+
+	return applySubscription(cloudName, logLevel, target, g.ToOpenstackMetadata(), logger)
+}
+
+// SubscribeVolumeHooks configures the quiesce hooks run around the snapshots of the volume's server.
+// Disabling removes both hooks.
+func SubscribeVolumeHooks(cloudName, logLevel string, target SubscriptionTarget, enabled bool, pre, post string, timeoutSeconds int) error {
+	logger := SetupLogger(logLevel, cloudName).With("workflow", "subscribe-hooks", "target", target.String())
+
+	h := policy.QuiesceHookConfig{
+		Pre:            pre,
+		Post:           post,
+		TimeoutSeconds: timeoutSeconds,
+	}
+	if !enabled {
+		h = policy.QuiesceHookConfig{}
+	} else if !h.IsConfigured() {
+		return fmt.Errorf("at least one of the pre and post hooks is required")
+	}
+
+	return applySubscription(cloudName, logLevel, target, h.ToOpenstackMetadata(), logger)
+}
+
+// SubscribeVolumeBackup configures the export of a policy's snapshots to Cinder backups.
+func SubscribeVolumeBackup(cloudName, logLevel string, target SubscriptionTarget, enabled bool, policyType string, retentionDays int, incremental bool, container string) error {
+	logger := SetupLogger(logLevel, cloudName).With("workflow", "subscribe-backup", "target", target.String())
+
+	b := policy.BackupConfig{
+		Enabled:       enabled,
+		PolicyType:    policyType,
+		RetentionDays: retentionDays,
+		Incremental:   incremental,
+		Container:     container,
+	}
+	if enabled {
+		if err := b.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return applySubscription(cloudName, logLevel, target, b.ToOpenstackMetadata(), logger)
+}
+
+// SubscribeVolumePause pauses snapshot creation for a volume, until the given time or (zero) until it is resumed.
+// Disabling resumes the volume.
+func SubscribeVolumePause(cloudName, logLevel string, target SubscriptionTarget, enabled bool, until time.Time, reason string) error {
+	logger := SetupLogger(logLevel, cloudName).With("workflow", "subscribe-pause", "target", target.String())
+
+	p := policy.PauseConfig{
+		Paused: enabled,
+		Until:  until,
+		Reason: reason,
+	}
+	if !enabled {
+		p = policy.PauseConfig{}
+	} else if !until.IsZero() && !until.After(time.Now()) {
+		return fmt.Errorf("pause end %s is in the past", until.Format(time.RFC3339))
+	}
+
+	return applySubscription(cloudName, logLevel, target, p.ToOpenstackMetadata(), logger)
+}
+
+// UnsubscribeVolume removes the named settings (see policy.SubscriptionKeys) from a volume, or every setting
+// with "all". Once no setting is left, the managed tag is removed as well, so the volume is no longer
+// discovered. Existing snapshots are untouched and still expire according to their own metadata.
+func UnsubscribeVolume(cloudName, logLevel, volID string, settings []string) error {
+	logger := SetupLogger(logLevel, cloudName).With("workflow", "unsubscribe", "volume_id", volID)
+
+	if slices.Contains(settings, "all") {
+		settings = policy.SubscriptionNames()
+	}
+	for _, name := range settings {
+		if _, ok := policy.SubscriptionKeys[name]; !ok {
+			return fmt.Errorf("unknown policy or setting '%s'; must be 'all' or one of %v", name, policy.SubscriptionNames())
+		}
+	}
+
+	client, err := initClient(cloudName, logLevel)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	vol, err := client.GetVolume(ctx, volID)
+	if err != nil {
+		logger.Error("Failed to fetch volume", "error", err)
+		return err
+	}
+
+	// Only delete the keys present on the volume; the remaining metadata tells whether the volume is still managed.
+	remaining := maps.Clone(vol.Metadata)
+	var keys []string
+	for _, name := range settings {
+		for _, key := range policy.SubscriptionMetadataKeys(name) {
+			if _, ok := remaining[key]; ok {
+				keys = append(keys, key)
+				delete(remaining, key)
+			}
+		}
+	}
+	if _, ok := remaining[policy.ManagedTag]; ok && !policy.HasSubscription(remaining) {
+		keys = append(keys, policy.ManagedTag)
+	}
+
+	if len(keys) == 0 {
+		logger.Info("Volume has none of the settings; nothing to remove", "settings", settings)
+		return nil
+	}
+
+	logger.Info("Removing subscription from volume", "settings", settings, "key_count", len(keys))
+	reqID, err := client.DeleteVolumeMetadataKeys(ctx, volID, keys)
+	if err != nil {
+		logger.Error("Failed to remove volume metadata", "error", err, "request_id", reqID)
+		return err
+	}
+
+	logger.Info("Subscription removed successfully", "request_id", reqID, "still_managed", !slices.Contains(keys, policy.ManagedTag))
+	return nil
+}
+
+// applySubscription writes the policy tags to the target volume, or to every volume matched by its selector.
+func applySubscription(cloudName, logLevel string, target SubscriptionTarget, metadata map[string]string, logger *slog.Logger) error {
+	if err := target.Validate(); err != nil {
+		return err
+	}
+
 	client, err := initClient(cloudName, logLevel)
 	if err != nil {
 		return err
 	}
 
+	if !target.Selector.IsEmpty() {
+		out := target.Output
+		if out == nil {
+			out = os.Stdout
+		}
+		return applyBulkSubscription(context.Background(), client, out, target, metadata, logger)
+	}
+
 	logger.Info("Applying subscription policy to volume")
 
 	// CreateVolumeSubscription handles fetching existing metadata and merging the new tags.
-	_, reqID, err := client.CreateVolumeSubscription(context.Background(), volID, metadata)
+	_, reqID, err := client.CreateVolumeSubscription(context.Background(), target.VolumeID, metadata)
 	if err != nil {
 		logger.Error("Failed to update volume metadata", "error", err)
 		return err
@@ -58,14 +58,16 @@ create_property x-snapsentry-managed "Enable SnapSentry" boolean \
 # Daily schedule
 create_property x-snapsentry-daily-enabled "Enable Daily Schedule" boolean '{"default":false}'
 create_property x-snapsentry-daily-retention-days "Daily Retention (Days)" integer '{"minimum":1,"default":1}'
-create_property x-snapsentry-daily-retention-type "Daily Retention Logic" string '{"enum":["time"],"default":"time"}'
+create_property x-snapsentry-daily-retention-type "Daily Retention Logic" string '{"enum":["time","count"],"default":"time"}'
+create_property x-snapsentry-daily-retention-count "Daily Retention (Snapshot Count)" integer '{"minimum":0,"default":0}'
 create_property x-snapsentry-daily-timezone "Daily Timezone" string '{"default":"UTC"}'
 create_property x-snapsentry-daily-start-time "Daily Start Time" string '{"pattern":"^([0-1]?[0-9]|2[0-3]):[0-5][0-9]$","default":"00:00"}'
 
 # Weekly schedule
 create_property x-snapsentry-weekly-enabled "Enable Weekly Schedule" boolean '{"default":false}'
 create_property x-snapsentry-weekly-retention-days "Weekly Retention (Days)" integer '{"minimum":7,"default":7}'
-create_property x-snapsentry-weekly-retention-type "Weekly Retention Logic" string '{"enum":["time"],"default":"time"}'
+create_property x-snapsentry-weekly-retention-type "Weekly Retention Logic" string '{"enum":["time","count"],"default":"time"}'
+create_property x-snapsentry-weekly-retention-count "Weekly Retention (Snapshot Count)" integer '{"minimum":0,"default":0}'
 create_property x-snapsentry-weekly-timezone "Weekly Timezone" string '{"default":"UTC"}'
 create_property x-snapsentry-weekly-start-time "Weekly Start Time" string '{"pattern":"^([0-1]?[0-9]|2[0-3]):[0-5][0-9]$","default":"00:00"}'
 create_property x-snapsentry-weekly-start-day-of-week "Weekly Day of Week" string '{"enum":["monday","tuesday","wednesday","thursday","friday","saturday","sunday"],"default":"sunday"}'
@@ -73,16 +75,34 @@ create_property x-snapsentry-weekly-start-day-of-week "Weekly Day of Week" strin
 # Monthly schedule
 create_property x-snapsentry-monthly-enabled "Enable Monthly Schedule" boolean '{"default":false}'
 create_property x-snapsentry-monthly-retention-days "Monthly Retention (Days)" integer '{"minimum":31,"default":31}'
-create_property x-snapsentry-monthly-retention-type "Monthly Retention Logic" string '{"enum":["time"],"default":"time"}'
+create_property x-snapsentry-monthly-retention-type "Monthly Retention Logic" string '{"enum":["time","count"],"default":"time"}'
+create_property x-snapsentry-monthly-retention-count "Monthly Retention (Snapshot Count)" integer '{"minimum":0,"default":0}'
 create_property x-snapsentry-monthly-timezone "Monthly Timezone" string '{"default":"UTC"}'
 create_property x-snapsentry-monthly-start-time "Monthly Start Time" string '{"pattern":"^([0-1]?[0-9]|2[0-3]):[0-5][0-9]$","default":"00:00"}'
 create_property x-snapsentry-monthly-start-day-of-month "Monthly Day of Month" integer '{"minimum":1,"maximum":31,"default":1}'
 
 # Express schedule
 create_property x-snapsentry-express-enabled "Enable Express Schedule" boolean '{"default":false}'
-create_property x-snapsentry-express-interval-hours "Express Interval (Hours)" string '{"enum":["6","8","12"],"default":"6"}'
+create_property x-snapsentry-express-interval-hours "Express Interval (Hours)" string '{"enum":["0","1","2","3","4","6","8","12"],"default":"6"}'
+create_property x-snapsentry-express-interval-minutes "Express Interval (Minutes)" integer \
+    '{"description":"Optional minute based interval (e.g. 15, 30). Must divide 24 hours and overrides the hour interval when set.","minimum":0,"default":0}'
+create_property x-snapsentry-express-start-time "Express Anchor Time" string '{"pattern":"^([0-1]?[0-9]|2[0-3]):[0-5][0-9]$","default":"00:00"}'
 create_property x-snapsentry-express-retention-days "Express Retention (Days)" integer '{"minimum":1,"default":1}'
-create_property x-snapsentry-express-retention-type "Express Retention Logic" string '{"enum":["time"],"default":"time"}'
+create_property x-snapsentry-express-retention-type "Express Retention Logic" string '{"enum":["time","count"],"default":"time"}'
+create_property x-snapsentry-express-retention-count "Express Retention (Snapshot Count)" integer '{"minimum":0,"default":0}'
 create_property x-snapsentry-express-timezone "Express Timezone" string '{"default":"UTC"}'
 
+# Cron schedule
+create_property x-snapsentry-cron-enabled "Enable Cron Schedule" boolean '{"default":false}'
+create_property x-snapsentry-cron-expression "Cron Expression" string \
+    '{"description":"Standard 5-field cron expression (minute hour day-of-month month day-of-week), e.g. 0 2,14 * * 1-5."}'
+create_property x-snapsentry-cron-retention-days "Cron Retention (Days)" integer '{"minimum":1,"default":7}'
+create_property x-snapsentry-cron-retention-type "Cron Retention Logic" string '{"enum":["time","count"],"default":"time"}'
+create_property x-snapsentry-cron-retention-count "Cron Retention (Snapshot Count)" integer '{"minimum":0,"default":0}'
+create_property x-snapsentry-cron-timezone "Cron Timezone" string '{"default":"UTC"}'
+
+# Grandfather-Father-Son
+create_property x-snapsentry-gfs-enabled "Enable GFS Promotion" boolean \
+    '{"description":"If set to true, daily/weekly/monthly policies share one snapshot per window and promote it instead of creating duplicates.","default":false}'
+
 echo "Setup completed successfully!"